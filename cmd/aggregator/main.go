@@ -1,22 +1,32 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"moto-news/internal/config"
 	"moto-news/internal/server"
 	"moto-news/internal/service"
 	"moto-news/internal/storage"
+	"moto-news/internal/tracing"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfgFile string
-	cfg     *config.Config
-	store   *storage.SQLiteStorage
-	svc     *service.Service
+	cfgFile         string
+	cfg             *config.Config
+	store           *storage.SQLiteStorage
+	svc             *service.Service
+	shutdownTracing = func() {}
 )
 
 func main() {
@@ -26,6 +36,24 @@ func main() {
 	}
 }
 
+// configOverrides lists, per command, the flags that override config keys
+// for that invocation (flag > env > file > default)
+var configOverrides = map[string][]config.FlagOverride{
+	"translate": {
+		{Flag: "provider", Key: "translator.provider"},
+		{Flag: "model", Key: "translator.{provider}.model"},
+		{Flag: "host", Key: "translator.{provider}.host"},
+	},
+	"translate-test": {
+		{Flag: "provider", Key: "translator.provider"},
+		{Flag: "model", Key: "translator.{provider}.model"},
+		{Flag: "host", Key: "translator.{provider}.host"},
+	},
+	"publish": {
+		{Flag: "branch", Key: "hugo.git_branch"},
+	},
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "aggregator",
 	Short: "Moto News Aggregator - парсинг, перевод и публикация мотоновостей",
@@ -41,20 +69,29 @@ var rootCmd = &cobra.Command{
 		}
 
 		var err error
-		cfg, err = config.Load(cfgFile)
+		cfg, err = config.LoadWithOverrides(cfgFile, cmd.Flags(), configOverrides[cmd.Name()])
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		store, err = storage.NewSQLiteStorage(cfg.Database.Path)
+		// config subcommands only inspect the loaded config
+		if cmd.Parent() == configCmd {
+			return nil
+		}
+
+		shutdownTracing = tracing.Init(&cfg.Tracing, printProgress)
+
+		store, err = storage.NewSQLiteStorage(&cfg.Database)
 		if err != nil {
 			return fmt.Errorf("failed to open database: %w", err)
 		}
 
 		svc = service.NewService(cfg, store)
+		svc.SetProgress(printProgress)
 		return nil
 	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		shutdownTracing()
 		if store != nil {
 			store.Close()
 		}
@@ -65,27 +102,93 @@ var fetchCmd = &cobra.Command{
 	Use:   "fetch",
 	Short: "Получить новые статьи из RSS фидов",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		result, err := svc.Fetch()
+		source, _ := cmd.Flags().GetString("source")
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return runFetchPreview(source, asJSON)
+		}
+
+		result, err := svc.Fetch(source)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("\nDone! New: %d, Skipped: %d, Errors: %d\n",
-			result.NewArticles, result.SkippedArticles, result.Errors)
+		fmt.Printf("\nDone! New: %d, Skipped: %d, Filtered: %d, Errors: %d\n",
+			result.NewArticles, result.SkippedArticles, result.Filtered, result.Errors)
+		if result.NotHTML > 0 {
+			fmt.Printf("Skipped %d links that are not HTML pages (PDF, images...)\n", result.NotHTML)
+		}
+		if result.FeedsFailed > 0 {
+			fmt.Printf("%d of %d feeds failed:\n", result.FeedsFailed, result.FeedsTotal)
+			for _, fr := range result.FailedFeeds() {
+				fmt.Printf("  ✗ %s (%s): %s\n", fr.URL, fr.Source, fr.Error)
+			}
+		}
+		for _, name := range result.Disabled {
+			fmt.Printf("Source %s disabled after repeated failures (enable-source %s to re-enable)\n", name, name)
+		}
 		return nil
 	},
 }
 
+// runFetchPreview prints what fetch would ingest without scraping or saving
+func runFetchPreview(source string, asJSON bool) error {
+	preview, err := svc.FetchPreview(source)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		out, err := json.MarshalIndent(preview, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	for _, fr := range preview.FeedResults {
+		if fr.Error != "" {
+			fmt.Printf("✗ %s (%s): %s\n", fr.URL, fr.Source, fr.Error)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tSOURCE\tTITLE\tURL\tFEED")
+	for _, item := range preview.New {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			item.PublishedAt.Format("2006-01-02"), item.Source, truncate(item.Title, 60), item.URL, item.Feed)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\nDry run: %d new, %d already in DB, %d filtered (nothing saved)\n", len(preview.New), preview.Existing, preview.Filtered)
+	return nil
+}
+
 var translateCmd = &cobra.Command{
 	Use:   "translate",
 	Short: "Перевести непереведённые статьи",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		limit, _ := cmd.Flags().GetInt("limit")
-		result, err := svc.Translate(limit)
+		resume, _ := cmd.Flags().GetBool("resume")
+		var result *service.TranslateResult
+		var err error
+		if resume {
+			result, err = svc.TranslateResume()
+		} else {
+			result, err = svc.Translate(limit)
+		}
 		if err != nil {
 			return err
 		}
 		fmt.Printf("\nTranslated %d of %d articles (errors: %d)\n",
 			result.Translated, result.Total, result.Errors)
+		if result.PublishStatus == service.PublishFailed || result.PublishStatus == service.PublishPartial {
+			fmt.Printf("Publish %s: %s (translations are saved, articles stay in the publish queue)\n", result.PublishStatus, result.PublishError)
+		}
+		if result.JobID != 0 {
+			fmt.Printf("Job #%d\n", result.JobID)
+		}
 		return nil
 	},
 }
@@ -95,12 +198,37 @@ var publishCmd = &cobra.Command{
 	Short: "Опубликовать переведённые статьи в Hugo блог",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		limit, _ := cmd.Flags().GetInt("limit")
-		result, err := svc.Publish(limit)
-		if err != nil {
-			return err
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		date, _ := cmd.Flags().GetString("date")
+
+		var result *service.PublishResult
+		if date != "" {
+			from, to, err := svc.ParseDateRange(date)
+			if err != nil {
+				return err
+			}
+			result, err = svc.PublishRange(from, to)
+			if err != nil {
+				return err
+			}
+		} else {
+			var err error
+			result, err = svc.Publish(limit, refresh)
+			if err != nil {
+				return err
+			}
 		}
 		fmt.Printf("\nPublished %d of %d articles (errors: %d)\n",
 			result.Published, result.Total, result.Errors)
+		if refresh {
+			fmt.Printf("Re-rendered %d stale articles\n", result.Refreshed)
+		}
+		if result.Commits > 1 {
+			fmt.Printf("Pushed in %d commits (hugo.max_files_per_commit)\n", result.Commits)
+		}
+		if result.Skipped > 0 {
+			fmt.Printf("Held back %d articles (hugo.require_full_translation, hugo.require_review or schedule.publish_delay)\n", result.Skipped)
+		}
 		return nil
 	},
 }
@@ -109,7 +237,8 @@ var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Выполнить полный цикл: fetch -> translate -> publish",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("=== Starting full pipeline ===\n")
+		fmt.Println("=== Starting full pipeline ===")
+		fmt.Println()
 		result, err := svc.Run()
 		if err != nil {
 			return err
@@ -144,6 +273,66 @@ var statsCmd = &cobra.Command{
 		fmt.Printf("Published to Hugo:   %d\n", stats.Published)
 		fmt.Printf("Pending translation: %d\n", stats.Pending)
 		fmt.Printf("Pending publishing:  %d\n", stats.Unpublished)
+		since := "-"
+		if stats.TranslatedCharsSince != nil {
+			since = stats.TranslatedCharsSince.Format("2006-01-02")
+		}
+		fmt.Printf("Translated chars:    %d this month, %d total (since %s)\n", stats.TranslatedCharsMonth, stats.TranslatedChars, since)
+
+		if len(stats.Sources) > 0 {
+			fmt.Println("\n=== Sources ===")
+			for _, src := range stats.Sources {
+				last := "never fetched"
+				if src.LastFetchedAt != nil {
+					last = fmt.Sprintf("last fetch %s: +%d", src.LastFetchedAt.Format("2006-01-02 15:04"), src.LastNew)
+				}
+				disabled := ""
+				if !src.Enabled {
+					disabled = " [disabled]"
+				}
+				fmt.Printf("%s%s: %d new in last 24h (%s, %d total)\n", src.Name, disabled, src.RecentNew, last, src.TotalNew)
+			}
+		}
+		return nil
+	},
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Показать последние статьи таблицей",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+		status, _ := cmd.Flags().GetString("status")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		articles, err := svc.ListArticles(status, limit)
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			out, err := json.MarshalIndent(articles, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tDATE\tSTATUS\tTITLE")
+		for _, a := range articles {
+			title := a.TitleRU
+			if title == "" {
+				title = a.Title
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n",
+				a.ID, a.PublishedAt.Format("2006-01-02"), a.Status, truncate(title, 70))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d articles\n", len(articles))
 		return nil
 	},
 }
@@ -152,12 +341,454 @@ var rescrapeCmd = &cobra.Command{
 	Use:   "rescrape",
 	Short: "Повторно загрузить контент для статей с пустым содержимым",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if changed, _ := cmd.Flags().GetBool("changed"); changed {
+			result, err := svc.RecheckContent()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\nRe-checked %d articles: changed %d (queued for re-translation), minor %d, unchanged %d, errors %d\n",
+				result.Total, result.Changed, result.Minor, result.Unchanged, result.Errors)
+			return nil
+		}
+
 		result, err := svc.Rescrape()
 		if err != nil {
 			return err
 		}
-		fmt.Printf("\nRe-scraped %d of %d articles (errors: %d)\n",
-			result.Rescraped, result.Total, result.Errors)
+		fmt.Printf("\nRe-scraped %d of %d articles (unchanged: %d, errors: %d)\n",
+			result.Rescraped, result.Total, result.Unchanged, result.Errors)
+		if len(result.PermanentlyFailed) > 0 {
+			fmt.Printf("%d articles gave up and need manual review:\n", len(result.PermanentlyFailed))
+			for _, f := range result.PermanentlyFailed {
+				fmt.Printf("  id=%d %s\n", f.ID, f.URL)
+			}
+		}
+		return nil
+	},
+}
+
+var cleanContentCmd = &cobra.Command{
+	Use:   "clean-content [id...]",
+	Short: "Заново очистить сохранённый контент статей по текущим правилам (без сети)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, _ := cmd.Flags().GetString("source")
+		requeue, _ := cmd.Flags().GetBool("retranslate")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		var ids []int64
+		for _, arg := range args {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid article id %q", arg)
+			}
+			ids = append(ids, id)
+		}
+
+		result, err := svc.CleanContent(ids, source, requeue, dryRun)
+		if err != nil {
+			return err
+		}
+		for _, a := range result.Articles {
+			fmt.Printf("  id=%d %d -> %d chars  %s\n", a.ID, a.Before, a.After, a.Title)
+		}
+		fmt.Printf("\nChanged %d of %d articles", result.Changed, result.Checked)
+		if dryRun {
+			fmt.Print(" (dry run, nothing saved)")
+		}
+		fmt.Println()
+		if result.NoRaw > 0 {
+			fmt.Printf("%d articles have no raw content (scraped before it was kept); use rescrape\n", result.NoRaw)
+		}
+		return nil
+	},
+}
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Пересчитать производные данные статей (статус, изображения) и индексы БД после обновления",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var kinds []string
+		for _, kind := range service.ReindexKinds() {
+			if on, _ := cmd.Flags().GetBool(kind); on {
+				kinds = append(kinds, kind)
+			}
+		}
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		resume, _ := cmd.Flags().GetBool("resume")
+
+		result, err := svc.Reindex(kinds, batchSize, resume)
+		if result != nil && result.JobID != 0 && err != nil {
+			fmt.Printf("Stopped: continue with reindex --resume (job #%d)\n", result.JobID)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nChecked %d articles (%d of %d in job #%d)\n", result.Checked, result.Done, result.Total, result.JobID)
+		for _, kind := range result.Kinds {
+			if kind == service.ReindexIndexes {
+				fmt.Println("  indexes: rebuilt")
+				continue
+			}
+			fmt.Printf("  %s: %d updated\n", kind, result.Changed[kind])
+		}
+		return nil
+	},
+}
+
+var regenerateCmd = &cobra.Command{
+	Use:   "regenerate",
+	Short: "Пересобрать markdown всех опубликованных статей из БД в локальную директорию",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, _ := cmd.Flags().GetString("out")
+		all, _ := cmd.Flags().GetBool("all")
+		result, err := svc.Regenerate(out, all)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nRegenerated %d of %d articles into %s (errors: %d)\n",
+			result.Written, result.Total, result.OutDir, result.Errors)
+		if result.IndexPath != "" {
+			fmt.Printf("Index: %s", result.IndexPath)
+			if result.IndexPages > 1 {
+				fmt.Printf(" (+%d archive pages)", result.IndexPages-1)
+			}
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Удалить статьи старше заданного срока",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, _ := cmd.Flags().GetString("older-than")
+		publishedOnly, _ := cmd.Flags().GetBool("published-only")
+		deleteFiles, _ := cmd.Flags().GetBool("delete-files")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		age, err := parseRetention(olderThan)
+		if err != nil {
+			return err
+		}
+		cutoff := time.Now().Add(-age)
+
+		candidates, err := svc.PruneCandidates(cutoff, publishedOnly)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			fmt.Printf("No articles published before %s\n", cutoff.Format("2006-01-02"))
+			return nil
+		}
+
+		if !yes {
+			what := "articles"
+			if publishedOnly {
+				what = "published articles"
+			}
+			fmt.Printf("Delete %d %s published before %s", len(candidates), what, cutoff.Format("2006-01-02"))
+			if deleteFiles {
+				fmt.Print(" (and their blog files)")
+			}
+			fmt.Print("? [y/N] ")
+			var answer string
+			fmt.Scanln(&answer)
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+				fmt.Println("Aborted")
+				return nil
+			}
+		}
+
+		result, err := svc.Prune(cutoff, publishedOnly, deleteFiles)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Deleted %d articles", result.Deleted)
+		if deleteFiles {
+			fmt.Printf(", %d blog files", result.FilesDeleted)
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+var verifyPublishedCmd = &cobra.Command{
+	Use:   "verify-published",
+	Short: "Проверить, что файлы опубликованных статей есть в репозитории блога",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reset, _ := cmd.Flags().GetBool("reset")
+
+		result, err := svc.VerifyPublished(reset)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range result.Missing {
+			fmt.Printf("  missing [%d] %s\n        %s\n", m.ID, m.Title, m.Path)
+		}
+		fmt.Printf("Checked %d published articles (%s): %d missing", result.Checked, result.Target, len(result.Missing))
+		if reset {
+			fmt.Printf(", %d reset for re-publishing", result.Reset)
+		} else if len(result.Missing) > 0 {
+			fmt.Print(" (use --reset to re-publish them)")
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// parseRetention parses a retention period: Go durations (720h) plus a
+// day suffix (365d)
+func parseRetention(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid retention %q: expected e.g. 365d or 720h", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid retention %q: expected e.g. 365d or 720h", s)
+	}
+	return d, nil
+}
+
+var checkFeedsCmd = &cobra.Command{
+	Use:   "check-feeds",
+	Short: "Проверить все фиды источников: ошибки, пустые и давно не обновлявшиеся",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		staleDays, _ := cmd.Flags().GetInt("stale-days")
+		if staleDays <= 0 {
+			return fmt.Errorf("--stale-days must be > 0")
+		}
+
+		result, err := svc.CheckFeeds(staleDays)
+		if err != nil {
+			return err
+		}
+
+		for _, src := range result.Sources {
+			label := src.Name
+			if src.Critical {
+				label += " (critical)"
+			}
+			if src.Down {
+				label += " — DOWN"
+			}
+			fmt.Println(label)
+			for _, f := range src.Feeds {
+				switch {
+				case f.Down():
+					fmt.Printf("  ✗ %s [%s] %s\n", f.URL, f.Status, f.Error)
+				case f.Newest == nil:
+					fmt.Printf("  ✓ %s — %d items, no dates\n", f.URL, f.Items)
+				default:
+					mark := "✓"
+					if f.Newest.Before(time.Now().AddDate(0, 0, -staleDays)) {
+						mark = "!"
+					}
+					fmt.Printf("  %s %s — %d items, newest %s\n", mark, f.URL, f.Items, f.Newest.Format("2006-01-02"))
+				}
+			}
+			if src.Stale {
+				fmt.Printf("  ! no new articles in %d days (last fetch %s)\n", staleDays, src.LastFetchedAt.Format("2006-01-02 15:04"))
+			}
+		}
+
+		fmt.Printf("\nFeeds down: %d, stale feeds: %d (newest item older than %d days)\n", result.FeedsDown, result.StaleFeeds, staleDays)
+		if len(result.CriticalDown) > 0 {
+			return fmt.Errorf("critical sources down: %s", strings.Join(result.CriticalDown, ", "))
+		}
+		return nil
+	},
+}
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover <site-url>",
+	Short: "Найти RSS/Atom фиды на сайте и проверить их",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		add, _ := cmd.Flags().GetBool("add")
+		name, _ := cmd.Flags().GetString("name")
+
+		feeds, err := svc.DiscoverFeeds(args[0])
+		if err != nil {
+			return err
+		}
+
+		var valid []string
+		for _, f := range feeds {
+			if f.Error != "" {
+				fmt.Printf("✗ %s (%s): %s\n", f.URL, f.Source, f.Error)
+				continue
+			}
+			fmt.Printf("✓ %s — %s [%s, %d items, %s]\n", f.URL, f.Title, f.Type, f.Items, f.Source)
+			valid = append(valid, f.URL)
+		}
+		if len(valid) == 0 {
+			return fmt.Errorf("no feeds found on %s", args[0])
+		}
+
+		if name == "" {
+			name = service.SourceNameFromURL(args[0])
+		}
+		src := config.SourceConfig{Name: name, Feeds: valid, Enabled: true}
+		if !add {
+			fmt.Println("\nAdd to config.yaml (or re-run with --add):")
+			fmt.Printf("  - name: %s\n    feeds:\n", src.Name)
+			for _, u := range src.Feeds {
+				fmt.Printf("      - %s\n", u)
+			}
+			fmt.Println("    enabled: true")
+			return nil
+		}
+
+		path, err := config.AddSource(src)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nAdded source %q with %d feeds to %s\n", src.Name, len(src.Feeds), path)
+		return nil
+	},
+}
+
+var reprocessCmd = &cobra.Command{
+	Use:   "reprocess <id>",
+	Short: "Заново скачать, перевести и опубликовать одну статью",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid article id %q", args[0])
+		}
+		result, err := svc.Reprocess(id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n#%d %s -> %s (status: %s)\n", result.ID, result.Title, orDash(result.TitleRU), result.Status)
+		for _, st := range result.Steps {
+			fmt.Printf("  %-10s %-8s %s\n", st.Step, st.Status, strings.TrimSpace(st.Error+" "+st.Detail))
+		}
+		if !result.Success {
+			return fmt.Errorf("reprocess of article %d did not complete", id)
+		}
+		return nil
+	},
+}
+
+var translateTestCmd = &cobra.Command{
+	Use:   "translate-test",
+	Short: "Перевести произвольный текст текущим переводчиком без записи в БД (подбор промпта)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := service.TranslateTestRequest{}
+		req.Text, _ = cmd.Flags().GetString("text")
+		req.Title, _ = cmd.Flags().GetBool("title")
+		req.Prompt, _ = cmd.Flags().GetString("prompt")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if cmd.Flags().Changed("temperature") {
+			t, _ := cmd.Flags().GetFloat64("temperature")
+			req.Temperature = &t
+		}
+
+		result, err := svc.TranslateTest(context.Background(), req)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			out, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+		fmt.Println(result.Output)
+		if result.RawOutput != "" {
+			fmt.Printf("\n--- before postprocess ---\n%s\n", result.RawOutput)
+		}
+		model := ""
+		if result.Model != "" {
+			model = fmt.Sprintf(", model %s, temperature %g", result.Model, result.Temperature)
+		}
+		fmt.Printf("\n%s%s: %d -> %d characters in %d ms\n", result.Provider, model, result.InputChars, result.OutputChars, result.DurationMs)
+		return nil
+	},
+}
+
+var reviewCmd = &cobra.Command{
+	Use:   "review <id...>",
+	Short: "Отметить статьи как проверенные редактором (см. hugo.require_review)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		unset, _ := cmd.Flags().GetBool("unset")
+
+		var ids []int64
+		for _, arg := range args {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid article id %q", arg)
+			}
+			ids = append(ids, id)
+		}
+		updated, err := store.SetReviewed(ids, !unset)
+		if err != nil {
+			return err
+		}
+		state := "reviewed"
+		if unset {
+			state = "not reviewed"
+		}
+		fmt.Printf("%d of %d articles marked %s\n", updated, len(ids), state)
+		if updated < int64(len(ids)) {
+			return fmt.Errorf("%d articles not found", int64(len(ids))-updated)
+		}
+		return nil
+	},
+}
+
+var featureCmd = &cobra.Command{
+	Use:   "feature <id>",
+	Short: "Пометить статью как избранную (переводится и публикуется первой)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		unset, _ := cmd.Flags().GetBool("unset")
+
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid article id %q", args[0])
+		}
+		if err := store.SetFeatured(id, !unset); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("article %d not found", id)
+			}
+			return err
+		}
+		if unset {
+			fmt.Printf("Article %d is no longer featured\n", id)
+		} else {
+			fmt.Printf("Article %d is featured\n", id)
+		}
+		return nil
+	},
+}
+
+var enableSourceCmd = &cobra.Command{
+	Use:   "enable-source <name>",
+	Short: "Снова включить источник, отключённый после серии неудачных загрузок",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		enabled, err := svc.EnableSource(args[0])
+		if err != nil {
+			return err
+		}
+		if enabled {
+			fmt.Printf("Source %s re-enabled\n", args[0])
+		} else {
+			fmt.Printf("Source %s was not disabled\n", args[0])
+		}
 		return nil
 	},
 }
@@ -178,6 +809,126 @@ var pushCmd = &cobra.Command{
 	},
 }
 
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Обслуживание базы данных",
+}
+
+var dbInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Размер БД, число строк по таблицам, индексы и диапазон дат статей",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := store.Info()
+		if err != nil {
+			return err
+		}
+		warnIfDBInUse()
+
+		fmt.Println("=== Database Info ===")
+		fmt.Printf("File:        %s\n", cfg.Database.Path)
+		fmt.Printf("Size:        %s\n", formatBytes(fileSize(cfg.Database.Path)))
+		if wal := fileSize(cfg.Database.Path + "-wal"); wal > 0 {
+			fmt.Printf("WAL:         %s\n", formatBytes(wal))
+		}
+		fmt.Printf("Pages:       %d x %d bytes (free: %d, %s reclaimable)\n",
+			info.PageCount, info.PageSize, info.FreePages, formatBytes(info.FreePages*info.PageSize))
+		fmt.Printf("Articles:    %s .. %s\n", orDash(info.OldestArticle), orDash(info.NewestArticle))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\nTABLE\tROWS")
+		for _, t := range info.Tables {
+			fmt.Fprintf(w, "%s\t%d\n", t.Name, t.Rows)
+		}
+		fmt.Fprintln(w, "\nINDEX\tTABLE\tSIZE")
+		for _, idx := range info.Indexes {
+			size := "n/a"
+			if idx.Bytes >= 0 {
+				size = formatBytes(idx.Bytes)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", idx.Name, idx.Table, size)
+		}
+		return w.Flush()
+	},
+}
+
+var dbVacuumCmd = &cobra.Command{
+	Use:   "vacuum",
+	Short: "Выполнить VACUUM и освободить место на диске (лучше при остановленном сервере)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		inUse, err := store.InUse()
+		if err != nil {
+			return err
+		}
+		if inUse && !force {
+			return fmt.Errorf("database appears to be in use (server running?); stop it or pass --force")
+		}
+
+		before := fileSize(cfg.Database.Path) + fileSize(cfg.Database.Path+"-wal")
+		fmt.Println("Running VACUUM...")
+		if err := store.Vacuum(); err != nil {
+			return err
+		}
+		after := fileSize(cfg.Database.Path) + fileSize(cfg.Database.Path+"-wal")
+		fmt.Printf("Done: %s -> %s\n", formatBytes(before), formatBytes(after))
+		return nil
+	},
+}
+
+// warnIfDBInUse prints a warning when another process holds the database
+func warnIfDBInUse() {
+	if inUse, err := store.InUse(); err == nil && inUse {
+		fmt.Println("Warning: database appears to be in use by another process (server running?)")
+	}
+}
+
+// fileSize returns the size of path in bytes, 0 if it does not exist
+func fileSize(path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Работа с конфигурацией",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Показать итоговую конфигурацию (JSON, секреты скрыты)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, err := json.MarshalIndent(config.Describe(cfg), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
 var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "Запустить HTTP API сервер (Gin)",
@@ -188,30 +939,114 @@ var serverCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		store, err = storage.NewSQLiteStorage(cfg.Database.Path)
+		store, err = storage.NewSQLiteStorage(&cfg.Database)
 		if err != nil {
 			return fmt.Errorf("failed to open database: %w", err)
 		}
 		defer store.Close()
 
+		shutdownTracing = tracing.Init(&cfg.Tracing, printProgress)
+		defer shutdownTracing()
+
 		srv := server.New(cfg, store)
 		return srv.Run()
 	},
 }
 
+// printProgress prints the progress lines of the service and the tracing
+// warnings on the console
+func printProgress(format string, args ...any) {
+	fmt.Printf(format, args...)
+}
+
+// truncate shortens s to n runes, adding an ellipsis
+func truncate(s string, n int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= n {
+		return string(r)
+	}
+	return string(r[:n-1]) + "…"
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ./config.yaml)")
 
+	fetchCmd.Flags().Bool("dry-run", false, "list new articles without scraping or saving them")
+	fetchCmd.Flags().Bool("json", false, "with --dry-run: print the preview as JSON")
+	fetchCmd.Flags().String("source", "", "fetch only this source (by name)")
 	translateCmd.Flags().IntP("limit", "l", 10, "maximum number of articles to translate")
+	translateCmd.Flags().Bool("resume", false, "continue the last unfinished translate job (--limit is taken from the job)")
+	translateCmd.Flags().String("provider", "", "override translator.provider for this run (ollama, deepl, libretranslate, openrouter, google)")
+	translateCmd.Flags().String("model", "", "override the model of the selected provider (ollama, openrouter)")
+	translateCmd.Flags().String("host", "", "override the host of the selected provider (ollama, libretranslate)")
 	publishCmd.Flags().IntP("limit", "l", 100, "maximum number of articles to publish")
+	publishCmd.Flags().String("branch", "", "override hugo.git_branch for this run")
+	publishCmd.Flags().Bool("refresh", false, "also re-render published articles whose formatter fingerprint is stale")
+	publishCmd.Flags().String("date", "", "re-publish translated articles dated this day or range (2024-06-01 or 2024-06-01..2024-06-03), ignoring --limit and the published flag")
+	listCmd.Flags().IntP("limit", "l", 20, "maximum number of articles to show")
+	listCmd.Flags().StringP("status", "s", "all", "filter: all, untranslated, translated, unpublished, published, new, scraped, errored, stub")
+	listCmd.Flags().Bool("json", false, "print articles as JSON")
+	regenerateCmd.Flags().StringP("out", "o", "", "output directory (mirrors blog repo layout)")
+	regenerateCmd.Flags().Bool("all", false, "include translated but not yet published articles")
+	regenerateCmd.MarkFlagRequired("out")
+	dbVacuumCmd.Flags().Bool("force", false, "vacuum even if the database appears to be in use")
+	checkFeedsCmd.Flags().Int("stale-days", 14, "flag feeds and sources with nothing new for this many days")
+	discoverCmd.Flags().Bool("add", false, "append the discovered feeds as a new source to the config file")
+	discoverCmd.Flags().String("name", "", "source name for --add (default: derived from the site host)")
+	pruneCmd.Flags().String("older-than", "365d", "retention period (e.g. 365d, 720h)")
+	pruneCmd.Flags().Bool("published-only", false, "only delete articles already published (keep unpublished ones)")
+	pruneCmd.Flags().Bool("delete-files", false, "also delete the articles' markdown files from the blog repo")
+	pruneCmd.Flags().BoolP("yes", "y", false, "do not ask for confirmation")
+	featureCmd.Flags().Bool("unset", false, "clear the featured flag")
+	translateTestCmd.Flags().String("text", "", "text to translate (required)")
+	translateTestCmd.Flags().Bool("title", false, "translate the text as a title (title prompt)")
+	translateTestCmd.Flags().String("prompt", "", "one-off prompt instead of the configured one (ollama, openrouter)")
+	translateTestCmd.Flags().Float64("temperature", 0, "one-off temperature (ollama, openrouter)")
+	translateTestCmd.Flags().String("provider", "", "override translator.provider for this run")
+	translateTestCmd.Flags().String("model", "", "override the model of the selected provider (ollama, openrouter)")
+	translateTestCmd.Flags().String("host", "", "override the host of the selected provider (ollama, libretranslate)")
+	translateTestCmd.Flags().Bool("json", false, "print the result as JSON")
+	translateTestCmd.MarkFlagRequired("text")
+	reviewCmd.Flags().Bool("unset", false, "clear the reviewed flag (back to the review queue)")
+	cleanContentCmd.Flags().String("source", "", "only articles of this source (when no ids are given)")
+	rescrapeCmd.Flags().Bool("changed", false, "re-check recent articles against their source and update the changed ones (scraper.recheck_days)")
+	reindexCmd.Flags().Bool(service.ReindexStatus, false, "infer unknown statuses again and sync the published flag with the status")
+	reindexCmd.Flags().Bool(service.ReindexImages, false, "drop blank and repeated image URLs, set missing covers from the image list")
+	reindexCmd.Flags().Bool(service.ReindexTags, false, "add hugo.formatter.auto_tags tags to stored articles")
+	reindexCmd.Flags().Bool(service.ReindexIndexes, false, "rebuild SQLite indexes and planner statistics (REINDEX, ANALYZE)")
+	reindexCmd.Flags().Int("batch-size", 500, "articles per batch; progress is saved after every batch")
+	reindexCmd.Flags().Bool("resume", false, "continue the last unfinished reindex job after the last finished article")
+
+	cleanContentCmd.Flags().Bool("retranslate", false, "send changed articles back for translation and re-publishing")
+	cleanContentCmd.Flags().Bool("dry-run", false, "show what would change without saving")
+	verifyPublishedCmd.Flags().Bool("reset", false, "mark articles with missing files as unpublished so the next publish writes them")
 
 	rootCmd.AddCommand(fetchCmd)
 	rootCmd.AddCommand(translateCmd)
 	rootCmd.AddCommand(publishCmd)
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(rescrapeCmd)
+	rootCmd.AddCommand(cleanContentCmd)
+	rootCmd.AddCommand(reindexCmd)
+	rootCmd.AddCommand(regenerateCmd)
+	rootCmd.AddCommand(pruneCmd)
+	rootCmd.AddCommand(verifyPublishedCmd)
+	rootCmd.AddCommand(featureCmd)
+	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(reprocessCmd)
+	rootCmd.AddCommand(translateTestCmd)
+	rootCmd.AddCommand(discoverCmd)
+	rootCmd.AddCommand(checkFeedsCmd)
+	rootCmd.AddCommand(enableSourceCmd)
 	rootCmd.AddCommand(pullCmd)
 	rootCmd.AddCommand(pushCmd)
 	rootCmd.AddCommand(serverCmd)
+	dbCmd.AddCommand(dbInfoCmd)
+	dbCmd.AddCommand(dbVacuumCmd)
+	rootCmd.AddCommand(dbCmd)
+
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
 }
@@ -1,9 +1,16 @@
 package config
 
 import (
+	"crypto/x509"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -15,20 +22,70 @@ type Config struct {
 	Schedule   ScheduleConfig   `mapstructure:"schedule"`
 	Database   DatabaseConfig   `mapstructure:"database"`
 	Server     ServerConfig     `mapstructure:"server"`
+	Scraper    ScraperConfig    `mapstructure:"scraper"`
+	Network    NetworkConfig    `mapstructure:"network"`
+	Tracing    TracingConfig    `mapstructure:"tracing"`
+	Exclude    []string         `mapstructure:"exclude"` // feed items whose title/description match are never fetched; see sources[].exclude
 }
 
 type SourceConfig struct {
-	Name    string   `mapstructure:"name"`
-	Feeds   []string `mapstructure:"feeds"`
-	Enabled bool     `mapstructure:"enabled"`
+	Name           string   `mapstructure:"name"`
+	DisplayName    string   `mapstructure:"display_name"` // shown in published articles instead of Name (e.g. "RideApart")
+	Feeds          []string `mapstructure:"feeds"`
+	Enabled        bool     `mapstructure:"enabled"`
+	UseFeedContent bool     `mapstructure:"use_feed_content"` // take the body from content:encoded instead of scraping
+	UpdateExisting bool     `mapstructure:"update_existing"`  // re-scrape stored articles whose feed <updated> is newer, re-translate on real changes
+	CategoryField  string   `mapstructure:"category_field"`   // feed element for the category: "categories" (default, first one), "dc:subject", a custom element, "none"
+	TagsField      string   `mapstructure:"tags_field"`       // feed element for tags, same values as category_field
+	CutoffMarkers  []string `mapstructure:"cutoff_markers"`   // added to scraper.cutoff_markers for this source's articles
+	Exclude        []string `mapstructure:"exclude"`          // skip items whose title/description contain a keyword (case-insensitive) or match "re:<regexp>"
+	Critical       bool     `mapstructure:"critical"`         // check-feeds fails when every feed of this source is down
+	ContentSubdir  string   `mapstructure:"content_subdir"`   // articles go to posts/<content_subdir>/YYYY/MM/ (a Hugo section with its own _index.md); "" = posts/YYYY/MM/
+	Priority       int      `mapstructure:"priority"`         // higher-priority sources are fetched first and keep an article whose URL or near-duplicate title another source also has (ties: config order)
+
+	// Credentials for private feeds; ${VAR} references are expanded from the environment
+	Username string            `mapstructure:"username"`
+	Password string            `mapstructure:"password"`
+	Headers  map[string]string `mapstructure:"headers"` // e.g. {"X-API-Key": "${MOTOFEED_KEY}"}
 }
 
 type TranslatorConfig struct {
-	Provider       string               `mapstructure:"provider"`
-	Ollama         OllamaConfig         `mapstructure:"ollama"`
-	DeepL          DeepLConfig          `mapstructure:"deepl"`
-	LibreTranslate LibreTranslateConfig `mapstructure:"libretranslate"`
-	OpenRouter     OpenRouterConfig     `mapstructure:"openrouter"`
+	Provider        string               `mapstructure:"provider"`
+	MaxContentChars int                  `mapstructure:"max_content_chars"` // truncate content at a paragraph boundary before translating (0 = off)
+	Concurrency     int                  `mapstructure:"concurrency"`       // articles translated in parallel (0 = provider default: ollama 1, deepl/google 4, others 2)
+	MinOutputRatio  float64              `mapstructure:"min_output_ratio"`  // content translations shorter than this share of the input are errors (0 = only reject empty)
+	Glossary        []GlossaryTermConfig `mapstructure:"glossary"`          // terms with fixed translations (brands, models, jargon)
+	Order           string               `mapstructure:"order"`             // which untranslated articles a batch takes first: "newest", "oldest" (clears a backlog) or "random"
+	SourcePriority  []string             `mapstructure:"source_priority"`   // source names translated before all others, in this order (featured articles still come first)
+	CheckConnection bool                 `mapstructure:"check_connection"`  // verify the provider once before the first batch; a failure aborts the batch instead of erroring every article
+	StatusTTLSec    int                  `mapstructure:"status_ttl_sec"`    // GET /api/status reuses a provider check this long before asking the provider again (0 = every request)
+	PostProcess     PostProcessConfig    `mapstructure:"postprocess"`
+	Ollama          OllamaConfig         `mapstructure:"ollama"`
+	DeepL           DeepLConfig          `mapstructure:"deepl"`
+	LibreTranslate  LibreTranslateConfig `mapstructure:"libretranslate"`
+	OpenRouter      OpenRouterConfig     `mapstructure:"openrouter"`
+	Google          GoogleConfig         `mapstructure:"google"`
+}
+
+// GlossaryTermConfig is one translator.glossary entry. The glossary is a list
+// rather than a map because viper lower-cases map keys, which would mangle
+// brand names like "Öhlins".
+type GlossaryTermConfig struct {
+	Source string `mapstructure:"source"`
+	Target string `mapstructure:"target"` // empty = keep the source term untranslated
+}
+
+// PostProcessConfig fixes translator output before it is saved
+type PostProcessConfig struct {
+	Builtin bool                    `mapstructure:"builtin"` // straight/curly quotes to «», repeated spaces, spaces before punctuation, a trailing "Читать далее"
+	Rules   []PostProcessRuleConfig `mapstructure:"rules"`   // run after the built-in fixes, in order
+}
+
+// PostProcessRuleConfig is a regexp replacement on the translated title and
+// text. Use (?m) for ^/$ per line; replace may refer to groups as $1.
+type PostProcessRuleConfig struct {
+	Pattern string `mapstructure:"pattern"`
+	Replace string `mapstructure:"replace"`
 }
 
 type OpenRouterConfig struct {
@@ -48,11 +105,23 @@ type OllamaConfig struct {
 	Temperature float64 `mapstructure:"temperature"`
 	TopP        float64 `mapstructure:"top_p"`
 	NumCtx      int     `mapstructure:"num_ctx"`
+	Endpoint    string  `mapstructure:"endpoint"` // "chat" (/api/chat, system + user messages) or "generate" (/api/generate, one prompt)
 }
 
 type DeepLConfig struct {
-	APIKey string `mapstructure:"api_key"`
-	Free   bool   `mapstructure:"free"`
+	APIKey     string `mapstructure:"api_key"`
+	Free       bool   `mapstructure:"free"`
+	Formality  string `mapstructure:"formality"`   // "default", "more" (formal «вы»), "less" (informal «ты»), "prefer_more" or "prefer_less"
+	GlossaryID string `mapstructure:"glossary_id"` // existing DeepL glossary (EN->RU) to use instead of creating one from translator.glossary
+}
+
+// GoogleConfig configures Google Cloud Translation: an API key selects the
+// v2 API, a service account (credentials_file) the v3 API
+type GoogleConfig struct {
+	APIKey          string `mapstructure:"api_key"`
+	CredentialsFile string `mapstructure:"credentials_file"` // service account key; default GOOGLE_APPLICATION_CREDENTIALS
+	ProjectID       string `mapstructure:"project_id"`       // v3; default: project_id from the credentials file
+	Location        string `mapstructure:"location"`         // v3; "global" or a region such as us-central1
 }
 
 type LibreTranslateConfig struct {
@@ -60,29 +129,247 @@ type LibreTranslateConfig struct {
 }
 
 type HugoConfig struct {
-	Path       string `mapstructure:"path"`
-	ContentDir string `mapstructure:"content_dir"`
-	AutoCommit bool   `mapstructure:"auto_commit"`
-	GitRemote  string `mapstructure:"git_remote"`
-	GitBranch  string `mapstructure:"git_branch"`
-	GitRepo    string `mapstructure:"git_repo"`
+	Path              string `mapstructure:"path"`
+	ContentDir        string `mapstructure:"content_dir"`
+	AutoCommit        bool   `mapstructure:"auto_commit"`
+	GitRemote         string `mapstructure:"git_remote"`
+	GitBranch         string `mapstructure:"git_branch"`
+	GitRepo           string `mapstructure:"git_repo"`
+	APIBaseURL        string `mapstructure:"api_base_url"`         // GitHub REST API root; a GitHub Enterprise host without a path gets /api/v3
+	SlugSource        string `mapstructure:"slug_source"`          // "original" (source title, at fetch) or "translated" (title_ru, at translation)
+	SlugMaxLength     int    `mapstructure:"slug_max_length"`      // longest slug; longer ones are cut at a word boundary
+	SkipExisting      bool   `mapstructure:"skip_existing"`        // GitHub API: skip files whose repo copy is identical (still marked published)
+	GitLockTimeoutSec int    `mapstructure:"git_lock_timeout_sec"` // how long a local git operation waits for another one to finish
+	// GitHub API publish attempts after the first one fails; the wait before
+	// a retry starts at PublishRetryDelaySec and doubles each time
+	PublishRetries       int `mapstructure:"publish_retries"`
+	PublishRetryDelaySec int `mapstructure:"publish_retry_delay_sec"`
+	// GitHub API: most files in one commit; a larger batch goes out as
+	// several chained commits (0 = everything in one commit)
+	MaxFilesPerCommit int `mapstructure:"max_files_per_commit"`
+	// Publish only articles with both title_ru and content_ru; others are
+	// skipped and re-queued for translation instead of going out half in English
+	RequireFullTranslation bool `mapstructure:"require_full_translation"`
+	// Publish only articles an editor marked reviewed (review command,
+	// POST /api/articles/review); the rest wait in the publish queue
+	RequireReview bool `mapstructure:"require_review"`
+	// Articles of one publish batch that resolve to the same file: "suffix"
+	// appends the article ID to all but one slug, "off" lets the last one win
+	DuplicateSlugs string `mapstructure:"duplicate_slugs"`
+	// Once an article is published its file path is saved and later
+	// re-publishes write that file even if the slug or date changes
+	StablePermalinks bool `mapstructure:"stable_permalinks"`
+
+	Formatter FormatterConfig `mapstructure:"formatter"`
+	Index     IndexConfig     `mapstructure:"index"`
+}
+
+// IndexConfig controls the generated posts index (posts/_index.md)
+type IndexConfig struct {
+	Title        string   `mapstructure:"title"`         // heading of the main page and the yearly archives
+	Paginate     string   `mapstructure:"paginate"`      // "" or "none" (one page), "year" (page per year), "recent" (latest page_size + yearly archives)
+	PageSize     int      `mapstructure:"page_size"`     // articles on the main page with paginate: recent
+	Categories   []string `mapstructure:"categories"`    // only list articles in these categories (source or translated name); empty = all
+	MonthOrder   string   `mapstructure:"month_order"`   // "desc" (newest month and year first) or "asc"
+	ArticleOrder string   `mapstructure:"article_order"` // articles within a month by publish date: "desc" (newest first) or "asc"
+}
+
+// FormatterConfig controls how articles are rendered to markdown
+type FormatterConfig struct {
+	// Extra/overriding EN->RU translations (keys are matched case-insensitively)
+	CategoryTranslations map[string]string `mapstructure:"category_translations"`
+	TagTranslations      map[string]string `mapstructure:"tag_translations"`
+	DropUnknownTags      bool              `mapstructure:"drop_unknown_tags"` // drop tags with no translation instead of keeping them in English
+	BaseCategories       []string          `mapstructure:"base_categories"`   // always listed first in categories; empty = only the source category
+	Timezone             string            `mapstructure:"timezone"`          // IANA zone for frontmatter dates (e.g. Europe/Moscow)
+
+	// Image hosts (subdomains included). Covers on a host that is blocked, or
+	// not in a non-empty allowlist, are replaced by the default image.
+	ImageAllowlist []string `mapstructure:"image_allowlist"`
+	ImageBlocklist []string `mapstructure:"image_blocklist"`
+	// Cover for articles without a usable image: DefaultImages by category
+	// (source or translated name, case-insensitive), then DefaultImage.
+	// Both empty = no cover block.
+	DefaultImage  string            `mapstructure:"default_image"` // e.g. /images/placeholder.jpg
+	DefaultImages map[string]string `mapstructure:"default_images"`
+
+	// MaxTitleLength shortens frontmatter titles longer than this many
+	// characters at a word boundary with "…" (0 = keep the full title)
+	MaxTitleLength int `mapstructure:"max_title_length"`
+
+	// FeaturedFrontmatter adds "featured: true" to featured articles (themes
+	// such as PaperMod can pin them)
+	FeaturedFrontmatter bool `mapstructure:"featured_frontmatter"`
+
+	// SourceNames maps source names to sources[].display_name; filled by Load
+	SourceNames map[string]string `mapstructure:"-"`
+	// StablePermalinks copies hugo.stable_permalinks; filled by Load
+	StablePermalinks bool `mapstructure:"-"`
+	// SourceSubdirs maps source names to sources[].content_subdir; filled by Load
+	SourceSubdirs map[string]string `mapstructure:"-"`
+
+	// Footer is a Go template (text/template) appended after the content with
+	// .SourceSite, .SourceURL, .Author, .Title, .TitleRU and .OriginalTitle
+	// (set only with FooterOriginalTitle). Empty = DefaultFooter.
+	Footer              string `mapstructure:"footer"`
+	DisableFooter       bool   `mapstructure:"disable_footer"`
+	FooterOriginalTitle bool   `mapstructure:"footer_original_title"` // expose the English title as .OriginalTitle
+
+	// IncludeOriginal publishes the English original with the translation:
+	// "none", "details" (collapsible block after the text) or "file"
+	// (companion slug.en.md for a multilingual Hugo site)
+	IncludeOriginal string `mapstructure:"include_original"`
+
+	// StripBOM removes byte order marks (U+FEFF) from rendered files; line
+	// endings are always written as LF
+	StripBOM bool `mapstructure:"strip_bom"`
+
+	// Gallery places the article images after the cover: "frontmatter"
+	// (images: list), "shortcode" (figure shortcodes after the text) or "none"
+	Gallery string `mapstructure:"gallery"`
+
+	// FrontmatterFormat is the post frontmatter syntax: "yaml" (---),
+	// "toml" (+++) or "json"
+	FrontmatterFormat string `mapstructure:"frontmatter_format"`
+
+	// AutoTags adds tags by keyword: an article whose title or text
+	// (translated or original) contains one of an entry's keywords as whole
+	// words gets its tag when it is scraped or translated, after the source
+	// tags and within their cap. Hand-set tags are left alone.
+	AutoTags []AutoTagConfig `mapstructure:"auto_tags"`
+
+	// Links sets how links kept in the text are written: "inline"
+	// ([text](url)), "footnote" ([text][1] with numbered references after
+	// the text) or "strip" (text only)
+	Links string `mapstructure:"links"`
 }
 
+// AutoTagConfig is one hugo.formatter.auto_tags entry. A list rather than a
+// map for the same reason as the glossary: viper would lower-case the tags.
+type AutoTagConfig struct {
+	Tag      string   `mapstructure:"tag"`
+	Keywords []string `mapstructure:"keywords"` // case-insensitive whole words, "word*" = any ending; "re:" = regexp
+}
+
+// DefaultFooter is the built-in source attribution
+const DefaultFooter = "*Источник: [{{.SourceSite}}]({{.SourceURL}}){{with .OriginalTitle}} — «{{.}}»{{end}}*"
+
 type ScheduleConfig struct {
-	FetchInterval  string `mapstructure:"fetch_interval"`
-	TranslateBatch int    `mapstructure:"translate_batch"`
+	FetchInterval    string `mapstructure:"fetch_interval"`
+	TranslateBatch   int    `mapstructure:"translate_batch"`
+	MaxNewPerRun     int    `mapstructure:"max_new_per_run"`     // stop fetching once this many new articles are saved (0 = unlimited)
+	MaxPublishPerRun int    `mapstructure:"max_publish_per_run"` // upper bound for a single publish batch (0 = unlimited)
+	PublishDelay     string `mapstructure:"publish_delay"`       // Go duration an article waits after fetching before it is published ("0" = publish right away)
+	// A feed failing with a network error, timeout or HTTP 5xx/429 is retried
+	// up to FeedRetries times; the wait starts at FeedRetryDelaySec and doubles
+	FeedRetries       int `mapstructure:"feed_retries"`
+	FeedRetryDelaySec int `mapstructure:"feed_retry_delay_sec"`
+	// A source whose feeds all failed in this many fetches in a row is
+	// disabled until re-enabled by hand (0 = never)
+	DisableAfterFailures int `mapstructure:"disable_after_failures"`
+	// An item whose title shares at least DedupTitleSimilarity of its words
+	// (Jaccard index, 0..1) with an article another source brought in the
+	// last DedupWindowHours is a near-duplicate: only the article of the
+	// higher-priority source is kept (0 = off)
+	DedupTitleSimilarity float64 `mapstructure:"dedup_title_similarity"`
+	DedupWindowHours     int     `mapstructure:"dedup_window_hours"`
+
+	// PublishDelayDuration is PublishDelay parsed; filled by Load
+	PublishDelayDuration time.Duration `mapstructure:"-"`
 }
 
 type DatabaseConfig struct {
-	Path string `mapstructure:"path"`
+	Path          string `mapstructure:"path"`
+	JournalMode   string `mapstructure:"journal_mode"`    // WAL, DELETE, TRUNCATE, PERSIST, MEMORY, OFF
+	BusyTimeoutMs int    `mapstructure:"busy_timeout_ms"` // how long a writer waits on a lock before "database is locked"
+	MaxOpenConns  int    `mapstructure:"max_open_conns"`
+	MaxIdleConns  int    `mapstructure:"max_idle_conns"`
+}
+
+type ScraperConfig struct {
+	NormalizeQuotes     bool     `mapstructure:"normalize_quotes"`      // convert typographic quotes/apostrophes to ASCII
+	MaxRescrapeAttempts int      `mapstructure:"max_rescrape_attempts"` // after this many rescrapes without improvement the article is left for manual review
+	MaxBodyBytes        int64    `mapstructure:"max_body_bytes"`        // pages larger than this are rejected (0 = 5 MiB)
+	RequestTimeoutSec   int      `mapstructure:"request_timeout_sec"`   // deadline for one page request, including the body read (0 = 20s)
+	MaxRedirects        int      `mapstructure:"max_redirects"`         // redirects followed per page; a longer chain fails the scrape (0 = 10)
+	HTMLTags            string   `mapstructure:"html_tags"`             // tags left in scraped text: "markdown" (links/bold/italics to Markdown, rest stripped) or "strip"
+	UpdateMinChange     float64  `mapstructure:"update_min_change"`     // update_existing: share of paragraphs that must differ to take an update (0..1)
+	MaxTags             int      `mapstructure:"max_tags"`              // tags kept per scraped page, article-specific ones first (0 = 10)
+	MaxImages           int      `mapstructure:"max_images"`            // images kept per article: featured first, then figures/galleries from the body (0 = 20)
+	CutoffMarkers       []string `mapstructure:"cutoff_markers"`        // a paragraph starting with one of these (case-insensitive; "re:" = regexp) ends the article body
+	Concurrency         int      `mapstructure:"concurrency"`           // new articles scraped in parallel, across all hosts (0 = 1)
+	PerHostConcurrency  int      `mapstructure:"per_host_concurrency"`  // parallel requests to one host (0 = 1)
+	PerHostDelayMs      int      `mapstructure:"per_host_delay_ms"`     // minimum gap between request starts to one host
+	KeepRawContent      bool     `mapstructure:"keep_raw_content"`      // store the extracted text before cleanup (raw_content) for clean-content and change detection
+	// Re-scrape articles fetched in the last RecheckDays days (0 = off), each
+	// at most every RecheckIntervalHours, RecheckBatch per run, and take the
+	// new version when the page text hash changed
+	RecheckDays          int `mapstructure:"recheck_days"`
+	RecheckIntervalHours int `mapstructure:"recheck_interval_hours"`
+	RecheckBatch         int `mapstructure:"recheck_batch"`
+	// Which page URLs may be scraped (redirects included): "public" = http(s)
+	// not resolving to internal addresses, "sources" = public and on a host of
+	// a configured feed or AllowedHosts, "scheme" = any http(s) URL
+	URLPolicy    string   `mapstructure:"url_policy"`
+	AllowedHosts []string `mapstructure:"allowed_hosts"` // url_policy: sources — extra article hosts, subdomains included
+
+	// SourceCutoffMarkers maps source names to sources[].cutoff_markers; filled by Load
+	SourceCutoffMarkers map[string][]string `mapstructure:"-"`
+	// SourceHosts are the hosts of all sources' feeds, without "www."; filled by Load
+	SourceHosts []string `mapstructure:"-"`
+}
+
+// NetworkConfig sets outbound proxies. Each value is "" (use HTTP_PROXY /
+// HTTPS_PROXY env), "direct" (no proxy) or a proxy URL: http://, https://,
+// socks5://. Per-destination values override Proxy.
+type NetworkConfig struct {
+	Proxy           string `mapstructure:"proxy"`
+	ScraperProxy    string `mapstructure:"scraper_proxy"`
+	FeedsProxy      string `mapstructure:"feeds_proxy"`
+	TranslatorProxy string `mapstructure:"translator_proxy"`
+	GitHubProxy     string `mapstructure:"github_proxy"`
+
+	// Connection pooling and TLS, shared by every outbound client
+	MaxIdleConns           int    `mapstructure:"max_idle_conns"`          // idle connections kept in total (0 = unlimited)
+	MaxIdleConnsPerHost    int    `mapstructure:"max_idle_conns_per_host"` // idle connections kept per host; raise for concurrent fetch/translate
+	MaxConnsPerHost        int    `mapstructure:"max_conns_per_host"`      // 0 = unlimited
+	IdleConnTimeoutSec     int    `mapstructure:"idle_conn_timeout_sec"`   // idle connections are closed after this long
+	TLSHandshakeTimeoutSec int    `mapstructure:"tls_handshake_timeout_sec"`
+	TLSMinVersion          string `mapstructure:"tls_min_version"` // "1.2" or "1.3"; "" = Go default (1.2)
+	CAFile                 string `mapstructure:"ca_file"`         // extra PEM roots, e.g. for a TLS-intercepting corporate proxy
+}
+
+// TracingConfig enables OTLP/HTTP span export. Empty endpoint falls back to
+// OTEL_EXPORTER_OTLP_ENDPOINT; if that is unset too, tracing is off.
+type TracingConfig struct {
+	OTLPEndpoint string `mapstructure:"otlp_endpoint"` // e.g. http://localhost:4318
+	ServiceName  string `mapstructure:"service_name"`
 }
 
 type ServerConfig struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	ETag     bool   `mapstructure:"etag"`     // ETag/If-None-Match (304) on GET /api responses
+	Compress bool   `mapstructure:"compress"` // gzip GET /api responses for clients that accept it
+	// POST /api/translate-test sends any text to the configured (possibly
+	// paid) translator: off unless enabled, and limited to
+	// TranslateTestPerMinute calls a minute (0 = no limit)
+	TranslateTest          bool `mapstructure:"translate_test"`
+	TranslateTestPerMinute int  `mapstructure:"translate_test_per_minute"`
 }
 
+// EnvPrefix is the prefix for environment overrides: translator.provider can
+// be set with MOTONEWS_TRANSLATOR_PROVIDER. Precedence: env > file > default
+// (command-line flags go on top, see LoadWithOverrides).
+const EnvPrefix = "MOTONEWS"
+
 func Load(configPath string) (*Config, error) {
+	return load(configPath, nil)
+}
+
+// load reads defaults, env and the config file; bindOverrides (optional)
+// runs once they are merged, before the result is unmarshalled
+func load(configPath string, bindOverrides func() error) (*Config, error) {
 	if configPath != "" {
 		viper.SetConfigFile(configPath)
 	} else {
@@ -94,12 +381,22 @@ func Load(configPath string) (*Config, error) {
 
 	// Set defaults
 	viper.SetDefault("translator.provider", "ollama")
+	viper.SetDefault("translator.max_content_chars", 0)
+	viper.SetDefault("translator.concurrency", 0)
+	viper.SetDefault("translator.min_output_ratio", 0.3)
+	viper.SetDefault("translator.order", "newest")
+	viper.SetDefault("translator.check_connection", true)
+	viper.SetDefault("translator.status_ttl_sec", 300)
+	viper.SetDefault("translator.postprocess.builtin", true)
+	viper.SetDefault("translator.deepl.formality", "default")
 	viper.SetDefault("translator.ollama.model", "gemma2:9b")
 	viper.SetDefault("translator.ollama.host", "http://localhost:11434")
 	viper.SetDefault("translator.ollama.temperature", 0.15)
 	viper.SetDefault("translator.ollama.top_p", 0.9)
 	viper.SetDefault("translator.ollama.num_ctx", 8192)
+	viper.SetDefault("translator.ollama.endpoint", "chat")
 	viper.SetDefault("translator.deepl.free", true)
+	viper.SetDefault("translator.google.location", "global")
 	viper.SetDefault("translator.libretranslate.host", "http://localhost:5000")
 	viper.SetDefault("translator.openrouter.base_url", "https://openrouter.ai/api/v1")
 	viper.SetDefault("translator.openrouter.temperature", 0.3)
@@ -108,11 +405,71 @@ func Load(configPath string) (*Config, error) {
 	viper.SetDefault("hugo.auto_commit", true)
 	viper.SetDefault("hugo.git_remote", "origin")
 	viper.SetDefault("hugo.git_branch", "main")
+	viper.SetDefault("hugo.api_base_url", "https://api.github.com")
+	viper.SetDefault("hugo.slug_source", "original")
+	viper.SetDefault("hugo.slug_max_length", 80)
+	viper.SetDefault("hugo.git_lock_timeout_sec", 120)
+	viper.SetDefault("hugo.publish_retries", 2)
+	viper.SetDefault("hugo.publish_retry_delay_sec", 5)
+	viper.SetDefault("hugo.max_files_per_commit", 0)
+	viper.SetDefault("hugo.duplicate_slugs", "suffix")
+	viper.SetDefault("hugo.stable_permalinks", false)
+	viper.SetDefault("hugo.formatter.base_categories", []string{"Новости"})
+	viper.SetDefault("hugo.formatter.timezone", "UTC")
+	viper.SetDefault("hugo.formatter.footer", DefaultFooter)
+	viper.SetDefault("hugo.formatter.include_original", "none")
+	viper.SetDefault("hugo.formatter.strip_bom", true)
+	viper.SetDefault("hugo.formatter.gallery", "frontmatter")
+	viper.SetDefault("hugo.formatter.frontmatter_format", "yaml")
+	viper.SetDefault("hugo.formatter.links", "inline")
+	viper.SetDefault("hugo.index.title", "Новости")
+	viper.SetDefault("hugo.index.paginate", "none")
+	viper.SetDefault("hugo.index.page_size", 50)
+	viper.SetDefault("hugo.index.month_order", "desc")
+	viper.SetDefault("hugo.index.article_order", "desc")
 	viper.SetDefault("schedule.fetch_interval", "6h")
 	viper.SetDefault("schedule.translate_batch", 10)
+	viper.SetDefault("schedule.max_new_per_run", 50)
+	viper.SetDefault("schedule.max_publish_per_run", 100)
+	viper.SetDefault("schedule.publish_delay", "0")
+	viper.SetDefault("schedule.feed_retries", 2)
+	viper.SetDefault("schedule.feed_retry_delay_sec", 2)
+	viper.SetDefault("schedule.disable_after_failures", 0)
+	viper.SetDefault("schedule.dedup_title_similarity", 0)
+	viper.SetDefault("schedule.dedup_window_hours", 48)
 	viper.SetDefault("database.path", "./moto-news.db")
+	viper.SetDefault("database.journal_mode", "WAL")
+	viper.SetDefault("database.busy_timeout_ms", 5000)
+	viper.SetDefault("database.max_open_conns", 4)
+	viper.SetDefault("database.max_idle_conns", 4)
 	viper.SetDefault("server.host", "0.0.0.0")
 	viper.SetDefault("server.port", 8080)
+	viper.SetDefault("server.etag", true)
+	viper.SetDefault("server.compress", true)
+	viper.SetDefault("server.translate_test", false)
+	viper.SetDefault("server.translate_test_per_minute", 10)
+	viper.SetDefault("scraper.normalize_quotes", false)
+	viper.SetDefault("scraper.max_rescrape_attempts", 3)
+	viper.SetDefault("scraper.max_body_bytes", 5<<20)
+	viper.SetDefault("scraper.request_timeout_sec", 20)
+	viper.SetDefault("scraper.max_redirects", 10)
+	viper.SetDefault("scraper.html_tags", "markdown")
+	viper.SetDefault("scraper.max_tags", 10)
+	viper.SetDefault("scraper.max_images", 20)
+	viper.SetDefault("scraper.update_min_change", 0.1)
+	viper.SetDefault("scraper.concurrency", 4)
+	viper.SetDefault("scraper.per_host_concurrency", 1)
+	viper.SetDefault("scraper.per_host_delay_ms", 1000)
+	viper.SetDefault("scraper.url_policy", "public")
+	viper.SetDefault("scraper.keep_raw_content", true)
+	viper.SetDefault("scraper.recheck_days", 0)
+	viper.SetDefault("scraper.recheck_interval_hours", 24)
+	viper.SetDefault("scraper.recheck_batch", 20)
+	viper.SetDefault("tracing.service_name", "moto-news")
+	viper.SetDefault("network.max_idle_conns", 100)
+	viper.SetDefault("network.max_idle_conns_per_host", 10)
+	viper.SetDefault("network.idle_conn_timeout_sec", 90)
+	viper.SetDefault("network.tls_handshake_timeout_sec", 10)
 
 	// Default sources
 	viper.SetDefault("sources", []map[string]interface{}{
@@ -127,6 +484,15 @@ func Load(configPath string) (*Config, error) {
 		},
 	})
 
+	// Environment overrides. Every key is bound explicitly because viper only
+	// consults the environment for keys it already knows about on Unmarshal.
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	if err := bindEnvs(reflect.TypeOf(Config{}), ""); err != nil {
+		return nil, err
+	}
+
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, err
@@ -134,11 +500,270 @@ func Load(configPath string) (*Config, error) {
 		// Config file not found, use defaults
 	}
 
+	if bindOverrides != nil {
+		if err := bindOverrides(); err != nil {
+			return nil, err
+		}
+	}
+
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, err
 	}
 
+	if err := validateProxies(&cfg.Network); err != nil {
+		return nil, err
+	}
+	if err := validatePooling(&cfg.Network); err != nil {
+		return nil, err
+	}
+	if d := strings.TrimSpace(cfg.Schedule.PublishDelay); d != "" && d != "0" {
+		delay, err := time.ParseDuration(d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule.publish_delay %q: %w", d, err)
+		}
+		if delay < 0 {
+			return nil, fmt.Errorf("schedule.publish_delay must be >= 0, got %q", d)
+		}
+		cfg.Schedule.PublishDelayDuration = delay
+	}
+	if n := cfg.Schedule.FeedRetries; n < 0 {
+		return nil, fmt.Errorf("schedule.feed_retries must be >= 0, got %d", n)
+	}
+	if n := cfg.Schedule.FeedRetryDelaySec; n < 0 {
+		return nil, fmt.Errorf("schedule.feed_retry_delay_sec must be >= 0, got %d", n)
+	}
+	if n := cfg.Schedule.DisableAfterFailures; n < 0 {
+		return nil, fmt.Errorf("schedule.disable_after_failures must be >= 0, got %d", n)
+	}
+	if x := cfg.Schedule.DedupTitleSimilarity; x < 0 || x > 1 {
+		return nil, fmt.Errorf("schedule.dedup_title_similarity must be between 0 and 1, got %g", x)
+	}
+	if n := cfg.Schedule.DedupWindowHours; n < 0 {
+		return nil, fmt.Errorf("schedule.dedup_window_hours must be >= 0, got %d", n)
+	}
+	if _, err := time.LoadLocation(cfg.Hugo.Formatter.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid hugo.formatter.timezone %q: %w", cfg.Hugo.Formatter.Timezone, err)
+	}
+	if _, err := template.New("footer").Parse(cfg.Hugo.Formatter.Footer); err != nil {
+		return nil, fmt.Errorf("invalid hugo.formatter.footer: %w", err)
+	}
+	if e := cfg.Translator.Ollama.Endpoint; e != "chat" && e != "generate" {
+		return nil, fmt.Errorf("translator.ollama.endpoint must be \"chat\" or \"generate\", got %q", e)
+	}
+	if n := cfg.Translator.StatusTTLSec; n < 0 {
+		return nil, fmt.Errorf("translator.status_ttl_sec must be >= 0, got %d", n)
+	}
+	if mode := cfg.Scraper.HTMLTags; mode != "" && mode != "markdown" && mode != "strip" {
+		return nil, fmt.Errorf("scraper.html_tags must be \"markdown\" or \"strip\", got %q", mode)
+	}
+	if n := cfg.Scraper.MaxTags; n < 0 {
+		return nil, fmt.Errorf("scraper.max_tags must be >= 0, got %d", n)
+	}
+	if n := cfg.Scraper.MaxRedirects; n < 0 {
+		return nil, fmt.Errorf("scraper.max_redirects must be >= 0, got %d", n)
+	}
+	if n := cfg.Scraper.MaxImages; n < 0 {
+		return nil, fmt.Errorf("scraper.max_images must be >= 0, got %d", n)
+	}
+	if n := cfg.Scraper.Concurrency; n < 0 {
+		return nil, fmt.Errorf("scraper.concurrency must be >= 0, got %d", n)
+	}
+	if n := cfg.Scraper.PerHostConcurrency; n < 0 {
+		return nil, fmt.Errorf("scraper.per_host_concurrency must be >= 0, got %d", n)
+	}
+	if n := cfg.Scraper.PerHostDelayMs; n < 0 {
+		return nil, fmt.Errorf("scraper.per_host_delay_ms must be >= 0, got %d", n)
+	}
+	if n := cfg.Scraper.RecheckDays; n < 0 {
+		return nil, fmt.Errorf("scraper.recheck_days must be >= 0, got %d", n)
+	}
+	if n := cfg.Scraper.RecheckIntervalHours; n < 1 {
+		return nil, fmt.Errorf("scraper.recheck_interval_hours must be >= 1, got %d", n)
+	}
+	if n := cfg.Scraper.RecheckBatch; n < 1 {
+		return nil, fmt.Errorf("scraper.recheck_batch must be >= 1, got %d", n)
+	}
+	if p := cfg.Scraper.URLPolicy; p != "public" && p != "sources" && p != "scheme" {
+		return nil, fmt.Errorf("scraper.url_policy must be \"public\", \"sources\" or \"scheme\", got %q", p)
+	}
+	switch cfg.Hugo.Index.Paginate {
+	case "", "none", "year", "recent":
+	default:
+		return nil, fmt.Errorf("hugo.index.paginate must be \"none\", \"year\" or \"recent\", got %q", cfg.Hugo.Index.Paginate)
+	}
+	if o := cfg.Hugo.Index.MonthOrder; o != "" && o != "asc" && o != "desc" {
+		return nil, fmt.Errorf("hugo.index.month_order must be \"asc\" or \"desc\", got %q", o)
+	}
+	if o := cfg.Hugo.Index.ArticleOrder; o != "" && o != "asc" && o != "desc" {
+		return nil, fmt.Errorf("hugo.index.article_order must be \"asc\" or \"desc\", got %q", o)
+	}
+	switch cfg.Translator.Order {
+	case "", "newest", "oldest", "random":
+	default:
+		return nil, fmt.Errorf("translator.order must be \"newest\", \"oldest\" or \"random\", got %q", cfg.Translator.Order)
+	}
+	switch f := cfg.Translator.DeepL.Formality; f {
+	case "", "default", "more", "less", "prefer_more", "prefer_less":
+	default:
+		return nil, fmt.Errorf("translator.deepl.formality must be \"default\", \"more\", \"less\", \"prefer_more\" or \"prefer_less\", got %q", f)
+	}
+	for i, rule := range cfg.Translator.PostProcess.Rules {
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("translator.postprocess.rules[%d]: pattern is required", i)
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("translator.postprocess.rules[%d]: invalid pattern %q: %w", i, rule.Pattern, err)
+		}
+	}
+	for i, term := range cfg.Translator.Glossary {
+		if strings.TrimSpace(term.Source) == "" {
+			return nil, fmt.Errorf("translator.glossary[%d]: source is required", i)
+		}
+		if strings.ContainsAny(term.Source+term.Target, "\t\r\n") {
+			return nil, fmt.Errorf("translator.glossary[%d]: terms must not contain tabs or line breaks", i)
+		}
+	}
+	if src := cfg.Hugo.SlugSource; src != "" && src != "original" && src != "translated" {
+		return nil, fmt.Errorf("hugo.slug_source must be \"original\" or \"translated\", got %q", src)
+	}
+	switch cfg.Hugo.Formatter.IncludeOriginal {
+	case "", "none", "details", "file":
+	default:
+		return nil, fmt.Errorf("hugo.formatter.include_original must be \"none\", \"details\" or \"file\", got %q", cfg.Hugo.Formatter.IncludeOriginal)
+	}
+	switch cfg.Hugo.Formatter.Gallery {
+	case "", "frontmatter", "shortcode", "none":
+	default:
+		return nil, fmt.Errorf("hugo.formatter.gallery must be \"frontmatter\", \"shortcode\" or \"none\", got %q", cfg.Hugo.Formatter.Gallery)
+	}
+	switch cfg.Hugo.Formatter.FrontmatterFormat {
+	case "", "yaml", "toml", "json":
+	default:
+		return nil, fmt.Errorf("hugo.formatter.frontmatter_format must be \"yaml\", \"toml\" or \"json\", got %q", cfg.Hugo.Formatter.FrontmatterFormat)
+	}
+	switch cfg.Hugo.Formatter.Links {
+	case "", "inline", "footnote", "strip":
+	default:
+		return nil, fmt.Errorf("hugo.formatter.links must be \"inline\", \"footnote\" or \"strip\", got %q", cfg.Hugo.Formatter.Links)
+	}
+	if raw := strings.TrimSpace(cfg.Hugo.APIBaseURL); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hugo.api_base_url %q: %w", raw, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("hugo.api_base_url must be an http(s) URL, got %q", raw)
+		}
+	}
+	if mode := cfg.Hugo.DuplicateSlugs; mode != "" && mode != "suffix" && mode != "off" {
+		return nil, fmt.Errorf("hugo.duplicate_slugs must be \"suffix\" or \"off\", got %q", mode)
+	}
+	if n := cfg.Hugo.SlugMaxLength; n < 20 || n > 200 {
+		return nil, fmt.Errorf("hugo.slug_max_length must be between 20 and 200, got %d", n)
+	}
+	if r := cfg.Scraper.UpdateMinChange; r < 0 || r > 1 {
+		return nil, fmt.Errorf("scraper.update_min_change must be between 0 and 1, got %g", r)
+	}
+	if n := cfg.Hugo.GitLockTimeoutSec; n < 0 {
+		return nil, fmt.Errorf("hugo.git_lock_timeout_sec must be >= 0, got %d", n)
+	}
+	if n := cfg.Hugo.PublishRetries; n < 0 {
+		return nil, fmt.Errorf("hugo.publish_retries must be >= 0, got %d", n)
+	}
+	if n := cfg.Hugo.PublishRetryDelaySec; n < 0 {
+		return nil, fmt.Errorf("hugo.publish_retry_delay_sec must be >= 0, got %d", n)
+	}
+	if n := cfg.Hugo.MaxFilesPerCommit; n < 0 {
+		return nil, fmt.Errorf("hugo.max_files_per_commit must be >= 0, got %d", n)
+	}
+	if n := cfg.Hugo.Formatter.MaxTitleLength; n < 0 {
+		return nil, fmt.Errorf("hugo.formatter.max_title_length must be >= 0, got %d", n)
+	}
+
+	for i, rule := range cfg.Hugo.Formatter.AutoTags {
+		if strings.TrimSpace(rule.Tag) == "" {
+			return nil, fmt.Errorf("hugo.formatter.auto_tags[%d]: tag is required", i)
+		}
+		if len(rule.Keywords) == 0 {
+			return nil, fmt.Errorf("hugo.formatter.auto_tags[%d]: keywords are required", i)
+		}
+		for _, keyword := range rule.Keywords {
+			if err := validatePattern(keyword); err != nil {
+				return nil, fmt.Errorf("invalid hugo.formatter.auto_tags[%d] keyword %q: %w", i, keyword, err)
+			}
+		}
+	}
+	for _, marker := range cfg.Scraper.CutoffMarkers {
+		if err := validatePattern(marker); err != nil {
+			return nil, fmt.Errorf("invalid scraper.cutoff_markers entry %q: %w", marker, err)
+		}
+	}
+	for _, entry := range cfg.Exclude {
+		if err := validatePattern(entry); err != nil {
+			return nil, fmt.Errorf("invalid exclude entry %q: %w", entry, err)
+		}
+	}
+	for _, src := range cfg.Sources {
+		for _, entry := range src.Exclude {
+			if err := validatePattern(entry); err != nil {
+				return nil, fmt.Errorf("invalid exclude entry %q of source %s: %w", entry, src.Name, err)
+			}
+		}
+		for _, marker := range src.CutoffMarkers {
+			if err := validatePattern(marker); err != nil {
+				return nil, fmt.Errorf("invalid cutoff_markers entry %q of source %s: %w", marker, src.Name, err)
+			}
+		}
+		if len(src.CutoffMarkers) > 0 {
+			if cfg.Scraper.SourceCutoffMarkers == nil {
+				cfg.Scraper.SourceCutoffMarkers = make(map[string][]string)
+			}
+			cfg.Scraper.SourceCutoffMarkers[src.Name] = src.CutoffMarkers
+		}
+		for _, feed := range src.Feeds {
+			if u, err := url.Parse(feed); err == nil && u.Hostname() != "" {
+				cfg.Scraper.SourceHosts = append(cfg.Scraper.SourceHosts, strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."))
+			}
+		}
+	}
+
+	cfg.Hugo.Formatter.StablePermalinks = cfg.Hugo.StablePermalinks
+
+	for _, src := range cfg.Sources {
+		if src.DisplayName == "" {
+			continue
+		}
+		if cfg.Hugo.Formatter.SourceNames == nil {
+			cfg.Hugo.Formatter.SourceNames = make(map[string]string)
+		}
+		cfg.Hugo.Formatter.SourceNames[src.Name] = src.DisplayName
+	}
+
+	for _, src := range cfg.Sources {
+		if src.ContentSubdir == "" {
+			continue
+		}
+		subdir, err := cleanContentSubdir(src.ContentSubdir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content_subdir %q of source %s: %w", src.ContentSubdir, src.Name, err)
+		}
+		if cfg.Hugo.Formatter.SourceSubdirs == nil {
+			cfg.Hugo.Formatter.SourceSubdirs = make(map[string]string)
+		}
+		cfg.Hugo.Formatter.SourceSubdirs[src.Name] = subdir
+	}
+
+	for _, name := range cfg.Translator.SourcePriority {
+		known := false
+		for _, src := range cfg.Sources {
+			known = known || src.Name == name
+		}
+		if !known {
+			return nil, fmt.Errorf("translator.source_priority: unknown source %q", name)
+		}
+	}
+
 	// Resolve relative paths
 	if !filepath.IsAbs(cfg.Database.Path) {
 		cwd, err := os.Getwd()
@@ -158,3 +783,143 @@ func Load(configPath string) (*Config, error) {
 
 	return &cfg, nil
 }
+
+// EnvKey returns the environment variable that overrides a config key
+func EnvKey(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// bindEnvs binds every scalar key of a config struct (by mapstructure tag)
+// to its environment variable. Maps and slices of structs (sources) are skipped.
+func bindEnvs(t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			if err := bindEnvs(field.Type, key); err != nil {
+				return err
+			}
+		case reflect.Map:
+			continue
+		case reflect.Slice:
+			if field.Type.Elem().Kind() == reflect.Struct {
+				continue
+			}
+			if err := viper.BindEnv(key); err != nil {
+				return err
+			}
+		default:
+			if err := viper.BindEnv(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validatePattern rejects empty cutoff/exclude entries and "re:" entries
+// whose regexp doesn't compile
+func validatePattern(marker string) error {
+	if strings.TrimSpace(marker) == "" {
+		return fmt.Errorf("marker is empty")
+	}
+	if pattern, ok := strings.CutPrefix(marker, "re:"); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// yearDir matches a first path segment that would mix with the posts/YYYY
+// directories
+var yearDir = regexp.MustCompile(`^[0-9]{4}$`)
+
+// cleanContentSubdir trims surrounding slashes from a sources[].content_subdir
+// and rejects paths that would leave posts/ or collide with the year
+// directories
+func cleanContentSubdir(subdir string) (string, error) {
+	subdir = strings.Trim(strings.TrimSpace(subdir), "/")
+	if subdir == "" || strings.Contains(subdir, `\`) {
+		return "", fmt.Errorf("must be a relative path with forward slashes")
+	}
+	segments := strings.Split(subdir, "/")
+	for _, seg := range segments {
+		if seg == "" || seg == "." || seg == ".." {
+			return "", fmt.Errorf("must not contain empty, \".\" or \"..\" segments")
+		}
+	}
+	if yearDir.MatchString(segments[0]) {
+		return "", fmt.Errorf("must not start with a year, posts/YYYY holds the dated articles")
+	}
+	return subdir, nil
+}
+
+// validatePooling checks the connection pool and TLS settings
+func validatePooling(n *NetworkConfig) error {
+	limits := map[string]int{
+		"network.max_idle_conns":            n.MaxIdleConns,
+		"network.max_idle_conns_per_host":   n.MaxIdleConnsPerHost,
+		"network.max_conns_per_host":        n.MaxConnsPerHost,
+		"network.idle_conn_timeout_sec":     n.IdleConnTimeoutSec,
+		"network.tls_handshake_timeout_sec": n.TLSHandshakeTimeoutSec,
+	}
+	for key, v := range limits {
+		if v < 0 {
+			return fmt.Errorf("%s must be >= 0, got %d", key, v)
+		}
+	}
+	switch n.TLSMinVersion {
+	case "", "1.2", "1.3":
+	default:
+		return fmt.Errorf("network.tls_min_version must be \"1.2\" or \"1.3\", got %q", n.TLSMinVersion)
+	}
+	if n.CAFile != "" {
+		pem, err := os.ReadFile(n.CAFile)
+		if err != nil {
+			return fmt.Errorf("invalid network.ca_file: %w", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+			return fmt.Errorf("invalid network.ca_file: no PEM certificates in %s", n.CAFile)
+		}
+	}
+	return nil
+}
+
+// validateProxies rejects proxy URLs the HTTP transport can't use
+func validateProxies(n *NetworkConfig) error {
+	proxies := map[string]string{
+		"network.proxy":            n.Proxy,
+		"network.scraper_proxy":    n.ScraperProxy,
+		"network.feeds_proxy":      n.FeedsProxy,
+		"network.translator_proxy": n.TranslatorProxy,
+		"network.github_proxy":     n.GitHubProxy,
+	}
+	for key, value := range proxies {
+		v := strings.ToLower(strings.TrimSpace(value))
+		if v == "" || v == "direct" || v == "none" {
+			continue
+		}
+		u, err := url.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("invalid %s: unsupported scheme %q (use http, https or socks5)", key, u.Scheme)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("invalid %s: missing host", key)
+		}
+	}
+	return nil
+}
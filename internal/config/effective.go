@@ -0,0 +1,175 @@
+package config
+
+import (
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// secretKeys are config keys (last path segment) whose values are masked
+var secretKeys = map[string]bool{
+	"api_key":  true,
+	"token":    true,
+	"password": true,
+	"secret":   true,
+}
+
+// secretEnvVars are secrets that are only read from the environment
+var secretEnvVars = []string{"GITHUB_TOKEN", "DEEPL_API_KEY", "OPENROUTER_API_KEY", "GOOGLE_TRANSLATE_API_KEY"}
+
+// Effective describes the configuration actually in effect after Load:
+// merged settings (secrets masked), the config file used and where every
+// key's value came from.
+type Effective struct {
+	ConfigFile string            `json:"config_file"`
+	Settings   map[string]any    `json:"settings"`
+	Sources    map[string]string `json:"sources"` // key -> "env", "file" or "default"
+	Env        map[string]string `json:"env"`     // secret env vars (masked), empty if unset
+}
+
+// Describe returns the effective configuration. Must be called after Load.
+// Resolved paths from cfg replace the raw (possibly relative) values.
+func Describe(cfg *Config) *Effective {
+	eff := &Effective{
+		ConfigFile: viper.ConfigFileUsed(),
+		Settings:   maskSecrets(viper.AllSettings()),
+		Sources:    make(map[string]string),
+		Env:        make(map[string]string),
+	}
+	if eff.ConfigFile == "" {
+		eff.ConfigFile = "(none, defaults only)"
+	}
+
+	if cfg != nil {
+		setNested(eff.Settings, "database.path", cfg.Database.Path)
+		setNested(eff.Settings, "hugo.path", cfg.Hugo.Path)
+	}
+
+	keys := viper.AllKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := os.LookupEnv(EnvKey(key)); ok {
+			eff.Sources[key] = "env"
+		} else if viper.InConfig(key) {
+			eff.Sources[key] = "file"
+		} else {
+			eff.Sources[key] = "default"
+		}
+	}
+
+	for _, name := range secretEnvVars {
+		eff.Env[name] = MaskSecret(os.Getenv(name))
+	}
+
+	return eff
+}
+
+// MaskSecret hides all but the last 4 characters of a secret
+func MaskSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= 4 {
+		return "****"
+	}
+	return "****" + s[len(s)-4:]
+}
+
+// secretQueryParams are query parameters masked by RedactURL
+var secretQueryParams = map[string]bool{
+	"key": true, "apikey": true, "api_key": true, "token": true, "access_token": true,
+	"auth": true, "password": true, "secret": true, "sig": true, "signature": true,
+}
+
+// RedactURL masks userinfo and secret-looking query parameters so feed URLs
+// with embedded credentials can be logged or shown
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if u.User != nil {
+		u.User = url.User("***")
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for name := range q {
+			if secretQueryParams[strings.ToLower(name)] {
+				q.Set(name, "***")
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// maskSecrets returns a copy of a nested settings map with secret values masked
+func maskSecrets(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		switch val := v.(type) {
+		case map[string]any:
+			if strings.ToLower(k) == "headers" {
+				out[k] = maskAll(val)
+				continue
+			}
+			out[k] = maskSecrets(val)
+		case []any:
+			items := make([]any, len(val))
+			for i, item := range val {
+				switch item := item.(type) {
+				case map[string]any:
+					items[i] = maskSecrets(item)
+				case string:
+					if strings.ToLower(k) == "feeds" {
+						items[i] = RedactURL(item) // credentials in the URL, as in logs
+					} else {
+						items[i] = item
+					}
+				default:
+					items[i] = item
+				}
+			}
+			out[k] = items
+		case string:
+			if secretKeys[strings.ToLower(k)] {
+				out[k] = MaskSecret(val)
+			} else {
+				out[k] = val
+			}
+		default:
+			out[k] = val
+		}
+	}
+	return out
+}
+
+// maskAll masks every string value (feed headers usually carry keys)
+func maskAll(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if str, ok := v.(string); ok {
+			out[k] = MaskSecret(str)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// setNested sets a dotted key in a nested settings map, creating levels as needed
+func setNested(m map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[p] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
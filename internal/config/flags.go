@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// providerPlaceholder in a FlagOverride key is replaced with the effective
+// translator.provider, so e.g. --model follows --provider
+const providerPlaceholder = "{provider}"
+
+// FlagOverride maps a command-line flag onto a config key for one
+// invocation. Precedence is flag > env > file > default; a flag only counts
+// when it was given explicitly.
+type FlagOverride struct {
+	Flag string // flag name without dashes
+	Key  string // config key, e.g. "translator.ollama.model" or "translator.{provider}.model"
+}
+
+// LoadWithOverrides is Load with the given flags layered on top of the
+// environment and the config file
+func LoadWithOverrides(configPath string, flags *pflag.FlagSet, overrides []FlagOverride) (*Config, error) {
+	return load(configPath, func() error {
+		return bindFlags(flags, overrides)
+	})
+}
+
+// bindFlags binds the explicitly set override flags. Plain keys are bound
+// first so a --provider override is already visible when resolving
+// "{provider}" keys.
+func bindFlags(flags *pflag.FlagSet, overrides []FlagOverride) error {
+	if flags == nil {
+		return nil
+	}
+	var deferred []FlagOverride
+	for _, o := range overrides {
+		if strings.Contains(o.Key, providerPlaceholder) {
+			deferred = append(deferred, o)
+			continue
+		}
+		if err := bindFlag(flags, o.Flag, o.Key); err != nil {
+			return err
+		}
+	}
+
+	provider := viper.GetString("translator.provider")
+	for _, o := range deferred {
+		key := strings.ReplaceAll(o.Key, providerPlaceholder, provider)
+		if f := flags.Lookup(o.Flag); f != nil && f.Changed && !knownKey(reflect.TypeOf(Config{}), key) {
+			return fmt.Errorf("--%s is not supported for translator.provider %q", o.Flag, provider)
+		}
+		if err := bindFlag(flags, o.Flag, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindFlag binds one flag to key; flags that were not given are skipped so
+// their defaults never shadow the config file
+func bindFlag(flags *pflag.FlagSet, name, key string) error {
+	f := flags.Lookup(name)
+	if f == nil || !f.Changed {
+		return nil
+	}
+	if err := viper.BindPFlag(key, f); err != nil {
+		return fmt.Errorf("bind --%s to %s: %w", name, key, err)
+	}
+	return nil
+}
+
+// knownKey reports whether the dotted key names a field of t (by its
+// mapstructure tags)
+func knownKey(t reflect.Type, key string) bool {
+	head, rest, nested := strings.Cut(key, ".")
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("mapstructure") != head {
+			continue
+		}
+		if !nested {
+			return true
+		}
+		return field.Type.Kind() == reflect.Struct && knownKey(field.Type, rest)
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var testOverrides = []FlagOverride{
+	{Flag: "provider", Key: "translator.provider"},
+	{Flag: "model", Key: "translator.{provider}.model"},
+	{Flag: "host", Key: "translator.{provider}.host"},
+	{Flag: "branch", Key: "hugo.git_branch"},
+}
+
+// loadWithFlags loads a config file holding data with args parsed into the
+// override flags; viper is reset before and after
+func loadWithFlags(t *testing.T, data string, args ...string) (*Config, error) {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	for _, o := range testOverrides {
+		flags.String(o.Flag, "", "")
+	}
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return LoadWithOverrides(path, flags, testOverrides)
+}
+
+const flagsTestConfig = `
+translator:
+  provider: ollama
+  ollama:
+    model: gemma2:9b
+    host: http://file:11434
+  openrouter:
+    model: file/model
+hugo:
+  git_branch: main
+`
+
+func TestFlagOverridesWin(t *testing.T) {
+	t.Setenv(EnvPrefix+"_TRANSLATOR_OLLAMA_MODEL", "env-model")
+	t.Setenv(EnvPrefix+"_HUGO_GIT_BRANCH", "env-branch")
+
+	cfg, err := loadWithFlags(t, flagsTestConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Translator.Ollama.Model != "env-model" || cfg.Hugo.GitBranch != "env-branch" || cfg.Translator.Ollama.Host != "http://file:11434" {
+		t.Errorf("no flags: model=%q branch=%q host=%q, want env over file", cfg.Translator.Ollama.Model, cfg.Hugo.GitBranch, cfg.Translator.Ollama.Host)
+	}
+
+	cfg, err = loadWithFlags(t, flagsTestConfig, "--model", "gemma2:27b", "--host", "http://flag:11434", "--branch", "drafts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Translator.Ollama.Model != "gemma2:27b" || cfg.Translator.Ollama.Host != "http://flag:11434" || cfg.Hugo.GitBranch != "drafts" {
+		t.Errorf("flags: model=%q host=%q branch=%q, want the flag values", cfg.Translator.Ollama.Model, cfg.Translator.Ollama.Host, cfg.Hugo.GitBranch)
+	}
+}
+
+func TestFlagModelFollowsProvider(t *testing.T) {
+	cfg, err := loadWithFlags(t, flagsTestConfig, "--provider", "openrouter", "--model", "flag/model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Translator.Provider != "openrouter" || cfg.Translator.OpenRouter.Model != "flag/model" || cfg.Translator.Ollama.Model != "gemma2:9b" {
+		t.Errorf("provider=%q openrouter model=%q ollama model=%q, want the flag model on openrouter only",
+			cfg.Translator.Provider, cfg.Translator.OpenRouter.Model, cfg.Translator.Ollama.Model)
+	}
+
+	// DeepL has no model setting
+	if _, err := loadWithFlags(t, flagsTestConfig, "--provider", "deepl", "--model", "x"); err == nil {
+		t.Error("expected --model to be refused for deepl")
+	}
+}
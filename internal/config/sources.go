@@ -0,0 +1,107 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// AddSource appends a source to the sources list of the config file that
+// was loaded. The file goes through a yaml.Node tree rather than
+// viper.WriteConfig, so comments and key order survive.
+func AddSource(src SourceConfig) (string, error) {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return "", fmt.Errorf("no config file loaded; create config.yaml first")
+	}
+	if src.Name == "" || len(src.Feeds) == 0 {
+		return "", fmt.Errorf("source needs a name and at least one feed")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	data, err = appendSource(data, src)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// appendSource adds src to the end of the top-level sources list of the
+// YAML document data, creating the list when there is none
+func appendSource(data []byte, src SourceConfig) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if doc.Kind == 0 {
+		// empty file
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top level is not a mapping")
+	}
+
+	var sources *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "sources" {
+			sources = root.Content[i+1]
+			break
+		}
+	}
+	switch {
+	case sources == nil:
+		sources = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, scalarNode("sources"), sources)
+	case sources.Kind == yaml.ScalarNode && sources.Tag == "!!null":
+		// "sources:" with nothing after it
+		*sources = yaml.Node{Kind: yaml.SequenceNode, HeadComment: sources.HeadComment, LineComment: sources.LineComment}
+	case sources.Kind != yaml.SequenceNode:
+		return nil, fmt.Errorf("sources is not a list")
+	}
+	for _, existing := range sources.Content {
+		var s struct {
+			Name string `yaml:"name"`
+		}
+		if existing.Decode(&s) == nil && s.Name == src.Name {
+			return nil, fmt.Errorf("source %q already exists", src.Name)
+		}
+	}
+
+	feeds := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, feed := range src.Feeds {
+		feeds.Content = append(feeds.Content, scalarNode(feed))
+	}
+	sources.Style = 0 // block style, also for a list that was written as []
+	sources.Content = append(sources.Content, &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		scalarNode("name"), scalarNode(src.Name),
+		scalarNode("feeds"), feeds,
+		scalarNode("enabled"), {Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(src.Enabled)},
+	}})
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scalarNode is a plain string node; the encoder quotes it when needed
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
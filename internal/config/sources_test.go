@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sourceNames decodes data and returns the names in its sources list
+func sourceNames(t *testing.T, data []byte) []string {
+	t.Helper()
+	var cfg struct {
+		Sources []struct {
+			Name    string   `yaml:"name"`
+			Feeds   []string `yaml:"feeds"`
+			Enabled bool     `yaml:"enabled"`
+		} `yaml:"sources"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("result is not valid YAML: %v\n%s", err, data)
+	}
+	var names []string
+	for _, s := range cfg.Sources {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestAppendSource(t *testing.T) {
+	src := SourceConfig{Name: "new: site", Feeds: []string{"https://example.com/feed?a=1&b=#x"}, Enabled: true}
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"existing list", "# top\nserver:\n  port: 8080 # port\nsources:\n  # first source\n  - name: old\n    feeds:\n      - https://old.example/feed\n    enabled: false\n", []string{"old", "new: site"}},
+		{"no list", "server:\n  port: 8080\n", []string{"new: site"}},
+		{"empty key", "sources:\nserver:\n  port: 8080\n", []string{"new: site"}},
+		{"flow list", "sources: []\n", []string{"new: site"}},
+		{"empty file", "", []string{"new: site"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := appendSource([]byte(tt.in), src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sourceNames(t, out); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("sources = %q, want %q\n%s", got, tt.want, out)
+			}
+			for _, comment := range []string{"# top", "# port", "# first source"} {
+				if strings.Contains(tt.in, comment) && !strings.Contains(string(out), comment) {
+					t.Errorf("comment %q lost:\n%s", comment, out)
+				}
+			}
+			if !strings.Contains(string(out), "https://example.com/feed?a=1&b=#x") {
+				t.Errorf("feed URL mangled:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestAppendSourceRejects(t *testing.T) {
+	src := SourceConfig{Name: "old", Feeds: []string{"https://example.com/feed"}}
+	for name, in := range map[string]string{
+		"duplicate":     "sources:\n  - name: old\n",
+		"not a list":    "sources: old\n",
+		"invalid":       "sources: [\n",
+		"not a mapping": "- a\n- b\n",
+	} {
+		if _, err := appendSource([]byte(in), src); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestAppendSourceRepoConfig(t *testing.T) {
+	data, err := os.ReadFile("../../config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := sourceNames(t, data)
+	out, err := appendSource(data, SourceConfig{Name: "added", Feeds: []string{"https://example.com/feed"}, Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := sourceNames(t, out)
+	if len(after) != len(before)+1 || after[len(after)-1] != "added" {
+		t.Errorf("sources %q -> %q, want one more at the end", before, after)
+	}
+}
@@ -0,0 +1,85 @@
+// Package events fans pipeline progress (step started, article done, final
+// counts) out to live listeners such as the SSE endpoint. Publishing never
+// blocks the pipeline: a listener that falls behind loses events instead.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many events a slow listener may lag behind
+const subscriberBuffer = 64
+
+// Event types
+const (
+	StepStarted     = "step.started"     // Step begins; Total = items to process when known
+	StepFinished    = "step.finished"    // Step ends; Counts has the result totals
+	ArticleStarted  = "article.started"  // work on one article begins
+	ArticleFinished = "article.finished" // one article done; Error set when it failed
+	Error           = "error"            // failure not tied to an article (feed, publish batch)
+)
+
+// Event is one progress update
+type Event struct {
+	Type      string         `json:"type"`
+	Step      string         `json:"step"` // fetch, translate, publish, run
+	Time      time.Time      `json:"time"`
+	ArticleID int64          `json:"article_id,omitempty"`
+	Title     string         `json:"title,omitempty"`
+	Source    string         `json:"source,omitempty"`
+	Message   string         `json:"message,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Index     int            `json:"index,omitempty"` // 1-based position of the article in the step
+	Total     int            `json:"total,omitempty"`
+	Counts    map[string]int `json:"counts,omitempty"`
+}
+
+// Bus delivers published events to every current subscriber. A nil *Bus
+// is valid and drops everything.
+type Bus struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus() *Bus {
+	return &Bus{subs: make(map[chan Event]struct{})}
+}
+
+// Publish sends e to all subscribers, skipping those whose buffer is full
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a listener. The returned func unsubscribes and
+// closes the channel; call it when the listener goes away.
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
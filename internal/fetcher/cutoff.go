@@ -0,0 +1,69 @@
+package fetcher
+
+import (
+	"regexp"
+	"strings"
+)
+
+// cutoffMarker is one scraper.cutoff_markers entry: a case-insensitive
+// paragraph prefix, or a regexp when written as "re:<pattern>"
+type cutoffMarker struct {
+	prefix string
+	re     *regexp.Regexp
+}
+
+// parseCutoffMarkers compiles marker strings. Invalid regexps are rejected
+// by config.Load, so compile errors here just skip the marker.
+func parseCutoffMarkers(markers []string) []cutoffMarker {
+	var parsed []cutoffMarker
+	for _, m := range markers {
+		if pattern, ok := strings.CutPrefix(m, "re:"); ok {
+			if re, err := regexp.Compile(pattern); err == nil {
+				parsed = append(parsed, cutoffMarker{re: re})
+			}
+			continue
+		}
+		if m = strings.ToLower(strings.TrimSpace(m)); m != "" {
+			parsed = append(parsed, cutoffMarker{prefix: m})
+		}
+	}
+	return parsed
+}
+
+func (m cutoffMarker) matches(paragraph string) bool {
+	if m.re != nil {
+		return m.re.MatchString(paragraph)
+	}
+	return strings.HasPrefix(strings.ToLower(paragraph), m.prefix)
+}
+
+// cutAtMarkers drops everything from the first paragraph matching a global
+// or per-source cutoff marker onwards (promo blocks, "Source:" lines).
+// Paragraphs are the non-blank lines of content. A match on the very first
+// paragraph is ignored so a marker never empties the article.
+func (s *ArticleScraper) cutAtMarkers(content, source string) string {
+	markers := s.cutoffMarkers[""]
+	markers = append(markers[:len(markers):len(markers)], s.cutoffMarkers[source]...)
+	if len(markers) == 0 || content == "" {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	first := true
+	for i, line := range lines {
+		p := strings.TrimSpace(line)
+		if p == "" {
+			continue
+		}
+		if first {
+			first = false
+			continue
+		}
+		for _, m := range markers {
+			if m.matches(p) {
+				return strings.TrimRight(strings.Join(lines[:i], "\n"), "\n")
+			}
+		}
+	}
+	return content
+}
@@ -0,0 +1,95 @@
+package fetcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"moto-news/internal/config"
+)
+
+func TestCleanContentCutoffMarkers(t *testing.T) {
+	s := newTestScraper(func(c *config.ScraperConfig) {
+		c.CutoffMarkers = []string{"Follow us on", "re:^(Source|Via):"}
+		c.SourceCutoffMarkers = map[string][]string{"RideApart": {"Got a tip for us?"}}
+	})
+	body := []string{
+		"Honda confirmed the new Transalp for the European market.",
+		"Deliveries start in March at dealers across the region.",
+	}
+	tests := []struct {
+		name   string
+		source string
+		promo  []string
+		want   []string
+	}{
+		{
+			name:  "prefix marker, any case",
+			promo: []string{"FOLLOW US ON Instagram and Facebook for more news.", "Shop the best helmets of the year here."},
+			want:  body,
+		},
+		{
+			name:  "regexp marker",
+			promo: []string{"Source: Honda press release about the new model.", "Related: Ten adventure bikes to buy this year."},
+			want:  body,
+		},
+		{
+			name:   "source marker on its source",
+			source: "RideApart",
+			promo:  []string{"Got a tip for us? Email us at tips@example.com any time.", "Shop the best helmets of the year here."},
+			want:   body,
+		},
+		{
+			name:   "source marker elsewhere",
+			source: "Other",
+			promo:  []string{"Got a tip for us? Email us at tips@example.com any time.", "Shop the best helmets of the year here."},
+			// only the built-in boilerplate filter applies
+			want: append(body[:len(body):len(body)], "Shop the best helmets of the year here."),
+		},
+		{
+			// the marker must start the paragraph
+			name:  "marker mid-paragraph",
+			promo: []string{"Honda Europe posted the news first. Source: the official press release."},
+			want:  append(body[:len(body):len(body)], "Honda Europe posted the news first. Source: the official press release."),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := paragraphHTML(append(body[:len(body):len(body)], tt.promo...))
+			if got, want := s.CleanContent(raw, tt.source), strings.Join(tt.want, "\n\n"); got != want {
+				t.Errorf("CleanContent =\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+
+	// a marker on the first paragraph never empties the article
+	first := []string{"Source: Honda says the Transalp arrives in March.", body[1]}
+	if got, want := s.CleanContent(paragraphHTML(first), ""), strings.Join(first, "\n\n"); got != want {
+		t.Errorf("first paragraph marker: CleanContent = %q, want %q", got, want)
+	}
+}
+
+func TestDebugAppliesSourceCutoffMarkers(t *testing.T) {
+	s := newTestScraper(func(c *config.ScraperConfig) {
+		c.SourceCutoffMarkers = map[string][]string{"RideApart": {"Shop the best"}}
+	})
+	body := "Honda confirmed the new Transalp for the European market.\n\n" +
+		"Deliveries start in March at dealers across the region."
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(articlePage(body + "\n\nShop the best helmets of the year here.")))
+	}))
+	defer srv.Close()
+
+	// the report shows what a scrape of the source would store
+	debug, err := s.Debug(srv.URL, "RideApart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if debug.Content != body {
+		t.Errorf("content = %q, want %q", debug.Content, body)
+	}
+	if debug, err := s.Debug(srv.URL, "Other"); err != nil || !strings.Contains(debug.Content, "Shop the best") {
+		t.Errorf("other source: content = %q (%v), want the paragraph kept", debug.Content, err)
+	}
+}
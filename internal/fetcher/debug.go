@@ -0,0 +1,123 @@
+package fetcher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxDebugChars caps every text field of a debug report so a huge page
+// doesn't turn into a multi-megabyte API response.
+const maxDebugChars = 20000
+
+// ScrapeDebug describes what the scraper saw on a page and which strategy won.
+// Nothing is persisted — it's for troubleshooting empty or partial scrapes.
+type ScrapeDebug struct {
+	URL           string           `json:"url"`
+	FinalURL      string           `json:"final_url,omitempty"` // where redirects led, when not URL
+	HTMLLength    int              `json:"html_length"`
+	Strategy      string           `json:"strategy"`           // "json-ld", "html" or "none"
+	Selector      string           `json:"selector,omitempty"` // CSS selector that matched (html strategy)
+	ContentLength int              `json:"content_length"`
+	Content       string           `json:"content"`
+	Category      string           `json:"category,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	ImageURLs     []string         `json:"image_urls,omitempty"`
+	JSONLDBlocks  []string         `json:"jsonld_blocks"`
+	Candidates    []DebugCandidate `json:"candidates"`
+	RawHTML       string           `json:"raw_html"`
+	Truncated     bool             `json:"truncated"` // at least one field was cut to maxDebugChars
+}
+
+// DebugCandidate is one HTML selector the fallback strategy considered
+type DebugCandidate struct {
+	Selector   string `json:"selector"`
+	Paragraphs int    `json:"paragraphs"`
+	Chars      int    `json:"chars"`
+	Preview    string `json:"preview,omitempty"`
+}
+
+// Debug fetches pageURL and runs both extraction strategies, reporting every
+// candidate block instead of stopping at the first match. source selects the
+// per-source cutoff markers, as for a real scrape of the page.
+func (s *ArticleScraper) Debug(pageURL, source string) (*ScrapeDebug, error) {
+	page, err := s.fetchPage(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	htmlStr := page.html
+
+	result := &ScrapeDebug{
+		URL:          pageURL,
+		HTMLLength:   len(htmlStr),
+		Strategy:     "none",
+		JSONLDBlocks: []string{},
+		Candidates:   []DebugCandidate{},
+	}
+	if page.finalURL != pageURL {
+		result.FinalURL = page.finalURL
+	}
+
+	for _, match := range jsonLDRe.FindAllStringSubmatch(htmlStr, -1) {
+		if len(match) < 2 {
+			continue
+		}
+		result.JSONLDBlocks = append(result.JSONLDBlocks, result.capText(strings.TrimSpace(match[1])))
+	}
+
+	raw, imageURLs, category, tags := s.extractFromJSONLD(htmlStr)
+	content := s.CleanContent(raw, source)
+	if content != "" {
+		result.Strategy = "json-ld"
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlStr))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	for _, selector := range contentSelectors {
+		paragraphs := selectorParagraphs(doc, selector)
+		joined := strings.Join(paragraphs, "\n\n")
+		result.Candidates = append(result.Candidates, DebugCandidate{
+			Selector:   selector,
+			Paragraphs: len(paragraphs),
+			Chars:      len(joined),
+			Preview:    result.capText(joined),
+		})
+		if result.Strategy == "none" && len(paragraphs) > 0 {
+			result.Strategy = "html"
+			result.Selector = selector
+		}
+	}
+
+	if result.Strategy == "html" {
+		var htmlCategory string
+		raw, imageURLs, htmlCategory, tags = s.extractFromHTML(htmlStr)
+		content = s.CleanContent(raw, source)
+		if category == "" {
+			category = htmlCategory
+		}
+	}
+
+	result.ContentLength = len(content)
+	result.Content = result.capText(content)
+	result.Category = category
+	result.Tags = uniqueStrings(tags)
+	if result.Strategy == "json-ld" {
+		imageURLs = append(imageURLs, bodyImageURLs(doc)...)
+	}
+	result.ImageURLs = s.galleryImages(pageURL, imageURLs)
+	result.RawHTML = result.capText(htmlStr)
+
+	return result, nil
+}
+
+// capText truncates text to maxDebugChars and records that it did
+func (d *ScrapeDebug) capText(text string) string {
+	if len(text) <= maxDebugChars {
+		return text
+	}
+	d.Truncated = true
+	return strings.ToValidUTF8(text[:maxDebugChars], "") + "…"
+}
@@ -0,0 +1,133 @@
+package fetcher
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DiscoveredFeed is a feed URL found on a site and checked with gofeed
+type DiscoveredFeed struct {
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Type   string `json:"type"` // rss, atom or json as reported by gofeed
+	Items  int    `json:"items"`
+	Source string `json:"source"` // "link" (<link rel=alternate>) or "guess"
+	Error  string `json:"error,omitempty"`
+}
+
+// feedLinkTypes are the <link rel="alternate"> types treated as feeds
+var feedLinkTypes = []string{"application/rss+xml", "application/atom+xml", "application/feed+json"}
+
+// feedPathGuesses are tried relative to the site root when the page does
+// not advertise its feeds
+var feedPathGuesses = []string{"/feed", "/feed/", "/rss", "/rss/", "/rss.xml", "/atom.xml", "/index.xml", "/feed.xml"}
+
+// Discover fetches siteURL, collects the feeds it advertises via
+// <link rel="alternate"> plus common feed paths, and validates each one by
+// parsing it. Advertised feeds that fail to parse are returned with Error set;
+// failed guesses are dropped.
+func (f *RSSFetcher) Discover(siteURL string) ([]DiscoveredFeed, error) {
+	base, err := url.Parse(strings.TrimSpace(siteURL))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid site URL %q", siteURL)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("invalid site URL %q: scheme must be http or https", siteURL)
+	}
+
+	var candidates []DiscoveredFeed
+	seen := make(map[string]bool)
+	add := func(href, source string) {
+		ref, err := url.Parse(strings.TrimSpace(href))
+		if err != nil || href == "" {
+			return
+		}
+		abs := base.ResolveReference(ref)
+		abs.Fragment = ""
+		key := abs.String()
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		candidates = append(candidates, DiscoveredFeed{URL: key, Source: source})
+	}
+
+	links, err := f.advertisedFeeds(base.String())
+	if err != nil {
+		return nil, err
+	}
+	for _, href := range links {
+		add(href, "link")
+	}
+	for _, p := range feedPathGuesses {
+		add(p, "guess")
+	}
+
+	var found []DiscoveredFeed
+	selfLinks := make(map[string]bool)
+	for _, c := range candidates {
+		feed, err := f.parser.ParseURL(c.URL)
+		if err != nil {
+			if c.Source == "link" {
+				c.Error = err.Error()
+				found = append(found, c)
+			}
+			continue
+		}
+		// The same feed is often reachable at /feed and /feed/
+		if feed.FeedLink != "" {
+			if selfLinks[feed.FeedLink] {
+				continue
+			}
+			selfLinks[feed.FeedLink] = true
+		}
+		c.Title = strings.TrimSpace(feed.Title)
+		c.Type = feed.FeedType
+		c.Items = len(feed.Items)
+		found = append(found, c)
+	}
+	return found, nil
+}
+
+// advertisedFeeds returns the href of every feed <link rel="alternate"> on the page
+func (f *RSSFetcher) advertisedFeeds(pageURL string) ([]string, error) {
+	req, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", pageURL, err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+
+	resp, err := f.parser.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("unexpected status %d for %s", resp.StatusCode, pageURL)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", pageURL, err)
+	}
+
+	var hrefs []string
+	doc.Find("link[rel='alternate']").Each(func(i int, sel *goquery.Selection) {
+		typ := strings.ToLower(strings.TrimSpace(sel.AttrOr("type", "")))
+		for _, t := range feedLinkTypes {
+			if typ == t {
+				hrefs = append(hrefs, sel.AttrOr("href", ""))
+				return
+			}
+		}
+	})
+	return hrefs, nil
+}
@@ -0,0 +1,79 @@
+package fetcher
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"moto-news/internal/config"
+)
+
+// Feed check statuses
+const (
+	FeedOK          = "ok"
+	FeedHTTPError   = "http_error"  // non-2xx response
+	FeedUnreachable = "unreachable" // DNS, connection or timeout failure
+	FeedParseError  = "parse_error" // response is not a feed gofeed understands
+	FeedEmpty       = "empty"       // valid feed without items
+)
+
+// FeedCheck is the health of one feed URL
+type FeedCheck struct {
+	URL    string     `json:"url"` // credentials redacted
+	Status string     `json:"status"`
+	Error  string     `json:"error,omitempty"`
+	Items  int        `json:"items"`
+	Newest *time.Time `json:"newest,omitempty"` // latest item date (published, else updated)
+}
+
+// Down reports whether the feed yields no items at all
+func (c *FeedCheck) Down() bool {
+	return c.Status != FeedOK
+}
+
+// CheckFeed downloads and parses a feed without converting its items,
+// classifying what went wrong when it does not work. auth may be nil.
+func (f *RSSFetcher) CheckFeed(feedURL string, auth *FeedAuth) FeedCheck {
+	check := FeedCheck{URL: config.RedactURL(feedURL)}
+
+	resp, err := f.get(feedURL, auth)
+	if err != nil {
+		check.Status = FeedUnreachable
+		check.Error = err.Error()
+		return check
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(io.Discard, resp.Body)
+		check.Status = FeedHTTPError
+		check.Error = fmt.Sprintf("HTTP %s", resp.Status)
+		return check
+	}
+
+	feed, err := f.parser.Parse(resp.Body)
+	if err != nil {
+		check.Status = FeedParseError
+		check.Error = err.Error()
+		return check
+	}
+
+	for _, item := range feed.Items {
+		if item == nil {
+			continue
+		}
+		check.Items++
+		date := item.PublishedParsed
+		if date == nil {
+			date = item.UpdatedParsed
+		}
+		if date != nil && (check.Newest == nil || date.After(*check.Newest)) {
+			check.Newest = date
+		}
+	}
+	check.Status = FeedOK
+	if check.Items == 0 {
+		check.Status = FeedEmpty
+	}
+	return check
+}
@@ -0,0 +1,89 @@
+package fetcher
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"moto-news/internal/models"
+)
+
+const (
+	// feedContentAutoChars is how long the feed body must be for it to be used
+	// without scraping when the source does not set use_feed_content
+	feedContentAutoChars = 1500
+	// feedContentMinChars is the floor below which even use_feed_content
+	// falls back to scraping (teaser-only feeds)
+	feedContentMinChars = 300
+)
+
+// ApplyFeedContent fills article content from the full HTML embedded in the
+// feed item (content:encoded) instead of scraping the page. It is used when the
+// body is substantial, or when force is set and the body is not a mere teaser.
+// Images, category and tags from the feed go through the same cleanup as
+// scraped ones. Returns false when the caller should scrape the page.
+func (s *ArticleScraper) ApplyFeedContent(article *models.Article, force bool) bool {
+	if article == nil || article.FeedContent == "" {
+		return false
+	}
+
+	raw, imageURLs := s.feedHTMLToText(article.FeedContent)
+	content := s.CleanContent(raw, article.SourceSite)
+	threshold := feedContentAutoChars
+	if force {
+		threshold = feedContentMinChars
+	}
+	if len(content) < threshold {
+		return false
+	}
+
+	article.Content = content
+	article.RawContent = s.keptRaw(raw)
+	if imageURLs = s.galleryImages(article.SourceURL, append(article.ImageURLs, imageURLs...)); len(imageURLs) > 0 {
+		article.ImageURLs = imageURLs
+		if article.ImageURL == "" {
+			article.ImageURL = imageURLs[0]
+		}
+	}
+	category, tags := article.Category, article.Tags
+	article.Category, article.Tags = "", nil
+	s.applyTaxonomy(article, category, tags)
+	return true
+}
+
+// feedHTMLToText converts feed HTML into the same raw paragraph form the
+// scraper extracts (see CleanContent) and collects image URLs in document order
+func (s *ArticleScraper) feedHTMLToText(htmlStr string) (string, []string) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlStr))
+	if err != nil {
+		return "", nil
+	}
+
+	var paragraphs []string
+	doc.Find("p, h2, h3, h4, li, blockquote").Each(func(i int, sel *goquery.Selection) {
+		// Nested matches (p inside blockquote/li) are taken from the inner node
+		if sel.Find("p").Length() > 0 {
+			return
+		}
+		text := strings.TrimSpace(sel.Text())
+		if text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	})
+	if len(paragraphs) == 0 {
+		// Plain-text bodies without block markup
+		paragraphs = []string{strings.TrimSpace(doc.Text())}
+	}
+
+	var imageURLs []string
+	doc.Find("img").Each(func(i int, img *goquery.Selection) {
+		src, _ := img.Attr("src")
+		if src == "" {
+			src, _ = img.Attr("data-src")
+		}
+		if src != "" {
+			imageURLs = append(imageURLs, src)
+		}
+	})
+
+	return paragraphHTML(paragraphs), imageURLs
+}
@@ -0,0 +1,51 @@
+package fetcher
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+// feedBody is content:encoded HTML of n paragraphs
+func feedBody(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "<p>Paragraph %d of the full article text that the feed carries in content:encoded.</p>", i)
+	}
+	return b.String()
+}
+
+func TestApplyFeedContent(t *testing.T) {
+	s := newTestScraper(func(cfg *config.ScraperConfig) { cfg.MaxTags = 2 })
+
+	teaser := &models.Article{SourceURL: "https://example.com/a", FeedContent: feedBody(2)}
+	if s.ApplyFeedContent(teaser, false) || s.ApplyFeedContent(teaser, true) {
+		t.Error("a teaser was used instead of scraping")
+	}
+
+	full := &models.Article{
+		SourceURL:   "https://example.com/news/a",
+		FeedContent: feedBody(30) + `<img src="/img/1.jpg"><img src="data:image/png;base64,AAAA">`,
+		Category:    "  Reviews ",
+		Tags:        []string{" Ducati", "Ducati", "", "Panigale", "V4"},
+	}
+	if !s.ApplyFeedContent(full, false) {
+		t.Fatal("full feed content not used")
+	}
+	if !strings.HasPrefix(full.Content, "Paragraph 1 of") {
+		t.Errorf("content = %.60q", full.Content)
+	}
+	if full.Category != "Reviews" {
+		t.Errorf("category = %q, want it trimmed", full.Category)
+	}
+	if !slices.Equal(full.Tags, []string{"Ducati", "Panigale"}) {
+		t.Errorf("tags = %q, want deduplicated and capped at 2", full.Tags)
+	}
+	if !slices.Equal(full.ImageURLs, []string{"https://example.com/img/1.jpg"}) || full.ImageURL != full.ImageURLs[0] {
+		t.Errorf("images = %q (featured %q)", full.ImageURLs, full.ImageURL)
+	}
+}
@@ -0,0 +1,78 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"moto-news/internal/models"
+)
+
+// SetRetries makes FetchMultipleFeeds retry a feed that failed with a
+// transient error up to retries times; the wait before a retry starts at
+// delay and doubles each time (retries <= 0 = one attempt)
+func (f *RSSFetcher) SetRetries(retries int, delay time.Duration) {
+	f.retries = retries
+	f.retryDelay = delay
+}
+
+// FetchFeedWithRetry is FetchFeed retried on transient errors (see
+// IsTransientFeedError). Permanent errors such as 404 or a body that is not
+// a feed fail at once, and so does a retry wait cut short by ctx. Also
+// returns the errors of the attempts that were retried, so the attempts
+// made are len(retried)+1.
+func (f *RSSFetcher) FetchFeedWithRetry(ctx context.Context, feedURL string, sourceSite string, auth *FeedAuth, fields *FieldMapping) ([]*models.Article, []error, error) {
+	var retried []error
+	delay := f.retryDelay
+	for attempt := 1; ; attempt++ {
+		articles, err := f.FetchFeed(feedURL, sourceSite, auth, fields)
+		if err == nil || attempt > f.retries || !IsTransientFeedError(err) {
+			return articles, retried, err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, retried, err
+		}
+		retried = append(retried, err)
+		delay *= 2
+	}
+}
+
+// IsTransientFeedError reports whether a feed request may succeed when
+// repeated: network errors (DNS, refused or reset connections, TLS
+// handshake, timeouts) and HTTP 408, 429 and 5xx. Other HTTP statuses and
+// parse errors, a truncated or invalid XML body included, are permanent.
+func IsTransientFeedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpErr gofeed.HTTPError
+	if errors.As(err, &httpErr) {
+		code := httpErr.StatusCode
+		return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+	}
+	var syntaxErr *xml.SyntaxError
+	if errors.Is(err, gofeed.ErrFeedTypeNotDetected) || errors.As(err, &syntaxErr) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr), errors.As(err, &opErr):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const retryTestFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Test</title>
+<item><title>Item</title><link>https://example.com/item</link></item>
+</channel></rss>`
+
+// feedServer answers with the given status codes in turn, then with the feed
+func feedServer(t *testing.T, body string, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestIsTransientFeedError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"503", gofeed.HTTPError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"429", gofeed.HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		{"408", gofeed.HTTPError{StatusCode: http.StatusRequestTimeout}, true},
+		{"404", gofeed.HTTPError{StatusCode: http.StatusNotFound}, false},
+		{"not a feed", gofeed.ErrFeedTypeNotDetected, false},
+		{"EOF", io.EOF, false},
+		{"unexpected EOF", fmt.Errorf("failed to parse feed: %w", io.ErrUnexpectedEOF), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransientFeedError(tt.err); got != tt.want {
+			t.Errorf("%s: IsTransientFeedError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFetchFeedWithRetry(t *testing.T) {
+	srv, requests := feedServer(t, retryTestFeed, http.StatusServiceUnavailable, http.StatusBadGateway)
+	f := NewRSSFetcher(nil)
+	f.SetRetries(2, time.Millisecond)
+
+	articles, retried, err := f.FetchFeedWithRetry(context.Background(), srv.URL, "test", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts := len(retried) + 1; attempts != 3 || requests.Load() != 3 || len(articles) != 1 {
+		t.Errorf("attempts = %d, requests = %d, articles = %d, want 3, 3 and 1", attempts, requests.Load(), len(articles))
+	}
+}
+
+func TestFetchFeedWithRetryPermanentErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		statuses []int
+	}{
+		{"404", retryTestFeed, []int{http.StatusNotFound}},
+		{"invalid XML", `<?xml version="1.0"?><rss version="2.0"><channel><title>Cut`, nil},
+	}
+	for _, tt := range tests {
+		srv, requests := feedServer(t, tt.body, tt.statuses...)
+		f := NewRSSFetcher(nil)
+		f.SetRetries(3, time.Millisecond)
+
+		_, retried, err := f.FetchFeedWithRetry(context.Background(), srv.URL, "test", nil, nil)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+		if attempts := len(retried) + 1; attempts != 1 || requests.Load() != 1 {
+			t.Errorf("%s: attempts = %d, requests = %d, want 1 and 1 (%v)", tt.name, attempts, requests.Load(), err)
+		}
+	}
+}
+
+func TestFetchFeedWithRetryStopsOnCancel(t *testing.T) {
+	srv, requests := feedServer(t, retryTestFeed, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	f := NewRSSFetcher(nil)
+	f.SetRetries(2, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, retried, err := f.FetchFeedWithRetry(ctx, srv.URL, "test", nil, nil)
+	if err == nil {
+		t.Fatal("expected the 503 to be returned")
+	}
+	if attempts := len(retried) + 1; attempts != 1 || requests.Load() != 1 {
+		t.Errorf("attempts = %d, requests = %d, want 1 and 1", attempts, requests.Load())
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("the retry wait ignored the cancelled context")
+	}
+}
+
+func TestFetchMultipleFeedsRecordsRetries(t *testing.T) {
+	srv, _ := feedServer(t, retryTestFeed, http.StatusServiceUnavailable)
+	down, _ := feedServer(t, retryTestFeed, http.StatusBadGateway, http.StatusBadGateway)
+	f := NewRSSFetcher(nil)
+	f.SetRetries(1, time.Millisecond)
+
+	_, results, err := f.FetchMultipleFeeds(context.Background(), []string{srv.URL, down.URL}, "test", nil, nil)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("results = %+v (%v)", results, err)
+	}
+	if ok := results[0]; ok.Attempts != 2 || len(ok.Retried) != 1 || ok.Error != "" || ok.Articles != 1 {
+		t.Errorf("recovered feed: %+v, want 2 attempts with the first error recorded", ok)
+	}
+	if failed := results[1]; failed.Attempts != 2 || len(failed.Retried) != 1 || failed.Error == "" {
+		t.Errorf("failed feed: %+v, want the retried and the final error", failed)
+	}
+}
@@ -0,0 +1,61 @@
+package fetcher
+
+import (
+	"regexp"
+	"strings"
+
+	"moto-news/internal/models"
+)
+
+// ExcludeFilter drops feed items by keyword or regexp (global exclude plus
+// sources[].exclude). Keywords match anywhere in the title or description,
+// case-insensitively; "re:<pattern>" entries are case-insensitive regexps.
+type ExcludeFilter struct {
+	keywords []string
+	patterns []*regexp.Regexp
+	raw      []string // original entry per keyword, then per pattern, for reporting
+}
+
+// NewExcludeFilter compiles exclude entries. Invalid regexps are rejected by
+// config.Load, so compile errors here just skip the entry.
+func NewExcludeFilter(entries ...[]string) *ExcludeFilter {
+	f := &ExcludeFilter{}
+	var keywordRaw, patternRaw []string
+	for _, list := range entries {
+		for _, e := range list {
+			if pattern, ok := strings.CutPrefix(e, "re:"); ok {
+				if re, err := regexp.Compile("(?i)" + pattern); err == nil {
+					f.patterns = append(f.patterns, re)
+					patternRaw = append(patternRaw, e)
+				}
+				continue
+			}
+			if kw := strings.ToLower(strings.TrimSpace(e)); kw != "" {
+				f.keywords = append(f.keywords, kw)
+				keywordRaw = append(keywordRaw, e)
+			}
+		}
+	}
+	f.raw = append(keywordRaw, patternRaw...)
+	return f
+}
+
+// Match returns the entry that excludes the article, or "" when it is kept
+func (f *ExcludeFilter) Match(article *models.Article) string {
+	if f == nil || article == nil {
+		return ""
+	}
+	text := article.Title + "\n" + article.Description
+	lower := strings.ToLower(text)
+	for i, kw := range f.keywords {
+		if strings.Contains(lower, kw) {
+			return f.raw[i]
+		}
+	}
+	for i, re := range f.patterns {
+		if re.MatchString(text) {
+			return f.raw[len(f.keywords)+i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package fetcher
+
+import (
+	"testing"
+
+	"moto-news/internal/models"
+)
+
+func TestExcludeFilter(t *testing.T) {
+	f := NewExcludeFilter([]string{"Sweepstakes", "re:\\bgiveaway\\b"}, []string{"harley", " "})
+	tests := []struct {
+		title, description string
+		want               string
+	}{
+		{"Win a bike in our SWEEPSTAKES", "", "Sweepstakes"},
+		{"New Ducati Panigale", "Enter the Giveaway today", `re:\bgiveaway\b`},
+		{"Harley-Davidson recalls the Pan America", "", "harley"},
+		{"Giveaways are back", "", ""}, // the regexp wants a whole word
+		{"Honda updates the Africa Twin", "A new colour for 2026", ""},
+	}
+	for _, tt := range tests {
+		got := f.Match(&models.Article{Title: tt.title, Description: tt.description})
+		if got != tt.want {
+			t.Errorf("Match(%q, %q) = %q, want %q", tt.title, tt.description, got, tt.want)
+		}
+	}
+
+	var none *ExcludeFilter
+	if got := none.Match(&models.Article{Title: "Sweepstakes"}); got != "" {
+		t.Errorf("nil filter matched %q", got)
+	}
+}
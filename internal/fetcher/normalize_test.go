@@ -0,0 +1,75 @@
+package fetcher
+
+import (
+	"slices"
+	"testing"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		quotes bool
+		want   string
+	}{
+		{"nbsp and zero-width", "200\u00a0hp\u200b and\u202fmore", false, "200 hp and more"},
+		{"runs of spaces", "a   lot \t of  space   ", false, "a lot of space"},
+		{"indentation kept", "Specs:\n    Engine:  1,254 cc\n\tPrice: $17,999", false, "Specs:\n    Engine: 1,254 cc\n\tPrice: $17,999"},
+		{"blank edges dropped", "\n  \nText\n\n", false, "Text"},
+		{"entities left alone", "Fish &amp; chips", false, "Fish &amp; chips"},
+		{"smart quotes kept", "“Fast”, it’s", false, "“Fast”, it’s"},
+		{"smart quotes converted", "“Fast”, it’s", true, `"Fast", it's`},
+		{"symbols untouched", "€15,000 — £12k ± 5% <= x", true, "€15,000 — £12k ± 5% <= x"},
+		{"CRLF", "one\r\ntwo", false, "one\ntwo"},
+	}
+	for _, tt := range tests {
+		if got := normalizeText(tt.in, tt.quotes); got != tt.want {
+			t.Errorf("%s: normalizeText(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeHTMLOnce(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Ducati&#8217;s V4 &amp; the R1", "Ducati’s V4 & the R1"},
+		{"Use &amp;lt;b&amp;gt; for bold", "Use &lt;b&gt; for bold"},
+		{"&amp;amp;", "&amp;"},
+		{"Price: 10&nbsp;000 $", "Price: 10\u00a0000 $"},
+	}
+	for _, tt := range tests {
+		if got := decodeHTML(tt.in, true); got != tt.want {
+			t.Errorf("decodeHTML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCleanContentEntities(t *testing.T) {
+	s := NewArticleScraper(&config.ScraperConfig{NormalizeQuotes: true}, nil)
+	raw := "Honda&#8217;s new CBR&nbsp;650R costs $9,899 &amp; up.\n\n" +
+		"The &ldquo;E-Clutch&rdquo; system is optional.\n\n" +
+		"Type &amp;lt;code&amp;gt; to see an entity."
+	want := "Honda's new CBR 650R costs $9,899 & up.\n\n" +
+		`The "E-Clutch" system is optional.` + "\n\n" +
+		"Type &lt;code&gt; to see an entity."
+	if got := s.CleanContent(raw, ""); got != want {
+		t.Errorf("CleanContent =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestNilConfigScraper(t *testing.T) {
+	s := NewArticleScraper(nil, nil)
+	if got := s.CleanContent("Honda&#8217;s <b>new</b> bike is here.", ""); got != "Honda’s **new** bike is here." {
+		t.Errorf("CleanContent = %q", got)
+	}
+	a := &models.Article{}
+	s.applyTaxonomy(a, " News ", []string{"Honda", "Honda", " "})
+	if a.Category != "News" || !slices.Equal(a.Tags, []string{"Honda"}) {
+		t.Errorf("category = %q, tags = %q", a.Category, a.Tags)
+	}
+	if got := s.keptRaw("raw"); got != "" {
+		t.Errorf("keptRaw = %q, want nothing kept without a config", got)
+	}
+}
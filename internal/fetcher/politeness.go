@@ -0,0 +1,108 @@
+package fetcher
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostLimiter keeps concurrent scraping polite: per host, at most perHost
+// requests run at once and request starts are at least delay apart.
+// Different hosts never wait for each other.
+type HostLimiter struct {
+	perHost int
+	delay   time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+type hostSlot struct {
+	sem  chan struct{}
+	next time.Time // earliest start of the next request
+}
+
+// NewHostLimiter creates a limiter; perHost < 1 is treated as 1
+func NewHostLimiter(perHost int, delay time.Duration) *HostLimiter {
+	if perHost < 1 {
+		perHost = 1
+	}
+	return &HostLimiter{perHost: perHost, delay: delay, hosts: make(map[string]*hostSlot)}
+}
+
+// Acquire waits for a turn at rawURL's host and returns the func that
+// frees the slot once the request is done
+func (l *HostLimiter) Acquire(ctx context.Context, rawURL string) (func(), error) {
+	host := limiterHost(rawURL)
+
+	l.mu.Lock()
+	slot, ok := l.hosts[host]
+	if !ok {
+		slot = &hostSlot{sem: make(chan struct{}, l.perHost)}
+		l.hosts[host] = slot
+	}
+	l.mu.Unlock()
+
+	select {
+	case slot.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-slot.sem }
+
+	l.mu.Lock()
+	start := time.Now()
+	if slot.next.After(start) {
+		start = slot.next
+	}
+	slot.next = start.Add(l.delay)
+	l.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// limiterHost is the key requests are grouped by: the lowercased host
+// without port, or the raw string when it does not parse
+func limiterHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	return rawURL
+}
+
+// InterleaveByHost orders indexes of urls round-robin across hosts, keeping
+// the order within each host, so a worker pool reaches every host early
+// instead of queueing up behind the first one
+func InterleaveByHost(urls []string) []int {
+	var hosts []string
+	byHost := make(map[string][]int)
+	for i, u := range urls {
+		h := limiterHost(u)
+		if _, ok := byHost[h]; !ok {
+			hosts = append(hosts, h)
+		}
+		byHost[h] = append(byHost[h], i)
+	}
+
+	order := make([]int, 0, len(urls))
+	for round := 0; len(order) < len(urls); round++ {
+		for _, h := range hosts {
+			if round < len(byHost[h]) {
+				order = append(order, byHost[h][round])
+			}
+		}
+	}
+	return order
+}
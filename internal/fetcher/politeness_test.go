@@ -0,0 +1,88 @@
+package fetcher
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterDelay(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	l := NewHostLimiter(4, delay)
+
+	var mu sync.Mutex
+	starts := make(map[string][]time.Time)
+	var wg sync.WaitGroup
+	for _, u := range []string{
+		"https://a.example.com/1", "https://a.example.com/2", "https://A.example.com:443/3",
+		"https://b.example.com/1",
+	} {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			release, err := l.Acquire(context.Background(), u)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+			mu.Lock()
+			starts[limiterHost(u)] = append(starts[limiterHost(u)], time.Now())
+			mu.Unlock()
+		}(u)
+	}
+	begin := time.Now()
+	wg.Wait()
+
+	a := starts["a.example.com"]
+	if len(a) != 3 {
+		t.Fatalf("a.example.com: %d requests, want 3", len(a))
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i].Before(a[j]) })
+	for i := 1; i < len(a); i++ {
+		// a little slack for timer granularity
+		if gap := a[i].Sub(a[i-1]); gap < delay-5*time.Millisecond {
+			t.Errorf("a.example.com request %d started %s after the previous one, want >= %s", i+1, gap, delay)
+		}
+	}
+	if b := starts["b.example.com"]; len(b) != 1 || b[0].Sub(begin) > delay {
+		t.Errorf("b.example.com waited for a.example.com: started %v", b)
+	}
+}
+
+func TestHostLimiterPerHost(t *testing.T) {
+	l := NewHostLimiter(1, 0)
+	release, err := l.Acquire(context.Background(), "https://example.com/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the host's only slot is taken: the next request waits
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, "https://example.com/2"); err == nil {
+		t.Error("second request got a slot while the first one ran")
+	}
+	if other, err := l.Acquire(context.Background(), "https://other.com/1"); err != nil {
+		t.Errorf("other host: %v", err)
+	} else {
+		other()
+	}
+
+	release()
+	if next, err := l.Acquire(context.Background(), "https://example.com/2"); err != nil {
+		t.Errorf("after release: %v", err)
+	} else {
+		next()
+	}
+}
+
+func TestInterleaveByHost(t *testing.T) {
+	urls := []string{"https://a.com/1", "https://a.com/2", "https://a.com/3", "https://b.com/1", "https://c.com/1", "https://b.com/2"}
+	if got, want := InterleaveByHost(urls), []int{0, 3, 4, 1, 5, 2}; !slices.Equal(got, want) {
+		t.Errorf("InterleaveByHost = %v, want %v", got, want)
+	}
+}
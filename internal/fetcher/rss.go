@@ -1,34 +1,91 @@
 package fetcher
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
-	"github.com/gosimple/slug"
 	"github.com/mmcdole/gofeed"
+	"moto-news/internal/config"
 	"moto-news/internal/models"
+	"moto-news/internal/slugify"
 )
 
 type RSSFetcher struct {
-	parser *gofeed.Parser
+	parser        *gofeed.Parser
+	slugMaxLength int
+	retries       int           // extra attempts after a transient failure (FetchFeedWithRetry)
+	retryDelay    time.Duration // wait before the first retry, doubled before each next one
 }
 
-func NewRSSFetcher() *RSSFetcher {
+// NewRSSFetcher creates a feed fetcher. transport may be nil (default transport).
+func NewRSSFetcher(transport http.RoundTripper) *RSSFetcher {
+	parser := gofeed.NewParser()
+	parser.Client = &http.Client{Transport: transport}
 	return &RSSFetcher{
-		parser: gofeed.NewParser(),
+		parser: parser,
 	}
 }
 
-// FetchFeed fetches articles from an RSS feed URL
-func (f *RSSFetcher) FetchFeed(feedURL string, sourceSite string) ([]*models.Article, error) {
+// SetSlugMaxLength sets the longest slug generated for fetched articles
+// (<= 0 = slugify.MaxLength)
+func (f *RSSFetcher) SetSlugMaxLength(n int) {
+	f.slugMaxLength = n
+}
+
+// SetTimeout limits how long a single feed request may take (0 = no limit)
+func (f *RSSFetcher) SetTimeout(d time.Duration) {
+	f.parser.Client.Timeout = d
+}
+
+// FeedAuth holds credentials for private feeds: HTTP basic auth and/or extra
+// request headers (e.g. an API key). Values never appear in logs or errors.
+type FeedAuth struct {
+	Username string
+	Password string
+	Headers  map[string]string
+}
+
+// IsEmpty reports whether no credentials are set
+func (a *FeedAuth) IsEmpty() bool {
+	return a == nil || (a.Username == "" && a.Password == "" && len(a.Headers) == 0)
+}
+
+// FieldMapping names the feed elements that fill Category and Tags. Empty
+// fields use the item's <category> elements (Category takes the first).
+// A field is "categories", a namespaced element as written in the feed
+// ("dc:subject", "media:keywords"), a plain custom element ("section"), or
+// "none". Comma-separated values are split.
+type FieldMapping struct {
+	Category string
+	Tags     string
+}
+
+// FetchFeed fetches articles from an RSS feed URL. auth and fields may be nil.
+func (f *RSSFetcher) FetchFeed(feedURL string, sourceSite string, auth *FeedAuth, fields *FieldMapping) ([]*models.Article, error) {
 	if strings.TrimSpace(feedURL) == "" {
 		return nil, fmt.Errorf("feed URL is empty")
 	}
 
-	feed, err := f.parser.ParseURL(feedURL)
+	var feed *gofeed.Feed
+	var err error
+	if auth.IsEmpty() {
+		feed, err = f.parser.ParseURL(feedURL)
+		// *url.Error repeats the URL, which may carry a key
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+	} else {
+		feed, err = f.parseWithAuth(feedURL, auth)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse feed %s: %w", feedURL, err)
+		return nil, fmt.Errorf("failed to parse feed %s: %w", config.RedactURL(feedURL), err)
 	}
 
 	var articles []*models.Article
@@ -36,20 +93,60 @@ func (f *RSSFetcher) FetchFeed(feedURL string, sourceSite string) ([]*models.Art
 		if item == nil {
 			continue
 		}
-		article := f.itemToArticle(item, sourceSite)
+		article := f.itemToArticle(item, sourceSite, fields)
 		articles = append(articles, article)
 	}
 
 	return articles, nil
 }
 
-func (f *RSSFetcher) itemToArticle(item *gofeed.Item, sourceSite string) *models.Article {
+// parseWithAuth downloads the feed with credentials attached and parses the body
+func (f *RSSFetcher) parseWithAuth(feedURL string, auth *FeedAuth) (*gofeed.Feed, error) {
+	resp, err := f.get(feedURL, auth)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil, gofeed.HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return f.parser.Parse(resp.Body)
+}
+
+// get requests the feed, with credentials attached when auth is set
+func (f *RSSFetcher) get(feedURL string, auth *FeedAuth) (*http.Response, error) {
+	req, err := http.NewRequest("GET", feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed URL")
+	}
+	req.Header.Set("User-Agent", "Gofeed/1.0")
+	if !auth.IsEmpty() {
+		if auth.Username != "" || auth.Password != "" {
+			req.SetBasicAuth(auth.Username, auth.Password)
+		}
+		for name, value := range auth.Headers {
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp, err := f.parser.Client.Do(req)
+	if err != nil {
+		// *url.Error repeats the URL, which may carry a key
+		return nil, fmt.Errorf("request failed: %w", errors.Unwrap(err))
+	}
+	return resp, nil
+}
+
+func (f *RSSFetcher) itemToArticle(item *gofeed.Item, sourceSite string, fields *FieldMapping) *models.Article {
 	article := &models.Article{
 		SourceURL:  item.Link,
 		SourceSite: sourceSite,
 		Title:      item.Title,
 		Description: item.Description,
 		FetchedAt:  time.Now(),
+		FeedContent: item.Content,
 	}
 
 	// Parse published date
@@ -60,6 +157,10 @@ func (f *RSSFetcher) itemToArticle(item *gofeed.Item, sourceSite string) *models
 	} else {
 		article.PublishedAt = time.Now()
 	}
+	if item.UpdatedParsed != nil {
+		updated := *item.UpdatedParsed
+		article.SourceUpdatedAt = &updated
+	}
 
 	// Extract author
 	if len(item.Authors) > 0 && item.Authors[0] != nil {
@@ -68,10 +169,16 @@ func (f *RSSFetcher) itemToArticle(item *gofeed.Item, sourceSite string) *models
 		article.Author = item.Author.Name
 	}
 
-	// Extract category
-	if len(item.Categories) > 0 {
-		article.Category = item.Categories[0]
-		article.Tags = item.Categories
+	// Extract category and tags (sources[].category_field / tags_field)
+	var categoryField, tagsField string
+	if fields != nil {
+		categoryField, tagsField = fields.Category, fields.Tags
+	}
+	if values := itemFieldValues(item, categoryField); len(values) > 0 {
+		article.Category = values[0]
+	}
+	if values := itemFieldValues(item, tagsField); len(values) > 0 {
+		article.Tags = values
 	}
 
 	// Extract image from enclosures or media
@@ -89,38 +196,95 @@ func (f *RSSFetcher) itemToArticle(item *gofeed.Item, sourceSite string) *models
 		}
 	}
 
-	// Generate slug from title
-	article.Slug = slug.Make(item.Title)
-	if len(article.Slug) > 80 {
-		article.Slug = article.Slug[:80]
-	}
+	// Generate slug from title (Cyrillic is transliterated; empty slugs get
+	// article-<id> on insert)
+	article.Slug = slugify.MakeMax(item.Title, f.slugMaxLength)
 
 	return article
 }
 
+// itemFieldValues returns the non-empty values of a FieldMapping field.
+// Custom and namespaced elements often carry a list in one element
+// ("ducati, superbike"), so their values are split on commas.
+func itemFieldValues(item *gofeed.Item, field string) []string {
+	var raw []string
+	switch field = strings.TrimSpace(field); field {
+	case "", "categories":
+		// one value per <category>, commas included ("Touring, Adventure" is one category)
+		var values []string
+		for _, c := range item.Categories {
+			if c = strings.TrimSpace(c); c != "" {
+				values = append(values, c)
+			}
+		}
+		return values
+	case "none":
+		return nil
+	default:
+		if prefix, name, ok := strings.Cut(field, ":"); ok {
+			for _, ext := range item.Extensions[prefix][name] {
+				raw = append(raw, ext.Value)
+			}
+		} else if value, ok := item.Custom[field]; ok {
+			raw = append(raw, value)
+		}
+	}
+
+	var values []string
+	for _, r := range raw {
+		for _, v := range strings.Split(r, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// FeedResult is the outcome of fetching a single feed URL
+type FeedResult struct {
+	URL      string   `json:"url"`
+	Source   string   `json:"source"`
+	Articles int      `json:"articles"`
+	Attempts int      `json:"attempts,omitempty"` // requests made, retries included
+	Retried  []string `json:"retried,omitempty"`  // errors of the attempts that were retried
+	Error    string   `json:"error,omitempty"`
+}
+
 // FetchMultipleFeeds fetches articles from multiple feed URLs.
-// Returns an error only when ALL feeds fail. Partial failures are logged.
-func (f *RSSFetcher) FetchMultipleFeeds(feedURLs []string, sourceSite string) ([]*models.Article, error) {
+// Every feed gets a FeedResult so callers can report which ones failed.
+// Transient failures are retried (SetRetries) before a feed counts as failed;
+// cancelling ctx ends the retry waits.
+// Returns an error only when ALL feeds fail.
+func (f *RSSFetcher) FetchMultipleFeeds(ctx context.Context, feedURLs []string, sourceSite string, auth *FeedAuth, fields *FieldMapping) ([]*models.Article, []FeedResult, error) {
 	var allArticles []*models.Article
 	var lastErr error
 	failCount := 0
+	feedResults := make([]FeedResult, 0, len(feedURLs))
 
 	for _, feedURL := range feedURLs {
-		articles, err := f.FetchFeed(feedURL, sourceSite)
+		articles, retried, err := f.FetchFeedWithRetry(ctx, feedURL, sourceSite, auth, fields)
+		result := FeedResult{URL: config.RedactURL(feedURL), Source: sourceSite, Attempts: len(retried) + 1}
+		for _, retryErr := range retried {
+			result.Retried = append(result.Retried, retryErr.Error())
+		}
 		if err != nil {
-			// Log error but continue with other feeds
-			fmt.Printf("Warning: failed to fetch %s: %v\n", feedURL, err)
+			// Recorded for the caller to report; the other feeds go on
+			result.Error = err.Error()
+			feedResults = append(feedResults, result)
 			lastErr = err
 			failCount++
 			continue
 		}
+		result.Articles = len(articles)
+		feedResults = append(feedResults, result)
 		allArticles = append(allArticles, articles...)
 	}
 
 	// Return an error when every single feed failed
 	if failCount == len(feedURLs) && failCount > 0 {
-		return nil, fmt.Errorf("all %d feeds failed, last error: %w", failCount, lastErr)
+		return nil, feedResults, fmt.Errorf("all %d feeds failed, last error: %w", failCount, lastErr)
 	}
 
-	return allArticles, nil
+	return allArticles, feedResults, nil
 }
@@ -0,0 +1,109 @@
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const fieldsTestFeed = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:media="http://search.yahoo.com/mrss/">
+<channel><title>Test</title>
+<item>
+  <title>Item</title>
+  <link>https://example.com/item</link>
+  <category>Touring, Adventure</category>
+  <category>Gear</category>
+  <dc:subject>Reviews</dc:subject>
+  <media:keywords>ducati, superbike ,, panigale</media:keywords>
+  <section>Racing, MotoGP</section>
+</item>
+</channel></rss>`
+
+func TestFetchFeedFieldMapping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, fieldsTestFeed)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		fields       *FieldMapping
+		wantCategory string
+		wantTags     []string
+	}{
+		// gofeed adds dc:subject to the item categories
+		{"default categories keep commas", nil, "Touring, Adventure", []string{"Touring, Adventure", "Gear", "Reviews"}},
+		{"namespaced elements", &FieldMapping{Category: "dc:subject", Tags: "media:keywords"}, "Reviews", []string{"ducati", "superbike", "panigale"}},
+		{"custom element", &FieldMapping{Category: "section", Tags: "none"}, "Racing", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles, err := NewRSSFetcher(nil).FetchFeed(srv.URL, "test", nil, tt.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(articles) != 1 {
+				t.Fatalf("got %d articles, want 1", len(articles))
+			}
+			a := articles[0]
+			if a.Category != tt.wantCategory || !slices.Equal(a.Tags, tt.wantTags) {
+				t.Errorf("category=%q tags=%q, want %q and %q", a.Category, a.Tags, tt.wantCategory, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestFetchFeedAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "rider" || pass != "s3cret" || r.Header.Get("X-Api-Key") != "k3y" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="premium"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, retryTestFeed)
+	}))
+	defer srv.Close()
+	f := NewRSSFetcher(nil)
+
+	articles, err := f.FetchFeed(srv.URL, "test", &FeedAuth{Username: "rider", Password: "s3cret", Headers: map[string]string{"X-Api-Key": "k3y"}}, nil)
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("with credentials: %d articles, err %v", len(articles), err)
+	}
+
+	for _, auth := range []*FeedAuth{nil, {Username: "rider", Password: "wrong", Headers: map[string]string{"X-Api-Key": "k3y"}}} {
+		_, err := f.FetchFeed(srv.URL, "test", auth, nil)
+		var httpErr gofeed.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+			t.Errorf("auth %+v: err = %v, want a 401", auth, err)
+		}
+		if err != nil && strings.Contains(err.Error(), "wrong") {
+			t.Errorf("the password is in the error: %v", err)
+		}
+	}
+}
+
+func TestFetchFeedErrorsHideSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	feedURL := "http://rider:s3cret@" + srv.Listener.Addr().String() + "/feed?api_key=k3y&page=2"
+	srv.Close() // refuse the connection
+
+	for _, auth := range []*FeedAuth{nil, {Headers: map[string]string{"X-Api-Key": "h3ader"}}} {
+		_, err := NewRSSFetcher(nil).FetchFeed(feedURL, "test", auth, nil)
+		if err == nil {
+			t.Fatal("expected a connection error")
+		}
+		for _, secret := range []string{"s3cret", "k3y", "h3ader"} {
+			if strings.Contains(err.Error(), secret) {
+				t.Errorf("auth %+v: %q is in the error: %v", auth, secret, err)
+			}
+		}
+	}
+}
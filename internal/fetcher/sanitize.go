@@ -0,0 +1,122 @@
+package fetcher
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// blockAtoms are tags whose start and end become line breaks (paragraphs)
+var blockAtoms = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Li: true, atom.Blockquote: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// sanitizeHTML removes HTML left in text (JSON-LD articleBody often embeds
+// <a>, <b>, <span style=...>). With markdown=true, links, bold, italics and
+// inline code become Markdown; every other tag is dropped and its text kept.
+// Block tags and <br> become line breaks; script/style contents are removed.
+func sanitizeHTML(text string, markdown bool) string {
+	if !strings.Contains(text, "<") {
+		return text
+	}
+
+	var sb strings.Builder
+	var links []string // href per open <a>, "" when the link is not rendered
+	skip := 0          // depth inside script/style
+
+	z := html.NewTokenizer(strings.NewReader(text))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF {
+				return sb.String()
+			}
+			// Malformed input: keep what was converted so far
+			return sb.String() + string(z.Raw())
+		}
+
+		tok := z.Token()
+		switch tt {
+		case html.TextToken:
+			if skip == 0 {
+				sb.WriteString(tok.Data)
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tok.DataAtom {
+			case atom.Script, atom.Style:
+				if tt == html.StartTagToken {
+					skip++
+				}
+			case atom.Br:
+				sb.WriteString("\n")
+			case atom.A:
+				href := linkHref(tok)
+				if !markdown || tt == html.SelfClosingTagToken {
+					href = ""
+				}
+				links = append(links, href)
+				if href != "" {
+					sb.WriteString("[")
+				}
+			default:
+				if blockAtoms[tok.DataAtom] {
+					sb.WriteString("\n")
+				}
+				writeInlineMarker(&sb, tok.DataAtom, markdown)
+			}
+		case html.EndTagToken:
+			switch tok.DataAtom {
+			case atom.Script, atom.Style:
+				if skip > 0 {
+					skip--
+				}
+			case atom.A:
+				if n := len(links); n > 0 {
+					if href := links[n-1]; href != "" {
+						sb.WriteString("](" + href + ")")
+					}
+					links = links[:n-1]
+				}
+			default:
+				if blockAtoms[tok.DataAtom] {
+					sb.WriteString("\n")
+				}
+				writeInlineMarker(&sb, tok.DataAtom, markdown)
+			}
+		}
+	}
+}
+
+// writeInlineMarker writes the Markdown marker for an opening or closing
+// bold/italic/code tag; nothing for other tags or when markdown is off
+func writeInlineMarker(sb *strings.Builder, a atom.Atom, markdown bool) {
+	if !markdown {
+		return
+	}
+	switch a {
+	case atom.B, atom.Strong:
+		sb.WriteString("**")
+	case atom.I, atom.Em:
+		sb.WriteString("*")
+	case atom.Code:
+		sb.WriteString("`")
+	}
+}
+
+// linkHref returns an <a>'s absolute http(s) href, or "" for anchors,
+// javascript: links and the like
+func linkHref(tok html.Token) string {
+	for _, attr := range tok.Attr {
+		if attr.Key != "href" {
+			continue
+		}
+		href := strings.TrimSpace(attr.Val)
+		if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
+			return href
+		}
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package fetcher
+
+import (
+	"testing"
+
+	"moto-news/internal/config"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		in       string
+		markdown bool
+		want     string
+	}{
+		{`Read <a href="https://example.com/test">our test</a> first.`, true, "Read [our test](https://example.com/test) first."},
+		{`Read <a href="https://example.com/test">our test</a> first.`, false, "Read our test first."},
+		{`<a href="#top">Top</a> and <a href="javascript:void(0)">that</a>`, true, "Top and that"},
+		{`<b>Bold</b>, <strong>strong</strong>, <em>em</em> and <code>x</code>`, true, "**Bold**, **strong**, *em* and `x`"},
+		{`<b>Bold</b> <i>italic</i>`, false, "Bold italic"},
+		{`<span style="color:red">Red</span> text<script>alert(1)</script><style>p{}</style>`, true, "Red text"},
+		{`First<br>second<p>third</p>`, true, "First\nsecond\nthird\n"},
+		{`Plain text, no tags`, true, "Plain text, no tags"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeHTML(tt.in, tt.markdown); got != tt.want {
+			t.Errorf("sanitizeHTML(%q, %v) = %q, want %q", tt.in, tt.markdown, got, tt.want)
+		}
+	}
+}
+
+func TestCleanContentTagLadenBody(t *testing.T) {
+	raw := `<p>The <b>new</b> <a href="https://example.com/r1">R1</a> makes <span style="font-weight:bold">200 hp</span>.</p>` +
+		`<p>Writing &amp;lt;b&amp;gt; in a post shows the tag &amp;amp; nothing else.</p>`
+
+	md := NewArticleScraper(&config.ScraperConfig{HTMLTags: "markdown"}, nil)
+	want := "The **new** [R1](https://example.com/r1) makes 200 hp.\n\n" +
+		"Writing &lt;b&gt; in a post shows the tag &amp; nothing else."
+	if got := md.CleanContent(raw, ""); got != want {
+		t.Errorf("markdown:\n got %q\nwant %q", got, want)
+	}
+
+	strip := NewArticleScraper(&config.ScraperConfig{HTMLTags: "strip"}, nil)
+	want = "The new R1 makes 200 hp.\n\n" +
+		"Writing &lt;b&gt; in a post shows the tag &amp; nothing else."
+	if got := strip.CleanContent(raw, ""); got != want {
+		t.Errorf("strip:\n got %q\nwant %q", got, want)
+	}
+}
@@ -1,33 +1,77 @@
 package fetcher
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"moto-news/internal/config"
 	"moto-news/internal/models"
 )
 
+// Fallbacks for unset scraper.max_body_bytes / scraper.request_timeout_sec / scraper.max_tags / scraper.max_images / scraper.max_redirects
+const (
+	defaultMaxBodyBytes   = 5 << 20
+	defaultRequestTimeout = 20 * time.Second
+	defaultMaxTags        = 10
+	defaultMaxImages      = 20
+	defaultMaxRedirects   = 10
+)
+
+// ErrBodyTooLarge is returned when a page exceeds scraper.max_body_bytes
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// ErrNotHTML is returned when a link leads to something other than an HTML
+// page (a PDF, an image, a feed); there is no article text to extract
+var ErrNotHTML = errors.New("not an HTML page")
+
 type ArticleScraper struct {
-	client *http.Client
+	config        *config.ScraperConfig
+	client        *http.Client
+	cutoffMarkers map[string][]cutoffMarker // by source name; "" holds the global ones
 }
 
-func NewArticleScraper() *ArticleScraper {
-	return &ArticleScraper{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
+// NewArticleScraper creates a scraper. transport may be nil (default transport).
+func NewArticleScraper(cfg *config.ScraperConfig, transport http.RoundTripper) *ArticleScraper {
+	markers := make(map[string][]cutoffMarker)
+	if cfg != nil {
+		markers[""] = parseCutoffMarkers(cfg.CutoffMarkers)
+		for source, list := range cfg.SourceCutoffMarkers {
+			markers[source] = parseCutoffMarkers(list)
+		}
+	}
+	s := &ArticleScraper{
+		config:        cfg,
+		cutoffMarkers: markers,
+	}
+	s.client = &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+		// Redirect targets are as untrusted as the link itself
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if n := s.maxRedirects(); len(via) > n {
+				return fmt.Errorf("stopped after %d redirects", n)
+			}
+			return s.checkPageURL(req.Context(), req.URL)
 		},
 	}
+	return s
 }
 
 // jsonLDArticle represents the JSON-LD structured data on article pages
 type jsonLDArticle struct {
-	Type           string      `json:"@type"`
+	Type           interface{} `json:"@type"` // string or list of types
 	Headline       string      `json:"headline"`
 	ArticleBody    string      `json:"articleBody"`
 	ArticleSection string      `json:"articleSection"`
@@ -37,47 +81,65 @@ type jsonLDArticle struct {
 	Author         interface{} `json:"author"`
 }
 
-// ScrapeArticle fetches the full content of an article from its URL
-func (s *ArticleScraper) ScrapeArticle(article *models.Article) error {
-	if article == nil || article.SourceURL == "" {
-		return fmt.Errorf("article has no source URL")
+// keptRaw is the raw extracted text to store with the article ("" with
+// scraper.keep_raw_content off)
+func (s *ArticleScraper) keptRaw(raw string) string {
+	if s.config == nil || !s.config.KeepRawContent {
+		return ""
 	}
+	return raw
+}
 
-	req, err := http.NewRequest("GET", article.SourceURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request for %s: %w", article.SourceURL, err)
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to fetch %s: %w", article.SourceURL, err)
-	}
-	defer resp.Body.Close()
+// ScrapeResult is what ScrapeArticle reports besides the article fields it
+// fills in. Scrapes run in concurrent workers, so callers log it themselves.
+type ScrapeResult struct {
+	Redirect string // where the link led, "" when it did not redirect
+}
 
-	if resp.StatusCode != http.StatusOK {
-		// Drain body to allow connection reuse
-		io.Copy(io.Discard, resp.Body)
-		return fmt.Errorf("unexpected status %d for %s", resp.StatusCode, article.SourceURL)
+// ScrapeArticle fetches the full content of an article from its URL. When
+// the link redirects only permanently (301/308), SourceURL becomes the final
+// URL and FeedURL keeps the link as it was; after a temporary redirect the
+// link stays and the final URL is only reported.
+func (s *ArticleScraper) ScrapeArticle(article *models.Article) (*ScrapeResult, error) {
+	if article == nil || article.SourceURL == "" {
+		return nil, fmt.Errorf("article has no source URL")
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	page, err := s.fetchPage(article.SourceURL)
 	if err != nil {
-		return fmt.Errorf("failed to read body from %s: %w", article.SourceURL, err)
+		return nil, err
 	}
-
-	htmlStr := string(body)
+	result := &ScrapeResult{}
+	if page.finalURL != article.SourceURL {
+		if page.permanent {
+			result.Redirect = fmt.Sprintf("moved permanently: %s → %s", config.RedactURL(article.SourceURL), config.RedactURL(page.finalURL))
+			if article.FeedURL == "" {
+				article.FeedURL = article.SourceURL
+			}
+			article.SourceURL = page.finalURL
+		} else {
+			result.Redirect = fmt.Sprintf("redirected: %s → %s (link kept)", config.RedactURL(article.SourceURL), config.RedactURL(page.finalURL))
+		}
+	}
+	htmlStr := page.html
 
 	// Strategy 1: Extract from JSON-LD structured data (most reliable)
-	content, imageURLs, category, tags := s.extractFromJSONLD(htmlStr)
+	raw, imageURLs, category, tags := s.extractFromJSONLD(htmlStr)
+	content := s.CleanContent(raw, article.SourceSite)
+
+	// JSON-LD image usually holds only the featured image(s): add the
+	// figures and galleries from the body
+	if content != "" {
+		if doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlStr)); err == nil {
+			imageURLs = append(imageURLs, bodyImageURLs(doc)...)
+		}
+	}
 
 	// Strategy 2: Fallback to HTML scraping if JSON-LD didn't work
 	if content == "" {
 		var htmlCategory string
-		content, imageURLs, htmlCategory, tags = s.extractFromHTML(htmlStr)
+		raw, imageURLs, htmlCategory, tags = s.extractFromHTML(htmlStr)
+		content = s.CleanContent(raw, article.SourceSite)
 		if category == "" {
 			category = htmlCategory
 		}
@@ -85,80 +147,197 @@ func (s *ArticleScraper) ScrapeArticle(article *models.Article) error {
 
 	// Update article with scraped content
 	if content != "" {
-		article.Content = strings.TrimSpace(content)
+		article.Content = content
+		article.RawContent = s.keptRaw(raw)
 	}
 
-	if len(imageURLs) > 0 {
+	if imageURLs = s.galleryImages(article.SourceURL, imageURLs); len(imageURLs) > 0 {
 		article.ImageURLs = imageURLs
 		if article.ImageURL == "" {
 			article.ImageURL = imageURLs[0]
 		}
 	}
+	s.applyTaxonomy(article, category, tags)
 
+	return result, nil
+}
+
+// applyTaxonomy gives an article the category and tags found for it, the
+// same way for scraped pages and feed content: the category is normalized
+// and only fills an empty one; the tags are normalized, deduplicated, capped
+// at scraper.max_tags and replace the article's when there are any
+func (s *ArticleScraper) applyTaxonomy(article *models.Article, category string, tags []string) {
+	category = strings.TrimSpace(normalizeText(category, s.normalizeQuotes()))
 	if category != "" && article.Category == "" {
 		article.Category = category
 	}
 
-	if len(tags) > 0 {
-		article.Tags = uniqueStrings(tags)
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(normalizeText(tag, s.normalizeQuotes())); tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	if normalized = uniqueStrings(normalized); len(normalized) > s.maxTags() {
+		normalized = normalized[:s.maxTags()]
+	}
+	if len(normalized) > 0 {
+		article.Tags = normalized
+	}
+}
+
+// fetchedPage is an HTML page downloaded by fetchPage
+type fetchedPage struct {
+	html      string
+	finalURL  string // after redirects; pageURL when there were none
+	permanent bool   // every redirect on the way was a 301 or 308
+}
+
+// fetchPage downloads a page with browser-like headers, following up to scraper.max_redirects
+// redirects, and returns the body with the URL it came from. Responses
+// other than HTML fail with ErrNotHTML before the body is read.
+func (s *ArticleScraper) fetchPage(pageURL string) (*fetchedPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.requestTimeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", pageURL, err)
+	}
+	if err := s.checkPageURL(ctx, req.URL); err != nil {
+		return nil, fmt.Errorf("refusing to fetch %s: %w", pageURL, err)
+	}
+
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	page := &fetchedPage{finalURL: resp.Request.URL.String(), permanent: true}
+	// resp.Request.Response is the redirect that led to the final request,
+	// its Request the one before, and so on back to ours
+	for r := resp.Request; r.Response != nil; r = r.Response.Request {
+		if code := r.Response.StatusCode; code != http.StatusMovedPermanently && code != http.StatusPermanentRedirect {
+			page.permanent = false
+		}
+	}
+	fetched := pageURL
+	if page.finalURL != pageURL {
+		fetched = fmt.Sprintf("%s (redirected to %s)", pageURL, page.finalURL)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// Drain body to allow connection reuse
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("unexpected status %d for %s", resp.StatusCode, fetched)
+	}
+	if mediaType, ok := htmlMediaType(resp.Header.Get("Content-Type")); !ok {
+		return nil, fmt.Errorf("%s: %w (%s)", fetched, ErrNotHTML, mediaType)
+	}
+
+	limit := s.maxBodyBytes()
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%s: %w (%d bytes, limit %d)", fetched, ErrBodyTooLarge, resp.ContentLength, limit)
+	}
+	// Read one byte past the limit to tell "exactly at" from "over"
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body from %s: %w", fetched, err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%s: %w (over %d bytes)", fetched, ErrBodyTooLarge, limit)
+	}
+	if resp.Header.Get("Content-Type") == "" {
+		// No header to go by: sniff the body
+		if mediaType, ok := htmlMediaType(http.DetectContentType(body)); !ok {
+			return nil, fmt.Errorf("%s: %w (%s)", fetched, ErrNotHTML, mediaType)
+		}
+	}
+
+	page.html = string(body)
+	return page, nil
+}
+
+// htmlMediaType parses a Content-Type header and reports whether it is an
+// HTML page. A missing header counts as HTML here (the body is sniffed
+// later).
+func htmlMediaType(contentType string) (string, bool) {
+	if strings.TrimSpace(contentType) == "" {
+		return "", true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType, false
+	}
+	return mediaType, mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+func (s *ArticleScraper) maxBodyBytes() int64 {
+	if s.config != nil && s.config.MaxBodyBytes > 0 {
+		return s.config.MaxBodyBytes
+	}
+	return defaultMaxBodyBytes
+}
+
+func (s *ArticleScraper) maxRedirects() int {
+	if s.config != nil && s.config.MaxRedirects > 0 {
+		return s.config.MaxRedirects
 	}
+	return defaultMaxRedirects
+}
 
-	return nil
+func (s *ArticleScraper) requestTimeout() time.Duration {
+	if s.config != nil && s.config.RequestTimeoutSec > 0 {
+		return time.Duration(s.config.RequestTimeoutSec) * time.Second
+	}
+	return defaultRequestTimeout
 }
 
-// extractFromJSONLD extracts article content from JSON-LD structured data
-func (s *ArticleScraper) extractFromJSONLD(html string) (content string, imageURLs []string, category string, tags []string) {
+// jsonLDRe matches JSON-LD script blocks
+var jsonLDRe = regexp.MustCompile(`(?s)<script[^>]*type="application/ld\+json"[^>]*>(.*?)</script>`)
+
+// extractFromJSONLD extracts the raw article body (see CleanContent) and
+// metadata from JSON-LD structured data
+func (s *ArticleScraper) extractFromJSONLD(htmlStr string) (content string, imageURLs []string, category string, tags []string) {
 	// Find all JSON-LD blocks
-	re := regexp.MustCompile(`(?s)<script[^>]*type="application/ld\+json"[^>]*>(.*?)</script>`)
-	matches := re.FindAllStringSubmatch(html, -1)
+	matches := jsonLDRe.FindAllStringSubmatch(htmlStr, -1)
 
 	for _, match := range matches {
 		if len(match) < 2 {
 			continue
 		}
 
-		var data jsonLDArticle
-		if err := json.Unmarshal([]byte(match[1]), &data); err != nil {
-			continue
-		}
-
-		// Check if this is an Article type with body content
-		if data.ArticleBody == "" {
+		// Blocks may be a single object, an array, or wrap the Article in
+		// @graph (Yoast, Rank Math) or a nested property
+		data := findJSONLDArticle([]byte(match[1]))
+		if data == nil {
 			continue
 		}
 
-		// Clean the article body - remove related articles section
-		content = s.cleanArticleBody(data.ArticleBody)
+		content = data.ArticleBody
 
 		// Extract category from articleSection
-		category = data.ArticleSection
+		category = html.UnescapeString(data.ArticleSection)
 
 		// Extract all image URLs (schema.org Article can have multiple)
-		switch img := data.Image.(type) {
-		case string:
-			if img != "" {
-				imageURLs = append(imageURLs, img)
-			}
-		case []interface{}:
-			for _, v := range img {
-				if imgStr, ok := v.(string); ok && imgStr != "" {
-					imageURLs = append(imageURLs, imgStr)
-				}
-			}
-		}
-		imageURLs = uniqueStrings(imageURLs)
+		imageURLs = uniqueStrings(jsonLDImageURLs(data.Image))
 
 		// Extract keywords/tags — filter out generic site-wide categories
 		switch kw := data.Keywords.(type) {
 		case []interface{}:
 			for _, k := range kw {
 				if kStr, ok := k.(string); ok && !isGenericCategory(kStr) {
-					tags = append(tags, kStr)
+					tags = append(tags, html.UnescapeString(kStr))
 				}
 			}
 		case string:
 			for _, k := range strings.Split(kw, ",") {
-				k = strings.TrimSpace(k)
+				k = strings.TrimSpace(html.UnescapeString(k))
 				if k != "" && !isGenericCategory(k) {
 					tags = append(tags, k)
 				}
@@ -171,7 +350,131 @@ func (s *ArticleScraper) extractFromJSONLD(html string) (content string, imageUR
 	return
 }
 
-// extractFromHTML extracts article content by parsing HTML (fallback)
+// jsonLDArticleTypes are the schema.org types preferred when several nodes
+// in a block carry an articleBody
+var jsonLDArticleTypes = map[string]bool{
+	"Article":              true,
+	"NewsArticle":          true,
+	"BlogPosting":          true,
+	"ReportageNewsArticle": true,
+	"ReviewNewsArticle":    true,
+	"AnalysisNewsArticle":  true,
+	"TechArticle":          true,
+}
+
+// findJSONLDArticle walks a JSON-LD block and returns the node with an
+// articleBody, preferring Article-like @types. Returns nil if none has a body.
+func findJSONLDArticle(raw []byte) *jsonLDArticle {
+	var root interface{}
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return nil
+	}
+
+	var fallback map[string]interface{}
+	var found map[string]interface{}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		if found != nil {
+			return
+		}
+		switch node := v.(type) {
+		case []interface{}:
+			for _, item := range node {
+				walk(item)
+			}
+		case map[string]interface{}:
+			if body, ok := node["articleBody"].(string); ok && strings.TrimSpace(body) != "" {
+				if isJSONLDArticleType(node["@type"]) {
+					found = node
+					return
+				}
+				if fallback == nil {
+					fallback = node
+				}
+			}
+			// @graph first, then any nested entity (mainEntity, hasPart, ...)
+			if graph, ok := node["@graph"]; ok {
+				walk(graph)
+			}
+			for key, child := range node {
+				if key != "@graph" {
+					walk(child)
+				}
+			}
+		}
+	}
+	walk(root)
+
+	if found == nil {
+		found = fallback
+	}
+	if found == nil {
+		return nil
+	}
+
+	// articleSection is sometimes a list; keep the first entry so the
+	// typed struct still decodes
+	if sections, ok := found["articleSection"].([]interface{}); ok {
+		found["articleSection"] = ""
+		if len(sections) > 0 {
+			if first, ok := sections[0].(string); ok {
+				found["articleSection"] = first
+			}
+		}
+	}
+
+	// Round-trip the generic node into the typed struct
+	b, err := json.Marshal(found)
+	if err != nil {
+		return nil
+	}
+	var article jsonLDArticle
+	if err := json.Unmarshal(b, &article); err != nil {
+		return nil
+	}
+	return &article
+}
+
+// isJSONLDArticleType reports whether @type (a string or a list) names an Article type
+func isJSONLDArticleType(t interface{}) bool {
+	switch v := t.(type) {
+	case string:
+		return jsonLDArticleTypes[v]
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && jsonLDArticleTypes[s] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonLDImageURLs extracts URLs from a schema.org image value: a URL string,
+// an ImageObject ({"url": ...}), or a list of either
+func jsonLDImageURLs(img interface{}) []string {
+	var urls []string
+	switch v := img.(type) {
+	case string:
+		if v != "" {
+			urls = append(urls, v)
+		}
+	case map[string]interface{}:
+		if u, ok := v["url"].(string); ok && u != "" {
+			urls = append(urls, u)
+		} else if u, ok := v["contentUrl"].(string); ok && u != "" {
+			urls = append(urls, u)
+		}
+	case []interface{}:
+		for _, item := range v {
+			urls = append(urls, jsonLDImageURLs(item)...)
+		}
+	}
+	return urls
+}
+
+// extractFromHTML extracts the raw article paragraphs (see CleanContent) and
+// metadata by parsing HTML (fallback)
 func (s *ArticleScraper) extractFromHTML(htmlStr string) (content string, imageURLs []string, category string, tags []string) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlStr))
 	if err != nil {
@@ -179,50 +482,15 @@ func (s *ArticleScraper) extractFromHTML(htmlStr string) (content string, imageU
 	}
 
 	var paragraphs []string
-
-	// Primary selector: div.postBody (RideApart)
-	doc.Find("div.postBody").Each(func(i int, sel *goquery.Selection) {
-		sel.Find("p").Each(func(j int, p *goquery.Selection) {
-			text := strings.TrimSpace(p.Text())
-			if text != "" && !isBoilerplate(text) {
-				paragraphs = append(paragraphs, text)
-			}
-		})
-	})
-
-	// Alternative selectors
-	if len(paragraphs) == 0 {
-		selectors := []string{
-			"article.article-content",
-			"div.article-body",
-			"div.content-body",
-			"div[class*='article'] p",
-			"main p",
-		}
-		for _, selector := range selectors {
-			doc.Find(selector).Each(func(i int, sel *goquery.Selection) {
-				if strings.Contains(selector, " p") {
-					text := strings.TrimSpace(sel.Text())
-					if text != "" && len(text) > 50 && !isBoilerplate(text) {
-						paragraphs = append(paragraphs, text)
-					}
-				} else {
-					sel.Find("p").Each(func(j int, p *goquery.Selection) {
-						text := strings.TrimSpace(p.Text())
-						if text != "" && !isBoilerplate(text) {
-							paragraphs = append(paragraphs, text)
-						}
-					})
-				}
-			})
-			if len(paragraphs) > 0 {
-				break
-			}
+	for _, selector := range contentSelectors {
+		paragraphs = selectorParagraphs(doc, selector)
+		if len(paragraphs) > 0 {
+			break
 		}
 	}
 
 	if len(paragraphs) > 0 {
-		content = strings.Join(paragraphs, "\n\n")
+		content = paragraphHTML(paragraphs)
 	}
 
 	// Collect all image URLs: first og:image (featured), then all <img> in article body
@@ -231,54 +499,258 @@ func (s *ArticleScraper) extractFromHTML(htmlStr string) (content string, imageU
 			imageURLs = append(imageURLs, val)
 		}
 	})
-	// All <img> inside article body (src or data-src for lazy loading)
-	articleSelectors := []string{"div.postBody", "article.article-content", "div.article-body", "div.content-body", "main"}
-	for _, sel := range articleSelectors {
+	imageURLs = uniqueStrings(append(imageURLs, bodyImageURLs(doc)...))
+
+	tags = s.collectTags(doc)
+
+	return
+}
+
+// imageBodySelectors are the article body containers whose <img> (figures
+// and galleries included) are collected as article images
+var imageBodySelectors = []string{"div.postBody", "article.article-content", "div.article-body", "div.content-body", "main"}
+
+// bodyImageURLs returns the images inside the article body in document
+// order. Lazy-loaded images keep the real URL in data-src/data-lazy-src or
+// only in srcset; inline data: images are skipped.
+func bodyImageURLs(doc *goquery.Document) []string {
+	var urls []string
+	for _, sel := range imageBodySelectors {
 		doc.Find(sel).Find("img").Each(func(i int, img *goquery.Selection) {
-			src, _ := img.Attr("src")
+			var src string
+			for _, attr := range []string{"data-src", "data-lazy-src", "src"} {
+				if v := strings.TrimSpace(img.AttrOr(attr, "")); v != "" && !strings.HasPrefix(v, "data:") {
+					src = v
+					break
+				}
+			}
 			if src == "" {
-				src, _ = img.Attr("data-src")
+				src = srcsetLargest(img.AttrOr("srcset", img.AttrOr("data-srcset", "")))
 			}
 			if src != "" {
-				imageURLs = append(imageURLs, src)
+				urls = append(urls, src)
 			}
 		})
 	}
-	imageURLs = uniqueStrings(imageURLs)
+	return urls
+}
+
+// srcsetLargest picks the widest candidate of a srcset attribute ("a.jpg
+// 480w, b.jpg 1024w"); candidates without a width descriptor count as 0
+func srcsetLargest(srcset string) string {
+	var best string
+	bestWidth := -1
+	for _, candidate := range strings.Split(srcset, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "data:") {
+			continue
+		}
+		width := 0
+		if len(fields) > 1 && strings.HasSuffix(fields[1], "w") {
+			width, _ = strconv.Atoi(strings.TrimSuffix(fields[1], "w"))
+		}
+		if width > bestWidth {
+			best, bestWidth = fields[0], width
+		}
+	}
+	return best
+}
 
-	// Extract tags
-	doc.Find("a[href*='/tag/'], a[href*='/category/'], span.tag").Each(func(i int, sel *goquery.Selection) {
-		tag := strings.TrimSpace(sel.Text())
-		if tag != "" && len(tag) < 50 {
+// galleryImages resolves image URLs against the page URL, drops the ones
+// that are not http(s), dedupes and keeps at most scraper.max_images
+func (s *ArticleScraper) galleryImages(pageURL string, imageURLs []string) []string {
+	base, _ := url.Parse(pageURL)
+	var resolved []string
+	for _, raw := range imageURLs {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			continue
+		}
+		if base != nil {
+			u = base.ResolveReference(u)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			continue
+		}
+		resolved = append(resolved, u.String())
+	}
+	if resolved = uniqueStrings(resolved); len(resolved) > s.maxImages() {
+		resolved = resolved[:s.maxImages()]
+	}
+	return resolved
+}
+
+// tagLinkSelector matches tag links in the HTML fallback
+const tagLinkSelector = "a[href*='/tag/'], a[href*='/category/'], span.tag"
+
+// tagScopeSelectors are the page parts that hold the article's own tags.
+// Tag links elsewhere (sidebars, footers, tag clouds) are site-wide and only
+// used when the article itself has none.
+const tagScopeSelectors = "article, div.postBody, div.article-body, div.content-body, .article-tags, .post-tags"
+
+// collectTags returns up to scraper.max_tags distinct, non-generic tags,
+// preferring the ones inside the article over the rest of the page
+func (s *ArticleScraper) collectTags(doc *goquery.Document) []string {
+	limit := s.maxTags()
+	collect := func(links *goquery.Selection) []string {
+		var tags []string
+		seen := make(map[string]bool)
+		links.EachWithBreak(func(i int, sel *goquery.Selection) bool {
+			tag := strings.TrimSpace(sel.Text())
+			key := strings.ToLower(tag)
+			if tag == "" || len(tag) >= 50 || seen[key] || isGenericCategory(tag) {
+				return true
+			}
+			seen[key] = true
 			tags = append(tags, tag)
+			return len(tags) < limit
+		})
+		return tags
+	}
+
+	if tags := collect(doc.Find(tagScopeSelectors).Find(tagLinkSelector)); len(tags) > 0 {
+		return tags
+	}
+	return collect(doc.Find(tagLinkSelector))
+}
+
+// maxImages returns scraper.max_images, or the default when unset
+func (s *ArticleScraper) maxImages() int {
+	if s.config != nil && s.config.MaxImages > 0 {
+		return s.config.MaxImages
+	}
+	return defaultMaxImages
+}
+
+// normalizeQuotes returns scraper.normalize_quotes (off without a config)
+func (s *ArticleScraper) normalizeQuotes() bool {
+	return s.config != nil && s.config.NormalizeQuotes
+}
+
+// maxTags returns scraper.max_tags, or the default when unset
+func (s *ArticleScraper) maxTags() int {
+	if s.config != nil && s.config.MaxTags > 0 {
+		return s.config.MaxTags
+	}
+	return defaultMaxTags
+}
+
+// contentSelectors are tried in order by the HTML fallback; the first one that
+// yields paragraphs wins. div.postBody is the RideApart article body.
+var contentSelectors = []string{
+	"div.postBody",
+	"article.article-content",
+	"div.article-body",
+	"div.content-body",
+	"div[class*='article'] p",
+	"main p",
+}
+
+// selectorParagraphs returns the paragraphs matched by selector, or none
+// when they are all boilerplate. Boilerplate is kept here and dropped by
+// CleanContent, after the cutoff markers have seen it. Selectors ending in
+// " p" match paragraphs directly (short ones are skipped), container
+// selectors are searched for <p> children.
+func selectorParagraphs(doc *goquery.Document, selector string) []string {
+	var paragraphs []string
+	content := false
+	add := func(text string) {
+		paragraphs = append(paragraphs, text)
+		content = content || !isBoilerplate(text)
+	}
+	doc.Find(selector).Each(func(i int, sel *goquery.Selection) {
+		if strings.Contains(selector, " p") {
+			if text := strings.TrimSpace(sel.Text()); text != "" && len(text) > 50 {
+				add(text)
+			}
+		} else {
+			sel.Find("p").Each(func(j int, p *goquery.Selection) {
+				if text := strings.TrimSpace(p.Text()); text != "" {
+					add(text)
+				}
+			})
 		}
 	})
+	if !content {
+		return nil
+	}
+	return paragraphs
+}
 
-	return
+// CleanContent turns extracted raw content into the stored article content:
+// HTML decoding, text normalization, the cutoff markers of the source, then
+// boilerplate removal. Markers go first: a marker line that is boilerplate
+// itself ("Follow us on...") still cuts the promo block after it. Raw content is HTML-ish text (a JSON-LD
+// articleBody as published, or paragraphHTML for the HTML fallback and feed
+// bodies), so entities are decoded exactly once. It needs no network, so
+// stored raw content can be re-cleaned after the rules change (clean-content
+// command).
+func (s *ArticleScraper) CleanContent(raw, source string) string {
+	if raw == "" {
+		return ""
+	}
+	markdown := s.config == nil || s.config.HTMLTags != "strip"
+	text := normalizeText(decodeHTML(raw, markdown), s.normalizeQuotes())
+	text = s.cutAtMarkers(text, source)
+	return s.cleanArticleBody(text, !isParagraphHTML(raw))
+}
+
+// decodeHTML turns raw content into text, decoding entities once: markup
+// goes through sanitizeHTML, whose tokenizer decodes the text between the
+// tags (whitespace around lines is only formatting there), and text without
+// tags is unescaped directly.
+func decodeHTML(raw string, markdown bool) string {
+	if !strings.Contains(raw, "<") {
+		return html.UnescapeString(raw)
+	}
+	lines := strings.Split(sanitizeHTML(raw, markdown), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, "\n")
 }
 
-// cleanArticleBody removes trailing related article text and cleans up the body
-func (s *ArticleScraper) cleanArticleBody(body string) string {
-	// Split by newlines
+// paragraphHTML encodes paragraphs taken from a parsed document (text
+// already decoded) as the raw markup CleanContent expects
+func paragraphHTML(paragraphs []string) string {
+	encoded := make([]string, len(paragraphs))
+	for i, p := range paragraphs {
+		encoded[i] = "<p>" + html.EscapeString(p) + "</p>"
+	}
+	return strings.Join(encoded, "\n")
+}
+
+// isParagraphHTML reports whether raw is paragraphHTML output: the page's
+// own paragraphs, without the related-article titles JSON-LD bodies end with
+func isParagraphHTML(raw string) bool {
+	if !strings.HasPrefix(raw, "<p>") || !strings.HasSuffix(raw, "</p>") {
+		return false
+	}
+	return !strings.Contains(strings.NewReplacer("<p>", "", "</p>", "").Replace(raw), "<")
+}
+
+// cleanArticleBody drops boilerplate paragraphs and, with trimTrailing, the
+// short trailing lines that are usually related-article titles
+func (s *ArticleScraper) cleanArticleBody(body string, trimTrailing bool) string {
 	paragraphs := strings.Split(body, "\n")
 	var cleaned []string
 
 	for _, p := range paragraphs {
-		p = strings.TrimSpace(p)
-		if p == "" {
+		text := strings.TrimSpace(p)
+		if text == "" {
 			continue
 		}
-		if isBoilerplate(p) {
+		if isBoilerplate(text) {
 			continue
 		}
 		// Skip common section headers that indicate the end of article content
-		lower := strings.ToLower(p)
+		lower := strings.ToLower(text)
 		if lower == "more fun off road" || lower == "recommended for you" ||
-			strings.HasPrefix(lower, "more ") && len(p) < 50 {
+			strings.HasPrefix(lower, "more ") && len(text) < 50 {
 			continue
 		}
 		// Skip list items like "- The RideApart Team"
-		if strings.HasPrefix(p, "- The ") && len(p) < 50 {
+		if strings.HasPrefix(text, "- The ") && len(text) < 50 {
 			continue
 		}
 		cleaned = append(cleaned, p)
@@ -286,7 +758,7 @@ func (s *ArticleScraper) cleanArticleBody(body string) string {
 
 	// Remove trailing very short paragraphs (likely related article titles)
 	// Work backwards from the end
-	for len(cleaned) > 1 {
+	for trimTrailing && len(cleaned) > 1 {
 		last := cleaned[len(cleaned)-1]
 		// Related article titles are usually short standalone lines without periods
 		if len(last) < 120 && !strings.Contains(last, ".") {
@@ -362,6 +834,59 @@ func isBoilerplate(text string) bool {
 	return false
 }
 
+// invisibleReplacer maps non-breaking/exotic spaces to a plain space and drops
+// zero-width characters that survive into the markdown otherwise.
+var invisibleReplacer = strings.NewReplacer(
+	"\u00a0", " ", // no-break space
+	"\u202f", " ", // narrow no-break space
+	"\u2007", " ", // figure space
+	"\u2009", " ", // thin space
+	"\u200a", " ", // hair space
+	"\u200b", "", // zero-width space
+	"\u200c", "", // zero-width non-joiner
+	"\u200d", "", // zero-width joiner
+	"\u2060", "", // word joiner
+	"\ufeff", "", // BOM / zero-width no-break space
+)
+
+// smartQuoteReplacer converts typographic quotes to their ASCII counterparts.
+// Only quote characters are touched — currency, dashes and symbols are kept.
+var smartQuoteReplacer = strings.NewReplacer(
+	"\u2018", "'", "\u2019", "'", "\u201a", "'", "\u201b", "'",
+	"\u201c", `"`, "\u201d", `"`, "\u201e", `"`, "\u201f", `"`,
+	"\u2032", "'", "\u2033", `"`,
+)
+
+var multiSpaceRe = regexp.MustCompile(`[ \t]{2,}`)
+
+// normalizeText replaces non-breaking and zero-width characters, collapses
+// runs of spaces inside lines and trims line ends. Indentation and
+// paragraph breaks are preserved; entities are left alone (decodeHTML
+// decodes them). Smart quotes are converted only when asked.
+func normalizeText(text string, normalizeQuotes bool) string {
+	if text == "" {
+		return ""
+	}
+
+	text = invisibleReplacer.Replace(text)
+	if normalizeQuotes {
+		text = smartQuoteReplacer.Replace(text)
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		body := strings.TrimLeft(line, " \t")
+		if body == "" {
+			lines[i] = ""
+			continue
+		}
+		indent := line[:len(line)-len(body)]
+		lines[i] = indent + strings.TrimRight(multiSpaceRe.ReplaceAllString(body, " "), " \t")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
 // uniqueStrings returns unique strings from a slice
 func uniqueStrings(input []string) []string {
 	seen := make(map[string]bool)
@@ -0,0 +1,377 @@
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+// articlePage is a minimal article page with the body in JSON-LD
+func articlePage(body string) string {
+	return `<html><head><script type="application/ld+json">{"@type":"NewsArticle","headline":"Test","articleBody":` +
+		jsonString(body) + `}</script></head><body><p>page</p></body></html>`
+}
+
+func jsonString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// newTestScraper returns a scraper allowed to fetch httptest servers
+func newTestScraper(mutate func(*config.ScraperConfig)) *ArticleScraper {
+	cfg := &config.ScraperConfig{URLPolicy: URLPolicyScheme}
+	if mutate != nil {
+		mutate(cfg)
+	}
+	return NewArticleScraper(cfg, nil)
+}
+
+func TestScrapeArticleSkipsNonHTML(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/report.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7"))
+	})
+	mux.HandleFunc("/sniffed", func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil // keep net/http from setting one
+		w.Write([]byte("%PDF-1.7\n%binary"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := newTestScraper(nil)
+	for _, path := range []string{"/report.pdf", "/sniffed"} {
+		article := &models.Article{SourceURL: srv.URL + path}
+		if _, err := s.ScrapeArticle(article); !errors.Is(err, ErrNotHTML) {
+			t.Errorf("%s: err = %v, want ErrNotHTML", path, err)
+		}
+		if article.Content != "" {
+			t.Errorf("%s: content = %q, want none", path, article.Content)
+		}
+	}
+}
+
+func TestScrapeArticleRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/older", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/older", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/article", http.StatusPermanentRedirect)
+	})
+	mux.HandleFunc("/temp", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/old", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(articlePage("The new bike has more power.")))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := newTestScraper(func(c *config.ScraperConfig) { c.MaxRedirects = 3 })
+
+	// Only permanent hops: the article moves to the final URL
+	moved := &models.Article{SourceURL: srv.URL + "/old"}
+	res, err := s.ScrapeArticle(moved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.SourceURL != srv.URL+"/article" || moved.FeedURL != srv.URL+"/old" {
+		t.Errorf("permanent chain: SourceURL=%s FeedURL=%s, want the final URL and the feed link", moved.SourceURL, moved.FeedURL)
+	}
+	if !strings.HasPrefix(res.Redirect, "moved permanently") {
+		t.Errorf("permanent chain: Redirect = %q", res.Redirect)
+	}
+	if moved.Content != "The new bike has more power." {
+		t.Errorf("content = %q", moved.Content)
+	}
+
+	// A temporary hop anywhere on the way keeps the link
+	kept := &models.Article{SourceURL: srv.URL + "/temp"}
+	res, err = s.ScrapeArticle(kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept.SourceURL != srv.URL+"/temp" || kept.FeedURL != "" {
+		t.Errorf("temporary chain: SourceURL=%s FeedURL=%s, want the link kept", kept.SourceURL, kept.FeedURL)
+	}
+	if !strings.HasPrefix(res.Redirect, "redirected") {
+		t.Errorf("temporary chain: Redirect = %q", res.Redirect)
+	}
+
+	if _, err := s.ScrapeArticle(&models.Article{SourceURL: srv.URL + "/loop"}); err == nil || !strings.Contains(err.Error(), "stopped after 3 redirects") {
+		t.Errorf("redirect loop: err = %v, want the redirect limit", err)
+	}
+
+	direct := &models.Article{SourceURL: srv.URL + "/article"}
+	if res, err = s.ScrapeArticle(direct); err != nil || res.Redirect != "" {
+		t.Errorf("no redirect: Redirect = %q, err = %v", res.Redirect, err)
+	}
+}
+
+func TestScrapeArticleHTMLFallback(t *testing.T) {
+	page := `<html><body><div class="postBody">
+		<p>Kawasaki updated the Ninja 500 for the new season with a revised ECU.</p>
+		<p>Writing &amp;lt;b&amp;gt; shows &lt;b&gt; in a post &amp; nothing more.</p>
+		<p>Photos: Kawasaki</p>
+	</div></body></html>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	s := newTestScraper(func(c *config.ScraperConfig) { c.KeepRawContent = true })
+	article := &models.Article{SourceURL: srv.URL}
+	if _, err := s.ScrapeArticle(article); err != nil {
+		t.Fatal(err)
+	}
+	// The page's own short last paragraph stays; entities are decoded once
+	want := "Kawasaki updated the Ninja 500 for the new season with a revised ECU.\n\n" +
+		"Writing &lt;b&gt; shows <b> in a post & nothing more.\n\n" +
+		"Photos: Kawasaki"
+	if article.Content != want {
+		t.Errorf("Content =\n%q\nwant\n%q", article.Content, want)
+	}
+	// Re-cleaning the kept raw content gives the same text
+	if got := s.CleanContent(article.RawContent, ""); got != want {
+		t.Errorf("CleanContent(RawContent) =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCleanContentTrailingTitles(t *testing.T) {
+	s := newTestScraper(nil)
+	// JSON-LD bodies end with related-article titles glued on
+	raw := "The R1 gets winglets for 2025.\n\nBest Track Bikes Of The Year\nMore From Yamaha"
+	if got := s.CleanContent(raw, ""); got != "The R1 gets winglets for 2025." {
+		t.Errorf("JSON-LD body: %q, want the related titles dropped", got)
+	}
+	// Feed and HTML-fallback paragraphs are the page's own
+	raw = paragraphHTML([]string{"The R1 gets winglets for 2025.", "Photos: Yamaha"})
+	if got := s.CleanContent(raw, ""); got != "The R1 gets winglets for 2025.\n\nPhotos: Yamaha" {
+		t.Errorf("paragraphs: %q, want the short last paragraph kept", got)
+	}
+}
+
+// jsonLDPage wraps JSON-LD blocks in an article page
+func jsonLDPage(blocks ...string) string {
+	var b strings.Builder
+	b.WriteString("<html><head>")
+	for _, block := range blocks {
+		b.WriteString(`<script type="application/ld+json">` + block + `</script>`)
+	}
+	b.WriteString("</head><body><p>page</p></body></html>")
+	return b.String()
+}
+
+func TestExtractFromJSONLDGraph(t *testing.T) {
+	tests := []struct {
+		name         string
+		page         string
+		wantBody     string
+		wantCategory string
+		wantImages   []string
+		wantTags     []string
+	}{
+		{
+			// Yoast SEO: one block, @graph with the site entities around the article
+			name: "yoast graph",
+			page: jsonLDPage(`{"@context":"https://schema.org","@graph":[
+				{"@type":"WebSite","@id":"https://example.com/#website","name":"Example Moto"},
+				{"@type":"Organization","@id":"https://example.com/#org","logo":{"@type":"ImageObject","url":"https://example.com/logo.png"}},
+				{"@type":"WebPage","@id":"https://example.com/story/","name":"Story"},
+				{"@type":"NewsArticle","@id":"https://example.com/story/#article","isPartOf":{"@id":"https://example.com/story/"},
+				 "articleSection":["Reviews","News"],"keywords":"Ducati, Panigale",
+				 "image":{"@type":"ImageObject","url":"https://example.com/cover.jpg"},
+				 "articleBody":"The Yoast article body."}]}`),
+			wantBody: "The Yoast article body.", wantCategory: "Reviews",
+			wantImages: []string{"https://example.com/cover.jpg"}, wantTags: []string{"Ducati", "Panigale"},
+		},
+		{
+			// Rank Math: @type as a list, a BreadcrumbList with no body first
+			name: "rank math graph",
+			page: jsonLDPage(`{"@context":"https://schema.org","@graph":[
+				{"@type":"BreadcrumbList","itemListElement":[{"@type":"ListItem","position":1,"name":"Home"}]},
+				{"@type":["Person"],"name":"Jane Rider"},
+				{"@type":["BlogPosting","NewsArticle"],"articleSection":"Touring",
+				 "image":["https://example.com/a.jpg","https://example.com/b.jpg","https://example.com/a.jpg"],
+				 "keywords":["Adventure","BMW"],"articleBody":"The Rank Math body."}]}`),
+			wantBody: "The Rank Math body.", wantCategory: "Touring",
+			wantImages: []string{"https://example.com/a.jpg", "https://example.com/b.jpg"}, wantTags: []string{"Adventure", "BMW"},
+		},
+		{
+			// the article nested in a WebPage, after a block without one
+			name: "nested mainEntity",
+			page: jsonLDPage(`{"@type":"Organization","name":"Example Moto"}`,
+				`{"@type":"WebPage","mainEntity":{"@type":"Article","articleSection":"Gear","articleBody":"The nested body."}}`),
+			wantBody: "The nested body.", wantCategory: "Gear",
+		},
+		{
+			// a top-level array where a VideoObject also has a body
+			name: "Article type preferred",
+			page: jsonLDPage(`[{"@type":"VideoObject","articleBody":"Video transcript."},
+				{"@type":"ReportageNewsArticle","articleBody":"The report."}]`),
+			wantBody: "The report.",
+		},
+		{
+			name:     "malformed block skipped",
+			page:     jsonLDPage(`{"@graph":[{"@type":"NewsArticle",`, `{"@type":"NewsArticle","articleBody":"From the valid block."}`),
+			wantBody: "From the valid block.",
+		},
+	}
+	s := newTestScraper(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, images, category, tags := s.extractFromJSONLD(tt.page)
+			if body != tt.wantBody || category != tt.wantCategory {
+				t.Errorf("body=%q category=%q, want %q and %q", body, category, tt.wantBody, tt.wantCategory)
+			}
+			if strings.Join(images, " ") != strings.Join(tt.wantImages, " ") || strings.Join(tags, "|") != strings.Join(tt.wantTags, "|") {
+				t.Errorf("images=%q tags=%q, want %q and %q", images, tags, tt.wantImages, tt.wantTags)
+			}
+		})
+	}
+}
+
+// tagCloudPage is an article page with its own tags and a site-wide tag
+// cloud of n links in the sidebar
+func tagCloudPage(articleTags []string, n int) string {
+	var b strings.Builder
+	b.WriteString(`<html><body><article><div class="postBody"><p>The new Tracer 9 gets radar cruise control.</p></div><div class="post-tags">`)
+	for _, tag := range articleTags {
+		b.WriteString(`<a href="/tag/` + strings.ToLower(tag) + `/">` + tag + `</a>`)
+	}
+	b.WriteString(`</div></article><aside class="tag-cloud">`)
+	for i := 0; i < n; i++ {
+		b.WriteString(fmt.Sprintf(`<a href="/tag/cloud-%d/">Cloud %d</a>`, i, i))
+	}
+	b.WriteString(`</aside></body></html>`)
+	return b.String()
+}
+
+func TestExtractFromHTMLTagCloud(t *testing.T) {
+	s := newTestScraper(func(c *config.ScraperConfig) { c.MaxTags = 4 })
+
+	// the article's tags win over the cloud; generic and repeated ones drop
+	_, _, _, tags := s.extractFromHTML(tagCloudPage([]string{"Yamaha", "Racing", "Tracer 9", "yamaha", "Radar"}, 40))
+	if got := strings.Join(tags, "|"); got != "Yamaha|Tracer 9|Radar" {
+		t.Errorf("article tags = %q, want %q", got, "Yamaha|Tracer 9|Radar")
+	}
+
+	// without article tags the cloud is used, capped at max_tags
+	_, _, _, tags = s.extractFromHTML(tagCloudPage(nil, 40))
+	if got := strings.Join(tags, "|"); got != "Cloud 0|Cloud 1|Cloud 2|Cloud 3" {
+		t.Errorf("cloud tags = %q, want the first 4", got)
+	}
+
+	// unset max_tags falls back to the default
+	_, _, _, tags = newTestScraper(nil).extractFromHTML(tagCloudPage(nil, 40))
+	if len(tags) != defaultMaxTags {
+		t.Errorf("default cap: %d tags, want %d", len(tags), defaultMaxTags)
+	}
+}
+
+func TestScrapeArticleGallery(t *testing.T) {
+	page := `<html><head><script type="application/ld+json">{"@type":"NewsArticle","articleBody":"The new Tenere 700 gets a bigger tank.",
+		"image":["https://cdn.example.com/cover.jpg","https://cdn.example.com/side.jpg"]}</script></head><body>
+		<div class="postBody">
+			<figure><img src="/img/front.jpg"></figure>
+			<img src="data:image/gif;base64,R0lGOD" data-src="https://cdn.example.com/lazy.jpg">
+			<img srcset="https://cdn.example.com/small.jpg 480w, https://cdn.example.com/large.jpg 1600w">
+			<img src="https://cdn.example.com/side.jpg">
+			<img src="ftp://example.com/old.jpg">
+			<img src="https://cdn.example.com/extra.jpg">
+		</div></body></html>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	s := newTestScraper(func(c *config.ScraperConfig) { c.MaxImages = 5 })
+	article := &models.Article{SourceURL: srv.URL + "/news/tenere"}
+	if _, err := s.ScrapeArticle(article); err != nil {
+		t.Fatal(err)
+	}
+	// JSON-LD images first, then the body in document order: resolved,
+	// deduped, non-http dropped and capped at max_images
+	want := []string{
+		"https://cdn.example.com/cover.jpg",
+		"https://cdn.example.com/side.jpg",
+		srv.URL + "/img/front.jpg",
+		"https://cdn.example.com/lazy.jpg",
+		"https://cdn.example.com/large.jpg",
+	}
+	if strings.Join(article.ImageURLs, " ") != strings.Join(want, " ") || article.ImageURL != want[0] {
+		t.Errorf("images = %q (cover %q), want %q", article.ImageURLs, article.ImageURL, want)
+	}
+}
+
+func TestScrapeArticleBodyLimit(t *testing.T) {
+	const limit = 4096
+	page := articlePage(strings.Repeat("word ", 200))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fits", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(page + strings.Repeat(" ", limit-len(page))))
+	})
+	mux.HandleFunc("/declared", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(page + strings.Repeat(" ", limit)))
+	})
+	mux.HandleFunc("/chunked", func(w http.ResponseWriter, r *http.Request) {
+		// no Content-Length: the limit has to be found while reading
+		w.Header().Set("Content-Type", "text/html")
+		for i := 0; i < 4; i++ {
+			w.Write([]byte(strings.Repeat(" ", limit/2)))
+			w.(http.Flusher).Flush()
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := newTestScraper(func(c *config.ScraperConfig) { c.MaxBodyBytes = limit })
+	if _, err := s.ScrapeArticle(&models.Article{SourceURL: srv.URL + "/fits"}); err != nil {
+		t.Errorf("page of exactly the limit: %v", err)
+	}
+	for _, path := range []string{"/declared", "/chunked"} {
+		article := &models.Article{SourceURL: srv.URL + path}
+		if _, err := s.ScrapeArticle(article); !errors.Is(err, ErrBodyTooLarge) {
+			t.Errorf("%s: err = %v, want ErrBodyTooLarge", path, err)
+		}
+		if article.Content != "" {
+			t.Errorf("%s: content = %q, want none", path, article.Content)
+		}
+	}
+}
+
+func TestScrapeArticleRequestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// headers arrive in time, the body never does
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	s := newTestScraper(func(c *config.ScraperConfig) { c.RequestTimeoutSec = 1 })
+	start := time.Now()
+	if _, err := s.ScrapeArticle(&models.Article{SourceURL: srv.URL}); err == nil {
+		t.Fatal("expected the stalled body to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("request took %s, want about 1s", elapsed)
+	}
+}
@@ -0,0 +1,40 @@
+package fetcher
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minTitleTokens is the fewest distinct words a title needs to be compared;
+// shorter titles ("Video", "Recall notice") are too generic to tell two
+// stories apart
+const minTitleTokens = 3
+
+// TitleTokens returns the distinct lower-cased words (letters and digits) of
+// a title, for TitleSimilarity
+func TitleTokens(title string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := make(map[string]bool, len(words))
+	for _, w := range words {
+		tokens[w] = true
+	}
+	return tokens
+}
+
+// TitleSimilarity is the Jaccard index of two TitleTokens sets: the shared
+// words over all distinct words, from 0 (nothing shared) to 1 (same words).
+// Sets with fewer than minTitleTokens words score 0.
+func TitleSimilarity(a, b map[string]bool) float64 {
+	if len(a) < minTitleTokens || len(b) < minTitleTokens {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,21 @@
+package fetcher
+
+import "testing"
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"Ducati unveils new Panigale V4 R for 2027", "Ducati Unveils the New 2027 Panigale V4 R", 0.7, 0.8},
+		{"Honda recalls CBR1000RR-R over fuel pump", "Honda recalls CBR1000RR-R over fuel pump!", 1, 1},
+		{"Ducati unveils new Panigale V4 R", "Yamaha MT-09 review: the best naked bike", 0, 0},
+		{"Recall notice", "Recall notice", 0, 0}, // too short to compare
+	}
+	for _, tt := range tests {
+		got := TitleSimilarity(TitleTokens(tt.a), TitleTokens(tt.b))
+		if got < tt.min || got > tt.max {
+			t.Errorf("TitleSimilarity(%q, %q) = %.2f, want %.2f..%.2f", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
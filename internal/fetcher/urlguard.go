@@ -0,0 +1,57 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"moto-news/internal/httpclient"
+)
+
+// Scraper URL policies (scraper.url_policy)
+const (
+	URLPolicyPublic  = "public"  // http(s), no loopback/private/link-local addresses
+	URLPolicySources = "sources" // public, on a configured feed host or scraper.allowed_hosts
+	URLPolicyScheme  = "scheme"  // any http(s) URL
+)
+
+// checkPageURL applies scraper.url_policy to a page about to be fetched.
+// Article links come from feeds, so a hostile or compromised feed must not
+// be able to point the scraper at file:// or the cloud metadata endpoint.
+// Errors wrap httpclient.ErrBlockedURL unless the host did not resolve.
+func (s *ArticleScraper) checkPageURL(ctx context.Context, u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", httpclient.ErrBlockedURL)
+	}
+	policy := URLPolicyPublic
+	if s.config != nil && s.config.URLPolicy != "" {
+		policy = s.config.URLPolicy
+	}
+	switch policy {
+	case URLPolicyScheme:
+		return nil
+	case URLPolicySources:
+		if !s.sourceHost(u.Hostname()) {
+			return fmt.Errorf("%w: %s is not a source host (see scraper.allowed_hosts)", httpclient.ErrBlockedURL, u.Hostname())
+		}
+	}
+	return httpclient.CheckPublicURL(ctx, u)
+}
+
+// sourceHost reports whether host is, or is a subdomain of, a feed host or
+// an entry of scraper.allowed_hosts ("www." is ignored on both sides)
+func (s *ArticleScraper) sourceHost(host string) bool {
+	if s.config == nil {
+		return false
+	}
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	allowed := append(append([]string{}, s.config.SourceHosts...), s.config.AllowedHosts...)
+	for _, a := range allowed {
+		a = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(a)), "www.")
+		if a != "" && (host == a || strings.HasSuffix(host, "."+a)) {
+			return true
+		}
+	}
+	return false
+}
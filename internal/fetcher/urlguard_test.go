@@ -0,0 +1,50 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"moto-news/internal/config"
+	"moto-news/internal/httpclient"
+	"moto-news/internal/models"
+)
+
+func TestCheckPageURLPolicies(t *testing.T) {
+	tests := []struct {
+		policy  string
+		url     string
+		blocked bool
+	}{
+		{URLPolicyPublic, "http://169.254.169.254/latest/meta-data/", true},
+		{URLPolicyPublic, "http://10.1.2.3/article", true},
+		{URLPolicyPublic, "ftp://93.184.216.34/file", true},
+		{URLPolicyPublic, "https://93.184.216.34/article", false},
+		{URLPolicySources, "https://93.184.216.34/article", true},
+		{URLPolicySources, "http://192.168.0.5/article", true},
+		{URLPolicyScheme, "http://10.1.2.3/article", false},
+		{URLPolicyScheme, "file:///etc/passwd", true},
+	}
+	for _, tt := range tests {
+		s := NewArticleScraper(&config.ScraperConfig{URLPolicy: tt.policy}, nil)
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = s.checkPageURL(context.Background(), u)
+		if blocked := errors.Is(err, httpclient.ErrBlockedURL); blocked != tt.blocked {
+			t.Errorf("%s %s: err = %v, want blocked=%v", tt.policy, tt.url, err, tt.blocked)
+		}
+	}
+}
+
+func TestScrapeArticleRefusesMetadataEndpoint(t *testing.T) {
+	s := NewArticleScraper(&config.ScraperConfig{}, httpclient.PublicTransport(nil, httpclient.DestScraper))
+	for _, link := range []string{"http://169.254.169.254/latest/meta-data/", "http://172.16.0.1/news"} {
+		_, err := s.ScrapeArticle(&models.Article{SourceURL: link})
+		if !errors.Is(err, httpclient.ErrBlockedURL) {
+			t.Errorf("ScrapeArticle(%s) = %v, want ErrBlockedURL", link, err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package formatter
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+// autoTag is a compiled hugo.formatter.auto_tags entry
+type autoTag struct {
+	tag      string
+	keywords []string         // lower-cased words or phrases; a trailing * matches any word ending
+	patterns []*regexp.Regexp // "re:" keywords
+}
+
+// parseAutoTags compiles hugo.formatter.auto_tags. Invalid regexps are
+// rejected by config.Load, so compile errors here just skip the keyword.
+func parseAutoTags(rules []config.AutoTagConfig) []autoTag {
+	var parsed []autoTag
+	for _, rule := range rules {
+		at := autoTag{tag: strings.TrimSpace(rule.Tag)}
+		for _, k := range rule.Keywords {
+			if pattern, ok := strings.CutPrefix(k, "re:"); ok {
+				if re, err := regexp.Compile(pattern); err == nil {
+					at.patterns = append(at.patterns, re)
+				}
+				continue
+			}
+			if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+				at.keywords = append(at.keywords, k)
+			}
+		}
+		if at.tag != "" && len(at.keywords)+len(at.patterns) > 0 {
+			parsed = append(parsed, at)
+		}
+	}
+	return parsed
+}
+
+func (at autoTag) matches(text, lower string) bool {
+	for _, k := range at.keywords {
+		if containsWord(lower, k) {
+			return true
+		}
+	}
+	for _, re := range at.patterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWord reports whether keyword occurs in text as whole words, so
+// "ev" does not match "every". A keyword ending in * only has to start a
+// word: "электро*" matches "электромотоцикл".
+func containsWord(text, keyword string) bool {
+	prefix := strings.HasSuffix(keyword, "*")
+	keyword = strings.TrimSuffix(keyword, "*")
+	if keyword == "" {
+		return false
+	}
+	for start := 0; ; {
+		i := strings.Index(text[start:], keyword)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(keyword)
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && (prefix || !isWordRune(after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		start = i + size
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// AutoTagger adds hugo.formatter.auto_tags tags to articles. The service
+// runs it after scraping and after translation, so the tags are stored with
+// the article like the source tags.
+type AutoTagger struct {
+	rules []autoTag
+}
+
+// NewAutoTagger compiles the auto_tags rules; nil or empty rules tag nothing
+func NewAutoTagger(rules []config.AutoTagConfig) *AutoTagger {
+	return &AutoTagger{rules: parseAutoTags(rules)}
+}
+
+// Apply adds the tags whose keywords occur in the article's title or text,
+// translated or original, in config order after the tags it has, without
+// case-insensitive repeats and while there are fewer than maxTags.
+// Hand-set tags are left alone. Reports whether the tags changed.
+func (t *AutoTagger) Apply(article *models.Article) bool {
+	if t == nil || len(t.rules) == 0 || article.TaxonomyOverridden {
+		return false
+	}
+	text := strings.Join([]string{article.TitleRU, article.ContentRU, article.Title, article.Content}, "\n\n")
+	lower := strings.ToLower(text)
+	var matched []string
+	for _, at := range t.rules {
+		if at.matches(text, lower) {
+			matched = append(matched, at.tag)
+		}
+	}
+	before := len(article.Tags)
+	article.Tags = appendAutoTags(article.Tags, matched)
+	return len(article.Tags) != before
+}
+
+// autoTagNames returns the lower-cased tags of the rules; translateTags
+// keeps them under drop_unknown_tags since they are written as wanted
+func autoTagNames(rules []autoTag) map[string]bool {
+	names := make(map[string]bool, len(rules))
+	for _, at := range rules {
+		names[strings.ToLower(at.tag)] = true
+	}
+	return names
+}
+
+// autoTagsSetting is auto_tags for Fingerprint: tag plus keywords per entry,
+// nil when unset so fingerprints from before the setting existed stay valid
+func autoTagsSetting(rules []config.AutoTagConfig) [][]string {
+	var setting [][]string
+	for _, rule := range rules {
+		setting = append(setting, append([]string{rule.Tag}, rule.Keywords...))
+	}
+	return setting
+}
+
+// appendAutoTags adds auto tags missing from tags (case-insensitive) while
+// there is room under maxTags; tags is not modified
+func appendAutoTags(tags, auto []string) []string {
+	tags = slices.Clip(tags)
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		seen[strings.ToLower(t)] = true
+	}
+	for _, t := range auto {
+		if len(tags) >= maxTags {
+			break
+		}
+		if key := strings.ToLower(t); !seen[key] {
+			seen[key] = true
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
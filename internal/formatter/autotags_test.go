@@ -0,0 +1,103 @@
+package formatter
+
+import (
+	"slices"
+	"testing"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+var testAutoTagRules = []config.AutoTagConfig{
+	{Tag: "Электромотоциклы", Keywords: []string{"электро*", "ev", "electric"}},
+	{Tag: "MotoGP", Keywords: []string{"motogp"}},
+	{Tag: "Отзывы", Keywords: []string{"re:(?i)\\brecall"}},
+	{Tag: "Туризм", Keywords: []string{"touring", "adventure bike"}},
+}
+
+func TestAutoTaggerApply(t *testing.T) {
+	tagger := NewAutoTagger(testAutoTagRules)
+	tests := []struct {
+		name    string
+		article models.Article
+		want    []string
+	}{
+		{
+			name: "some rules match",
+			article: models.Article{
+				Title:   "Every rider at the MotoGP test",
+				Content: "Ducati's electric prototype ran every lap.",
+				Tags:    []string{"Racing"},
+			},
+			want: []string{"Racing", "Электромотоциклы", "MotoGP"},
+		},
+		{
+			name: "keyword inside a word",
+			article: models.Article{
+				Title:   "Eleven events, every weekend",
+				Content: "Neverending adventures and a retouring plan.",
+			},
+			want: nil,
+		},
+		{
+			name: "translated text and word prefix",
+			article: models.Article{
+				Title:     "Zero SR/F review",
+				TitleRU:   "Обзор Zero SR/F",
+				ContentRU: "Электромотоцикл с запасом хода 250 км.",
+			},
+			want: []string{"Электромотоциклы"},
+		},
+		{
+			name: "phrase and regexp",
+			article: models.Article{
+				Title:   "Honda recalls the Africa Twin",
+				Content: "The adventure bike gets a new fuel pump.",
+			},
+			want: []string{"Отзывы", "Туризм"},
+		},
+		{
+			name: "no repeat and cap",
+			article: models.Article{
+				Title:   "MotoGP electric touring recall",
+				Content: "EV.",
+				Tags:    []string{"a", "b", "motogp", "c"},
+			},
+			want: []string{"a", "b", "motogp", "c", "Электромотоциклы"},
+		},
+		{
+			name: "hand-set tags",
+			article: models.Article{
+				Title:              "MotoGP electric",
+				Tags:               []string{"Гонки"},
+				TaxonomyOverridden: true,
+			},
+			want: []string{"Гонки"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := tt.article
+			before := slices.Clone(article.Tags)
+			changed := tagger.Apply(&article)
+			if !slices.Equal(article.Tags, tt.want) && !(len(article.Tags) == 0 && len(tt.want) == 0) {
+				t.Errorf("tags = %q, want %q", article.Tags, tt.want)
+			}
+			if changed != !slices.Equal(before, article.Tags) {
+				t.Errorf("Apply reported changed=%v for %q -> %q", changed, before, article.Tags)
+			}
+		})
+	}
+}
+
+func TestAutoTagsSurviveDropUnknownTags(t *testing.T) {
+	f := NewMarkdownFormatter(&config.FormatterConfig{
+		DropUnknownTags: true,
+		AutoTags:        testAutoTagRules,
+	})
+	article := testArticle()
+	article.Tags = []string{"racing", "Some Unknown Tag", "MotoGP"}
+	if got := f.translateTags(article.Tags); !slices.Equal(got, []string{"Гонки", "MotoGP"}) {
+		t.Errorf("tags = %q, want the translated tag and the auto tag", got)
+	}
+}
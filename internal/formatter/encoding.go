@@ -0,0 +1,21 @@
+package formatter
+
+import "strings"
+
+// byteOrderMark is U+FEFF; scraped pages and some translators leave it in
+// the text, where Hugo renders it as an invisible character
+const byteOrderMark = "\uFEFF"
+
+// normalizeOutput makes rendered markdown LF-only whatever the OS or the
+// input: a CR left in the frontmatter by scraped text, a translation or a
+// footer edited on Windows breaks Hugo's frontmatter parsing. CRLF becomes
+// LF and a stray CR is dropped rather than starting a new line. With
+// hugo.formatter.strip_bom, byte order marks are removed as well.
+func (f *MarkdownFormatter) normalizeOutput(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "")
+	if f.stripBOM {
+		s = strings.ReplaceAll(s, byteOrderMark, "")
+	}
+	return s
+}
@@ -0,0 +1,35 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeOutput(t *testing.T) {
+	f := NewMarkdownFormatter(nil)
+	tests := map[string]string{
+		"a\r\nb":          "a\nb",
+		"a\rb":            "ab",
+		"a\r\r\nb\n":      "a\nb\n",
+		"\ufefftitle\r\n": "title\n",
+	}
+	for in, want := range tests {
+		if got := f.normalizeOutput(in); got != want {
+			t.Errorf("normalizeOutput(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFormatHasNoCR(t *testing.T) {
+	a := testArticle()
+	a.TitleRU = "Новый\r мотоцикл"
+	a.ContentRU = "Первый абзац.\r\n\r\nВторой\rабзац.\r"
+	a.Tags = []string{"Ducati\r\n"}
+	out := NewMarkdownFormatter(nil).Format(a)
+	if strings.Contains(out, "\r") {
+		t.Errorf("output contains CR: %q", out)
+	}
+	if !strings.Contains(out, "Первый абзац.\n\nВторойабзац.") {
+		t.Errorf("CRLF not turned into LF or stray CR not dropped: %q", out)
+	}
+}
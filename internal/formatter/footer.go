@@ -0,0 +1,70 @@
+package formatter
+
+import (
+	"strings"
+	"text/template"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+// footerData is what the hugo.formatter.footer template sees
+type footerData struct {
+	SourceSite    string // display name of the source (sources[].display_name, else its name)
+	SourceURL     string
+	Author        string
+	Title         string // original (English) title
+	TitleRU       string
+	OriginalTitle string // Title when footer_original_title is set, else empty
+}
+
+// parseFooter compiles the footer template; nil disables the footer. An
+// empty template means config.DefaultFooter. Templates are validated at
+// config load, so a parse error here only comes from direct construction and
+// falls back to the default.
+func parseFooter(cfg *config.FormatterConfig) *template.Template {
+	if cfg.DisableFooter {
+		return nil
+	}
+	text := cfg.Footer
+	if text == "" {
+		text = config.DefaultFooter
+	}
+	tmpl, err := template.New("footer").Parse(text)
+	if err != nil {
+		tmpl = template.Must(template.New("footer").Parse(config.DefaultFooter))
+	}
+	return tmpl
+}
+
+// renderFooter executes the footer template for article; "" when the footer
+// is disabled or renders to nothing
+func (f *MarkdownFormatter) renderFooter(article *models.Article) string {
+	if f.footer == nil {
+		return ""
+	}
+	data := footerData{
+		SourceSite: f.sourceDisplayName(article.SourceSite),
+		SourceURL:  article.SourceURL,
+		Author:     article.Author,
+		Title:      article.Title,
+		TitleRU:    article.TitleRU,
+	}
+	if f.footerOriginalTitle {
+		data.OriginalTitle = article.Title
+	}
+	var sb strings.Builder
+	if err := f.footer.Execute(&sb, data); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// sourceDisplayName returns the configured display name of a source,
+// falling back to the raw source name
+func (f *MarkdownFormatter) sourceDisplayName(site string) string {
+	if name := f.sourceNames[site]; name != "" {
+		return name
+	}
+	return site
+}
@@ -0,0 +1,46 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"moto-news/internal/config"
+)
+
+func TestFooterTemplate(t *testing.T) {
+	article := testArticle()
+	article.Author = "Jane Rider"
+	tests := []struct {
+		name string
+		cfg  config.FormatterConfig
+		want string // "" = no footer
+	}{
+		{"default", config.FormatterConfig{}, "*Источник: [example.com](https://example.com/bike)*"},
+		{"custom", config.FormatterConfig{
+			Footer:      "Source: {{.SourceSite}} — {{.SourceURL}}{{with .Author}}, by {{.}}{{end}}",
+			SourceNames: map[string]string{"example.com": "Example Moto"},
+		},
+			"Source: Example Moto — https://example.com/bike, by Jane Rider"},
+		{"original title", config.FormatterConfig{FooterOriginalTitle: true},
+			"*Источник: [example.com](https://example.com/bike) — «New bike»*"},
+		{"both titles", config.FormatterConfig{Footer: "{{.TitleRU}} / {{.Title}}"}, "Новый мотоцикл / New bike"},
+		{"disabled", config.FormatterConfig{DisableFooter: true, Footer: "unused"}, ""},
+		{"renders empty", config.FormatterConfig{Footer: "{{if .OriginalTitle}}{{.OriginalTitle}}{{end}}"}, ""},
+		{"invalid falls back to the default", config.FormatterConfig{Footer: "{{.SourceSite"}, "*Источник: [example.com](https://example.com/bike)*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body := splitFrontmatter(t, NewMarkdownFormatter(&tt.cfg).Format(article), FrontmatterYAML)
+			_, footer, found := strings.Cut(body, "\n---\n\n")
+			if tt.want == "" {
+				if found {
+					t.Errorf("footer %q, want none", footer)
+				}
+				return
+			}
+			if footer != tt.want+"\n" {
+				t.Errorf("footer = %q, want %q", footer, tt.want)
+			}
+		})
+	}
+}
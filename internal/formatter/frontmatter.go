@@ -0,0 +1,116 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter formats (hugo.formatter.frontmatter_format)
+const (
+	FrontmatterYAML = "yaml" // between --- lines
+	FrontmatterTOML = "toml" // between +++ lines
+	FrontmatterJSON = "json" // a JSON object, no delimiters
+)
+
+// frontmatter is the post frontmatter. Format and FormatOriginal fill it
+// once and the YAML, TOML and JSON encoders marshal it, so all formats carry
+// the same keys in the same order.
+type frontmatter struct {
+	Title      string     `yaml:"title" toml:"title" json:"title"`
+	Date       time.Time  `yaml:"date" toml:"date" json:"date"`
+	Featured   bool       `yaml:"featured,omitempty" toml:"featured,omitempty" json:"featured,omitempty"`
+	Categories []string   `yaml:"categories,omitempty" toml:"categories,omitempty" json:"categories,omitempty"`
+	Tags       []string   `yaml:"tags,omitempty" toml:"tags,omitempty" json:"tags,omitempty"`
+	Source     string     `yaml:"source" toml:"source" json:"source"`
+	Author     string     `yaml:"author,omitempty" toml:"author,omitempty" json:"author,omitempty"`
+	Cover      *coverMeta `yaml:"cover,omitempty" toml:"cover,omitempty,inline" json:"cover,omitempty"`
+	Images     []string   `yaml:"images,omitempty" toml:"images,omitempty" json:"images,omitempty"`
+}
+
+// coverMeta is the PaperMod cover table; TOML writes it inline so the keys
+// after it stay top-level
+type coverMeta struct {
+	Image  string `yaml:"image" toml:"image" json:"image"`
+	Alt    string `yaml:"alt" toml:"alt" json:"alt"`
+	Hidden bool   `yaml:"hidden" toml:"hidden" json:"hidden"`
+}
+
+// render returns the frontmatter block with its delimiters and the blank
+// line that separates it from the content. Every field is a valid UTF-8
+// string, a bool or a time and the buffer takes any write, so the encoders
+// have nothing to fail on.
+func (fm frontmatter) render(format string) string {
+	fm = fm.clean()
+	var buf bytes.Buffer
+	switch format {
+	case FrontmatterTOML:
+		buf.WriteString("+++\n")
+		_ = toml.NewEncoder(&buf).Encode(fm)
+		buf.WriteString("+++\n\n")
+	case FrontmatterJSON:
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(fm)
+		buf.WriteString("\n")
+	default:
+		buf.WriteString("---\n")
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		_ = enc.Encode(fm)
+		_ = enc.Close()
+		buf.WriteString("---\n\n")
+	}
+	return buf.String()
+}
+
+// clean returns fm with its strings made single-line valid UTF-8 (YAML would
+// write other bytes as !!binary) and the date cut to whole seconds, so all
+// formats carry the same values
+func (fm frontmatter) clean() frontmatter {
+	fm.Title = singleLine(fm.Title)
+	fm.Date = fm.Date.Truncate(time.Second)
+	fm.Categories = singleLines(fm.Categories)
+	fm.Tags = singleLines(fm.Tags)
+	fm.Source = singleLine(fm.Source)
+	fm.Author = singleLine(fm.Author)
+	fm.Images = singleLines(fm.Images)
+	if fm.Cover != nil {
+		cover := *fm.Cover
+		cover.Image = singleLine(cover.Image)
+		cover.Alt = singleLine(cover.Alt)
+		fm.Cover = &cover
+	}
+	return fm
+}
+
+func singleLine(s string) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "\r", "")
+}
+
+func singleLines(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	out := make([]string, len(list))
+	for i, s := range list {
+		out[i] = singleLine(s)
+	}
+	return out
+}
+
+// frontmatterSetting is the frontmatter format for Fingerprint; "" for the
+// default so fingerprints from before the setting existed stay valid
+func frontmatterSetting(format string) string {
+	if format == FrontmatterYAML {
+		return ""
+	}
+	return format
+}
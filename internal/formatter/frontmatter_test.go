@@ -0,0 +1,109 @@
+package formatter
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"moto-news/internal/config"
+)
+
+// splitFrontmatter cuts a rendered post into its frontmatter block and body
+func splitFrontmatter(t *testing.T, post, format string) (string, string) {
+	t.Helper()
+	var delim string
+	switch format {
+	case FrontmatterTOML:
+		delim = "+++\n"
+	case FrontmatterJSON:
+		end := strings.Index(post, "\n}\n")
+		if !strings.HasPrefix(post, "{\n") || end < 0 {
+			t.Fatalf("no JSON frontmatter in %q", post)
+		}
+		return post[:end+2], post[end+3:]
+	default:
+		delim = "---\n"
+	}
+	rest, ok := strings.CutPrefix(post, delim)
+	block, body, found := strings.Cut(rest, delim)
+	if !ok || !found {
+		t.Fatalf("no %q delimited frontmatter in %q", delim, post)
+	}
+	return block, body
+}
+
+func decodeFrontmatter(t *testing.T, block, format string) frontmatter {
+	t.Helper()
+	var fm frontmatter
+	var err error
+	switch format {
+	case FrontmatterTOML:
+		err = toml.Unmarshal([]byte(block), &fm)
+	case FrontmatterJSON:
+		err = json.Unmarshal([]byte(block), &fm)
+	default:
+		err = yaml.Unmarshal([]byte(block), &fm)
+	}
+	if err != nil {
+		t.Fatalf("%s frontmatter does not parse: %v\n%s", format, err, block)
+	}
+	return fm
+}
+
+func TestFrontmatterRoundTrip(t *testing.T) {
+	article := testArticle()
+	article.TitleRU = "Да: \"нет\" #1 <b>&</b> \\ 'кавычки'\nвторая строка"
+	article.Author = "no"
+	article.Tags = []string{"true", "1.5", "[x]"}
+	article.ImageURLs = []string{"https://example.com/a.jpg", "https://example.com/b.jpg?w=1&h=2"}
+	article.PublishedAt = time.Date(2026, 3, 1, 12, 30, 15, 999, time.FixedZone("", 3*3600))
+
+	want := frontmatter{
+		Title:      "Да: \"нет\" #1 <b>&</b> \\ 'кавычки' вторая строка",
+		Date:       time.Date(2026, 3, 1, 9, 30, 15, 0, time.UTC),
+		Categories: []string{"Новости", "Обзоры"},
+		Tags:       []string{"true", "1.5", "[x]"},
+		Source:     "https://example.com/bike",
+		Author:     "no",
+		Cover:      &coverMeta{Image: "https://example.com/a.jpg", Alt: "Да: \"нет\" #1 <b>&</b> \\ 'кавычки' вторая строка"},
+		Images:     []string{"https://example.com/b.jpg?w=1&h=2"},
+	}
+
+	for _, format := range []string{FrontmatterYAML, FrontmatterTOML, FrontmatterJSON} {
+		t.Run(format, func(t *testing.T) {
+			f := NewMarkdownFormatter(&config.FormatterConfig{
+				BaseCategories:    []string{"Новости"},
+				FrontmatterFormat: format,
+			})
+			block, body := splitFrontmatter(t, f.Format(article), format)
+			got := decodeFrontmatter(t, block, format)
+
+			if !got.Date.Equal(want.Date) {
+				t.Errorf("date = %v, want %v", got.Date, want.Date)
+			}
+			got.Date = want.Date
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("frontmatter = %+v, want %+v", got, want)
+			}
+			if !strings.HasPrefix(body, "\n") || !strings.Contains(body, "Текст статьи.") {
+				t.Errorf("body = %q", body)
+			}
+		})
+	}
+}
+
+func TestFrontmatterOmitsEmptyFields(t *testing.T) {
+	for _, format := range []string{FrontmatterYAML, FrontmatterTOML, FrontmatterJSON} {
+		f := NewMarkdownFormatter(&config.FormatterConfig{FrontmatterFormat: format})
+		block, _ := splitFrontmatter(t, f.Format(testArticle()), format)
+		for _, key := range []string{"featured", "tags", "author", "cover", "images"} {
+			if strings.Contains(block, key) {
+				t.Errorf("%s: %q is in the frontmatter of an article without it:\n%s", format, key, block)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"moto-news/internal/models"
+)
+
+// Placements of the additional article images (hugo.formatter.gallery)
+const (
+	GalleryFrontmatter = "frontmatter" // images: list in the frontmatter (PaperMod uses it for og/twitter cards)
+	GalleryShortcode   = "shortcode"   // {{< figure >}} per image after the translation
+	GalleryNone        = "none"        // cover only
+)
+
+// galleryImages returns the article images after the cover that pass the
+// image allow/blocklist
+func (f *MarkdownFormatter) galleryImages(article *models.Article) []string {
+	if f.gallery == GalleryNone || len(article.ImageURLs) < 2 {
+		return nil
+	}
+	var gallery []string
+	for _, u := range article.ImageURLs[1:] {
+		if f.imageAllowed(u) {
+			gallery = append(gallery, u)
+		}
+	}
+	return gallery
+}
+
+// galleryFrontmatter returns the images: frontmatter list
+func (f *MarkdownFormatter) galleryFrontmatter(article *models.Article) []string {
+	if f.gallery != GalleryFrontmatter {
+		return nil
+	}
+	return f.galleryImages(article)
+}
+
+// galleryShortcodes renders the images as Hugo's built-in figure shortcode,
+// one per line, so any theme shows them in the post body
+func (f *MarkdownFormatter) galleryShortcodes(article *models.Article, alt string) string {
+	if f.gallery != GalleryShortcode {
+		return ""
+	}
+	gallery := f.galleryImages(article)
+	if len(gallery) == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(gallery))
+	for _, u := range gallery {
+		lines = append(lines, fmt.Sprintf("{{< figure src=%s alt=%s >}}", shortcodeQuote(u), shortcodeQuote(alt)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shortcodeQuote quotes a shortcode parameter value
+func shortcodeQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// gallerySetting is gallery for Fingerprint; "" for the default so
+// fingerprints from before the setting existed stay valid
+func gallerySetting(mode string) string {
+	if mode == GalleryFrontmatter {
+		return ""
+	}
+	return mode
+}
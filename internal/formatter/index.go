@@ -0,0 +1,214 @@
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+// IndexPage is one generated index file; Path is relative to the posts
+// directory and mirrors GetFilePath (posts/_index.md, posts/YYYY/_index.md,
+// posts/<subdir>/_index.md)
+type IndexPage struct {
+	Path    string
+	Content string
+}
+
+// GenerateIndexPages renders the posts index according to cfg (nil = one
+// page): a single page, a page per year linked from the main page ("year"),
+// or the cfg.PageSize most recent articles plus the yearly archives
+// ("recent"). title "" means defaultIndexTitle. Articles are filtered by
+// cfg.Categories first; months, years and the articles of a month follow
+// cfg.MonthOrder and cfg.ArticleOrder. Sources with a content_subdir also get a section page listing their
+// articles in that directory.
+func (f *MarkdownFormatter) GenerateIndexPages(articles []*models.Article, title string, cfg *config.IndexConfig) []IndexPage {
+	if cfg == nil {
+		cfg = &config.IndexConfig{}
+	}
+	if title == "" {
+		title = defaultIndexTitle
+	}
+	articles = sortNewestFirst(f.filterByCategory(articles, cfg.Categories))
+	if cfg.Paginate != "year" && cfg.Paginate != "recent" {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("# %s\n\n", title))
+		f.writeIndexMonths(&sb, sortForIndex(articles, cfg), "")
+		pages := []IndexPage{{Path: "_index.md", Content: f.normalizeOutput(sb.String())}}
+		return append(pages, f.sectionPages(articles, title, cfg)...)
+	}
+
+	var years []string
+	byYear := make(map[string][]*models.Article)
+	for _, a := range sortForIndex(articles, cfg) {
+		year := a.PublishedAt.Format("2006")
+		if _, ok := byYear[year]; !ok {
+			years = append(years, year)
+		}
+		byYear[year] = append(byYear[year], a)
+	}
+
+	var main strings.Builder
+	main.WriteString(fmt.Sprintf("# %s\n\n", title))
+	if cfg.Paginate == "recent" {
+		recent := articles
+		if cfg.PageSize > 0 && len(recent) > cfg.PageSize {
+			recent = recent[:cfg.PageSize]
+		}
+		f.writeIndexMonths(&main, sortForIndex(recent, cfg), "")
+	}
+	if len(years) > 0 {
+		main.WriteString("## Архив\n\n")
+		for _, year := range years {
+			main.WriteString(fmt.Sprintf("- [%s](%s/_index.md) (%d)\n", year, year, len(byYear[year])))
+		}
+		main.WriteString("\n")
+	}
+
+	pages := []IndexPage{{Path: "_index.md", Content: f.normalizeOutput(main.String())}}
+	for _, year := range years {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("# %s — %s\n\n", title, year))
+		f.writeIndexMonths(&sb, byYear[year], year+"/")
+		pages = append(pages, IndexPage{Path: year + "/_index.md", Content: f.normalizeOutput(sb.String())})
+	}
+	return append(pages, f.sectionPages(articles, title, cfg)...)
+}
+
+// sectionPages renders posts/<subdir>/_index.md for every content_subdir
+// holding some of articles, by subdir. A section shared by several sources
+// is titled with the subdir, else with the source's display name.
+func (f *MarkdownFormatter) sectionPages(articles []*models.Article, title string, cfg *config.IndexConfig) []IndexPage {
+	if len(f.sourceSubdirs) == 0 {
+		return nil
+	}
+	bySubdir := make(map[string][]*models.Article)
+	sources := make(map[string]map[string]bool)
+	for _, a := range articles {
+		subdir := f.sourceSubdirs[a.SourceSite]
+		// A path frozen before the subdir was set stays where it is
+		if subdir == "" || !strings.HasPrefix(f.PostPath(a), "posts/"+subdir+"/") {
+			continue
+		}
+		bySubdir[subdir] = append(bySubdir[subdir], a)
+		if sources[subdir] == nil {
+			sources[subdir] = make(map[string]bool)
+		}
+		sources[subdir][a.SourceSite] = true
+	}
+
+	subdirs := make([]string, 0, len(bySubdir))
+	for subdir := range bySubdir {
+		subdirs = append(subdirs, subdir)
+	}
+	sort.Strings(subdirs)
+
+	var pages []IndexPage
+	for _, subdir := range subdirs {
+		name := subdir
+		if len(sources[subdir]) == 1 {
+			name = f.sourceDisplayName(bySubdir[subdir][0].SourceSite)
+		}
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("# %s — %s\n\n", title, name))
+		f.writeIndexMonths(&sb, sortForIndex(bySubdir[subdir], cfg), subdir+"/")
+		pages = append(pages, IndexPage{Path: subdir + "/_index.md", Content: f.normalizeOutput(sb.String())})
+	}
+	return pages
+}
+
+// filterByCategory keeps articles whose source category or rendered
+// categories match one of categories (case-insensitive); empty keeps all
+func (f *MarkdownFormatter) filterByCategory(articles []*models.Article, categories []string) []*models.Article {
+	if len(categories) == 0 {
+		return articles
+	}
+	var result []*models.Article
+	for _, a := range articles {
+		names := append(f.categories(a), a.Category)
+	match:
+		for _, want := range categories {
+			for _, name := range names {
+				if strings.EqualFold(strings.TrimSpace(want), name) {
+					result = append(result, a)
+					break match
+				}
+			}
+		}
+	}
+	return result
+}
+
+// sortNewestFirst returns a copy of articles ordered by publish date, newest
+// first (ties: higher id first)
+func sortNewestFirst(articles []*models.Article) []*models.Article {
+	sorted := append([]*models.Article(nil), articles...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].PublishedAt.Equal(sorted[j].PublishedAt) {
+			return sorted[i].PublishedAt.After(sorted[j].PublishedAt)
+		}
+		return sorted[i].ID > sorted[j].ID
+	})
+	return sorted
+}
+
+// sortForIndex returns a copy of articles ordered for an index page: by
+// month (newest first unless cfg.MonthOrder is "asc"), then by publish date
+// within the month (newest first unless cfg.ArticleOrder is "asc"), ties by
+// id in the same direction
+func sortForIndex(articles []*models.Article, cfg *config.IndexConfig) []*models.Article {
+	monthAsc := cfg.MonthOrder == "asc"
+	articleAsc := cfg.ArticleOrder == "asc"
+	sorted := append([]*models.Article(nil), articles...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if ma, mb := a.PublishedAt.Format("2006-01"), b.PublishedAt.Format("2006-01"); ma != mb {
+			return (ma < mb) == monthAsc
+		}
+		if !a.PublishedAt.Equal(b.PublishedAt) {
+			return a.PublishedAt.Before(b.PublishedAt) == articleAsc
+		}
+		return (a.ID < b.ID) == articleAsc
+	})
+	return sorted
+}
+
+// writeIndexMonths writes articles ordered by sortForIndex as one
+// "## Month YYYY" section per month. Links are relative to the page's
+// directory pageDir under posts/ ("" for posts/_index.md, else ending in
+// "/").
+func (f *MarkdownFormatter) writeIndexMonths(sb *strings.Builder, articles []*models.Article, pageDir string) {
+	month := ""
+	for _, a := range articles {
+		if key := a.PublishedAt.Format("2006-01"); key != month {
+			if month != "" {
+				sb.WriteString("\n")
+			}
+			month = key
+			sb.WriteString(fmt.Sprintf("## %s\n\n", a.PublishedAt.Format("January 2006")))
+		}
+		title := a.TitleRU
+		if title == "" {
+			title = a.Title
+		}
+		sb.WriteString(fmt.Sprintf("- [%s](%s)\n", title, relativeLink(pageDir, strings.TrimPrefix(f.PostPath(a), "posts/"))))
+	}
+	if month != "" {
+		sb.WriteString("\n")
+	}
+}
+
+// relativeLink returns target (relative to posts/) as seen from pageDir: the
+// page's own directory is trimmed, otherwise one "../" per level climbs back
+// to posts/ (e.g. an article under rideapart/ listed on 2024/_index.md)
+func relativeLink(pageDir, target string) string {
+	if pageDir == "" {
+		return target
+	}
+	if rest, ok := strings.CutPrefix(target, pageDir); ok {
+		return rest
+	}
+	return strings.Repeat("../", strings.Count(pageDir, "/")) + target
+}
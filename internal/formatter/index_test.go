@@ -0,0 +1,173 @@
+package formatter
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+// indexArticles returns one article per date, ids in date order
+func indexArticles(dates ...string) []*models.Article {
+	articles := make([]*models.Article, len(dates))
+	for i, d := range dates {
+		published, _ := time.Parse("2006-01-02", d)
+		articles[i] = &models.Article{
+			ID:          int64(i + 1),
+			TitleRU:     fmt.Sprintf("Статья %d", i+1),
+			Category:    "News",
+			Slug:        fmt.Sprintf("article-%d", i+1),
+			PublishedAt: published,
+		}
+	}
+	return articles
+}
+
+// indexPages renders the index pages of articles and maps them by path
+func indexPages(articles []*models.Article, cfg *config.IndexConfig) (map[string]string, []string) {
+	pages := make(map[string]string)
+	var paths []string
+	for _, p := range NewMarkdownFormatter(nil).GenerateIndexPages(articles, "", cfg) {
+		pages[p.Path] = p.Content
+		paths = append(paths, p.Path)
+	}
+	return pages, paths
+}
+
+func TestGenerateIndexPagesPaginate(t *testing.T) {
+	articles := indexArticles("2024-11-05", "2025-01-10", "2025-03-01", "2025-03-20")
+	const (
+		year2025 = "# Новости — 2025\n\n## March 2025\n\n- [Статья 4](03/article-4.md)\n- [Статья 3](03/article-3.md)\n\n" +
+			"## January 2025\n\n- [Статья 2](01/article-2.md)\n\n"
+		year2024 = "# Новости — 2024\n\n## November 2024\n\n- [Статья 1](11/article-1.md)\n\n"
+		archive  = "## Архив\n\n- [2025](2025/_index.md) (3)\n- [2024](2024/_index.md) (1)\n\n"
+	)
+	tests := []struct {
+		name string
+		cfg  *config.IndexConfig
+		want map[string]string
+	}{
+		{"one page", nil, map[string]string{
+			"_index.md": "# Новости\n\n## March 2025\n\n- [Статья 4](2025/03/article-4.md)\n- [Статья 3](2025/03/article-3.md)\n\n" +
+				"## January 2025\n\n- [Статья 2](2025/01/article-2.md)\n\n## November 2024\n\n- [Статья 1](2024/11/article-1.md)\n\n",
+		}},
+		{"per year", &config.IndexConfig{Paginate: "year"}, map[string]string{
+			"_index.md":      "# Новости\n\n" + archive,
+			"2025/_index.md": year2025,
+			"2024/_index.md": year2024,
+		}},
+		{"recent", &config.IndexConfig{Paginate: "recent", PageSize: 2}, map[string]string{
+			"_index.md":      "# Новости\n\n## March 2025\n\n- [Статья 4](2025/03/article-4.md)\n- [Статья 3](2025/03/article-3.md)\n\n" + archive,
+			"2025/_index.md": year2025,
+			"2024/_index.md": year2024,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, paths := indexPages(articles, tt.cfg)
+			if len(pages) != len(tt.want) {
+				t.Errorf("pages = %q, want %d", paths, len(tt.want))
+			}
+			for path, want := range tt.want {
+				if pages[path] != want {
+					t.Errorf("%s = %q, want %q", path, pages[path], want)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateIndexPagesCategories(t *testing.T) {
+	articles := indexArticles("2025-01-10", "2025-01-11", "2025-01-12")
+	articles[1].Category = "Reviews"
+	articles[2].Category = "racing"
+
+	// source name or translated name, any case
+	pages, _ := indexPages(articles, &config.IndexConfig{Categories: []string{"reviews", "Гонки"}})
+	want := "# Новости\n\n## January 2025\n\n- [Статья 3](2025/01/article-3.md)\n- [Статья 2](2025/01/article-2.md)\n\n"
+	if got := pages["_index.md"]; got != want {
+		t.Errorf("index = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateIndexOrder(t *testing.T) {
+	// inserted out of order, two months sharing a year and one a year earlier
+	articles := indexArticles("2025-03-01", "2024-11-05", "2025-03-20", "2025-01-10", "2025-03-10")
+	tests := []struct {
+		name string
+		cfg  *config.IndexConfig
+		want string
+	}{
+		{"default", nil, "## March 2025\n\n- [Статья 3](2025/03/article-3.md)\n- [Статья 5](2025/03/article-5.md)\n- [Статья 1](2025/03/article-1.md)\n\n" +
+			"## January 2025\n\n- [Статья 4](2025/01/article-4.md)\n\n## November 2024\n\n- [Статья 2](2024/11/article-2.md)\n\n"},
+		{"months asc", &config.IndexConfig{MonthOrder: "asc"}, "## November 2024\n\n- [Статья 2](2024/11/article-2.md)\n\n## January 2025\n\n- [Статья 4](2025/01/article-4.md)\n\n" +
+			"## March 2025\n\n- [Статья 3](2025/03/article-3.md)\n- [Статья 5](2025/03/article-5.md)\n- [Статья 1](2025/03/article-1.md)\n\n"},
+		{"articles asc", &config.IndexConfig{ArticleOrder: "asc"}, "## March 2025\n\n- [Статья 1](2025/03/article-1.md)\n- [Статья 5](2025/03/article-5.md)\n- [Статья 3](2025/03/article-3.md)\n\n" +
+			"## January 2025\n\n- [Статья 4](2025/01/article-4.md)\n\n## November 2024\n\n- [Статья 2](2024/11/article-2.md)\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := "# Новости\n\n" + tt.want
+			for i := 0; i < 5; i++ {
+				if pages, _ := indexPages(articles, tt.cfg); pages["_index.md"] != want {
+					t.Fatalf("run %d: index = %q, want %q", i, pages["_index.md"], want)
+				}
+			}
+		})
+	}
+
+	// the yearly archive list follows the month order too
+	pages, _ := indexPages(articles, &config.IndexConfig{Paginate: "year", MonthOrder: "asc"})
+	if want := "# Новости\n\n## Архив\n\n- [2024](2024/_index.md) (1)\n- [2025](2025/_index.md) (4)\n\n"; pages["_index.md"] != want {
+		t.Errorf("archive = %q, want %q", pages["_index.md"], want)
+	}
+
+	if got, want := NewMarkdownFormatter(nil).GenerateIndex(articles, "Новости"), "# Новости\n\n"+tests[0].want; got != want {
+		t.Errorf("GenerateIndex = %q, want %q", got, want)
+	}
+}
+
+func TestSourceSubdirs(t *testing.T) {
+	f := NewMarkdownFormatter(&config.FormatterConfig{
+		SourceNames:   map[string]string{"rideapart": "RideApart"},
+		SourceSubdirs: map[string]string{"rideapart": "rideapart", "cycleworld": "cycle/world"},
+	})
+	articles := indexArticles("2025-03-01", "2025-03-02", "2025-03-03")
+	articles[0].SourceSite = "rideapart"
+	articles[1].SourceSite = "cycleworld"
+	articles[2].SourceSite = "other"
+
+	want := []string{
+		filepath.Join("content", "posts", "rideapart", "2025", "03", "article-1.md"),
+		filepath.Join("content", "posts", "cycle", "world", "2025", "03", "article-2.md"),
+		filepath.Join("content", "posts", "2025", "03", "article-3.md"),
+	}
+	for i, a := range articles {
+		if got := f.GetFilePath(a, "content"); got != want[i] {
+			t.Errorf("%s: path = %s, want %s", a.SourceSite, got, want[i])
+		}
+	}
+
+	pages := make(map[string]string)
+	for _, p := range f.GenerateIndexPages(articles, "", nil) {
+		pages[p.Path] = p.Content
+	}
+	wantPages := map[string]string{
+		"_index.md": "# Новости\n\n## March 2025\n\n- [Статья 3](2025/03/article-3.md)\n- [Статья 2](cycle/world/2025/03/article-2.md)\n" +
+			"- [Статья 1](rideapart/2025/03/article-1.md)\n\n",
+		// a section of one source is titled with its display name, else its name
+		"rideapart/_index.md":   "# Новости — RideApart\n\n## March 2025\n\n- [Статья 1](2025/03/article-1.md)\n\n",
+		"cycle/world/_index.md": "# Новости — cycleworld\n\n## March 2025\n\n- [Статья 2](2025/03/article-2.md)\n\n",
+	}
+	if len(pages) != len(wantPages) {
+		t.Errorf("pages = %v, want %d", pages, len(wantPages))
+	}
+	for path, want := range wantPages {
+		if pages[path] != want {
+			t.Errorf("%s = %q, want %q", path, pages[path], want)
+		}
+	}
+}
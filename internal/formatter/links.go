@@ -0,0 +1,65 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Rendering of the links kept in the article text (hugo.formatter.links)
+const (
+	LinksInline   = "inline"   // [text](url) as scraped
+	LinksFootnote = "footnote" // [text][1], with the numbered references after the text
+	LinksStrip    = "strip"    // link text only
+)
+
+// markdownLinkRe matches inline Markdown links and images; the scraper
+// writes only absolute http(s) targets (see fetcher.sanitizeHTML)
+var markdownLinkRe = regexp.MustCompile(`(!?)\[([^\[\]\n]*)\]\((https?://[^\s()]+)\)`)
+
+// renderLinks rewrites the inline links of content for hugo.formatter.links.
+// Footnote mode numbers each distinct URL once, in order of first
+// appearance, and lists the references after the text. Images are left
+// alone; a link without text is dropped in both modes.
+func (f *MarkdownFormatter) renderLinks(content string) string {
+	if f.links != LinksFootnote && f.links != LinksStrip {
+		return content
+	}
+	var refs []string
+	numbers := make(map[string]int)
+	content = markdownLinkRe.ReplaceAllStringFunc(content, func(link string) string {
+		m := markdownLinkRe.FindStringSubmatch(link)
+		image, text, target := m[1] != "", m[2], m[3]
+		if image {
+			return link
+		}
+		if f.links == LinksStrip || strings.TrimSpace(text) == "" {
+			return text
+		}
+		n, ok := numbers[target]
+		if !ok {
+			refs = append(refs, target)
+			n = len(refs)
+			numbers[target] = n
+		}
+		return fmt.Sprintf("[%s][%d]", text, n)
+	})
+	if len(refs) == 0 {
+		return content
+	}
+
+	lines := make([]string, len(refs))
+	for i, target := range refs {
+		lines[i] = fmt.Sprintf("[%d]: %s", i+1, target)
+	}
+	return content + "\n\n" + strings.Join(lines, "\n")
+}
+
+// linksSetting is links for Fingerprint; "" for the default so fingerprints
+// from before the setting existed stay valid
+func linksSetting(mode string) string {
+	if mode == LinksInline {
+		return ""
+	}
+	return mode
+}
@@ -0,0 +1,60 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"moto-news/internal/config"
+)
+
+func TestRenderLinks(t *testing.T) {
+	content := "Honda показала [новый CBR](https://honda.com/cbr) на выставке.\n\n" +
+		"![Фото](https://img.example.com/cbr.jpg)\n\n" +
+		"Подробности у [Honda](https://honda.com/cbr) и в [пресс-релизе](https://honda.com/press). [](https://example.com/empty)"
+	tests := []struct {
+		links string
+		want  string
+	}{
+		{"", content},
+		{LinksInline, content},
+		{LinksFootnote, "Honda показала [новый CBR][1] на выставке.\n\n" +
+			"![Фото](https://img.example.com/cbr.jpg)\n\n" +
+			"Подробности у [Honda][1] и в [пресс-релизе][2]. \n\n" +
+			"[1]: https://honda.com/cbr\n[2]: https://honda.com/press"},
+		{LinksStrip, "Honda показала новый CBR на выставке.\n\n" +
+			"![Фото](https://img.example.com/cbr.jpg)\n\n" +
+			"Подробности у Honda и в пресс-релизе. "},
+	}
+	for _, tt := range tests {
+		f := NewMarkdownFormatter(&config.FormatterConfig{Links: tt.links})
+		if got := f.renderLinks(content); got != tt.want {
+			t.Errorf("links %q:\n%s\nwant\n%s", tt.links, got, tt.want)
+		}
+	}
+
+	// no links: nothing appended
+	f := NewMarkdownFormatter(&config.FormatterConfig{Links: LinksFootnote})
+	if got := f.renderLinks("Просто текст."); got != "Просто текст." {
+		t.Errorf("footnote without links = %q", got)
+	}
+}
+
+func TestFormatLinksMode(t *testing.T) {
+	article := testArticle()
+	article.ContentRU = "Читайте [обзор](https://example.com/review) целиком."
+
+	inline := NewMarkdownFormatter(&config.FormatterConfig{Links: LinksInline})
+	footnote := NewMarkdownFormatter(&config.FormatterConfig{Links: LinksFootnote})
+	if post := inline.Format(article); !strings.Contains(post, "[обзор](https://example.com/review)") {
+		t.Errorf("inline post:\n%s\nwant the link kept inline", post)
+	}
+	post := footnote.Format(article)
+	if !strings.Contains(post, "Читайте [обзор][1] целиком.") || !strings.Contains(post, "\n[1]: https://example.com/review\n") {
+		t.Errorf("footnote post:\n%s\nwant a numbered reference", post)
+	}
+
+	// only a non-default mode changes the fingerprint
+	if NewMarkdownFormatter(&config.FormatterConfig{}).Fingerprint() != inline.Fingerprint() || inline.Fingerprint() == footnote.Fingerprint() {
+		t.Error("fingerprint: want inline equal to the default and footnote different")
+	}
+}
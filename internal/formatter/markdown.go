@@ -1,18 +1,182 @@
 package formatter
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"path"
 	"path/filepath"
 	"strings"
+	"text/template"
 	"time"
+	"unicode"
 
+	"moto-news/internal/config"
 	"moto-news/internal/models"
 )
 
-type MarkdownFormatter struct{}
+// maxTags is how many tags end up in the frontmatter
+const maxTags = 5
+
+// formatVersion is part of Fingerprint; bump it whenever Format's output
+// changes so published articles can be re-rendered with publish --refresh
+const formatVersion = 2
+
+// defaultBaseCategories is used when no formatter config is given
+var defaultBaseCategories = []string{"Новости"}
+
+// defaultIndexTitle heads the posts index when hugo.index.title is empty
+const defaultIndexTitle = "Новости"
+
+type MarkdownFormatter struct {
+	categoryTranslations map[string]string
+	tagTranslations      map[string]string
+	dropUnknownTags      bool
+	baseCategories       []string
+	location             *time.Location
+	imageAllowlist       []string
+	imageBlocklist       []string
+	defaultImage         string
+	defaultImages        map[string]string  // lowercased category -> cover
+	footerText           string             // template source, for Fingerprint
+	footer               *template.Template // nil = no footer
+	footerOriginalTitle  bool
+	maxTitleLength       int // 0 = full title
+	featuredFrontmatter  bool
+	sourceNames          map[string]string // source name -> display name
+	includeOriginal      string            // OriginalNone, OriginalDetails or OriginalFile
+	stripBOM             bool
+	gallery              string            // GalleryFrontmatter, GalleryShortcode or GalleryNone
+	stablePermalinks     bool              // use Article.PublishedPath once it is set
+	sourceSubdirs        map[string]string // source name -> directory under posts/
+	frontmatterFormat    string            // FrontmatterYAML, FrontmatterTOML or FrontmatterJSON
+	links                string            // LinksInline, LinksFootnote or LinksStrip
+	autoTagNames         map[string]bool   // auto_tags tags, kept by drop_unknown_tags
+	autoTagsSpec         [][]string        // auto_tags as configured, for Fingerprint
+}
+
+// NewMarkdownFormatter creates a formatter. Translations from cfg are merged
+// over the built-in defaults; cfg may be nil.
+func NewMarkdownFormatter(cfg *config.FormatterConfig) *MarkdownFormatter {
+	if cfg == nil {
+		cfg = &config.FormatterConfig{BaseCategories: defaultBaseCategories, StripBOM: true}
+	}
+	gallery := cfg.Gallery
+	if gallery == "" {
+		gallery = GalleryFrontmatter
+	}
+	frontmatterFormat := cfg.FrontmatterFormat
+	if frontmatterFormat == "" {
+		frontmatterFormat = FrontmatterYAML
+	}
+	links := cfg.Links
+	if links == "" {
+		links = LinksInline
+	}
+	return &MarkdownFormatter{
+		categoryTranslations: mergeTranslations(defaultTranslations, cfg.CategoryTranslations),
+		tagTranslations:      mergeTranslations(defaultTranslations, cfg.TagTranslations),
+		dropUnknownTags:      cfg.DropUnknownTags,
+		baseCategories:       cfg.BaseCategories,
+		location:             loadLocation(cfg.Timezone),
+		imageAllowlist:       cfg.ImageAllowlist,
+		imageBlocklist:       cfg.ImageBlocklist,
+		defaultImage:         cfg.DefaultImage,
+		defaultImages:        lowerKeys(cfg.DefaultImages),
+		footerText:           cfg.Footer,
+		footer:               parseFooter(cfg),
+		footerOriginalTitle:  cfg.FooterOriginalTitle,
+		maxTitleLength:       cfg.MaxTitleLength,
+		featuredFrontmatter:  cfg.FeaturedFrontmatter,
+		sourceNames:          cfg.SourceNames,
+		includeOriginal:      cfg.IncludeOriginal,
+		stripBOM:             cfg.StripBOM,
+		gallery:              gallery,
+		stablePermalinks:     cfg.StablePermalinks,
+		sourceSubdirs:        cfg.SourceSubdirs,
+		frontmatterFormat:    frontmatterFormat,
+		links:                links,
+		autoTagNames:         autoTagNames(parseAutoTags(cfg.AutoTags)),
+		autoTagsSpec:         autoTagsSetting(cfg.AutoTags),
+	}
+}
+
+// Fingerprint identifies the rendering: the format version plus every
+// setting that affects Format's output. Articles published with a different
+// fingerprint are stale.
+func (f *MarkdownFormatter) Fingerprint() string {
+	footer := f.footerText
+	if footer == config.DefaultFooter {
+		footer = ""
+	}
+	settings, _ := json.Marshal(struct {
+		Version         int
+		Categories      map[string]string
+		Tags            map[string]string
+		DropUnknownTags bool
+		BaseCategories  []string
+		Location        string
+		ImageAllowlist  []string
+		ImageBlocklist  []string
+		DefaultImage    string
+		// Omitted at their defaults so fingerprints from before these
+		// settings existed stay valid
+		Footer         string            `json:",omitempty"`
+		FooterDisabled bool              `json:",omitempty"`
+		FooterTitle    bool              `json:",omitempty"`
+		MaxTitleLength int               `json:",omitempty"`
+		Featured       bool              `json:",omitempty"`
+		SourceNames    map[string]string `json:",omitempty"`
+		DefaultImages  map[string]string `json:",omitempty"`
+		CoverFallback  bool              `json:",omitempty"` // default images also cover articles without one
+		Original       string            `json:",omitempty"`
+		KeepBOM        bool              `json:",omitempty"`
+		Gallery        string            `json:",omitempty"`
+		Frontmatter    string            `json:",omitempty"`
+		Links          string            `json:",omitempty"`
+		AutoTags       [][]string        `json:",omitempty"`
+	}{
+		formatVersion,
+		f.categoryTranslations,
+		f.tagTranslations,
+		f.dropUnknownTags,
+		f.baseCategories,
+		f.location.String(),
+		f.imageAllowlist,
+		f.imageBlocklist,
+		f.defaultImage,
+		footer,
+		f.footer == nil,
+		f.footerOriginalTitle,
+		f.maxTitleLength,
+		f.featuredFrontmatter,
+		f.sourceNames,
+		f.defaultImages,
+		f.defaultImage != "" || len(f.defaultImages) > 0,
+		originalSetting(f.includeOriginal),
+		!f.stripBOM,
+		gallerySetting(f.gallery),
+		frontmatterSetting(f.frontmatterFormat),
+		linksSetting(f.links),
+		f.autoTagsSpec,
+	})
+	sum := sha256.Sum256(settings)
+	return hex.EncodeToString(sum[:6])
+}
 
-func NewMarkdownFormatter() *MarkdownFormatter {
-	return &MarkdownFormatter{}
+// loadLocation resolves an IANA timezone name, falling back to UTC (names
+// are validated at config load, so this only guards direct construction)
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
 }
 
 // Format converts an article to Hugo-compatible markdown.
@@ -29,67 +193,93 @@ func (f *MarkdownFormatter) Format(article *models.Article) string {
 	if title == "" {
 		title = article.Title
 	}
+	title = shortenTitle(title, f.maxTitleLength)
 
 	// Frontmatter
-	sb.WriteString("---\n")
-	sb.WriteString(fmt.Sprintf("title: %s\n", yamlQuote(title)))
-	sb.WriteString(fmt.Sprintf("date: %s\n", article.PublishedAt.Format("2006-01-02T15:04:05")))
-
-	// Categories
-	sb.WriteString("categories:\n")
-	sb.WriteString("  - Новости\n")
-	if article.Category != "" {
-		sb.WriteString(fmt.Sprintf("  - %s\n", yamlQuote(f.translateCategory(article.Category))))
-	}
-
-	// Tags
-	if len(article.Tags) > 0 {
-		sb.WriteString("tags:\n")
-		for _, tag := range article.Tags[:min(5, len(article.Tags))] {
-			sb.WriteString(fmt.Sprintf("  - %s\n", yamlQuote(tag)))
-		}
+	fm := frontmatter{
+		Title: title,
+		// Feeds carry assorted offsets; render all dates in one zone with
+		// the offset so Hugo orders posts correctly
+		Date:     article.PublishedAt.In(f.location),
+		Featured: f.featuredFrontmatter && article.Featured,
+		// Categories: configured base list, then the translated source category
+		Categories: f.categories(article),
+		Source:     article.SourceURL,
+		Author:     article.Author,
 	}
 
-	// Source reference
-	sb.WriteString(fmt.Sprintf("source: %s\n", yamlQuote(article.SourceURL)))
-	if article.Author != "" {
-		sb.WriteString(fmt.Sprintf("author: %s\n", yamlQuote(article.Author)))
+	// Tags, auto tags included (hand-set tags are used as written)
+	tags := f.translateTags(article.Tags)
+	if article.TaxonomyOverridden {
+		tags = uniqueFold(article.Tags)
 	}
+	fm.Tags = tags
 
-	// Cover image (first of ImageURLs or legacy ImageURL)
-	coverURL := article.ImageURL
-	if coverURL == "" && len(article.ImageURLs) > 0 {
-		coverURL = article.ImageURLs[0]
-	}
-	if coverURL != "" {
-		sb.WriteString("cover:\n")
-		sb.WriteString(fmt.Sprintf("  image: %s\n", yamlQuote(coverURL)))
-		sb.WriteString(fmt.Sprintf("  alt: %s\n", yamlQuote(title)))
-		sb.WriteString("  hidden: false\n")
+	// Cover image, or the configured default
+	if coverURL := f.coverURL(article); coverURL != "" {
+		fm.Cover = &coverMeta{Image: coverURL, Alt: title}
 	}
 	// Additional images (gallery) — first is already in cover
-	if len(article.ImageURLs) > 1 {
-		sb.WriteString("images:\n")
-		for _, u := range article.ImageURLs[1:] {
-			sb.WriteString(fmt.Sprintf("  - %s\n", yamlQuote(u)))
-		}
-	}
+	fm.Images = f.galleryFrontmatter(article)
 
-	sb.WriteString("---\n\n")
+	sb.WriteString(fm.render(f.frontmatterFormat))
 
 	// Content (no # Title — Hugo renders title from frontmatter)
 	content := article.ContentRU
 	if content == "" {
 		content = article.Content
 	}
-	sb.WriteString(f.formatContent(content))
-	sb.WriteString("\n\n")
+	sb.WriteString(f.renderLinks(f.formatContent(content)))
+	sb.WriteString("\n")
 
-	// Footer with source
-	sb.WriteString("---\n\n")
-	sb.WriteString(fmt.Sprintf("*Источник: [%s](%s)*\n", article.SourceSite, article.SourceURL))
+	// Gallery in the body (hugo.formatter.gallery: shortcode)
+	if gallery := f.galleryShortcodes(article, title); gallery != "" {
+		sb.WriteString("\n")
+		sb.WriteString(gallery)
+		sb.WriteString("\n")
+	}
+
+	// Original English text (hugo.formatter.include_original: details)
+	if original := f.originalDetails(article); original != "" {
+		sb.WriteString("\n")
+		sb.WriteString(original)
+		sb.WriteString("\n")
+	}
+
+	// Footer with source (hugo.formatter.footer)
+	if footer := f.renderFooter(article); footer != "" {
+		sb.WriteString("\n---\n\n")
+		sb.WriteString(footer)
+		sb.WriteString("\n")
+	}
+
+	return f.normalizeOutput(sb.String())
+}
 
-	return sb.String()
+// coverURL picks the cover image: the first of ImageURLs or legacy
+// ImageURL. Images on hosts that are not allowed are dropped; missing covers
+// fall back to the category's default image, then default_image.
+func (f *MarkdownFormatter) coverURL(article *models.Article) string {
+	coverURL := article.ImageURL
+	if coverURL == "" && len(article.ImageURLs) > 0 {
+		coverURL = article.ImageURLs[0]
+	}
+	if coverURL != "" && !f.imageAllowed(coverURL) {
+		coverURL = ""
+	}
+	if coverURL == "" {
+		coverURL = f.defaultCover(article)
+	}
+	return coverURL
+}
+
+// originalSetting is include_original for Fingerprint; "" when off so
+// fingerprints from before the setting existed stay valid
+func originalSetting(mode string) string {
+	if mode == OriginalNone {
+		return ""
+	}
+	return mode
 }
 
 // formatContent cleans and formats the article content
@@ -113,6 +303,17 @@ func (f *MarkdownFormatter) GetFilePath(article *models.Article, baseDir string)
 	if article == nil {
 		return filepath.Join(baseDir, "posts", "unknown.md")
 	}
+	return filepath.Join(baseDir, filepath.FromSlash(f.PostPath(article)))
+}
+
+// PostPath returns the article's path under the content directory with
+// forward slashes: the path frozen at first publish when stable permalinks
+// are on, else posts/YYYY/MM/slug.md, or posts/<subdir>/YYYY/MM/slug.md for
+// a source with a content_subdir
+func (f *MarkdownFormatter) PostPath(article *models.Article) string {
+	if f.stablePermalinks && article.PublishedPath != "" {
+		return article.PublishedPath
+	}
 
 	year := article.PublishedAt.Format("2006")
 	month := article.PublishedAt.Format("01")
@@ -123,100 +324,188 @@ func (f *MarkdownFormatter) GetFilePath(article *models.Article, baseDir string)
 	}
 
 	// For Hugo: posts/YYYY/MM/slug.md (under content directory)
-	return filepath.Join(baseDir, "posts", year, month, slug+".md")
+	return path.Join("posts", f.sourceSubdirs[article.SourceSite], year, month, slug+".md")
 }
 
-// translateCategory translates common categories to Russian
-func (f *MarkdownFormatter) translateCategory(category string) string {
-	translations := map[string]string{
-		"news":                      "Новости",
-		"reviews":                   "Обзоры",
-		"features":                  "Статьи",
-		"sportbikes":                "Спортбайки",
-		"cruisers":                  "Круизеры",
-		"adventure":                 "Эндуро",
-		"touring":                   "Туринг",
-		"naked":                     "Нейкеды",
-		"electric":                  "Электромотоциклы",
-		"racing":                    "Гонки",
-		"gear":                      "Экипировка",
-		"technology":                "Технологии",
-		"industry":                  "Индустрия",
-		"custom":                    "Кастом",
-		"adventure-and-dual-sport":  "Эндуро",
-		"touring-and-sport-touring": "Туринг",
-		"standard-and-naked":        "Нейкеды",
-		"electric-motorcycles":      "Электромотоциклы",
-	}
-
-	lower := strings.ToLower(category)
-	if translated, ok := translations[lower]; ok {
-		return translated
+// defaultTranslations are the built-in EN->RU terms shared by categories and tags
+var defaultTranslations = map[string]string{
+	"news":                      "Новости",
+	"reviews":                   "Обзоры",
+	"features":                  "Статьи",
+	"sportbikes":                "Спортбайки",
+	"cruisers":                  "Круизеры",
+	"adventure":                 "Эндуро",
+	"touring":                   "Туринг",
+	"naked":                     "Нейкеды",
+	"electric":                  "Электромотоциклы",
+	"racing":                    "Гонки",
+	"gear":                      "Экипировка",
+	"technology":                "Технологии",
+	"industry":                  "Индустрия",
+	"custom":                    "Кастом",
+	"adventure-and-dual-sport":  "Эндуро",
+	"touring-and-sport-touring": "Туринг",
+	"standard-and-naked":        "Нейкеды",
+	"electric-motorcycles":      "Электромотоциклы",
+}
+
+// mergeTranslations copies base and applies overrides on top, lowercasing keys
+func mergeTranslations(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[strings.ToLower(k)] = v
 	}
-	return category
+	for k, v := range overrides {
+		merged[strings.ToLower(k)] = v
+	}
+	return merged
 }
 
-// GenerateIndex generates an index page for a directory
-func (f *MarkdownFormatter) GenerateIndex(articles []*models.Article, title string) string {
-	var sb strings.Builder
+// categories returns the base categories followed by the article's category
+// (translated unless set by hand), skipping blanks and case-insensitive duplicates
+func (f *MarkdownFormatter) categories(article *models.Article) []string {
+	all := append([]string{}, f.baseCategories...)
+	if article.TaxonomyOverridden {
+		all = append(all, article.Category)
+	} else if article.Category != "" {
+		all = append(all, f.translateCategory(article.Category))
+	}
+	return uniqueFold(all)
+}
 
-	sb.WriteString(fmt.Sprintf("# %s\n\n", title))
+// uniqueFold trims values and drops empty ones and case-insensitive duplicates
+func uniqueFold(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		key := strings.ToLower(v)
+		if v == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+// imageAllowed checks an image URL's host against the blocklist and, when
+// set, the allowlist. Entries match the host and its subdomains. Relative
+// URLs (site-local images) are always allowed.
+func (f *MarkdownFormatter) imageAllowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return true
+	}
+	if hostMatches(host, f.imageBlocklist) {
+		return false
+	}
+	return len(f.imageAllowlist) == 0 || hostMatches(host, f.imageAllowlist)
+}
 
-	// Group by month
-	byMonth := make(map[string][]*models.Article)
-	for _, a := range articles {
-		key := a.PublishedAt.Format("2006-01")
-		byMonth[key] = append(byMonth[key], a)
+// defaultCover returns the configured cover for an article without a usable
+// image: by source category, then translated category, then default_image
+func (f *MarkdownFormatter) defaultCover(article *models.Article) string {
+	if article.Category != "" {
+		if img, ok := f.defaultImages[strings.ToLower(article.Category)]; ok {
+			return img
+		}
+		if img, ok := f.defaultImages[strings.ToLower(f.translateCategory(article.Category))]; ok {
+			return img
+		}
 	}
+	return f.defaultImage
+}
 
-	// Sort months (newest first)
-	months := make([]string, 0, len(byMonth))
-	for m := range byMonth {
-		months = append(months, m)
+// lowerKeys copies a map with lowercased keys (nil stays nil)
+func lowerKeys(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
 	}
-	// Simple reverse sort
-	for i, j := 0, len(months)-1; i < j; i, j = i+1, j-1 {
-		months[i], months[j] = months[j], months[i]
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[strings.ToLower(k)] = v
 	}
+	return out
+}
 
-	for _, month := range months {
-		t, err := time.Parse("2006-01", month)
-		if err != nil {
-			// Skip months that fail to parse instead of showing zero time
-			continue
+// hostMatches reports whether host equals or is a subdomain of any entry
+func hostMatches(host string, patterns []string) bool {
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(p), "."))
+		if p != "" && (host == p || strings.HasSuffix(host, "."+p)) {
+			return true
 		}
-		sb.WriteString(fmt.Sprintf("## %s\n\n", t.Format("January 2006")))
+	}
+	return false
+}
+
+// translateCategory translates common categories to Russian
+func (f *MarkdownFormatter) translateCategory(category string) string {
+	if translated, ok := f.categoryTranslations[strings.ToLower(category)]; ok {
+		return translated
+	}
+	return category
+}
 
-		for _, a := range byMonth[month] {
-			title := a.TitleRU
-			if title == "" {
-				title = a.Title
+// translateTags translates tags to Russian, keeps (or drops) unknown ones,
+// de-duplicates the result and caps it at maxTags
+func (f *MarkdownFormatter) translateTags(tags []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		translated, ok := f.tagTranslations[strings.ToLower(tag)]
+		if !ok {
+			if f.dropUnknownTags && !f.autoTagNames[strings.ToLower(tag)] {
+				continue
 			}
-			link := fmt.Sprintf("%s/%s/%s.md", a.PublishedAt.Format("2006"), a.PublishedAt.Format("01"), a.Slug)
-			sb.WriteString(fmt.Sprintf("- [%s](%s)\n", title, link))
+			translated = tag
+		}
+		key := strings.ToLower(translated)
+		if translated == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, translated)
+		if len(result) == maxTags {
+			break
 		}
-		sb.WriteString("\n")
 	}
-
-	return sb.String()
+	return result
 }
 
-// yamlQuote wraps a string in double quotes if it contains YAML-special
-// characters (colon, hash, brackets, quotes, newlines, etc.). Always quotes
-// to be safe for frontmatter values.
-func yamlQuote(s string) string {
-	// Escape backslashes first, then double quotes
-	s = strings.ReplaceAll(s, `\`, `\\`)
-	s = strings.ReplaceAll(s, `"`, `\"`)
-	// Replace newlines with spaces to prevent YAML multiline issues
-	s = strings.ReplaceAll(s, "\n", " ")
-	s = strings.ReplaceAll(s, "\r", "")
-	return `"` + s + `"`
+// GenerateIndex generates an index page for a directory: articles grouped
+// by month, newest month and newest article first
+func (f *MarkdownFormatter) GenerateIndex(articles []*models.Article, title string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", title))
+	f.writeIndexMonths(&sb, sortForIndex(articles, &config.IndexConfig{}), "")
+	return f.normalizeOutput(sb.String())
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// shortenTitle cuts titles longer than maxLen characters to at most maxLen,
+// "…" included, ending on a whole word unless that would drop more than half
+// of the title; maxLen <= 0 keeps the title as is
+func shortenTitle(title string, maxLen int) string {
+	runes := []rune(title)
+	if maxLen <= 0 || len(runes) <= maxLen {
+		return title
+	}
+	keep := maxLen - 1 // room for "…"
+	cut := runes[:keep]
+	if !unicode.IsSpace(runes[keep]) {
+		for i := keep - 1; i > keep/2; i-- {
+			if unicode.IsSpace(cut[i]) {
+				cut = cut[:i]
+				break
+			}
+		}
 	}
-	return b
+	return strings.TrimRightFunc(string(cut), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
 }
@@ -0,0 +1,163 @@
+package formatter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+// testArticle is a translated article with the fields Format needs
+func testArticle() *models.Article {
+	return &models.Article{
+		ID:          1,
+		SourceURL:   "https://example.com/bike",
+		SourceSite:  "example.com",
+		Title:       "New bike",
+		TitleRU:     "Новый мотоцикл",
+		ContentRU:   "Текст статьи.",
+		Category:    "Reviews",
+		Slug:        "new-bike",
+		PublishedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestBaseCategories(t *testing.T) {
+	tests := []struct {
+		name string
+		base []string
+		want []string
+	}{
+		{"default", nil, []string{"Новости", "Обзоры"}},
+		{"empty", []string{}, []string{"Обзоры"}},
+		{"several", []string{"News", "Moto", "news"}, []string{"News", "Moto", "Обзоры"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f *MarkdownFormatter
+			if tt.base == nil {
+				f = NewMarkdownFormatter(nil)
+			} else {
+				f = NewMarkdownFormatter(&config.FormatterConfig{BaseCategories: tt.base})
+			}
+			if got := f.categories(testArticle()); !slices.Equal(got, tt.want) {
+				t.Errorf("categories = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateIndexPagesTitle(t *testing.T) {
+	f := NewMarkdownFormatter(nil)
+	for title, want := range map[string]string{"": "# Новости\n", "Moto News": "# Moto News\n"} {
+		pages := f.GenerateIndexPages([]*models.Article{testArticle()}, title, nil)
+		if len(pages) == 0 || !strings.HasPrefix(pages[0].Content, want) {
+			t.Errorf("title %q: index = %q, want it to start with %q", title, pages[0].Content, want)
+		}
+	}
+}
+
+func TestFormatDatesInConfiguredZone(t *testing.T) {
+	f := NewMarkdownFormatter(&config.FormatterConfig{Timezone: "Europe/Moscow"})
+	instant := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, zone := range []*time.Location{time.UTC, time.FixedZone("PDT", -7*3600), time.FixedZone("", 5*3600+30*60)} {
+		article := testArticle()
+		article.PublishedAt = instant.In(zone)
+		block, _ := splitFrontmatter(t, f.Format(article), FrontmatterYAML)
+		if !strings.Contains(block, "date: 2026-03-01T12:00:00+03:00\n") {
+			t.Errorf("feed date %s rendered as:\n%s", article.PublishedAt, block)
+		}
+	}
+
+	// No or an unknown zone renders UTC
+	for _, name := range []string{"", "Mars/Olympus"} {
+		article := testArticle()
+		article.PublishedAt = instant.In(time.FixedZone("", 3600))
+		block, _ := splitFrontmatter(t, NewMarkdownFormatter(&config.FormatterConfig{Timezone: name}).Format(article), FrontmatterYAML)
+		if !strings.Contains(block, "date: 2026-03-01T09:00:00Z\n") {
+			t.Errorf("timezone %q:\n%s", name, block)
+		}
+	}
+}
+
+func TestImageHostFilter(t *testing.T) {
+	const (
+		allowed     = "https://cdn.example.com/a.jpg"
+		sub         = "https://img.cdn.example.com/b.jpg"
+		disallowed  = "https://tracker.net/pixel.jpg"
+		placeholder = "/images/placeholder.jpg"
+	)
+	tests := []struct {
+		name       string
+		cfg        config.FormatterConfig
+		images     []string
+		wantCover  string
+		wantImages []string
+	}{
+		{"no lists", config.FormatterConfig{}, []string{disallowed, allowed}, disallowed, []string{allowed}},
+		{"allowed host and subdomain", config.FormatterConfig{ImageAllowlist: []string{"CDN.example.com"}, DefaultImage: placeholder},
+			[]string{allowed, sub, disallowed}, allowed, []string{sub}},
+		{"disallowed cover gets the placeholder", config.FormatterConfig{ImageAllowlist: []string{"cdn.example.com"}, DefaultImage: placeholder},
+			[]string{disallowed, allowed}, placeholder, []string{allowed}},
+		{"disallowed cover without placeholder", config.FormatterConfig{ImageBlocklist: []string{".tracker.net"}},
+			[]string{disallowed}, "", nil},
+		{"blocklist beats allowlist", config.FormatterConfig{ImageAllowlist: []string{"example.com"}, ImageBlocklist: []string{"img.cdn.example.com"}},
+			[]string{allowed, sub}, allowed, nil},
+		{"relative images are site-local", config.FormatterConfig{ImageAllowlist: []string{"cdn.example.com"}},
+			[]string{"/images/own.jpg"}, "/images/own.jpg", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := testArticle()
+			article.ImageURLs = tt.images
+			block, _ := splitFrontmatter(t, NewMarkdownFormatter(&tt.cfg).Format(article), FrontmatterYAML)
+			fm := decodeFrontmatter(t, block, FrontmatterYAML)
+			var cover string
+			if fm.Cover != nil {
+				cover = fm.Cover.Image
+			}
+			if cover != tt.wantCover || !slices.Equal(fm.Images, tt.wantImages) {
+				t.Errorf("cover=%q images=%q, want %q and %q", cover, fm.Images, tt.wantCover, tt.wantImages)
+			}
+		})
+	}
+}
+
+func TestDefaultCover(t *testing.T) {
+	const own = "https://cdn.example.com/own.jpg"
+	defaults := config.FormatterConfig{
+		DefaultImage:  "/images/placeholder.jpg",
+		DefaultImages: map[string]string{"REVIEWS": "/images/reviews.jpg", "Гонки": "/images/racing.jpg"},
+	}
+	tests := []struct {
+		name     string
+		cfg      config.FormatterConfig
+		image    string
+		category string
+		want     string
+	}{
+		{"own image kept", defaults, own, "Reviews", own},
+		{"category default", defaults, "", "reviews", "/images/reviews.jpg"},
+		{"translated category default", defaults, "", "Racing", "/images/racing.jpg"},
+		{"other category", defaults, "", "Touring", "/images/placeholder.jpg"},
+		{"no defaults", config.FormatterConfig{}, "", "Reviews", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := testArticle()
+			article.ImageURL, article.Category = tt.image, tt.category
+			block, _ := splitFrontmatter(t, NewMarkdownFormatter(&tt.cfg).Format(article), FrontmatterYAML)
+			fm := decodeFrontmatter(t, block, FrontmatterYAML)
+			var cover string
+			if fm.Cover != nil {
+				cover = fm.Cover.Image
+			}
+			if cover != tt.want {
+				t.Errorf("cover = %q, want %q", cover, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package formatter
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"moto-news/internal/models"
+)
+
+// Placements of the original English text (hugo.formatter.include_original)
+const (
+	OriginalNone    = "none"    // only the translation is published
+	OriginalDetails = "details" // collapsible <details> block after the translation
+	OriginalFile    = "file"    // companion <slug>.en.md next to the post (Hugo translation by file name)
+)
+
+// originalDetails renders the original title and content as a collapsed
+// <details> block; Hugo needs markup.goldmark.renderer.unsafe to show it
+func (f *MarkdownFormatter) originalDetails(article *models.Article) string {
+	if f.includeOriginal != OriginalDetails || !hasOriginal(article) {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("<details>\n")
+	sb.WriteString(fmt.Sprintf("<summary>Оригинал (English): %s</summary>\n\n", html.EscapeString(article.Title)))
+	sb.WriteString(f.formatContent(article.Content))
+	sb.WriteString("\n\n</details>")
+	return sb.String()
+}
+
+// FormatOriginal renders the companion English page written next to the
+// translated post with include_original: file. Hugo pairs slug.md and
+// slug.en.md as translations of one page when the site defines an "en"
+// language. Returns "" when no companion file is wanted.
+func (f *MarkdownFormatter) FormatOriginal(article *models.Article) string {
+	if f.includeOriginal != OriginalFile || !hasOriginal(article) {
+		return ""
+	}
+
+	fm := frontmatter{
+		Title:  article.Title,
+		Date:   article.PublishedAt.In(f.location),
+		Source: article.SourceURL,
+		Author: article.Author,
+	}
+	if coverURL := f.coverURL(article); coverURL != "" {
+		fm.Cover = &coverMeta{Image: coverURL, Alt: article.Title}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fm.render(f.frontmatterFormat))
+
+	sb.WriteString(f.formatContent(article.Content))
+	sb.WriteString("\n\n---\n\n")
+	sb.WriteString(fmt.Sprintf("*Source: [%s](%s)*\n", f.sourceDisplayName(article.SourceSite), article.SourceURL))
+	return f.normalizeOutput(sb.String())
+}
+
+// WritesOriginalFile reports whether posts get a companion English page
+func (f *MarkdownFormatter) WritesOriginalFile() bool {
+	return f.includeOriginal == OriginalFile
+}
+
+// OriginalFilePath returns the path of the companion English page: the
+// post's path with .en.md instead of .md
+func (f *MarkdownFormatter) OriginalFilePath(article *models.Article, baseDir string) string {
+	return strings.TrimSuffix(f.GetFilePath(article, baseDir), ".md") + ".en.md"
+}
+
+// hasOriginal reports whether the article is translated and still has the
+// English text to show next to it
+func hasOriginal(article *models.Article) bool {
+	return article.ContentRU != "" && strings.TrimSpace(article.Content) != ""
+}
@@ -0,0 +1,141 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"moto-news/internal/config"
+)
+
+// ErrBlockedURL is returned for URLs that must not be fetched on a user's
+// behalf: non-http(s) schemes and hosts resolving to internal addresses
+var ErrBlockedURL = errors.New("URL not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIP reports whether ip is routable on the public internet
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || sharedAddressSpace.Contains(ip))
+}
+
+// CheckPublicURL rejects URLs that are not http(s) or whose host resolves
+// to a loopback, private, link-local (cloud metadata) or otherwise internal
+// address. Errors wrap ErrBlockedURL unless the host could not be resolved.
+func CheckPublicURL(ctx context.Context, u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrBlockedURL)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrBlockedURL)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return fmt.Errorf("%w: %s is an internal address", ErrBlockedURL, host)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to internal address %s", ErrBlockedURL, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// dialGuard sends proxied requests through proxied and the others through
+// direct, whose dialer refuses internal addresses: a host cannot resolve to
+// a public IP for a URL check and an internal one for the dial
+type dialGuard struct {
+	direct  *http.Transport
+	proxied *http.Transport
+}
+
+var guarded = make(map[string]*dialGuard) // by transportKey, guarded by mu
+
+// newDialGuard pairs proxied with a copy of it that connects directly and
+// checks the dialed address
+func newDialGuard(proxied *http.Transport) *dialGuard {
+	direct := proxied.Clone()
+	direct.Proxy = nil
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: dialing internal address %s", ErrBlockedURL, host)
+			}
+			return nil
+		},
+	}
+	direct.DialContext = dialer.DialContext
+	return &dialGuard{direct: direct, proxied: proxied}
+}
+
+func (t *dialGuard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.proxied.Proxy != nil {
+		if proxyURL, err := t.proxied.Proxy(req); err == nil && proxyURL != nil {
+			return t.proxied.RoundTrip(req)
+		}
+	}
+	return t.direct.RoundTrip(req)
+}
+
+// GuardedTransport returns the shared transport for the destination with
+// the dial-time address check of PublicTransport, for clients that check
+// URLs themselves (the scraper's url_policy). It is cached like Transport,
+// so connections are pooled and kept alive.
+func GuardedTransport(cfg *config.NetworkConfig, dest string) http.RoundTripper {
+	proxy := ProxyFor(cfg, dest)
+	proxied := sharedTransport(cfg, proxy)
+	key := transportKey(cfg, proxy)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if t, ok := guarded[key]; ok {
+		return t
+	}
+	t := newDialGuard(proxied)
+	guarded[key] = t
+	return t
+}
+
+// publicOnly checks every request, redirects included, with CheckPublicURL
+// before sending it through the dial-checked transports
+type publicOnly struct {
+	*dialGuard
+}
+
+// PublicTransport returns a transport for fetching user-supplied URLs (feed
+// previews): like Transport for the destination, but refusing internal
+// addresses. Keep-alives are off; it is meant for one-off requests.
+func PublicTransport(cfg *config.NetworkConfig, dest string) http.RoundTripper {
+	proxied := newTransport(cfg, ProxyFor(cfg, dest))
+	proxied.DisableKeepAlives = true
+	return &publicOnly{newDialGuard(proxied)}
+}
+
+func (t *publicOnly) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := CheckPublicURL(req.Context(), req.URL); err != nil {
+		return nil, err
+	}
+	return t.dialGuard.RoundTrip(req)
+}
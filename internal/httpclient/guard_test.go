@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"moto-news/internal/config"
+)
+
+func TestCheckPublicURLRejectsInternalAddresses(t *testing.T) {
+	for _, raw := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/",
+		"http://192.168.1.10:8080/admin",
+		"http://127.0.0.1/",
+		"http://[::1]/",
+		"http://100.64.0.1/",
+		"file:///etc/passwd",
+	} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := CheckPublicURL(context.Background(), u); !errors.Is(err, ErrBlockedURL) {
+			t.Errorf("CheckPublicURL(%s) = %v, want ErrBlockedURL", raw, err)
+		}
+	}
+
+	u, _ := url.Parse("http://93.184.216.34/")
+	if err := CheckPublicURL(context.Background(), u); err != nil {
+		t.Errorf("CheckPublicURL(public IP) = %v, want nil", err)
+	}
+}
+
+// The dial-time check is what stops DNS rebinding: the address actually
+// connected to must be public too, whatever the URL check saw
+func TestPublicTransportRefusesInternalDial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the internal server")
+	}))
+	defer srv.Close()
+
+	rt := PublicTransport(nil, DestScraper).(*publicOnly)
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rt.direct.RoundTrip(req); !errors.Is(err, ErrBlockedURL) {
+		t.Errorf("dialing %s: got %v, want ErrBlockedURL", srv.URL, err)
+	}
+	if _, err := rt.RoundTrip(req); !errors.Is(err, ErrBlockedURL) {
+		t.Errorf("RoundTrip(%s): got %v, want ErrBlockedURL", srv.URL, err)
+	}
+}
+
+func TestGuardedTransportIsPooled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the internal server")
+	}))
+	defer srv.Close()
+
+	cfg := &config.NetworkConfig{Proxy: "direct", MaxIdleConnsPerHost: 12}
+	rt := GuardedTransport(cfg, DestScraper).(*dialGuard)
+	if GuardedTransport(cfg, DestScraper) != rt {
+		t.Error("same settings built a second guarded transport")
+	}
+	if rt.proxied != Transport(cfg, DestScraper) {
+		t.Error("proxied requests do not share the pooled transport")
+	}
+	if rt.direct.DisableKeepAlives || rt.direct.MaxIdleConnsPerHost != 12 {
+		t.Errorf("direct transport: keep-alives off = %v, max idle per host %d, want on and 12", rt.direct.DisableKeepAlives, rt.direct.MaxIdleConnsPerHost)
+	}
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rt.RoundTrip(req); !errors.Is(err, ErrBlockedURL) {
+		t.Errorf("RoundTrip(%s): got %v, want ErrBlockedURL", srv.URL, err)
+	}
+}
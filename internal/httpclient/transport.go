@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"moto-news/internal/config"
+)
+
+// Destinations that can have their own proxy override
+const (
+	DestScraper    = "scraper"
+	DestFeeds      = "feeds"
+	DestTranslator = "translator"
+	DestGitHub     = "github"
+)
+
+var (
+	mu         sync.Mutex
+	transports = make(map[string]*http.Transport)
+)
+
+// ProxyFor returns the proxy setting for a destination: the per-destination
+// override if set, otherwise network.proxy.
+func ProxyFor(cfg *config.NetworkConfig, dest string) string {
+	if cfg == nil {
+		return ""
+	}
+	override := ""
+	switch dest {
+	case DestScraper:
+		override = cfg.ScraperProxy
+	case DestFeeds:
+		override = cfg.FeedsProxy
+	case DestTranslator:
+		override = cfg.TranslatorProxy
+	case DestGitHub:
+		override = cfg.GitHubProxy
+	}
+	if override != "" {
+		return override
+	}
+	return cfg.Proxy
+}
+
+// Transport returns a shared transport for the destination. Transports are
+// cached per proxy setting so connections are pooled across clients.
+//
+// Proxy values: "" honors HTTP_PROXY/HTTPS_PROXY/NO_PROXY, "direct" disables
+// proxying, anything else is a proxy URL (http://, https://, socks5://).
+func Transport(cfg *config.NetworkConfig, dest string) http.RoundTripper {
+	return sharedTransport(cfg, ProxyFor(cfg, dest))
+}
+
+// transportKey identifies the settings a transport was built with
+func transportKey(cfg *config.NetworkConfig, proxy string) string {
+	if cfg == nil {
+		return proxy
+	}
+	return fmt.Sprintf("%s|%d|%d|%d|%d|%d|%s|%s", proxy, cfg.MaxIdleConns, cfg.MaxIdleConnsPerHost, cfg.MaxConnsPerHost,
+		cfg.IdleConnTimeoutSec, cfg.TLSHandshakeTimeoutSec, cfg.TLSMinVersion, cfg.CAFile)
+}
+
+// sharedTransport returns the cached transport for a proxy setting and the
+// pooling/TLS settings of cfg, building it on first use
+func sharedTransport(cfg *config.NetworkConfig, proxy string) *http.Transport {
+	key := transportKey(cfg, proxy)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if t, ok := transports[key]; ok {
+		return t
+	}
+	t := newTransport(cfg, proxy)
+	transports[key] = t
+	return t
+}
+
+// newTransport builds a transport with the network pooling and TLS settings
+// applied over Go's defaults (cfg may be nil)
+func newTransport(cfg *config.NetworkConfig, proxy string) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = proxyFunc(proxy)
+	if cfg == nil {
+		return t
+	}
+
+	if cfg.MaxIdleConns > 0 {
+		t.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	t.MaxConnsPerHost = cfg.MaxConnsPerHost
+	if cfg.IdleConnTimeoutSec > 0 {
+		t.IdleConnTimeout = time.Duration(cfg.IdleConnTimeoutSec) * time.Second
+	}
+	if cfg.TLSHandshakeTimeoutSec > 0 {
+		t.TLSHandshakeTimeout = time.Duration(cfg.TLSHandshakeTimeoutSec) * time.Second
+	}
+
+	if cfg.TLSMinVersion != "" || cfg.CAFile != "" {
+		tlsCfg := &tls.Config{}
+		switch cfg.TLSMinVersion {
+		case "1.2":
+			tlsCfg.MinVersion = tls.VersionTLS12
+		case "1.3":
+			tlsCfg.MinVersion = tls.VersionTLS13
+		}
+		if roots := caPool(cfg.CAFile); roots != nil {
+			tlsCfg.RootCAs = roots
+		}
+		t.TLSClientConfig = tlsCfg
+	}
+	return t
+}
+
+// caPool returns the system roots plus the certificates in caFile. The file
+// is validated by config.Load, so read errors fall back to the system roots.
+func caPool(caFile string) *x509.CertPool {
+	if caFile == "" {
+		return nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil
+	}
+	return pool
+}
+
+// proxyFunc converts a proxy setting into an http.Transport Proxy function.
+// Invalid URLs are rejected by config.Load, so parse errors fall back to env.
+func proxyFunc(proxy string) func(*http.Request) (*url.URL, error) {
+	switch strings.ToLower(strings.TrimSpace(proxy)) {
+	case "":
+		return http.ProxyFromEnvironment
+	case "direct", "none":
+		return nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return http.ProxyFromEnvironment
+	}
+	return http.ProxyURL(u)
+}
@@ -0,0 +1,114 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"moto-news/internal/config"
+)
+
+func TestTransportAppliesNetworkConfig(t *testing.T) {
+	cfg := &config.NetworkConfig{
+		MaxIdleConns:           50,
+		MaxIdleConnsPerHost:    8,
+		MaxConnsPerHost:        4,
+		IdleConnTimeoutSec:     30,
+		TLSHandshakeTimeoutSec: 5,
+		TLSMinVersion:          "1.3",
+	}
+	tr, ok := Transport(cfg, DestScraper).(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport returned %T, want *http.Transport", Transport(cfg, DestScraper))
+	}
+	if tr.MaxIdleConns != 50 || tr.MaxIdleConnsPerHost != 8 || tr.MaxConnsPerHost != 4 {
+		t.Errorf("pool = %d/%d/%d, want 50/8/4", tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost)
+	}
+	if tr.IdleConnTimeout != 30*time.Second || tr.TLSHandshakeTimeout != 5*time.Second {
+		t.Errorf("timeouts = %s/%s, want 30s/5s", tr.IdleConnTimeout, tr.TLSHandshakeTimeout)
+	}
+	if tr.TLSClientConfig == nil || tr.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("TLS config = %+v, want TLS 1.3 minimum", tr.TLSClientConfig)
+	}
+
+	// destinations with the same settings share one pool
+	if Transport(cfg, DestFeeds) != tr {
+		t.Error("feeds got a different transport for the same settings")
+	}
+	other := *cfg
+	other.MaxIdleConnsPerHost = 16
+	if Transport(&other, DestScraper) == tr {
+		t.Error("changed settings reused the cached transport")
+	}
+
+	// unset values keep Go's defaults
+	def := http.DefaultTransport.(*http.Transport)
+	if tr := Transport(&config.NetworkConfig{}, DestScraper).(*http.Transport); tr.IdleConnTimeout != def.IdleConnTimeout || tr.MaxIdleConns != def.MaxIdleConns {
+		t.Errorf("defaults: idle timeout %s, max idle %d, want %s and %d", tr.IdleConnTimeout, tr.MaxIdleConns, def.IdleConnTimeout, def.MaxIdleConns)
+	}
+}
+
+func TestTransportTrustsCAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(cfg *config.NetworkConfig) error {
+		client := &http.Client{Transport: Transport(cfg, DestGitHub), Timeout: 5 * time.Second}
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	if err := get(&config.NetworkConfig{Proxy: "direct"}); err == nil {
+		t.Error("system roots trusted the test certificate")
+	}
+	if err := get(&config.NetworkConfig{Proxy: "direct", CAFile: caFile}); err != nil {
+		t.Errorf("with ca_file: %v", err)
+	}
+}
+
+func TestTransportProxyFromConfig(t *testing.T) {
+	proxyOf := func(cfg *config.NetworkConfig, dest string) string {
+		tr := Transport(cfg, dest).(*http.Transport)
+		if tr.Proxy == nil {
+			return "direct"
+		}
+		req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+		u, err := tr.Proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy(%s): %v", dest, err)
+		}
+		if u == nil {
+			return ""
+		}
+		return u.String()
+	}
+
+	tests := []struct {
+		cfg  config.NetworkConfig
+		dest string
+		want string
+	}{
+		{config.NetworkConfig{Proxy: "http://proxy.local:3128"}, DestFeeds, "http://proxy.local:3128"},
+		{config.NetworkConfig{Proxy: "socks5://proxy.local:1080"}, DestTranslator, "socks5://proxy.local:1080"},
+		{config.NetworkConfig{Proxy: "http://proxy.local:3128", ScraperProxy: "socks5://scraper.local:1080"}, DestScraper, "socks5://scraper.local:1080"},
+		{config.NetworkConfig{Proxy: "http://proxy.local:3128", ScraperProxy: "socks5://scraper.local:1080"}, DestFeeds, "http://proxy.local:3128"},
+		{config.NetworkConfig{Proxy: "http://proxy.local:3128", GitHubProxy: "direct"}, DestGitHub, "direct"},
+	}
+	for _, tt := range tests {
+		if got := proxyOf(&tt.cfg, tt.dest); got != tt.want {
+			t.Errorf("%s with %+v: proxy = %q, want %q", tt.dest, tt.cfg, got, tt.want)
+		}
+	}
+}
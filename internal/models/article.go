@@ -1,30 +1,80 @@
 package models
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"strings"
 	"time"
 )
 
+// ArticleStatus is the pipeline state of an article and the single source of
+// truth for the translated/published helpers below
+type ArticleStatus string
+
+const (
+	StatusNew        ArticleStatus = "new"        // saved from the feed, not scraped yet
+	StatusScraped    ArticleStatus = "scraped"    // has content, waiting for translation
+	StatusTranslated ArticleStatus = "translated" // translated, waiting for publishing
+	StatusPublished  ArticleStatus = "published"  // published to the blog
+	StatusErrored    ArticleStatus = "errored"    // last translation attempt failed, retried on the next run
+	StatusStub       ArticleStatus = "stub"       // scraping produced no content (see rescrape)
+)
+
+// Valid reports whether s is one of the known statuses
+func (s ArticleStatus) Valid() bool {
+	switch s {
+	case StatusNew, StatusScraped, StatusTranslated, StatusPublished, StatusErrored, StatusStub:
+		return true
+	}
+	return false
+}
+
 type Article struct {
-	ID                int64      `json:"id"`
-	SourceURL         string     `json:"source_url"`
-	SourceSite        string     `json:"source_site"`
-	Title             string     `json:"title"`
-	TitleRU           string     `json:"title_ru"`
-	Description       string     `json:"description"`
-	Content           string     `json:"content"`
-	ContentRU         string     `json:"content_ru"`
-	Author            string     `json:"author"`
-	Category          string     `json:"category"`
-	Tags              []string   `json:"tags"`
-	ImageURL          string     `json:"image_url"`   // featured (first) image
-	ImageURLs         []string   `json:"image_urls"` // all images from article (first = featured)
-	PublishedAt       time.Time  `json:"published_at"`
-	FetchedAt         time.Time  `json:"fetched_at"`
-	TranslatedAt      *time.Time `json:"translated_at"`
-	PublishedToHugo bool       `json:"published_to_hugo"`
-	Slug              string     `json:"slug"`
+	ID                 int64         `json:"id"`
+	SourceURL          string        `json:"source_url"`
+	FeedURL            string        `json:"feed_url,omitempty"` // link from the feed when it permanently redirected to SourceURL
+	SourceSite         string        `json:"source_site"`
+	Title              string        `json:"title"`
+	TitleRU            string        `json:"title_ru"`
+	Description        string        `json:"description"`
+	Content            string        `json:"content"`
+	RawContent         string        `json:"-"` // extracted text before cleanup (CleanContent), for offline re-cleaning
+	ContentRU          string        `json:"content_ru"`
+	Author             string        `json:"author"`
+	Category           string        `json:"category"`
+	Tags               []string      `json:"tags"`
+	ImageURL           string        `json:"image_url"`  // featured (first) image
+	ImageURLs          []string      `json:"image_urls"` // all images from article (first = featured)
+	PublishedAt        time.Time     `json:"published_at"`
+	FetchedAt          time.Time     `json:"fetched_at"`
+	TranslatedAt       *time.Time    `json:"translated_at"`
+	PublishedToHugo    bool          `json:"published_to_hugo"`
+	Slug               string        `json:"slug"`
+	Translator         string        `json:"translator"`         // provider that produced the translation (e.g. "deepl")
+	TranslatorModel    string        `json:"translator_model"`   // model used by the provider, empty for non-LLM providers
+	ContentTruncated   bool          `json:"content_truncated"`  // content was cut to translator.max_content_chars before translation
+	RescrapeAttempts   int           `json:"rescrape_attempts"`  // rescrapes that did not improve the content
+	RenderFingerprint  string        `json:"render_fingerprint"` // formatter fingerprint of the published file (empty = unknown)
+	Status             ArticleStatus `json:"status"`
+	Featured           bool          `json:"featured"`                     // translated and published ahead of the backlog (set via the feature command)
+	SourceUpdatedAt    *time.Time    `json:"source_updated_at,omitempty"`  // feed <updated> of the version we hold (sources[].update_existing)
+	TaxonomyOverridden bool          `json:"taxonomy_overridden"`          // Category/Tags were set by hand and are used as-is
+	Reviewed           bool          `json:"reviewed"`                     // an editor checked it; hugo.require_review publishes only these
+	PublishedPath      string        `json:"published_path,omitempty"`     // file path frozen at first publish (hugo.stable_permalinks), relative to the content directory
+	ContentHash        string        `json:"content_hash,omitempty"`       // SourceContentHash of the stored version, set on save
+	ContentCheckedAt   *time.Time    `json:"content_checked_at,omitempty"` // last re-scrape by RecheckContent
+	FeedContent        string        `json:"-"`                            // full HTML body from the feed item (content:encoded), not stored
+}
+
+// ArticleLink is a lightweight reference to an article (prev/next navigation)
+type ArticleLink struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	TitleRU     string    `json:"title_ru"`
+	Slug        string    `json:"slug"`
+	PublishedAt time.Time `json:"published_at"`
 }
 
 // TagsJSON returns tags as JSON string for database storage
@@ -74,6 +124,27 @@ func (a *Article) ParseImageURLs(jsonStr string) {
 	}
 }
 
+// SourceContentHash fingerprints the page text we scraped: the raw extracted
+// text when it was kept, so changed cleanup rules do not look like source
+// edits, else the content
+func (a *Article) SourceContentHash() string {
+	if a.RawContent != "" {
+		return HashText(a.RawContent)
+	}
+	return HashText(a.Content)
+}
+
+// HashText is the hex SHA-256 of s without surrounding whitespace ("" for
+// empty text)
+func HashText(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
 // NullTimeToPtr converts sql.NullTime to *time.Time
 func NullTimeToPtr(nt sql.NullTime) *time.Time {
 	if nt.Valid {
@@ -90,22 +161,37 @@ func PtrToNullTime(t *time.Time) sql.NullTime {
 	return sql.NullTime{Valid: false}
 }
 
+// InferStatus derives the status from the article fields; used for new
+// articles and to backfill rows saved before the status column existed
+func (a *Article) InferStatus() ArticleStatus {
+	switch {
+	case a.PublishedToHugo:
+		return StatusPublished
+	case a.ContentRU != "":
+		return StatusTranslated
+	case a.Content != "":
+		return StatusScraped
+	default:
+		return StatusStub
+	}
+}
+
 // IsTranslated returns true if article has been translated
 func (a *Article) IsTranslated() bool {
-	return a.TranslatedAt != nil && a.ContentRU != ""
+	return a.Status == StatusTranslated || a.Status == StatusPublished
 }
 
 // IsPublished returns true if article has been published to Hugo blog
 func (a *Article) IsPublished() bool {
-	return a.PublishedToHugo
+	return a.Status == StatusPublished
 }
 
 // NeedsTranslation returns true if article needs translation
 func (a *Article) NeedsTranslation() bool {
-	return a.Content != "" && a.ContentRU == ""
+	return a.Status == StatusScraped || a.Status == StatusErrored
 }
 
 // NeedsPublishing returns true if article needs to be published
 func (a *Article) NeedsPublishing() bool {
-	return a.IsTranslated() && !a.IsPublished()
+	return a.Status == StatusTranslated
 }
@@ -27,13 +27,19 @@ type GitHubPublisher struct {
 	owner     string
 	repo      string
 	branch    string
+	apiBase   string // e.g. https://api.github.com or https://ghe.example.com/api/v3
 	client    *http.Client
 }
 
+// defaultAPIBase is the public GitHub REST API
+const defaultAPIBase = "https://api.github.com"
+
 // NewGitHubPublisher creates a publisher that uses GitHub API.
 // Token is read from GITHUB_TOKEN env var.
-// Repo is parsed from git_repo config (https://github.com/owner/repo.git).
-func NewGitHubPublisher(cfg *config.HugoConfig) *GitHubPublisher {
+// Repo is parsed from git_repo config (https://github.com/owner/repo.git,
+// or the same on a GitHub Enterprise host set in api_base_url).
+// transport may be nil (default transport).
+func NewGitHubPublisher(cfg *config.HugoConfig, transport http.RoundTripper) *GitHubPublisher {
 	token := os.Getenv("GITHUB_TOKEN")
 	owner, repo := parseGitHubRepo(cfg.GitRepo)
 
@@ -44,12 +50,13 @@ func NewGitHubPublisher(cfg *config.HugoConfig) *GitHubPublisher {
 
 	return &GitHubPublisher{
 		config:    cfg,
-		formatter: formatter.NewMarkdownFormatter(),
+		formatter: formatter.NewMarkdownFormatter(&cfg.Formatter),
 		token:     token,
 		owner:     owner,
 		repo:      repo,
 		branch:    branch,
-		client:    &http.Client{Timeout: 30 * time.Second},
+		apiBase:   githubAPIBase(cfg.APIBaseURL),
+		client:    &http.Client{Transport: transport, Timeout: 30 * time.Second},
 	}
 }
 
@@ -81,44 +88,151 @@ func (p *GitHubPublisher) Publish(article *models.Article) error {
 		message = fmt.Sprintf("Add article: %s", article.Title)
 	}
 
+	if p.config.SkipExisting && p.remoteMatches(filePath, content) {
+		fmt.Printf("Unchanged in repo, skipped: %s\n", filePath)
+		return nil
+	}
+
 	if err := p.putFile(filePath, content, message); err != nil {
 		return fmt.Errorf("failed to push %s: %w", filePath, err)
 	}
+	if original := p.formatter.FormatOriginal(article); original != "" {
+		originalPath := toForwardSlash(p.formatter.OriginalFilePath(article, p.config.ContentDir))
+		if !p.config.SkipExisting || !p.remoteMatches(originalPath, original) {
+			if err := p.putFile(originalPath, original, message); err != nil {
+				return fmt.Errorf("failed to push %s: %w", originalPath, err)
+			}
+		}
+	}
 
 	fmt.Printf("Published to GitHub: %s\n", filePath)
 	return nil
 }
 
-// PublishMultiple publishes multiple articles in a single commit using Git Trees API
-func (p *GitHubPublisher) PublishMultiple(articles []*models.Article) error {
+// PublishMultiple publishes multiple articles in a single commit using Git
+// Trees API, or in several chained commits when there are more files than
+// hugo.max_files_per_commit. message "" means "Add N new articles". Returns
+// the number of commits created and the articles whose files are all in the
+// branch; after a failure of a later chained commit these are the ones the
+// earlier commits carried.
+func (p *GitHubPublisher) PublishMultiple(articles []*models.Article, message string) (int, []*models.Article, error) {
 	if !p.IsAvailable() {
-		return fmt.Errorf("GitHub publisher not configured (GITHUB_TOKEN not set)")
+		return 0, nil, fmt.Errorf("GitHub publisher not configured (GITHUB_TOKEN not set)")
 	}
 
 	if len(articles) == 0 {
-		return nil
+		return 0, nil, nil
 	}
 
 	// Collect files
 	var files []treeFile
+	var listed []*models.Article
+	pending := make(map[*models.Article][]string)
 	fmt.Println("\nArticles to upload:")
 	for i, article := range articles {
 		if article == nil {
 			continue
 		}
+		listed = append(listed, article)
 		content := p.formatter.Format(article)
 		filePath := toForwardSlash(p.formatter.GetFilePath(article, p.config.ContentDir))
-		files = append(files, treeFile{path: filePath, content: content})
 		title := article.TitleRU
 		if title == "" {
 			title = article.Title
 		}
 		fmt.Printf("  [%d/%d] %s\n", i+1, len(articles), title)
+		if original := p.formatter.FormatOriginal(article); original != "" {
+			originalPath := toForwardSlash(p.formatter.OriginalFilePath(article, p.config.ContentDir))
+			if !p.config.SkipExisting || !p.remoteMatches(originalPath, original) {
+				files = append(files, treeFile{path: originalPath, content: original})
+				pending[article] = append(pending[article], originalPath)
+				fmt.Printf("        → %s\n", originalPath)
+			}
+		}
+		if p.config.SkipExisting && p.remoteMatches(filePath, content) {
+			fmt.Printf("        = %s (unchanged in repo, skipped)\n", filePath)
+			continue
+		}
+		files = append(files, treeFile{path: filePath, content: content})
+		pending[article] = append(pending[article], filePath)
 		fmt.Printf("        → %s\n", filePath)
 	}
 
-	message := fmt.Sprintf("Add %d new articles", len(articles))
-	return p.commitMultipleFiles(files, message)
+	if len(files) == 0 {
+		fmt.Println("All files already up to date in the repo, nothing to commit")
+		return 0, listed, nil
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Add %d new articles", len(pending))
+	}
+	commits, committed, err := p.commitMultipleFiles(files, message)
+	if err == nil {
+		return commits, listed, nil
+	}
+	inBranch := make(map[string]bool, len(committed))
+	for _, path := range committed {
+		inBranch[path] = true
+	}
+	var published []*models.Article
+	for _, article := range listed {
+		done := true
+		for _, path := range pending[article] {
+			done = done && inBranch[path]
+		}
+		if done {
+			published = append(published, article)
+		}
+	}
+	return commits, published, err
+}
+
+// DeleteMultiple removes the article files in a single commit (or chained
+// ones, see hugo.max_files_per_commit). Paths missing
+// from the branch are skipped (the Trees API rejects deleting them).
+// Returns the number of articles whose file was deleted, also when a later
+// chained commit fails.
+func (p *GitHubPublisher) DeleteMultiple(articles []*models.Article) (int, error) {
+	if !p.IsAvailable() {
+		return 0, fmt.Errorf("GitHub publisher not configured (GITHUB_TOKEN not set)")
+	}
+
+	var files []treeFile
+	removed := 0
+	mainFiles := make(map[string]bool)
+	for _, article := range articles {
+		if article == nil {
+			continue
+		}
+		filePath := toForwardSlash(p.formatter.GetFilePath(article, p.config.ContentDir))
+		if _, err := p.doRequest("GET", p.apiURL("/contents/"+encodePathSegments(filePath))+"?ref="+url.QueryEscape(p.branch), nil); err != nil {
+			continue
+		}
+		files = append(files, treeFile{path: filePath, delete: true})
+		mainFiles[filePath] = true
+		removed++
+		if p.formatter.WritesOriginalFile() {
+			originalPath := toForwardSlash(p.formatter.OriginalFilePath(article, p.config.ContentDir))
+			if _, err := p.doRequest("GET", p.apiURL("/contents/"+encodePathSegments(originalPath))+"?ref="+url.QueryEscape(p.branch), nil); err == nil {
+				files = append(files, treeFile{path: originalPath, delete: true})
+			}
+		}
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+	_, committed, err := p.commitMultipleFiles(files, fmt.Sprintf("Remove %d old articles", removed))
+	if err != nil {
+		deleted := 0
+		for _, path := range committed {
+			if mainFiles[path] {
+				deleted++
+			}
+		}
+		return deleted, err
+	}
+	return removed, nil
 }
 
 // --- GitHub API types ---
@@ -131,12 +245,15 @@ type contentsRequest struct {
 }
 
 type contentsResponse struct {
-	SHA string `json:"sha"`
+	SHA      string `json:"sha"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
 }
 
 type treeFile struct {
 	path    string
 	content string
+	delete  bool
 }
 
 type refResponse struct {
@@ -153,10 +270,11 @@ type commitResponse struct {
 }
 
 type treeEntry struct {
-	Path    string `json:"path"`
-	Mode    string `json:"mode"`
-	Type    string `json:"type"`
-	Content string `json:"content"`
+	Path    string          `json:"path"`
+	Mode    string          `json:"mode"`
+	Type    string          `json:"type"`
+	Content string          `json:"content,omitempty"`
+	SHA     json.RawMessage `json:"sha,omitempty"` // "null" deletes the path
 }
 
 type createTreeRequest struct {
@@ -184,8 +302,37 @@ type updateRefRequest struct {
 
 // --- GitHub API methods ---
 
+// apiError is an HTTP error status returned by the GitHub API
+type apiError struct {
+	StatusCode int
+	Body       string
+	RateLimit  bool // the primary rate limit is exhausted
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("GitHub API error %d: %s", e.StatusCode, e.Body)
+}
+
 func (p *GitHubPublisher) apiURL(path string) string {
-	return fmt.Sprintf("https://api.github.com/repos/%s/%s%s", p.owner, p.repo, path)
+	return fmt.Sprintf("%s/repos/%s/%s%s", p.apiBase, p.owner, p.repo, path)
+}
+
+// githubAPIBase normalizes hugo.api_base_url: empty means the public API,
+// and a GitHub Enterprise Server host given without a path gets the REST
+// prefix /api/v3
+func githubAPIBase(raw string) string {
+	raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+	if raw == "" {
+		return defaultAPIBase
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	if u.Path == "" && !strings.EqualFold(u.Hostname(), "api.github.com") {
+		u.Path = "/api/v3"
+	}
+	return u.String()
 }
 
 func (p *GitHubPublisher) doRequest(method, url string, body interface{}) ([]byte, error) {
@@ -222,12 +369,35 @@ func (p *GitHubPublisher) doRequest(method, url string, body interface{}) ([]byt
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("GitHub API error %d: %s", resp.StatusCode, string(respBody[:min(500, len(respBody))]))
+		return nil, &apiError{
+			StatusCode: resp.StatusCode,
+			Body:       string(respBody[:min(500, len(respBody))]),
+			RateLimit:  resp.Header.Get("X-RateLimit-Remaining") == "0",
+		}
 	}
 
 	return respBody, nil
 }
 
+// remoteMatches reports whether filePath exists on the branch with exactly
+// content. Any lookup error counts as "no match" so the file gets written.
+func (p *GitHubPublisher) remoteMatches(filePath, content string) bool {
+	data, err := p.doRequest("GET", p.apiURL("/contents/"+encodePathSegments(filePath))+"?ref="+url.QueryEscape(p.branch), nil)
+	if err != nil {
+		return false
+	}
+	var existing contentsResponse
+	if err := json.Unmarshal(data, &existing); err != nil || existing.Encoding != "base64" {
+		return false
+	}
+	// GitHub wraps the base64 payload at 60 columns
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(existing.Content, "\n", ""))
+	if err != nil {
+		return false
+	}
+	return string(decoded) == content
+}
+
 // putFile creates or updates a single file via Contents API
 func (p *GitHubPublisher) putFile(filePath, content, message string) error {
 	encodedPath := encodePathSegments(filePath)
@@ -256,78 +426,137 @@ func (p *GitHubPublisher) putFile(filePath, content, message string) error {
 	return err
 }
 
-// commitMultipleFiles creates a single commit with multiple files using Git Trees API
-func (p *GitHubPublisher) commitMultipleFiles(files []treeFile, message string) error {
+// commitMultipleFiles commits files using the Git Trees API: one commit, or
+// with hugo.max_files_per_commit set, one commit per that many files, each
+// on top of the previous one. The branch ref moves after every commit, so a
+// failure keeps the commits already made; a chunk that changes nothing (as
+// on a retry after such a failure) is skipped. Returns the number of
+// commits created and the paths now in the branch: those of the chunks
+// committed or found unchanged before any failure.
+func (p *GitHubPublisher) commitMultipleFiles(files []treeFile, message string) (int, []string, error) {
 	// 1. Get latest commit SHA on branch
 	refData, err := p.doRequest("GET", p.apiURL("/git/ref/heads/"+p.branch), nil)
 	if err != nil {
-		return fmt.Errorf("get ref: %w", err)
+		return 0, nil, fmt.Errorf("get ref: %w", err)
 	}
 	var ref refResponse
 	if err := json.Unmarshal(refData, &ref); err != nil {
-		return fmt.Errorf("parse ref: %w", err)
+		return 0, nil, fmt.Errorf("parse ref: %w", err)
 	}
-	latestCommitSHA := ref.Object.SHA
+	parentSHA := ref.Object.SHA
 
 	// 2. Get the tree SHA of that commit
-	commitData, err := p.doRequest("GET", p.apiURL("/git/commits/"+latestCommitSHA), nil)
+	commitData, err := p.doRequest("GET", p.apiURL("/git/commits/"+parentSHA), nil)
 	if err != nil {
-		return fmt.Errorf("get commit: %w", err)
+		return 0, nil, fmt.Errorf("get commit: %w", err)
 	}
 	var commit commitResponse
 	if err := json.Unmarshal(commitData, &commit); err != nil {
-		return fmt.Errorf("parse commit: %w", err)
+		return 0, nil, fmt.Errorf("parse commit: %w", err)
 	}
 	baseTreeSHA := commit.Tree.SHA
 
-	// 3. Create new tree with all files
-	var entries []treeEntry
-	for _, f := range files {
-		entries = append(entries, treeEntry{
-			Path:    f.path,
-			Mode:    "100644",
-			Type:    "blob",
-			Content: f.content,
-		})
-	}
+	chunks := chunkFiles(files, p.config.MaxFilesPerCommit)
+	commits := 0
+	var committed []string
+	for i, chunk := range chunks {
+		chunkMessage := message
+		if len(chunks) > 1 {
+			chunkMessage = fmt.Sprintf("%s (%d/%d)", message, i+1, len(chunks))
+		}
 
-	treeReq := createTreeRequest{
-		BaseTree: baseTreeSHA,
-		Tree:     entries,
-	}
-	treeData, err := p.doRequest("POST", p.apiURL("/git/trees"), treeReq)
-	if err != nil {
-		return fmt.Errorf("create tree: %w", err)
-	}
-	var newTree createTreeResponse
-	if err := json.Unmarshal(treeData, &newTree); err != nil {
-		return fmt.Errorf("parse tree: %w", err)
+		// 3. Create new tree with the chunk's files
+		var entries []treeEntry
+		for _, f := range chunk {
+			entry := treeEntry{
+				Path:    f.path,
+				Mode:    "100644",
+				Type:    "blob",
+				Content: f.content,
+			}
+			if f.delete {
+				entry.Content = ""
+				entry.SHA = json.RawMessage("null")
+			}
+			entries = append(entries, entry)
+		}
+
+		treeReq := createTreeRequest{
+			BaseTree: baseTreeSHA,
+			Tree:     entries,
+		}
+		treeData, err := p.doRequest("POST", p.apiURL("/git/trees"), treeReq)
+		if err != nil {
+			return commits, committed, fmt.Errorf("create tree%s: %w", chunkLabel(i, len(chunks)), err)
+		}
+		var newTree createTreeResponse
+		if err := json.Unmarshal(treeData, &newTree); err != nil {
+			return commits, committed, fmt.Errorf("parse tree: %w", err)
+		}
+		if newTree.SHA == baseTreeSHA {
+			fmt.Printf("Files %s already in the branch, no commit\n", strings.TrimSpace(chunkLabel(i, len(chunks))))
+			committed = appendPaths(committed, chunk)
+			continue
+		}
+
+		// 4. Create commit
+		commitReq := createCommitRequest{
+			Message: chunkMessage,
+			Tree:    newTree.SHA,
+			Parents: []string{parentSHA},
+		}
+		newCommitData, err := p.doRequest("POST", p.apiURL("/git/commits"), commitReq)
+		if err != nil {
+			return commits, committed, fmt.Errorf("create commit%s: %w", chunkLabel(i, len(chunks)), err)
+		}
+		var newCommit createCommitResponse
+		if err := json.Unmarshal(newCommitData, &newCommit); err != nil {
+			return commits, committed, fmt.Errorf("parse commit: %w", err)
+		}
+
+		// 5. Update branch ref
+		updateReq := updateRefRequest{SHA: newCommit.SHA}
+		_, err = p.doRequest("PATCH", p.apiURL("/git/refs/heads/"+p.branch), updateReq)
+		if err != nil {
+			return commits, committed, fmt.Errorf("update ref%s: %w", chunkLabel(i, len(chunks)), err)
+		}
+		parentSHA, baseTreeSHA = newCommit.SHA, newTree.SHA
+		commits++
+		committed = appendPaths(committed, chunk)
 	}
 
-	// 4. Create commit
-	commitReq := createCommitRequest{
-		Message: message,
-		Tree:    newTree.SHA,
-		Parents: []string{latestCommitSHA},
+	fmt.Printf("Committed %d files in %d commit(s) to GitHub (%s/%s@%s)\n", len(files), commits, p.owner, p.repo, p.branch)
+	return commits, committed, nil
+}
+
+// appendPaths appends the paths of files to paths
+func appendPaths(paths []string, files []treeFile) []string {
+	for _, f := range files {
+		paths = append(paths, f.path)
 	}
-	newCommitData, err := p.doRequest("POST", p.apiURL("/git/commits"), commitReq)
-	if err != nil {
-		return fmt.Errorf("create commit: %w", err)
+	return paths
+}
+
+// chunkFiles splits files into groups of at most limit (limit <= 0 = one
+// group)
+func chunkFiles(files []treeFile, limit int) [][]treeFile {
+	if limit <= 0 || len(files) <= limit {
+		return [][]treeFile{files}
 	}
-	var newCommit createCommitResponse
-	if err := json.Unmarshal(newCommitData, &newCommit); err != nil {
-		return fmt.Errorf("parse commit: %w", err)
+	var chunks [][]treeFile
+	for len(files) > limit {
+		chunks = append(chunks, files[:limit])
+		files = files[limit:]
 	}
+	return append(chunks, files)
+}
 
-	// 5. Update branch ref
-	updateReq := updateRefRequest{SHA: newCommit.SHA}
-	_, err = p.doRequest("PATCH", p.apiURL("/git/refs/heads/"+p.branch), updateReq)
-	if err != nil {
-		return fmt.Errorf("update ref: %w", err)
+// chunkLabel names commit i of n in errors (" 2/3"); "" for a single commit
+func chunkLabel(i, n int) string {
+	if n <= 1 {
+		return ""
 	}
-
-	fmt.Printf("Committed %d files to GitHub (%s/%s@%s)\n", len(files), p.owner, p.repo, p.branch)
-	return nil
+	return fmt.Sprintf(" %d/%d", i+1, n)
 }
 
 // toForwardSlash converts OS-specific path separators to forward slashes for GitHub API.
@@ -345,16 +574,31 @@ func encodePathSegments(filePath string) string {
 	return strings.Join(parts, "/")
 }
 
-// parseGitHubRepo extracts owner and repo from a GitHub URL
+// parseGitHubRepo extracts owner and repo from a GitHub URL; any host is
+// accepted, so GitHub Enterprise remotes work too
 func parseGitHubRepo(gitRepo string) (owner, repo string) {
 	// Handle: https://github.com/owner/repo.git
+	//         https://ghe.example.com/owner/repo.git
+	//         ssh://git@ghe.example.com/owner/repo.git
 	//         git@github.com:owner/repo.git
 	//         owner/repo
-	s := gitRepo
+	s := strings.TrimSpace(gitRepo)
+	s = strings.TrimSuffix(s, "/")
 	s = strings.TrimSuffix(s, ".git")
-	s = strings.TrimPrefix(s, "https://github.com/")
-	s = strings.TrimPrefix(s, "http://github.com/")
-	s = strings.TrimPrefix(s, "git@github.com:")
+	if i := strings.Index(s, "://"); i >= 0 {
+		// scheme://[user@]host[:port]/owner/repo
+		s = s[i+3:]
+		if j := strings.Index(s, "/"); j >= 0 {
+			s = s[j+1:]
+		} else {
+			s = ""
+		}
+	} else if at := strings.Index(s, "@"); at >= 0 {
+		// user@host:owner/repo
+		if colon := strings.Index(s[at:], ":"); colon >= 0 {
+			s = s[at+colon+1:]
+		}
+	}
 
 	parts := strings.SplitN(s, "/", 2)
 	if len(parts) == 2 {
@@ -0,0 +1,354 @@
+package publisher
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+// fakeGitHub serves the Git Trees API calls of commitMultipleFiles for
+// owner/repo on branch main. failTree makes that (1-based) tree request fail.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	head     string
+	trees    map[string]string // commit SHA → tree SHA
+	files    map[string]bool   // paths in the branch
+	contents map[string]string // content of the paths committed through the fake
+	pending  map[string][]treeEntry
+	messages []string // commit messages in order
+	treeN    int
+	commitN  int
+	failTree int
+	truncate bool // answer tree listings as truncated
+}
+
+func newFakeGitHub(t *testing.T, files ...string) (*fakeGitHub, *httptest.Server) {
+	t.Helper()
+	g := &fakeGitHub{
+		head:     "c0",
+		trees:    map[string]string{"c0": "t0"},
+		files:    make(map[string]bool),
+		contents: make(map[string]string),
+		pending:  make(map[string][]treeEntry),
+	}
+	for _, f := range files {
+		g.files[f] = true
+	}
+	srv := httptest.NewServer(http.HandlerFunc(g.serve))
+	t.Cleanup(srv.Close)
+	return g, srv
+}
+
+func (g *fakeGitHub) serve(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/api/v3/repos/owner/repo")
+	switch {
+	case r.Method == "GET" && path == "/git/ref/heads/main":
+		fmt.Fprintf(w, `{"object":{"sha":%q}}`, g.head)
+	case r.Method == "GET" && strings.HasPrefix(path, "/git/commits/"):
+		sha := strings.TrimPrefix(path, "/git/commits/")
+		fmt.Fprintf(w, `{"sha":%q,"tree":{"sha":%q}}`, sha, g.trees[sha])
+	case r.Method == "GET" && path == "/git/trees/main":
+		var tree []map[string]string
+		for file, ok := range g.files {
+			if ok {
+				tree = append(tree, map[string]string{"path": file, "type": "blob"})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"tree": tree, "truncated": g.truncate})
+	case r.Method == "GET" && strings.HasPrefix(path, "/contents/"):
+		file := strings.TrimPrefix(path, "/contents/")
+		if !g.files[file] {
+			http.NotFound(w, r)
+			return
+		}
+		// wrapped at 60 columns like GitHub's payload
+		encoded := base64.StdEncoding.EncodeToString([]byte(g.contents[file]))
+		var wrapped strings.Builder
+		for len(encoded) > 60 {
+			wrapped.WriteString(encoded[:60] + "\n")
+			encoded = encoded[60:]
+		}
+		wrapped.WriteString(encoded)
+		json.NewEncoder(w).Encode(map[string]string{"sha": "blob", "encoding": "base64", "content": wrapped.String()})
+	case r.Method == "POST" && path == "/git/trees":
+		g.treeN++
+		if g.treeN == g.failTree {
+			http.Error(w, `{"message":"server error"}`, http.StatusBadGateway)
+			return
+		}
+		var req createTreeRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		sha := fmt.Sprintf("t%d", g.treeN)
+		g.pending[sha] = req.Tree
+		fmt.Fprintf(w, `{"sha":%q}`, sha)
+	case r.Method == "POST" && path == "/git/commits":
+		var req createCommitRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		g.commitN++
+		g.messages = append(g.messages, req.Message)
+		sha := fmt.Sprintf("c%d", g.commitN)
+		g.trees[sha] = req.Tree
+		fmt.Fprintf(w, `{"sha":%q}`, sha)
+	case r.Method == "PATCH" && path == "/git/refs/heads/main":
+		var req updateRefRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		g.head = req.SHA
+		for _, e := range g.pending[g.trees[req.SHA]] {
+			g.files[e.Path] = string(e.SHA) != "null"
+			g.contents[e.Path] = e.Content
+		}
+		fmt.Fprintf(w, `{"object":{"sha":%q}}`, req.SHA)
+	default:
+		http.Error(w, "unexpected "+r.Method+" "+path, http.StatusNotFound)
+	}
+}
+
+func newTestGitHubPublisher(t *testing.T, apiURL string, maxFiles int) *GitHubPublisher {
+	t.Helper()
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	return NewGitHubPublisher(&config.HugoConfig{
+		ContentDir:        "content",
+		GitRepo:           "owner/repo",
+		APIBaseURL:        apiURL,
+		MaxFilesPerCommit: maxFiles,
+	}, nil)
+}
+
+func testArticles(n int) []*models.Article {
+	articles := make([]*models.Article, n)
+	for i := range articles {
+		articles[i] = &models.Article{
+			ID:          int64(i + 1),
+			Title:       fmt.Sprintf("Article %d", i+1),
+			TitleRU:     fmt.Sprintf("Статья %d", i+1),
+			ContentRU:   "Текст.",
+			Slug:        fmt.Sprintf("article-%d", i+1),
+			PublishedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		}
+	}
+	return articles
+}
+
+func TestPublishMultipleReportsArticlesOfLandedCommits(t *testing.T) {
+	g, srv := newFakeGitHub(t)
+	g.failTree = 2
+	p := newTestGitHubPublisher(t, srv.URL, 1)
+	articles := testArticles(3)
+
+	commits, published, err := p.PublishMultiple(articles, "")
+	if err == nil {
+		t.Fatal("expected the second chained commit to fail")
+	}
+	if commits != 1 {
+		t.Errorf("commits = %d, want 1", commits)
+	}
+	if len(published) != 1 || published[0] != articles[0] {
+		t.Fatalf("published = %v, want only the first article", published)
+	}
+	if !g.files["content/posts/2026/03/article-1.md"] || g.files["content/posts/2026/03/article-2.md"] {
+		t.Errorf("branch files = %v", g.files)
+	}
+
+	// a retry pushes the rest
+	g.failTree = 0
+	commits, published, err = p.PublishMultiple(articles, "")
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if commits != 3 || len(published) != 3 {
+		t.Errorf("retry: commits = %d, published = %d, want 3 and 3", commits, len(published))
+	}
+}
+
+func TestPublishMultipleAllCommitted(t *testing.T) {
+	_, srv := newFakeGitHub(t)
+	p := newTestGitHubPublisher(t, srv.URL, 2)
+
+	commits, published, err := p.PublishMultiple(testArticles(5), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commits != 3 || len(published) != 5 {
+		t.Errorf("commits = %d, published = %d, want 3 and 5", commits, len(published))
+	}
+}
+
+func TestDeleteMultiplePartialFailure(t *testing.T) {
+	g, srv := newFakeGitHub(t,
+		"content/posts/2026/03/article-1.md",
+		"content/posts/2026/03/article-2.md",
+		"content/posts/2026/03/article-3.md",
+	)
+	g.failTree = 3
+	p := newTestGitHubPublisher(t, srv.URL, 1)
+
+	deleted, err := p.DeleteMultiple(testArticles(3))
+	if err == nil {
+		t.Fatal("expected the third chained commit to fail")
+	}
+	if deleted != 2 {
+		t.Errorf("deleted = %d, want 2", deleted)
+	}
+	if g.files["content/posts/2026/03/article-2.md"] || !g.files["content/posts/2026/03/article-3.md"] {
+		t.Errorf("branch files = %v", g.files)
+	}
+}
+
+func TestPublishMultipleSkipExisting(t *testing.T) {
+	g, srv := newFakeGitHub(t)
+	p := newTestGitHubPublisher(t, srv.URL, 0)
+	p.config.SkipExisting = true
+	articles := testArticles(3)
+
+	if commits, published, err := p.PublishMultiple(articles, ""); err != nil || commits != 1 || len(published) != 3 {
+		t.Fatalf("first publish: commits=%d published=%d err=%v, want 1 commit of 3 articles", commits, len(published), err)
+	}
+	if !strings.Contains(g.contents["content/posts/2026/03/article-1.md"], "Статья 1") {
+		t.Fatalf("branch content = %q", g.contents["content/posts/2026/03/article-1.md"])
+	}
+
+	// a restored database publishes the same articles again
+	commits, published, err := p.PublishMultiple(articles, "")
+	if err != nil || commits != 0 || len(published) != 3 {
+		t.Errorf("identical files: commits=%d published=%d err=%v, want no commit and 3 articles", commits, len(published), err)
+	}
+
+	articles[1].ContentRU = "Новый текст."
+	g.pending = make(map[string][]treeEntry)
+	commits, published, err = p.PublishMultiple(articles, "")
+	if err != nil || commits != 1 || len(published) != 3 {
+		t.Fatalf("one changed file: commits=%d published=%d err=%v, want 1 commit and 3 articles", commits, len(published), err)
+	}
+	if tree := g.pending[g.trees[g.head]]; len(tree) != 1 || tree[0].Path != "content/posts/2026/03/article-2.md" {
+		t.Errorf("committed tree = %+v, want only article-2", tree)
+	}
+
+	// without the setting identical files are committed again
+	p.config.SkipExisting = false
+	if commits, _, err := p.PublishMultiple(articles, ""); err != nil || commits != 1 {
+		t.Errorf("skip_existing off: commits=%d err=%v, want 1", commits, err)
+	}
+}
+
+func TestVerifyReportsMissingFiles(t *testing.T) {
+	for _, truncate := range []bool{false, true} {
+		g, srv := newFakeGitHub(t,
+			"content/posts/2026/03/article-1.md",
+			"content/posts/2026/03/article-3.md",
+		)
+		g.truncate = truncate
+		p := newTestGitHubPublisher(t, srv.URL, 0)
+		articles := testArticles(3)
+
+		missing, err := p.Verify(articles)
+		if err != nil {
+			t.Fatalf("truncated=%v: %v", truncate, err)
+		}
+		if len(missing) != 1 || missing[0].Article != articles[1] || missing[0].Path != "content/posts/2026/03/article-2.md" {
+			t.Errorf("truncated=%v: missing = %+v, want only article-2", truncate, missing)
+		}
+	}
+}
+
+func TestPublishMultipleCompanionOriginal(t *testing.T) {
+	g, srv := newFakeGitHub(t)
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	cfg := &config.HugoConfig{ContentDir: "content", GitRepo: "owner/repo", APIBaseURL: srv.URL}
+	cfg.Formatter.IncludeOriginal = "file"
+	p := NewGitHubPublisher(cfg, nil)
+	articles := testArticles(2)
+	articles[0].Content = "The original English text."
+
+	if _, published, err := p.PublishMultiple(articles, ""); err != nil || len(published) != 2 {
+		t.Fatalf("published = %d (%v), want 2", len(published), err)
+	}
+	// three files, but the companion page is not an article of its own
+	if len(g.messages) != 1 || g.messages[0] != "Add 2 new articles" {
+		t.Errorf("commit messages = %q, want one \"Add 2 new articles\"", g.messages)
+	}
+	const dir = "content/posts/2026/03/"
+	original := g.contents[dir+"article-1.en.md"]
+	if !g.files[dir+"article-1.md"] || !strings.Contains(original, "title: Article 1\n") ||
+		!strings.Contains(original, "The original English text.") || strings.Contains(original, "Текст") {
+		t.Errorf("companion page next to the post missing or not English:\n%s", original)
+	}
+	if strings.Contains(g.contents[dir+"article-1.md"], "The original English text.") {
+		t.Error("the translated post repeats the original")
+	}
+	// no English text stored: no companion page
+	if g.files[dir+"article-2.en.md"] {
+		t.Error("companion page written for an article without the original")
+	}
+
+	if n, err := p.DeleteMultiple(articles[:1]); err != nil || n != 1 {
+		t.Fatalf("DeleteMultiple = %d (%v), want 1", n, err)
+	}
+	if g.files[dir+"article-1.md"] || g.files[dir+"article-1.en.md"] {
+		t.Errorf("post or companion left after delete: %v", g.files)
+	}
+}
+
+func TestEnterpriseBaseURL(t *testing.T) {
+	g, srv := newFakeGitHub(t)
+	var paths []string
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		handler.ServeHTTP(w, r)
+	})
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	// the repo as cloned from the enterprise host, the API base without /api/v3
+	p := NewGitHubPublisher(&config.HugoConfig{ContentDir: "content", GitRepo: srv.URL + "/owner/repo.git", APIBaseURL: srv.URL + "/"}, nil)
+
+	if _, published, err := p.PublishMultiple(testArticles(1), ""); err != nil || len(published) != 1 {
+		t.Fatalf("published = %d (%v), want 1", len(published), err)
+	}
+	if !g.files["content/posts/2026/03/article-1.md"] {
+		t.Errorf("branch files = %v", g.files)
+	}
+	for _, path := range paths {
+		if !strings.HasPrefix(path, "/api/v3/repos/owner/repo/") {
+			t.Errorf("request to %s, want it under /api/v3/repos/owner/repo", path)
+		}
+	}
+	if len(paths) == 0 {
+		t.Error("no requests reached the enterprise host")
+	}
+}
+
+func TestGitHubAPIBase(t *testing.T) {
+	for raw, want := range map[string]string{
+		"":                                "https://api.github.com",
+		"https://api.github.com/":         "https://api.github.com",
+		"https://ghe.example.com":         "https://ghe.example.com/api/v3",
+		"https://ghe.example.com/api/v3/": "https://ghe.example.com/api/v3",
+		"https://ghe.example.com/custom":  "https://ghe.example.com/custom",
+		"http://ghe.example.com:8080":     "http://ghe.example.com:8080/api/v3",
+	} {
+		if got := githubAPIBase(raw); got != want {
+			t.Errorf("githubAPIBase(%q) = %q, want %q", raw, got, want)
+		}
+	}
+
+	for raw, want := range map[string]string{
+		"https://ghe.example.com/team/site.git":  "team/site",
+		"ssh://git@ghe.example.com:22/team/site": "team/site",
+		"git@ghe.example.com:team/site.git":      "team/site",
+		"team/site":                              "team/site",
+	} {
+		if owner, repo := parseGitHubRepo(raw); owner+"/"+repo != want {
+			t.Errorf("parseGitHubRepo(%q) = %s/%s, want %s", raw, owner, repo, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package publisher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// errLocked is returned by tryLock when another process holds the lock
+var errLocked = errors.New("lock is held")
+
+// gitLockPoll is how often a waiting git operation retries the lock
+const gitLockPoll = 200 * time.Millisecond
+
+// gitLockPath is the lock file guarding git operations on the blog repo. It
+// lives next to hugo.path (".blog.lock" for ./blog) because clone replaces
+// the directory itself.
+func (p *HugoPublisher) gitLockPath() (string, error) {
+	abs, err := filepath.Abs(p.config.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve blog path: %w", err)
+	}
+	return filepath.Join(filepath.Dir(abs), "."+filepath.Base(abs)+".lock"), nil
+}
+
+// lockGit waits up to hugo.git_lock_timeout_sec for exclusive access to the
+// blog repo, so a scheduled run and a manual pull/push never run git there
+// at the same time. The lock is released by the returned func, or by the OS
+// if the process dies.
+func (p *HugoPublisher) lockGit() (func(), error) {
+	path, err := p.gitLockPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git lock %s: %w", path, err)
+	}
+
+	timeout := time.Duration(p.config.GitLockTimeoutSec) * time.Second
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLock(f)
+		if err == nil {
+			return func() {
+				unlock(f)
+				f.Close()
+			}, nil
+		}
+		if !errors.Is(err, errLocked) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("another git operation on %s is still running after %s (lock %s)", p.config.Path, timeout, path)
+		}
+		time.Sleep(gitLockPoll)
+	}
+}
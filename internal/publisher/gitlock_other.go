@@ -0,0 +1,22 @@
+//go:build !unix
+
+package publisher
+
+import (
+	"os"
+	"sync"
+)
+
+// Without flock the lock only serializes git operations within this process
+var gitMu sync.Mutex
+
+func tryLock(f *os.File) error {
+	if !gitMu.TryLock() {
+		return errLocked
+	}
+	return nil
+}
+
+func unlock(f *os.File) {
+	gitMu.Unlock()
+}
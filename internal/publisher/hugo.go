@@ -20,7 +20,7 @@ type HugoPublisher struct {
 func NewHugoPublisher(cfg *config.HugoConfig) *HugoPublisher {
 	return &HugoPublisher{
 		config:    cfg,
-		formatter: formatter.NewMarkdownFormatter(),
+		formatter: formatter.NewMarkdownFormatter(&cfg.Formatter),
 	}
 }
 
@@ -54,6 +54,14 @@ func (p *HugoPublisher) Publish(article *models.Article) error {
 		return fmt.Errorf("failed to write file %s: %w", filePath, err)
 	}
 
+	// Companion English page (include_original: file)
+	if original := p.formatter.FormatOriginal(article); original != "" {
+		originalPath := p.formatter.OriginalFilePath(article, contentPath)
+		if err := os.WriteFile(originalPath, []byte(original), 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", originalPath, err)
+		}
+	}
+
 	fmt.Printf("Published: %s\n", filePath)
 	return nil
 }
@@ -73,12 +81,69 @@ func (p *HugoPublisher) PublishMultiple(articles []*models.Article) error {
 	return nil
 }
 
+// Remove deletes the article files from the Hugo site and optionally commits.
+// Files that do not exist are skipped. Returns the number of files removed.
+func (p *HugoPublisher) Remove(articles []*models.Article) (int, error) {
+	if err := p.validateConfig(); err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, article := range articles {
+		filePath := p.formatter.GetFilePath(article, p.GetContentPath())
+		// A companion English page may exist from an earlier include_original setting
+		originalPath := p.formatter.OriginalFilePath(article, p.GetContentPath())
+		if err := os.Remove(originalPath); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove %s: %w", originalPath, err)
+		}
+		if err := os.Remove(filePath); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return removed, fmt.Errorf("failed to remove %s: %w", filePath, err)
+		}
+		removed++
+	}
+
+	if p.config.AutoCommit && removed > 0 {
+		return removed, p.GitCommit(fmt.Sprintf("Remove %d old articles", removed))
+	}
+	return removed, nil
+}
+
+// WriteIndex renders the index pages for the given articles (see
+// hugo.index) under <content>/posts and returns the written paths, main
+// page (<content>/posts/_index.md) first
+func (p *HugoPublisher) WriteIndex(articles []*models.Article, title string) ([]string, error) {
+	if err := p.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, page := range p.formatter.GenerateIndexPages(articles, title, &p.config.Index) {
+		filePath := filepath.Join(p.GetContentPath(), "posts", filepath.FromSlash(page.Path))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return paths, fmt.Errorf("failed to create directory %s: %w", filepath.Dir(filePath), err)
+		}
+		if err := os.WriteFile(filePath, []byte(page.Content), 0644); err != nil {
+			return paths, fmt.Errorf("failed to write file %s: %w", filePath, err)
+		}
+		paths = append(paths, filePath)
+	}
+	return paths, nil
+}
+
 // GitCommit commits changes to git.
 // Uses cmd.Dir instead of os.Chdir to avoid race conditions.
 func (p *HugoPublisher) GitCommit(message string) error {
 	if err := p.validateConfig(); err != nil {
 		return err
 	}
+	unlock, err := p.lockGit()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	dir := p.config.Path
 
@@ -118,6 +183,11 @@ func (p *HugoPublisher) GitPull() error {
 	if err := p.validateConfig(); err != nil {
 		return err
 	}
+	unlock, err := p.lockGit()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	gitDir := filepath.Join(p.config.Path, ".git")
 
@@ -166,6 +236,11 @@ func (p *HugoPublisher) GitPush() error {
 	if err := p.validateConfig(); err != nil {
 		return err
 	}
+	unlock, err := p.lockGit()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	if p.config.GitRemote == "" || p.config.GitBranch == "" {
 		return fmt.Errorf("git_remote and git_branch must be configured for push")
@@ -0,0 +1,106 @@
+package publisher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"moto-news/internal/models"
+)
+
+// verifyPause spaces out per-file lookups when the tree listing is truncated,
+// keeping well under GitHub's secondary rate limits
+const verifyPause = 250 * time.Millisecond
+
+// MissingFile is a published article whose markdown file is not in the repo
+type MissingFile struct {
+	Article *models.Article
+	Path    string
+}
+
+type gitTreeResponse struct {
+	Tree []struct {
+		Path string `json:"path"`
+		Type string `json:"type"`
+	} `json:"tree"`
+	Truncated bool `json:"truncated"`
+}
+
+// Verify returns the articles whose files are missing on the branch. The
+// branch tree is listed in one request; only when GitHub truncates it (very
+// large repos) are the files looked up one by one.
+func (p *GitHubPublisher) Verify(articles []*models.Article) ([]MissingFile, error) {
+	if !p.IsAvailable() {
+		return nil, fmt.Errorf("GitHub publisher not configured (GITHUB_TOKEN not set)")
+	}
+
+	data, err := p.doRequest("GET", p.apiURL("/git/trees/"+url.PathEscape(p.branch))+"?recursive=1", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branch %s: %w", p.branch, err)
+	}
+	var tree gitTreeResponse
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to parse tree: %w", err)
+	}
+
+	if !tree.Truncated {
+		existing := make(map[string]bool, len(tree.Tree))
+		for _, e := range tree.Tree {
+			if e.Type == "blob" {
+				existing[e.Path] = true
+			}
+		}
+		var missing []MissingFile
+		for _, article := range articles {
+			filePath := toForwardSlash(p.formatter.GetFilePath(article, p.config.ContentDir))
+			if !existing[filePath] {
+				missing = append(missing, MissingFile{Article: article, Path: filePath})
+			}
+		}
+		return missing, nil
+	}
+
+	fmt.Println("Repo tree is truncated, checking files one by one")
+	var missing []MissingFile
+	for i, article := range articles {
+		if i > 0 {
+			time.Sleep(verifyPause)
+		}
+		filePath := toForwardSlash(p.formatter.GetFilePath(article, p.config.ContentDir))
+		_, err := p.doRequest("GET", p.apiURL("/contents/"+encodePathSegments(filePath))+"?ref="+url.QueryEscape(p.branch), nil)
+		var apiErr *apiError
+		switch {
+		case err == nil:
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			missing = append(missing, MissingFile{Article: article, Path: filePath})
+		case errors.As(err, &apiErr) && apiErr.RateLimit:
+			return nil, fmt.Errorf("rate limit exhausted after %d of %d files: %w", i, len(articles), err)
+		default:
+			return nil, fmt.Errorf("failed to check %s: %w", filePath, err)
+		}
+	}
+	return missing, nil
+}
+
+// Verify returns the articles whose files are missing from the local Hugo site
+func (p *HugoPublisher) Verify(articles []*models.Article) ([]MissingFile, error) {
+	if err := p.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	var missing []MissingFile
+	for _, article := range articles {
+		filePath := p.formatter.GetFilePath(article, p.GetContentPath())
+		if _, err := os.Stat(filePath); err != nil {
+			if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to check %s: %w", filePath, err)
+			}
+			missing = append(missing, MissingFile{Article: article, Path: filePath})
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gzipMinSize is the smallest body worth compressing
+const gzipMinSize = 1024
+
+// bufferedWriter holds a GET response back until the handler finished, so
+// it can be hashed for the ETag and compressed in one go
+type bufferedWriter struct {
+	gin.ResponseWriter
+	buf    bytes.Buffer
+	status int
+}
+
+func (w *bufferedWriter) WriteHeader(code int)              { w.status = code }
+func (w *bufferedWriter) WriteHeaderNow()                   {}
+func (w *bufferedWriter) Write(b []byte) (int, error)       { return w.buf.Write(b) }
+func (w *bufferedWriter) WriteString(s string) (int, error) { return w.buf.WriteString(s) }
+func (w *bufferedWriter) Status() int                       { return w.status }
+func (w *bufferedWriter) Size() int                         { return w.buf.Len() }
+func (w *bufferedWriter) Written() bool                     { return w.buf.Len() > 0 }
+func (w *bufferedWriter) Flush()                            {}
+
+// responseCache adds a weak ETag (hash of the body) to successful GET
+// responses, answers a matching If-None-Match with 304 and gzips larger
+// bodies for clients that accept it. Other methods pass through untouched.
+// Hashing the rendered body is far cheaper than sending it again to a
+// dashboard that polls every few seconds. An ETag already set by the route
+// (see signatureETag) is kept.
+func responseCache(etag, compress bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || (!etag && !compress) {
+			c.Next()
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		body := w.buf.Bytes()
+		if etag && w.status == http.StatusOK && c.Writer.Header().Get("ETag") == "" {
+			sum := sha256.Sum256(body)
+			tag := `W/"` + hex.EncodeToString(sum[:8]) + `"`
+			c.Header("ETag", tag)
+			if etagMatches(c.GetHeader("If-None-Match"), tag) {
+				c.Header("Content-Type", "")
+				c.Status(http.StatusNotModified)
+				c.Writer.WriteHeaderNow()
+				return
+			}
+		}
+
+		if compress {
+			c.Header("Vary", "Accept-Encoding")
+			if len(body) >= gzipMinSize && acceptsGzip(c.GetHeader("Accept-Encoding")) {
+				c.Header("Content-Encoding", "gzip")
+				c.Status(w.status)
+				gz := gzip.NewWriter(c.Writer)
+				_, _ = gz.Write(body)
+				_ = gz.Close()
+				return
+			}
+		}
+
+		c.Status(w.status)
+		_, _ = c.Writer.Write(body)
+	}
+}
+
+// signatureETag answers a conditional GET from a cheap signature of the data
+// behind the route before the handler runs, so an unchanged listing is not
+// queried and rendered just to be hashed. The tag covers the request URI, so
+// queries with other parameters get their own. It is set on successful
+// responses only, which relies on responseCache buffering the response; a
+// failing signature falls back to hashing the body.
+func signatureETag(enabled bool, signature func() (string, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		sig, err := signature()
+		if err != nil {
+			c.Next()
+			return
+		}
+		sum := sha256.Sum256([]byte(c.Request.URL.RequestURI() + "\n" + sig))
+		tag := `W/"` + hex.EncodeToString(sum[:8]) + `"`
+		if etagMatches(c.GetHeader("If-None-Match"), tag) {
+			c.Header("ETag", tag)
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+		c.Next()
+		if c.Writer.Status() == http.StatusOK {
+			c.Header("ETag", tag)
+		}
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists tag (weak
+// comparison, so "abc" also matches W/"abc")
+func etagMatches(header, tag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, enc := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if strings.TrimSpace(name) != "gzip" {
+			continue
+		}
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventsHeartbeat keeps idle SSE connections from being closed by proxies
+const eventsHeartbeat = 15 * time.Second
+
+// handleEvents streams pipeline progress as server-sent events, one
+// "event: <type>" with a JSON Event per update, until the client goes away.
+// Each connection is its own subscriber; a client too slow to keep up
+// misses events rather than stalling the pipeline.
+func (s *Server) handleEvents(c *gin.Context) {
+	ch, unsubscribe := s.svc.Events().Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx: don't buffer the stream
+	c.Status(http.StatusOK)
+	_, _ = io.WriteString(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case e := <-ch:
+			c.SSEvent(e.Type, e)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
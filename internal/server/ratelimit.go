@@ -0,0 +1,43 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter allows at most limit calls in any window (sliding window);
+// limit <= 0 allows everything
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	calls  []time.Time // oldest first, all within the last window
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window}
+}
+
+// allow records a call at now if the limit permits it; otherwise it returns
+// false and how long until the oldest call leaves the window
+func (l *rateLimiter) allow(now time.Time) (bool, time.Duration) {
+	if l.limit <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+	kept := l.calls[:0]
+	for _, t := range l.calls {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	l.calls = kept
+	if len(l.calls) >= l.limit {
+		return false, l.calls[0].Sub(cutoff)
+	}
+	l.calls = append(l.calls, now)
+	return true, 0
+}
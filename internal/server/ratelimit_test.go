@@ -0,0 +1,37 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSlidingWindow(t *testing.T) {
+	l := newRateLimiter(2, time.Minute)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, at := range []time.Duration{0, 10 * time.Second} {
+		if ok, _ := l.allow(start.Add(at)); !ok {
+			t.Fatalf("call %d refused", i+1)
+		}
+	}
+	ok, wait := l.allow(start.Add(30 * time.Second))
+	if ok {
+		t.Fatal("third call within the minute allowed")
+	}
+	if wait != 30*time.Second {
+		t.Errorf("wait = %s, want 30s", wait)
+	}
+	if ok, _ := l.allow(start.Add(61 * time.Second)); !ok {
+		t.Error("call after the first one left the window refused")
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	l := newRateLimiter(0, time.Minute)
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.allow(now); !ok {
+			t.Fatalf("call %d refused without a limit", i+1)
+		}
+	}
+}
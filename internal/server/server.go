@@ -1,12 +1,20 @@
 package server
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"moto-news/internal/config"
+	"moto-news/internal/fetcher"
+	"moto-news/internal/httpclient"
+	"moto-news/internal/models"
 	"moto-news/internal/service"
 	"moto-news/internal/storage"
 )
@@ -17,6 +25,9 @@ type Server struct {
 	store   *storage.SQLiteStorage
 	svc     *service.Service
 	router  *gin.Engine
+	// translateTestLimit caps POST /api/translate-test calls
+	// (server.translate_test_per_minute)
+	translateTestLimit *rateLimiter
 }
 
 // New creates a new server instance
@@ -31,6 +42,8 @@ func New(cfg *config.Config, store *storage.SQLiteStorage) *Server {
 		store:  store,
 		svc:    svc,
 		router: router,
+
+		translateTestLimit: newRateLimiter(cfg.Server.TranslateTestPerMinute, time.Minute),
 	}
 
 	s.setupRoutes()
@@ -42,22 +55,37 @@ func (s *Server) Run() error {
 	addr := fmt.Sprintf("%s:%d", s.cfg.Server.Host, s.cfg.Server.Port)
 	fmt.Printf("Starting server on %s\n", addr)
 	fmt.Println("Endpoints:")
-	fmt.Println("  POST /api/fetch       - Fetch new articles from RSS feeds")
-	fmt.Println("  POST /api/translate   - Translate untranslated articles (?limit=10)")
-	fmt.Println("  POST /api/publish     - Publish translated articles (?limit=100)")
+	fmt.Println("  POST /api/fetch       - Fetch new articles from RSS feeds (?source=rideapart for one source)")
+	fmt.Println("  POST /api/translate   - Translate untranslated articles (?limit=10, ?resume=true continues the last unfinished job)")
+	fmt.Println("  POST /api/publish     - Publish translated articles (?limit=100, ?refresh=true re-renders stale ones, ?date=2024-06-01[..2024-06-03] re-publishes those days)")
 	fmt.Println("  POST /api/run         - Full pipeline: fetch -> translate -> publish")
-	fmt.Println("  POST /api/rescrape    - Re-scrape articles with empty content")
+	fmt.Println("  POST /api/rescrape    - Re-scrape articles with empty content (?changed=true: re-check recent articles and update those changed at the source)")
 	fmt.Println("  POST /api/pull        - Pull/update blog repository")
 	fmt.Println("  POST /api/push        - Push changes to blog repository")
+	fmt.Println("  POST /api/verify-published - Check published files exist in the repo (?reset=true re-queues missing ones)")
+	fmt.Println("  POST /api/translate-test - Translate a text with the current translator, nothing saved: {\"text\": \"...\", \"title\": false, \"prompt\": \"...\", \"temperature\": 0.2} (server.translate_test)")
 	fmt.Println("  GET  /api/stats       - Database statistics")
-	fmt.Println("  GET  /api/articles    - List recent articles (?limit=20)")
+	fmt.Println("  GET  /api/stats/timeseries - Articles per day for charts (?metric=fetched|translated|published, ?days=30)")
+	fmt.Println("  GET  /api/status      - Active translator, model, whether it is reachable (cached check) and DeepL quota")
+	fmt.Println("  GET  /api/sources     - Sources with last fetch, new articles in the last 24h and failures in a row")
+	fmt.Println("  POST /api/sources/:name/enable - Re-enable a source disabled after repeated failures")
+	fmt.Println("  GET  /api/jobs/:id    - Progress of a long-running job (processed/total, ETA)")
+	fmt.Println("  GET  /api/events      - Live pipeline progress as server-sent events (article started/finished, step counts, errors)")
+	fmt.Println("  GET  /api/preview-feed?url=... - Parse any feed without saving and show how items map to articles (?limit=20, ?category_field=dc:subject&tags_field=... to try a mapping)")
+	fmt.Println("  GET  /api/articles    - List recent articles (?limit=20, ?status=unpublished, ?translator=deepl)")
 	fmt.Println("  GET  /api/articles/recently-translated - Last translated articles (?limit=10)")
-	fmt.Println("  GET  /api/article/:id - Get single article by ID")
+	fmt.Println("  GET  /api/article/:id - Get single article by ID with prev/next links (?same_source=true)")
+	fmt.Println("  PUT  /api/article/:id - Set category/tags by hand: {\"category\": \"...\", \"tags\": [...]}; rescrapes keep them")
+	fmt.Println("  POST /api/article/:id/featured - Translate and publish the article first (?featured=false to clear)")
+	fmt.Println("  POST /api/article/:id/reprocess - Re-scrape, re-translate and re-publish one article; data.steps has each step's outcome")
+	fmt.Println("  POST /api/articles/review - Mark articles reviewed for hugo.require_review: {\"ids\": [1, 2], \"reviewed\": true}")
+	fmt.Println("  GET  /api/article/:id/raw-html - Re-scrape source page and show what the scraper saw (debug)")
+	fmt.Println("  GET  /api/article/:id/raw-content - Stored raw text, cleaned content and a re-clean by the current rules (no network)")
 	return s.router.Run(addr)
 }
 
 func (s *Server) setupRoutes() {
-	api := s.router.Group("/api")
+	api := s.router.Group("/api", responseCache(s.cfg.Server.ETag, s.cfg.Server.Compress))
 	{
 		// Actions
 		api.POST("/fetch", s.handleFetch)
@@ -67,14 +95,32 @@ func (s *Server) setupRoutes() {
 		api.POST("/rescrape", s.handleRescrape)
 		api.POST("/pull", s.handlePull)
 		api.POST("/push", s.handlePush)
+		api.POST("/verify-published", s.handleVerifyPublished)
+		api.POST("/translate-test", s.handleTranslateTest)
 
 		// Queries
-		api.GET("/stats", s.handleStats)
-		api.GET("/articles", s.handleArticles)
+		api.GET("/stats", signatureETag(s.cfg.Server.ETag, s.statsSignature), s.handleStats)
+		api.GET("/stats/timeseries", s.handleStatsTimeseries)
+		api.GET("/status", s.handleStatus)
+		api.GET("/sources", s.handleSources)
+		api.POST("/sources/:name/enable", s.handleEnableSource)
+		api.GET("/preview-feed", s.handlePreviewFeed)
+		api.GET("/jobs/:id", s.handleJob)
+		api.GET("/articles", signatureETag(s.cfg.Server.ETag, s.store.DataSignature), s.handleArticles)
 		api.GET("/articles/recently-translated", s.handleRecentlyTranslated)
 		api.GET("/article/:id", s.handleArticle)
+		api.GET("/article/:id/raw-html", s.handleArticleRawHTML)
+		api.GET("/article/:id/raw-content", s.handleArticleRawContent)
+		api.POST("/article/:id/featured", s.handleArticleFeatured)
+		api.POST("/article/:id/reprocess", s.handleArticleReprocess)
+		api.POST("/articles/review", s.handleArticlesReview)
+		api.PUT("/article/:id", s.handleArticleUpdate)
 	}
 
+	// Live progress stream; outside the /api group because responseCache
+	// would hold the never-ending body back
+	s.router.GET("/api/events", s.handleEvents)
+
 	// Health check
 	s.router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
@@ -82,18 +128,32 @@ func (s *Server) setupRoutes() {
 }
 
 func (s *Server) handleFetch(c *gin.Context) {
-	result, err := s.svc.Fetch()
+	result, err := s.svc.Fetch(c.Query("source"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrUnknownSource):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrSourceDisabled):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
 		return
 	}
 
+	msg := fmt.Sprintf("Fetched %d new articles, skipped %d", result.NewArticles, result.SkippedArticles)
+	if result.Filtered > 0 {
+		msg += fmt.Sprintf(", filtered %d", result.Filtered)
+	}
+	if result.FeedsFailed > 0 {
+		msg += fmt.Sprintf(" (%d of %d feeds failed)", result.FeedsFailed, result.FeedsTotal)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": fmt.Sprintf("Fetched %d new articles, skipped %d", result.NewArticles, result.SkippedArticles),
+		"message": msg,
 		"data":    result,
 	})
 }
@@ -106,7 +166,13 @@ func (s *Server) handleTranslate(c *gin.Context) {
 		}
 	}
 
-	result, err := s.svc.Translate(limit)
+	var result *service.TranslateResult
+	var err error
+	if c.Query("resume") == "true" {
+		result, err = s.svc.TranslateResume()
+	} else {
+		result, err = s.svc.Translate(limit)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -119,6 +185,9 @@ func (s *Server) handleTranslate(c *gin.Context) {
 	if result.PublishedThisBatch > 0 {
 		msg += fmt.Sprintf(", published %d to blog", result.PublishedThisBatch)
 	}
+	if result.PublishStatus == service.PublishFailed || result.PublishStatus == service.PublishPartial {
+		msg += fmt.Sprintf(", publish %s: %s", result.PublishStatus, result.PublishError)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": msg,
@@ -134,7 +203,21 @@ func (s *Server) handlePublish(c *gin.Context) {
 		}
 	}
 
-	result, err := s.svc.Publish(limit)
+	var result *service.PublishResult
+	var err error
+	if date := c.Query("date"); date != "" {
+		from, to, parseErr := s.svc.ParseDateRange(date)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   parseErr.Error(),
+			})
+			return
+		}
+		result, err = s.svc.PublishRange(from, to)
+	} else {
+		result, err = s.svc.Publish(limit, c.Query("refresh") == "true")
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -144,6 +227,9 @@ func (s *Server) handlePublish(c *gin.Context) {
 	}
 
 	msg := fmt.Sprintf("Published %d of %d articles", result.Published, result.Total)
+	if result.Skipped > 0 {
+		msg += fmt.Sprintf(", held back %d", result.Skipped)
+	}
 	if result.Total == 0 {
 		msg = "No articles to publish (0 pending). Translated articles are published automatically in the Translate step."
 	}
@@ -154,6 +240,28 @@ func (s *Server) handlePublish(c *gin.Context) {
 	})
 }
 
+func (s *Server) handleVerifyPublished(c *gin.Context) {
+	reset := c.Query("reset") == "true"
+	result, err := s.svc.VerifyPublished(reset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	msg := fmt.Sprintf("Checked %d published articles, %d missing", result.Checked, len(result.Missing))
+	if reset {
+		msg += fmt.Sprintf(", %d reset for re-publishing", result.Reset)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": msg,
+		"data":    result,
+	})
+}
+
 func (s *Server) handleRun(c *gin.Context) {
 	result, err := s.svc.Run()
 	if err != nil {
@@ -172,6 +280,11 @@ func (s *Server) handleRun(c *gin.Context) {
 }
 
 func (s *Server) handleRescrape(c *gin.Context) {
+	if c.Query("changed") == "true" {
+		s.handleRecheck(c)
+		return
+	}
+
 	result, err := s.svc.Rescrape()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -188,6 +301,27 @@ func (s *Server) handleRescrape(c *gin.Context) {
 	})
 }
 
+func (s *Server) handleRecheck(c *gin.Context) {
+	result, err := s.svc.RecheckContent()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrRecheckDisabled) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("Re-checked %d articles, %d changed at the source", result.Total, result.Changed),
+		"data":    result,
+	})
+}
+
 func (s *Server) handlePull(c *gin.Context) {
 	if err := s.svc.Pull(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -218,6 +352,17 @@ func (s *Server) handlePush(c *gin.Context) {
 	})
 }
 
+// statsSignature is the data signature plus the current minute: the recent
+// counts per source and the monthly character counter move with the clock
+// too, so an unchanged stats response is reused for at most a minute
+func (s *Server) statsSignature() (string, error) {
+	sig, err := s.store.DataSignature()
+	if err != nil {
+		return "", err
+	}
+	return sig + "@" + time.Now().Truncate(time.Minute).Format(time.RFC3339), nil
+}
+
 func (s *Server) handleStats(c *gin.Context) {
 	stats, err := s.svc.Stats()
 	if err != nil {
@@ -234,6 +379,171 @@ func (s *Server) handleStats(c *gin.Context) {
 	})
 }
 
+func (s *Server) handleStatus(c *gin.Context) {
+	status, err := s.svc.TranslatorStatus(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"translator": status},
+	})
+}
+
+func (s *Server) handleStatsTimeseries(c *gin.Context) {
+	metric := c.DefaultQuery("metric", "fetched")
+	if !slices.Contains(storage.TimeseriesMetrics(), metric) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("metric must be one of: %s", strings.Join(storage.TimeseriesMetrics(), ", ")),
+		})
+		return
+	}
+	days := 30
+	if d := c.Query("days"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 && parsed <= 365 {
+			days = parsed
+		}
+	}
+
+	series, err := s.svc.Timeseries(metric, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    series,
+		"metric":  metric,
+		"days":    days,
+	})
+}
+
+func (s *Server) handleSources(c *gin.Context) {
+	sources, err := s.svc.Sources()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sources,
+	})
+}
+
+func (s *Server) handleEnableSource(c *gin.Context) {
+	name := c.Param("name")
+	enabled, err := s.svc.EnableSource(name)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrUnknownSource) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	msg := fmt.Sprintf("Source %s re-enabled", name)
+	if !enabled {
+		msg = fmt.Sprintf("Source %s was not disabled", name)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": msg,
+		"data":    gin.H{"source": name, "enabled": enabled},
+	})
+}
+
+func (s *Server) handleJob(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid job id",
+		})
+		return
+	}
+
+	job, err := s.svc.GetJob(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "job not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("%s: %d of %d", job.Status, job.Processed, job.Total),
+		"data":    job,
+	})
+}
+
+func (s *Server) handlePreviewFeed(c *gin.Context) {
+	feedURL := c.Query("url")
+	if feedURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "url is required",
+		})
+		return
+	}
+	limit := 20
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	var fields *fetcher.FieldMapping
+	if c.Query("category_field") != "" || c.Query("tags_field") != "" {
+		fields = &fetcher.FieldMapping{Category: c.Query("category_field"), Tags: c.Query("tags_field")}
+	}
+
+	preview, err := s.svc.PreviewFeed(feedURL, limit, fields)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, httpclient.ErrBlockedURL) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("%d of %d items", len(preview.Items), preview.Total),
+		"data":    preview,
+	})
+}
+
 func (s *Server) handleArticles(c *gin.Context) {
 	limit := 20
 	if l := c.Query("limit"); l != "" {
@@ -242,7 +552,17 @@ func (s *Server) handleArticles(c *gin.Context) {
 		}
 	}
 
-	articles, err := s.store.GetRecentArticles(limit)
+	var articles []*models.Article
+	var err error
+	if name := c.Query("translator"); name != "" {
+		// Filter by translator provider, e.g. ?translator=libretranslate,
+		// combined with ?status= when set
+		articles, err = s.store.GetArticlesByTranslator(name, c.Query("status"), limit)
+	} else {
+		// ?status=untranslated|translated|unpublished|published or an exact
+		// pipeline status (new|scraped|errored|stub); default: all
+		articles, err = s.store.GetArticlesByStatus(c.Query("status"), limit)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -295,16 +615,337 @@ func (s *Server) handleArticle(c *gin.Context) {
 	}
 
 	article, err := s.store.GetArticleByID(id)
-	if err != nil {
+	if errors.Is(err, sql.ErrNoRows) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"success": false,
 			"error":   "article not found",
 		})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	sameSource := c.Query("same_source") == "true"
+	prev, next, err := s.store.GetAdjacentArticles(article, sameSource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    article,
+		"prev":    prev,
+		"next":    next,
+	})
+}
+
+// articleUpdateRequest is the PUT /api/article/:id body; omitted fields keep
+// their current value
+type articleUpdateRequest struct {
+	Category *string  `json:"category"`
+	Tags     []string `json:"tags"`
+}
+
+func (s *Server) handleArticleUpdate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid article id",
+		})
+		return
+	}
+
+	var req articleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+	if req.Category == nil && req.Tags == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "nothing to update: set category and/or tags",
+		})
+		return
+	}
+
+	article, err := s.store.GetArticleByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "article not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if req.Category != nil {
+		article.Category = strings.TrimSpace(*req.Category)
+	}
+	if req.Tags != nil {
+		article.Tags = req.Tags
+	}
+
+	if err := s.store.SetTaxonomy(id, article.Category, article.Tags); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("Article %d taxonomy updated", id),
+		"data":    gin.H{"id": id, "category": article.Category, "tags": article.Tags},
+	})
+}
+
+func (s *Server) handleArticleFeatured(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid article id",
+		})
+		return
+	}
+
+	featured := c.Query("featured") != "false"
+	if err := s.store.SetFeatured(id, featured); err != nil {
+		status := http.StatusInternalServerError
+		msg := err.Error()
+		if errors.Is(err, sql.ErrNoRows) {
+			status = http.StatusNotFound
+			msg = "article not found"
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   msg,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("Article %d featured: %t", id, featured),
+		"data":    gin.H{"id": id, "featured": featured},
+	})
+}
+
+func (s *Server) handleArticleReprocess(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid article id",
+		})
+		return
+	}
+
+	result, err := s.svc.Reprocess(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		msg := err.Error()
+		if errors.Is(err, sql.ErrNoRows) {
+			status = http.StatusNotFound
+			msg = "article not found"
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   msg,
+		})
+		return
+	}
+
+	steps := make([]string, 0, len(result.Steps))
+	for _, st := range result.Steps {
+		steps = append(steps, st.Step+" "+st.Status)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("Article %d reprocessed: %s", id, strings.Join(steps, ", ")),
+		"data":    result,
+	})
+}
+
+// translateTestMaxBody caps the POST /api/translate-test body; the text
+// itself is capped by service.MaxTranslateTestChars
+const translateTestMaxBody = 256 << 10
+
+func (s *Server) handleTranslateTest(c *gin.Context) {
+	if !s.cfg.Server.TranslateTest {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "translate-test is disabled (server.translate_test)",
+		})
+		return
+	}
+	if ok, wait := s.translateTestLimit.allow(time.Now()); !ok {
+		c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("translate-test rate limit reached (%d per minute)", s.cfg.Server.TranslateTestPerMinute),
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, translateTestMaxBody)
+	var req service.TranslateTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := s.svc.TranslateTest(c.Request.Context(), req)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, service.ErrTranslateTestInput) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("Translated %d characters with %s in %d ms", result.InputChars, result.Provider, result.DurationMs),
+		"data":    result,
+	})
+}
+
+// articlesReviewRequest is the POST /api/articles/review body; reviewed
+// defaults to true
+type articlesReviewRequest struct {
+	IDs      []int64 `json:"ids"`
+	Reviewed *bool   `json:"reviewed"`
+}
+
+func (s *Server) handleArticlesReview(c *gin.Context) {
+	var req articlesReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+	if len(req.IDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "ids must not be empty",
+		})
+		return
+	}
+	reviewed := req.Reviewed == nil || *req.Reviewed
+
+	updated, err := s.store.SetReviewed(req.IDs, reviewed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("%d of %d articles reviewed: %t", updated, len(req.IDs), reviewed),
+		"data":    gin.H{"ids": req.IDs, "reviewed": reviewed, "updated": updated},
+	})
+}
+
+func (s *Server) handleArticleRawContent(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid article id",
+		})
+		return
+	}
+
+	view, err := s.svc.RawContent(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "article not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	msg := fmt.Sprintf("Raw: %d chars, content: %d chars", view.RawChars, view.Chars)
+	if !view.HasRaw {
+		msg = "No raw content stored (scraped before it was kept, or scraper.keep_raw_content is off)"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": msg,
+		"data":    view,
+	})
+}
+
+func (s *Server) handleArticleRawHTML(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid article id",
+		})
+		return
+	}
+
+	debug, err := s.svc.ScrapeDebug(id)
+	if err != nil {
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			status = http.StatusNotFound
+		case errors.Is(err, httpclient.ErrBlockedURL):
+			status = http.StatusBadRequest
+		case errors.Is(err, fetcher.ErrNotHTML):
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("Strategy: %s, content: %d chars", debug.Strategy, debug.ContentLength),
+		"data":    debug,
 	})
 }
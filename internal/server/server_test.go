@@ -0,0 +1,281 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+	"moto-news/internal/storage"
+)
+
+func newTestServer(t *testing.T, cfg *config.Config) *Server {
+	t.Helper()
+	st, err := storage.NewSQLiteStorage(&config.DatabaseConfig{Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return New(cfg, st)
+}
+
+func postJSON(s *Server, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTranslateTestDisabledByDefault(t *testing.T) {
+	s := newTestServer(t, &config.Config{})
+	if w := postJSON(s, "/api/translate-test", `{"text":"hello"}`); w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body)
+	}
+}
+
+func TestTranslateTestRateLimit(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.TranslateTest = true
+	cfg.Server.TranslateTestPerMinute = 2
+	s := newTestServer(t, cfg)
+
+	for i := 0; i < 2; i++ {
+		// an empty text is refused by the service, after the limiter
+		if w := postJSON(s, "/api/translate-test", `{"text":""}`); w.Code != http.StatusBadRequest {
+			t.Fatalf("call %d: status = %d, want %d: %s", i+1, w.Code, http.StatusBadRequest, w.Body)
+		}
+	}
+	w := postJSON(s, "/api/translate-test", `{"text":""}`)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third call: status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+}
+
+func getJSON(s *Server, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestArticleAdjacentLinks(t *testing.T) {
+	s := newTestServer(t, &config.Config{})
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	insert := func(url, site string, hours int) *models.Article {
+		a := &models.Article{SourceURL: url, SourceSite: site, Title: "Title " + url, Slug: filepath.Base(url),
+			PublishedAt: base.Add(time.Duration(hours) * time.Hour), FetchedAt: base}
+		if err := s.store.InsertArticle(a); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	first := insert("https://example.com/first", "example.com", 0)
+	other := insert("https://other.com/middle", "other.com", 1)
+	last := insert("https://example.com/last", "example.com", 2)
+
+	// body keeps prev and next raw too, to tell null from a missing key
+	type body struct {
+		Data       models.Article
+		Prev, Next *models.ArticleLink
+		raw        map[string]json.RawMessage
+	}
+	get := func(path string) body {
+		t.Helper()
+		w := getJSON(s, path)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d: %s", path, w.Code, w.Body)
+		}
+		var b body
+		if err := json.Unmarshal(w.Body.Bytes(), &b); err != nil {
+			t.Fatal(err)
+		}
+		json.Unmarshal(w.Body.Bytes(), &b.raw)
+		return b
+	}
+
+	b := get(fmt.Sprintf("/api/article/%d", first.ID))
+	if b.Data.ID != first.ID || string(b.raw["prev"]) != "null" || b.Next == nil || b.Next.ID != other.ID || b.Next.Slug != "middle" {
+		t.Errorf("first article: data=#%d prev=%s next=%+v, want a null prev and #%d next", b.Data.ID, b.raw["prev"], b.Next, other.ID)
+	}
+	b = get(fmt.Sprintf("/api/article/%d", last.ID))
+	if b.Prev == nil || b.Prev.ID != other.ID || string(b.raw["next"]) != "null" {
+		t.Errorf("last article: prev=%+v next=%s, want #%d prev and a null next", b.Prev, b.raw["next"], other.ID)
+	}
+	b = get(fmt.Sprintf("/api/article/%d?same_source=true", first.ID))
+	if b.Next == nil || b.Next.ID != last.ID {
+		t.Errorf("same source: next=%+v, want #%d", b.Next, last.ID)
+	}
+
+	if w := getJSON(s, "/api/article/999"); w.Code != http.StatusNotFound {
+		t.Errorf("missing article: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := getJSON(s, "/api/article/x"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid id: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestFetchSingleSource(t *testing.T) {
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// one item per feed path, its page missing: saved unscraped
+		if strings.HasSuffix(r.URL.Path, "/item") {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>
+<item><title>Item</title><link>http://%s%s/item</link></item></channel></rss>`, r.Host, r.URL.Path)
+	}))
+	defer feed.Close()
+	cfg := &config.Config{}
+	cfg.Scraper.URLPolicy = "scheme"
+	for _, name := range []string{"one", "two", "off"} {
+		cfg.Sources = append(cfg.Sources, config.SourceConfig{Name: name, Feeds: []string{feed.URL + "/" + name}, Enabled: name != "off"})
+	}
+	s := newTestServer(t, cfg)
+
+	if w := postJSON(s, "/api/fetch?source=two", ""); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	for _, name := range []string{"one", "two"} {
+		_, err := s.store.GetArticleByURL(feed.URL + "/" + name + "/item")
+		if fetched := err == nil; fetched != (name == "two") {
+			t.Errorf("source %s fetched = %v (%v)", name, fetched, err)
+		}
+	}
+
+	if w := postJSON(s, "/api/fetch?source=nope", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown source: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := postJSON(s, "/api/fetch?source=off", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("disabled source: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestETagNotModified(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.ETag = true
+	s := newTestServer(t, cfg)
+	insert := func(url string) {
+		a := &models.Article{SourceURL: url, SourceSite: "example.com", Title: "Title", Slug: filepath.Base(url),
+			PublishedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+		if err := s.store.InsertArticle(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/articles", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		return w
+	}
+	insert("https://example.com/first")
+
+	w := get("")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag = %q: want 200 with an ETag", w.Code, etag)
+	}
+	w = get(etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("unchanged: status = %d, body %d bytes, want an empty 304", w.Code, w.Body.Len())
+	}
+	// the strong form of the same tag matches too
+	if w = get(strings.TrimPrefix(etag, "W/")); w.Code != http.StatusNotModified {
+		t.Errorf("strong tag: status = %d, want %d", w.Code, http.StatusNotModified)
+	}
+
+	insert("https://example.com/second")
+	w = get(etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("changed: status = %d, ETag = %q, want 200 with a new ETag", w.Code, w.Header().Get("ETag"))
+	}
+
+	if w := postJSON(s, "/api/fetch?source=nope", ""); w.Header().Get("ETag") != "" {
+		t.Errorf("POST got ETag %q", w.Header().Get("ETag"))
+	}
+}
+
+func TestSignatureETag(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.ETag = true
+	s := newTestServer(t, cfg)
+	a := &models.Article{SourceURL: "https://example.com/a", SourceSite: "example.com", Title: "A", Slug: "a",
+		PublishedAt: time.Now(), FetchedAt: time.Now()}
+	if err := s.store.InsertArticle(a); err != nil {
+		t.Fatal(err)
+	}
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/api/articles", "/api/stats"} {
+		etag := getJSON(s, path).Header().Get("ETag")
+		if etag == "" {
+			t.Fatalf("%s: no ETag", path)
+		}
+		if w := get(path, etag); w.Code != http.StatusNotModified {
+			t.Errorf("%s unchanged: status = %d, want %d", path, w.Code, http.StatusNotModified)
+		}
+		if w := get(path+"?limit=5", etag); w.Code != http.StatusOK {
+			t.Errorf("%s with other parameters: status = %d, want %d", path, w.Code, http.StatusOK)
+		}
+
+		// an edit changes neither the count nor the highest ID
+		a.TitleRU = "Заголовок " + path
+		if err := s.store.UpdateArticle(a); err != nil {
+			t.Fatal(err)
+		}
+		if w := get(path, etag); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+			t.Errorf("%s after an edit: status = %d, ETag = %q, want 200 with a new ETag", path, w.Code, w.Header().Get("ETag"))
+		}
+	}
+
+	// a failing request gets no ETag
+	if w := getJSON(s, "/api/articles?status=bogus"); w.Code == http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Errorf("bad status: code = %d, ETag = %q, want an error without ETag", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestArticleNotFoundVsDatabaseError(t *testing.T) {
+	s := newTestServer(t, &config.Config{})
+	a := &models.Article{SourceURL: "https://example.com/a", SourceSite: "example.com", Title: "A", Slug: "a",
+		PublishedAt: time.Now(), FetchedAt: time.Now()}
+	if err := s.store.InsertArticle(a); err != nil {
+		t.Fatal(err)
+	}
+	path := fmt.Sprintf("/api/article/%d", a.ID)
+	if w := getJSON(s, path); w.Code != http.StatusOK {
+		t.Fatalf("existing article: status = %d: %s", w.Code, w.Body)
+	}
+	if w := getJSON(s, fmt.Sprintf("/api/article/%d", a.ID+1)); w.Code != http.StatusNotFound {
+		t.Errorf("missing article: status = %d, want %d: %s", w.Code, http.StatusNotFound, w.Body)
+	}
+
+	// a failing database is a server error, not a missing article
+	s.store.Close()
+	if w := getJSON(s, path); w.Code != http.StatusInternalServerError {
+		t.Errorf("closed database: status = %d, want %d: %s", w.Code, http.StatusInternalServerError, w.Body)
+	}
+	req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(`{"category":"News"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("closed database update: status = %d, want %d: %s", w.Code, http.StatusInternalServerError, w.Body)
+	}
+}
@@ -2,14 +2,29 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"moto-news/internal/config"
+	"moto-news/internal/events"
 	"moto-news/internal/fetcher"
+	"moto-news/internal/formatter"
+	"moto-news/internal/httpclient"
 	"moto-news/internal/models"
 	"moto-news/internal/publisher"
+	"moto-news/internal/slugify"
 	"moto-news/internal/storage"
+	"moto-news/internal/tracing"
 	"moto-news/internal/translator"
 )
 
@@ -20,463 +35,2819 @@ type Result struct {
 	Data    any    `json:"data,omitempty"`
 }
 
+// Per-article outcomes reported in result Articles lists
+const (
+	OutcomeSaved      = "saved"      // new article stored
+	OutcomeUpdated    = "updated"    // existing article refreshed from the source
+	OutcomeFiltered   = "filtered"   // dropped by exclude rules
+	OutcomeNotHTML    = "not_html"   // link leads to a PDF, image or other non-HTML response
+	OutcomeTranslated = "translated" // translation stored
+	OutcomePublished  = "published"  // written to the blog and marked published
+	OutcomeSkipped    = "skipped"    // held back, will be retried later
+	OutcomeFailed     = "failed"     // Error says why
+)
+
+// Auto-publish statuses after translate (TranslateResult.PublishStatus)
+const (
+	PublishDone    = "published" // every translated article went out
+	PublishPartial = "partial"   // some articles failed, see Articles[].publish_error
+	PublishFailed  = "failed"    // nothing went out, see PublishError
+)
+
+// ArticleOutcome is what an operation did with one article
+type ArticleOutcome struct {
+	ID      int64  `json:"id,omitempty"` // 0 for feed items that were never stored
+	Title   string `json:"title"`
+	Source  string `json:"source,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+	// Translate only: what the auto-publish did with a translated article
+	// (published, skipped or failed) and why it failed
+	Publish      string `json:"publish,omitempty"`
+	PublishError string `json:"publish_error,omitempty"`
+}
+
+// articleOutcome describes article's outcome; err may be nil
+func articleOutcome(article *models.Article, outcome string, err error) ArticleOutcome {
+	o := ArticleOutcome{
+		ID: article.ID, Title: article.Title, Source: article.SourceSite, URL: article.SourceURL, Outcome: outcome,
+	}
+	if err != nil {
+		o.Error = err.Error()
+	}
+	return o
+}
+
+// setPublishOutcomes records the outcome of the auto-publish (published,
+// skipped or failed, see publishArticles) in the translate outcomes of the
+// same articles
+func setPublishOutcomes(outcomes, published []ArticleOutcome) {
+	byID := make(map[int64]ArticleOutcome, len(published))
+	for _, p := range published {
+		byID[p.ID] = p
+	}
+	for i := range outcomes {
+		if p, ok := byID[outcomes[i].ID]; ok {
+			outcomes[i].Publish = p.Outcome
+			outcomes[i].PublishError = p.Error
+		}
+	}
+}
+
 // FetchResult holds fetch operation results
 type FetchResult struct {
-	NewArticles     int      `json:"new_articles"`
-	SkippedArticles int      `json:"skipped_articles"`
-	Errors          int      `json:"errors"`
-	Log             []string `json:"log,omitempty"` // per-item progress for API/detailed logs
+	NewArticles     int                  `json:"new_articles"`
+	SkippedArticles int                  `json:"skipped_articles"`
+	UpdatedArticles int                  `json:"updated_articles,omitempty"` // existing articles refreshed from the source (sources[].update_existing)
+	Filtered        int                  `json:"filtered"`                   // items dropped by exclude / sources[].exclude
+	NotHTML         int                  `json:"not_html,omitempty"`         // items whose link is not an HTML page, skipped
+	Errors          int                  `json:"errors"`
+	CapReached      bool                 `json:"cap_reached,omitempty"` // schedule.max_new_per_run hit; more articles remain in feeds
+	FeedsTotal      int                  `json:"feeds_total"`
+	FeedsFailed     int                  `json:"feeds_failed"`
+	FeedResults     []fetcher.FeedResult `json:"feed_results"`       // per-feed url/count/error
+	Disabled        []string             `json:"disabled,omitempty"` // sources disabled by this fetch (schedule.disable_after_failures)
+	Articles        []ArticleOutcome     `json:"articles,omitempty"` // stored, updated, filtered and failed items
+	Log             []string             `json:"log,omitempty"`      // per-item progress for API/detailed logs
+}
+
+// FailedFeeds returns the feeds that could not be fetched
+func (r *FetchResult) FailedFeeds() []fetcher.FeedResult {
+	var failed []fetcher.FeedResult
+	for _, fr := range r.FeedResults {
+		if fr.Error != "" {
+			failed = append(failed, fr)
+		}
+	}
+	return failed
+}
+
+// FetchPreviewItem is a new article a fetch would ingest
+type FetchPreviewItem struct {
+	Source      string    `json:"source"`
+	Feed        string    `json:"feed"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// FetchPreviewResult holds dry-run fetch results
+type FetchPreviewResult struct {
+	New         []FetchPreviewItem   `json:"new"`
+	Existing    int                  `json:"existing"`
+	Filtered    int                  `json:"filtered"` // items that exclude rules would drop
+	FeedResults []fetcher.FeedResult `json:"feed_results"`
+}
+
+// FeedPreviewItem is how one item of an arbitrary feed maps to an Article
+type FeedPreviewItem struct {
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+	Author      string    `json:"author,omitempty"`
+	ImageURL    string    `json:"image_url,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Slug        string    `json:"slug"`
+}
+
+// FeedPreviewResult is a parsed feed that is not configured as a source
+type FeedPreviewResult struct {
+	URL   string            `json:"url"`
+	Total int               `json:"total"` // items in the feed; Items holds at most the requested limit
+	Items []FeedPreviewItem `json:"items"`
 }
 
 // TranslatedArticleSummary is one article translated in this batch (for API response)
 type TranslatedArticleSummary struct {
 	ID      int64  `json:"id"`
 	Title   string `json:"title"`    // original (EN)
-	TitleRU string `json:"title_ru"`  // translated title
+	TitleRU string `json:"title_ru"` // translated title
 }
 
 // TranslateResult holds translate operation results
 type TranslateResult struct {
-	Translated         int                      `json:"translated"`
-	Total              int                      `json:"total"`
-	Errors             int                      `json:"errors"`
-	LastError          string                   `json:"last_error,omitempty"`
-	PublishedThisBatch int                      `json:"published_this_batch,omitempty"`
+	Translated         int                        `json:"translated"`
+	Total              int                        `json:"total"`
+	Errors             int                        `json:"errors"`
+	LastError          string                     `json:"last_error,omitempty"`
+	PublishedThisBatch int                        `json:"published_this_batch,omitempty"`
+	PublishSkipped     int                        `json:"publish_skipped,omitempty"` // translated but held back by hugo.require_full_translation, hugo.require_review or schedule.publish_delay
+	PublishStatus      string                     `json:"publish_status,omitempty"`  // published, partial or failed; "" = nothing to publish
+	PublishError       string                     `json:"publish_error,omitempty"`
+	PublishAttempts    int                        `json:"publish_attempts,omitempty"` // GitHub API attempts, see hugo.publish_retries
+	PublishCommits     int                        `json:"publish_commits,omitempty"`  // GitHub commits created, see hugo.max_files_per_commit
+	TranslatedChars    int64                      `json:"translated_chars"`
 	TranslatedArticles []TranslatedArticleSummary `json:"translated_articles,omitempty"` // list of articles translated in this run
-	Log                []string                 `json:"log,omitempty"`
+	JobID              int64                      `json:"job_id,omitempty"`              // progress record, see GET /api/jobs/:id
+	Articles           []ArticleOutcome           `json:"articles,omitempty"`            // translated and failed articles
+	Log                []string                   `json:"log,omitempty"`
 }
 
 // PublishResult holds publish operation results
 type PublishResult struct {
-	Published int      `json:"published"`
-	Total     int      `json:"total"`
-	Errors    int      `json:"errors"`
-	Log       []string `json:"log,omitempty"`
+	Published  int              `json:"published"`
+	Total      int              `json:"total"`
+	Errors     int              `json:"errors"`
+	CapReached bool             `json:"cap_reached,omitempty"` // the batch was full at schedule.max_publish_per_run; more may be waiting
+	Refreshed  int              `json:"refreshed,omitempty"`   // already published articles re-rendered (publish --refresh)
+	Skipped    int              `json:"skipped,omitempty"`     // held back by hugo.require_full_translation, hugo.require_review or schedule.publish_delay
+	Commits    int              `json:"commits,omitempty"`     // GitHub API commits created, see hugo.max_files_per_commit
+	Attempts   int              `json:"attempts,omitempty"`    // GitHub API attempts, see hugo.publish_retries
+	Articles   []ArticleOutcome `json:"articles,omitempty"`    // published, skipped and failed articles
+	Log        []string         `json:"log,omitempty"`
 }
 
 // RescrapeResult holds rescrape operation results
 type RescrapeResult struct {
-	Rescraped int `json:"rescraped"`
-	Total     int `json:"total"`
-	Errors    int `json:"errors"`
+	Rescraped         int               `json:"rescraped"`
+	Unchanged         int               `json:"unchanged"` // scraped fine but got no more content than before
+	Total             int               `json:"total"`
+	Errors            int               `json:"errors"`
+	PermanentlyFailed []RescrapeFailure `json:"permanently_failed,omitempty"` // reached max_rescrape_attempts, need manual review
+}
+
+// RecheckResult holds the results of RecheckContent
+type RecheckResult struct {
+	Total      int     `json:"total"`   // articles due for a re-check
+	Changed    int     `json:"changed"` // changed at the source, queued for re-translation
+	Minor      int     `json:"minor"`   // changed by less than scraper.update_min_change, not taken
+	Unchanged  int     `json:"unchanged"`
+	Errors     int     `json:"errors"`
+	ChangedIDs []int64 `json:"changed_ids,omitempty"`
+}
+
+// CleanContentResult holds clean-content results
+type CleanContentResult struct {
+	Checked  int                  `json:"checked"`
+	Changed  int                  `json:"changed"`
+	NoRaw    int                  `json:"no_raw,omitempty"` // selected by id but scraped before raw content was kept
+	Requeued bool                 `json:"requeued,omitempty"`
+	DryRun   bool                 `json:"dry_run,omitempty"`
+	Articles []CleanContentChange `json:"articles,omitempty"`
+}
+
+// CleanContentChange is an article whose content the cleanup pass changed
+type CleanContentChange struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	Before int    `json:"before"` // content length, chars
+	After  int    `json:"after"`
+}
+
+// RawContentView is an article's stored raw text next to its cleaned content
+// and what the current cleanup rules make of the raw text
+type RawContentView struct {
+	ID         int64  `json:"id"`
+	Title      string `json:"title"`
+	HasRaw     bool   `json:"has_raw"` // false for articles scraped before raw content was kept
+	RawContent string `json:"raw_content"`
+	Content    string `json:"content"`   // the cleaned working copy in the DB
+	Recleaned  string `json:"recleaned"` // RawContent cleaned with the current rules
+	Changed    bool   `json:"changed"`   // clean-content would replace Content with Recleaned
+	RawChars   int    `json:"raw_chars"`
+	Chars      int    `json:"chars"`
+}
+
+// RescrapeFailure is an article that gave up on rescraping
+type RescrapeFailure struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Attempts int    `json:"attempts"`
+}
+
+// RegenerateResult holds regenerate (offline export) results
+type RegenerateResult struct {
+	Written    int    `json:"written"`
+	Total      int    `json:"total"`
+	Errors     int    `json:"errors"`
+	OutDir     string `json:"out_dir"`
+	IndexPath  string `json:"index_path,omitempty"`  // main index page
+	IndexPages int    `json:"index_pages,omitempty"` // index files written, including yearly archives
+}
+
+// PruneResult holds prune (retention) results
+type PruneResult struct {
+	Cutoff       time.Time `json:"cutoff"`
+	Deleted      int       `json:"deleted"`
+	FilesDeleted int       `json:"files_deleted"`
+	Log          []string  `json:"log"`
+}
+
+// VerifyResult holds verify-published results
+type VerifyResult struct {
+	Checked int              `json:"checked"`
+	Target  string           `json:"target"` // "github" or "local"
+	Missing []MissingArticle `json:"missing"`
+	Reset   int              `json:"reset"`
+}
+
+// MissingArticle is a published article whose file is not in the blog repo
+type MissingArticle struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	Path  string `json:"path"`
 }
 
 // StatsResult holds stats
 type StatsResult struct {
-	Total      int `json:"total"`
-	Translated int `json:"translated"`
-	Published  int `json:"published"`
-	Pending    int `json:"pending_translation"`
+	Total       int `json:"total"`
+	Translated  int `json:"translated"`
+	Published   int `json:"published"`
+	Pending     int `json:"pending_translation"`
 	Unpublished int `json:"pending_publishing"`
+
+	// Characters sent to the translator, counted in runes as DeepL bills them
+	TranslatedChars      int64      `json:"translated_chars"`
+	TranslatedCharsMonth int64      `json:"translated_chars_month"` // current calendar month (UTC)
+	TranslatedCharsSince *time.Time `json:"translated_chars_since,omitempty"`
+
+	Sources []SourceStatus `json:"sources,omitempty"`
+}
+
+// SourceStatus is a configured source with its fetch watermark
+type SourceStatus struct {
+	Name          string     `json:"name"`
+	Enabled       bool       `json:"enabled"`
+	Priority      int        `json:"priority,omitempty"` // sources[].priority
+	Feeds         []string   `json:"feeds"`              // credentials redacted
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	LastNew       int        `json:"last_new"`  // new articles saved by the last fetch
+	TotalNew      int64      `json:"total_new"` // new articles since tracking began
+	RecentNew     int        `json:"new_24h"`   // articles fetched in the last 24 hours
+
+	ConsecutiveFailures int        `json:"consecutive_failures"` // fetches in a row in which every feed failed
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	AutoDisabled        bool       `json:"auto_disabled"` // skipped by fetch until EnableSource
+	AutoDisabledAt      *time.Time `json:"auto_disabled_at,omitempty"`
+}
+
+// SourceFeedCheck is the feed health of one enabled source
+type SourceFeedCheck struct {
+	Name          string              `json:"name"`
+	Critical      bool                `json:"critical,omitempty"`
+	Feeds         []fetcher.FeedCheck `json:"feeds"`
+	Down          bool                `json:"down"` // no feed of the source works
+	LastFetchedAt *time.Time          `json:"last_fetched_at,omitempty"`
+	RecentNew     int                 `json:"recent_new"` // articles fetched within the stale window
+	Stale         bool                `json:"stale"`      // fetched during the window, but nothing new
+}
+
+// CheckFeedsResult holds check-feeds results
+type CheckFeedsResult struct {
+	StaleDays    int               `json:"stale_days"`
+	Sources      []SourceFeedCheck `json:"sources"`
+	FeedsDown    int               `json:"feeds_down"`
+	StaleFeeds   int               `json:"stale_feeds"` // working feeds whose newest item is older than the window
+	CriticalDown []string          `json:"critical_down,omitempty"`
 }
 
 // PipelineResult holds results from a full pipeline run
 type PipelineResult struct {
 	Fetch     *FetchResult     `json:"fetch"`
+	Recheck   *RecheckResult   `json:"recheck,omitempty"` // scraper.recheck_days > 0
 	Translate *TranslateResult `json:"translate"`
 	Publish   *PublishResult   `json:"publish"`
 }
 
 // Service provides all business logic operations
 type Service struct {
-	cfg   *config.Config
-	store *storage.SQLiteStorage
+	cfg      *config.Config
+	store    *storage.SQLiteStorage
+	events   *events.Bus
+	progress Progress
+
+	autoTagger       *formatter.AutoTagger // hugo.formatter.auto_tags
+	translators      translatorCache
+	translatorStatus translatorStatusCache
 }
 
+// Progress receives human-readable progress lines (printf-style, newline
+// included) while an operation runs. It may be called from several
+// goroutines at once.
+type Progress func(format string, args ...any)
+
 // NewService creates a new service instance
 func NewService(cfg *config.Config, store *storage.SQLiteStorage) *Service {
 	return &Service{
-		cfg:   cfg,
-		store: store,
+		cfg:        cfg,
+		store:      store,
+		events:     events.NewBus(),
+		autoTagger: formatter.NewAutoTagger(cfg.Hugo.Formatter.AutoTags),
+	}
+}
+
+// SetProgress sets where progress lines go; nil (the default) discards
+// them. The CLI prints them, the server relies on results and events.
+func (s *Service) SetProgress(p Progress) {
+	s.progress = p
+}
+
+// printf reports progress to the Progress callback, if any
+func (s *Service) printf(format string, args ...any) {
+	if s.progress != nil {
+		s.progress(format, args...)
 	}
 }
 
-// Fetch fetches new articles from RSS feeds
-func (s *Service) Fetch() (*FetchResult, error) {
-	rssFetcher := fetcher.NewRSSFetcher()
-	scraper := fetcher.NewArticleScraper()
+// Events returns the bus that live progress of fetch, translate and
+// publish is sent to (see GET /api/events)
+func (s *Service) Events() *events.Bus {
+	return s.events
+}
 
-	result := &FetchResult{Log: []string{}}
+// Errors for a fetch limited to one source
+var (
+	ErrUnknownSource  = errors.New("unknown source")
+	ErrSourceDisabled = errors.New("source is disabled")
+)
 
+// fetchSources returns the enabled sources, or only the named one when name
+// is set (ErrUnknownSource / ErrSourceDisabled when it cannot be fetched).
+// Sources disabled after repeated failures are left out until EnableSource.
+// The rest come in sources[].priority order, highest first.
+func (s *Service) fetchSources(name string) ([]config.SourceConfig, error) {
+	disabled, err := s.store.GetDisabledSources()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disabled sources: %w", err)
+	}
+	if name == "" {
+		var enabled []config.SourceConfig
+		for _, source := range s.cfg.Sources {
+			if !source.Enabled {
+				continue
+			}
+			if disabled[source.Name] {
+				s.printf("Skipping %s: disabled after repeated failures (enable-source to re-enable)\n", source.Name)
+				continue
+			}
+			enabled = append(enabled, source)
+		}
+		sort.SliceStable(enabled, func(i, j int) bool { return enabled[i].Priority > enabled[j].Priority })
+		return enabled, nil
+	}
 	for _, source := range s.cfg.Sources {
-		if !source.Enabled {
+		if source.Name != name {
 			continue
 		}
+		if !source.Enabled {
+			return nil, fmt.Errorf("%w: %s", ErrSourceDisabled, name)
+		}
+		if disabled[name] {
+			return nil, fmt.Errorf("%w: %s (after repeated failures; re-enable it first)", ErrSourceDisabled, name)
+		}
+		return []config.SourceConfig{source}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownSource, name)
+}
+
+// newScraper returns a scraper for article pages. Unless scraper.url_policy
+// is "scheme" its requests go through httpclient.GuardedTransport, which
+// checks the address again when connecting: a host cannot pass the
+// scraper's URL check with a public address and then resolve to an
+// internal one.
+func (s *Service) newScraper(cfg *config.ScraperConfig) *fetcher.ArticleScraper {
+	if cfg.URLPolicy == fetcher.URLPolicyScheme {
+		return fetcher.NewArticleScraper(cfg, httpclient.Transport(&s.cfg.Network, httpclient.DestScraper))
+	}
+	return fetcher.NewArticleScraper(cfg, httpclient.GuardedTransport(&s.cfg.Network, httpclient.DestScraper))
+}
+
+// Fetch fetches new articles from RSS feeds of all enabled sources, or only
+// of sourceName when it is set
+func (s *Service) Fetch(sourceName string) (*FetchResult, error) {
+	sources, err := s.fetchSources(sourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := tracing.Start(context.Background(), "fetch")
+	defer span.End()
+	if sourceName != "" {
+		span.SetAttr("source", sourceName)
+	}
+
+	rssFetcher := fetcher.NewRSSFetcher(httpclient.Transport(&s.cfg.Network, httpclient.DestFeeds))
+	rssFetcher.SetSlugMaxLength(s.cfg.Hugo.SlugMaxLength)
+	rssFetcher.SetRetries(s.cfg.Schedule.FeedRetries, time.Duration(s.cfg.Schedule.FeedRetryDelaySec)*time.Second)
+	scraper := s.newScraper(&s.cfg.Scraper)
+
+	result := &FetchResult{Log: []string{}, FeedResults: []fetcher.FeedResult{}}
+	maxNew := s.cfg.Schedule.MaxNewPerRun
+	s.events.Publish(events.Event{Type: events.StepStarted, Step: "fetch", Source: sourceName})
+
+	// New items are scraped (or taken from the feed content) and saved after
+	// all feeds are read, concurrently across hosts. A link listed by several
+	// sources is saved once, for the first (highest-priority) one; so is a
+	// story whose titles are near-duplicates (schedule.dedup_title_similarity).
+	var pending []pendingScrape
+	pendingBy := make(map[string]string) // source URL -> source it is pending for
+	dups, err := s.loadNearDuplicates()
+	if err != nil {
+		return nil, err
+	}
+	var fetched []string
+	newBySource := make(map[string]int)
 
+sources:
+	for _, source := range sources {
 		result.Log = append(result.Log, "source: "+source.Name)
-		articles, err := rssFetcher.FetchMultipleFeeds(source.Feeds, source.Name)
+		articles, feedResults, err := rssFetcher.FetchMultipleFeeds(ctx, source.Feeds, source.Name, feedAuth(source), feedFields(source))
+		for _, fr := range feedResults {
+			result.FeedsTotal++
+			for i, retryErr := range fr.Retried {
+				result.Log = append(result.Log, fmt.Sprintf("  feed %s: attempt %d failed, retried: %s", fr.URL, i+1, retryErr))
+				s.printf("Warning: attempt %d for %s failed: %s (retried)\n", i+1, fr.URL, retryErr)
+			}
+			if fr.Error != "" {
+				result.FeedsFailed++
+				s.printf("Warning: failed to fetch %s: %s\n", fr.URL, fr.Error)
+				if fr.Attempts > 1 {
+					result.Log = append(result.Log, fmt.Sprintf("  feed FAILED after %d attempts: %s: %s", fr.Attempts, fr.URL, fr.Error))
+				} else {
+					result.Log = append(result.Log, fmt.Sprintf("  feed FAILED: %s: %s", fr.URL, fr.Error))
+				}
+				s.events.Publish(events.Event{Type: events.Error, Step: "fetch", Source: source.Name, Message: "feed " + fr.URL, Error: fr.Error})
+			} else if fr.Attempts > 1 {
+				result.Log = append(result.Log, fmt.Sprintf("  feed %s: ok on attempt %d", fr.URL, fr.Attempts))
+			}
+		}
+		result.FeedResults = append(result.FeedResults, feedResults...)
 		if err != nil {
 			result.Log = append(result.Log, fmt.Sprintf("  ERROR: %v", err))
-			fmt.Printf("Warning: error fetching %s: %v\n", source.Name, err)
+			s.printf("Warning: error fetching %s: %v\n", source.Name, err)
 			result.Errors++
+			s.recordSourceFailure(source.Name, err, result)
 			continue
 		}
 
 		result.Log = append(result.Log, fmt.Sprintf("  found %d articles", len(articles)))
-		fmt.Printf("Found %d articles in feed\n", len(articles))
+		s.printf("Found %d articles in feed\n", len(articles))
+		fetched = append(fetched, source.Name)
+		exclude := fetcher.NewExcludeFilter(s.cfg.Exclude, source.Exclude)
 		for i, article := range articles {
+			if entry := exclude.Match(article); entry != "" {
+				result.Filtered++
+				result.Articles = append(result.Articles, articleOutcome(article, OutcomeFiltered, fmt.Errorf("matched %s", entry)))
+				result.Log = append(result.Log, fmt.Sprintf("  [%d/%d] filtered (%s): %s", i+1, len(articles), entry, article.Title))
+				continue
+			}
+
 			exists, err := s.store.ArticleExists(article.SourceURL)
 			if err != nil {
 				result.Log = append(result.Log, fmt.Sprintf("  [%d/%d] error check: %v", i+1, len(articles), err))
-				fmt.Printf("  ✗ Error checking article: %v\n", err)
+				s.printf("  ✗ Error checking article: %v\n", err)
 				result.Errors++
+				result.Articles = append(result.Articles, articleOutcome(article, OutcomeFailed, err))
 				continue
 			}
 
+			if exists && source.UpdateExisting {
+				updated, err := s.updateExisting(scraper, source, article)
+				if err != nil {
+					result.Log = append(result.Log, fmt.Sprintf("  [%d/%d] error update: %v", i+1, len(articles), err))
+					s.printf("  ✗ Error updating %s: %v\n", article.Title, err)
+					result.Errors++
+					result.Articles = append(result.Articles, articleOutcome(article, OutcomeFailed, err))
+					continue
+				}
+				if updated {
+					result.UpdatedArticles++
+					result.Articles = append(result.Articles, articleOutcome(article, OutcomeUpdated, nil))
+					result.Log = append(result.Log, fmt.Sprintf("  [%d/%d] updated from source, queued for re-translation: %s", i+1, len(articles), article.Title))
+					s.printf("  [%d/%d] Updated from source: %s\n", i+1, len(articles), article.Title)
+					s.publishFetched(source.Name, article, i, len(articles), "updated from source")
+					continue
+				}
+			}
+
 			if exists {
 				result.SkippedArticles++
 				result.Log = append(result.Log, fmt.Sprintf("  [%d/%d] skipped: %s", i+1, len(articles), article.Title))
 				continue
 			}
-
-			fmt.Printf("  [%d/%d] Scraping: %s\n", i+1, len(articles), article.Title)
-			if err := scraper.ScrapeArticle(article); err != nil {
-				fmt.Printf("    ✗ Warning: failed to scrape: %v\n", err)
+			if owner, ok := pendingBy[article.SourceURL]; ok {
+				result.SkippedArticles++
+				result.Log = append(result.Log, fmt.Sprintf("  [%d/%d] skipped, already taken from %s: %s", i+1, len(articles), owner, article.Title))
+				continue
 			}
-
-			if err := s.store.InsertArticle(article); err != nil {
-				result.Log = append(result.Log, fmt.Sprintf("  [%d/%d] error save: %v", i+1, len(articles), err))
-				fmt.Printf("    ✗ Error saving article: %v\n", err)
-				result.Errors++
+			dup := dups.match(article, source.Name)
+			if dup != nil && !s.replacesNearDuplicate(dup, source) {
+				result.SkippedArticles++
+				result.Log = append(result.Log, fmt.Sprintf("  [%d/%d] skipped, near-duplicate of %q from %s: %s", i+1, len(articles), dup.title, dup.source, article.Title))
 				continue
 			}
 
-			result.NewArticles++
-			result.Log = append(result.Log, fmt.Sprintf("  [%d/%d] saved: %s", i+1, len(articles), article.Title))
-			fmt.Printf("    ✓ Saved\n")
+			if maxNew > 0 && result.NewArticles+len(pending) >= maxNew {
+				result.CapReached = true
+				result.Log = append(result.Log, fmt.Sprintf("  cap reached: max_new_per_run=%d, more articles available (next run will pick them up)", maxNew))
+				s.printf("  Cap reached (max_new_per_run=%d), more articles available — stopping fetch\n", maxNew)
+				break sources
+			}
+
+			var replaces int64
+			if dup != nil {
+				// The stored copy is deleted only once this item is saved
+				// (scrapeOne); for the rest of the fetch the pending item
+				// stands for the story
+				replaces = dup.stored.ID
+				dups.remove(dup)
+				result.Log = append(result.Log, fmt.Sprintf("  [%d/%d] to replace near-duplicate #%d %q from %s: %s", i+1, len(articles), dup.stored.ID, dup.title, dup.source, article.Title))
+			}
 
-			time.Sleep(1 * time.Second)
+			dups.add(article.Title, source.Name, nil)
+			pendingBy[article.SourceURL] = source.Name
+			pending = append(pending, pendingScrape{source: source.Name, article: article, i: i, n: len(articles), useFeedContent: source.UseFeedContent, replaces: replaces})
 		}
 	}
 
-	result.Log = append(result.Log, fmt.Sprintf("done: new=%d skipped=%d errors=%d", result.NewArticles, result.SkippedArticles, result.Errors))
-	fmt.Printf("\nDone! New: %d, Skipped: %d, Errors: %d\n", result.NewArticles, result.SkippedArticles, result.Errors)
+	s.scrapeNew(ctx, scraper, pending, result, newBySource)
+	for _, name := range fetched {
+		s.recordSourceFetch(name, newBySource[name])
+	}
+
+	result.Log = append(result.Log, fmt.Sprintf("done: new=%d updated=%d skipped=%d filtered=%d not_html=%d errors=%d feeds_failed=%d/%d", result.NewArticles, result.UpdatedArticles, result.SkippedArticles, result.Filtered, result.NotHTML, result.Errors, result.FeedsFailed, result.FeedsTotal))
+	s.events.Publish(events.Event{Type: events.StepFinished, Step: "fetch", Source: sourceName, Counts: map[string]int{
+		"new": result.NewArticles, "updated": result.UpdatedArticles, "skipped": result.SkippedArticles,
+		"filtered": result.Filtered, "not_html": result.NotHTML, "errors": result.Errors, "feeds_failed": result.FeedsFailed,
+	}})
+	span.SetAttr("articles.new", result.NewArticles)
+	span.SetAttr("articles.skipped", result.SkippedArticles)
+	span.SetAttr("errors", result.Errors)
+	s.printf("\nDone! New: %d, Skipped: %d, Errors: %d\n", result.NewArticles, result.SkippedArticles, result.Errors)
 
 	return result, nil
 }
 
-// Translate translates untranslated articles
-func (s *Service) Translate(limit int) (*TranslateResult, error) {
-	articles, err := s.store.GetUntranslatedArticles(limit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get articles: %w", err)
-	}
-
-	result := &TranslateResult{
-		Total: len(articles),
-		Log:   []string{},
-	}
+// nearDuplicates finds feed items whose title is close to that of another
+// source's article (schedule.dedup_title_similarity): the articles stored in
+// the last schedule.dedup_window_hours and the items pending in this fetch.
+// A nil *nearDuplicates (dedup off) matches nothing.
+type nearDuplicates struct {
+	threshold float64
+	entries   []*titleEntry
+}
 
-	if len(articles) == 0 {
-		return result, nil
-	}
+type titleEntry struct {
+	title  string
+	tokens map[string]bool
+	source string
+	stored *models.Article // nil for an item pending in this fetch
+}
 
-	trans, err := s.createTranslator()
-	if err != nil {
-		return nil, err
+// loadNearDuplicates returns the titles to compare new items with, or nil
+// when near-duplicate detection is off
+func (s *Service) loadNearDuplicates() (*nearDuplicates, error) {
+	threshold := s.cfg.Schedule.DedupTitleSimilarity
+	if threshold <= 0 {
+		return nil, nil
 	}
-
-	result.Log = append(result.Log, "translator: "+trans.Name())
-	result.Log = append(result.Log, fmt.Sprintf("articles to translate: %d", len(articles)))
-	fmt.Printf("Using translator: %s\n", trans.Name())
-	fmt.Printf("Articles to translate: %d\n\n", len(articles))
-
-	ctx := context.Background()
-	totalStart := time.Now()
-
-	// Collect translated articles for batch publish
-	var translatedArticles []*models.Article
-	n := len(articles)
-
-	for i, article := range articles {
-		articleStart := time.Now()
-		line := fmt.Sprintf("[%d/%d] %s", i+1, n, article.Title)
-		result.Log = append(result.Log, line)
-		fmt.Printf("[%d/%d] Translating: %s\n", i+1, n, article.Title)
-
-		titleRU, err := trans.TranslateTitle(ctx, article.Title)
+	d := &nearDuplicates{threshold: threshold}
+	if hours := s.cfg.Schedule.DedupWindowHours; hours > 0 {
+		recent, err := s.store.GetArticlesFetchedSince(time.Now().Add(-time.Duration(hours) * time.Hour))
 		if err != nil {
-			result.Log = append(result.Log, fmt.Sprintf("[%d/%d] ERROR (title): %s", i+1, n, err.Error()))
-			result.Errors++
-			result.LastError = err.Error()
-			fmt.Printf("  ✗ Error translating title: %v\n", err)
-			continue
+			return nil, fmt.Errorf("failed to load recent articles for near-duplicate detection: %w", err)
 		}
-		article.TitleRU = titleRU
-
-		if article.Content != "" {
-			contentRU, err := trans.Translate(ctx, article.Content)
-			if err != nil {
-				result.Log = append(result.Log, fmt.Sprintf("[%d/%d] ERROR (content): %s", i+1, n, err.Error()))
-				result.Errors++
-				result.LastError = err.Error()
-				fmt.Printf("  ✗ Error translating content: %v\n", err)
-				continue
-			}
-			article.ContentRU = contentRU
+		for _, article := range recent {
+			d.add(article.Title, article.SourceSite, article)
 		}
+	}
+	return d, nil
+}
 
-		now := time.Now()
-		article.TranslatedAt = &now
+func (d *nearDuplicates) add(title, source string, stored *models.Article) {
+	if d == nil {
+		return
+	}
+	d.entries = append(d.entries, &titleEntry{title: title, tokens: fetcher.TitleTokens(title), source: source, stored: stored})
+}
 
-		if err := s.store.UpdateArticle(article); err != nil {
-			result.Log = append(result.Log, fmt.Sprintf("[%d/%d] ERROR (save): %s", i+1, n, err.Error()))
-			result.Errors++
-			result.LastError = err.Error()
-			fmt.Printf("  ✗ Error saving translation: %v\n", err)
+// match returns the most similar entry of another source at or above the
+// threshold, or nil
+func (d *nearDuplicates) match(article *models.Article, source string) *titleEntry {
+	if d == nil {
+		return nil
+	}
+	tokens := fetcher.TitleTokens(article.Title)
+	var best *titleEntry
+	bestScore := d.threshold
+	for _, e := range d.entries {
+		if e.source == source {
 			continue
 		}
-
-		elapsed := time.Since(articleStart).Round(time.Second)
-		result.Translated++
-		result.TranslatedArticles = append(result.TranslatedArticles, TranslatedArticleSummary{
-			ID: article.ID, Title: article.Title, TitleRU: article.TitleRU,
-		})
-		okLine := fmt.Sprintf("[%d/%d] OK: %s (%s)", i+1, n, article.TitleRU, elapsed)
-		result.Log = append(result.Log, okLine)
-		fmt.Printf("  ✓ Перевод: %s (%s)\n", article.TitleRU, elapsed)
-
-		translatedArticles = append(translatedArticles, article)
+		if score := fetcher.TitleSimilarity(tokens, e.tokens); score >= bestScore {
+			best, bestScore = e, score
+		}
 	}
+	return best
+}
 
-	totalElapsed := time.Since(totalStart).Round(time.Second)
-	result.Log = append(result.Log, fmt.Sprintf("done: %d translated, %d errors, total time %s", result.Translated, result.Errors, totalElapsed))
-	fmt.Printf("\nTranslated %d of %d articles (errors: %d) in %s\n",
-		result.Translated, result.Total, result.Errors, totalElapsed)
+func (d *nearDuplicates) remove(entry *titleEntry) {
+	d.entries = slices.DeleteFunc(d.entries, func(e *titleEntry) bool { return e == entry })
+}
 
-	// Publish all translated articles (same request — so "Publish" step later will see 0 pending)
-	if len(translatedArticles) > 0 {
-		ghPub := publisher.NewGitHubPublisher(&s.cfg.Hugo)
-		if ghPub.IsAvailable() {
-			result.Log = append(result.Log, "publish (GitHub API): starting")
-			fmt.Printf("\nPublishing %d articles via GitHub API...\n", len(translatedArticles))
-			if err := ghPub.PublishMultiple(translatedArticles); err != nil {
-				result.Log = append(result.Log, fmt.Sprintf("publish ERROR: %v", err))
-				fmt.Printf("  ✗ GitHub publish error: %v\n", err)
-			} else {
-				for _, a := range translatedArticles {
-					a.PublishedToHugo = true
-					if err := s.store.UpdateArticle(a); err != nil {
-						fmt.Printf("  ✗ Error updating article status (id=%d): %v\n", a.ID, err)
-					}
-				}
-				result.PublishedThisBatch = len(translatedArticles)
-				result.Log = append(result.Log, fmt.Sprintf("publish: %d articles pushed to GitHub", len(translatedArticles)))
-				fmt.Printf("  ✓ Published %d articles to GitHub\n", len(translatedArticles))
-			}
-		} else {
-			result.Log = append(result.Log, "publish (local git): starting")
-			fmt.Println("\nGITHUB_TOKEN not set, using local git publisher...")
-			pub := publisher.NewHugoPublisher(&s.cfg.Hugo)
-			published := 0
-			for _, article := range translatedArticles {
-				if err := pub.Publish(article); err != nil {
-					result.Log = append(result.Log, fmt.Sprintf("publish ERROR: %v", err))
-					fmt.Printf("  ✗ Error publishing: %v\n", err)
-				} else {
-					article.PublishedToHugo = true
-					if err := s.store.UpdateArticle(article); err != nil {
-						fmt.Printf("  ✗ Error updating article status (id=%d): %v\n", article.ID, err)
-					}
-					published++
-				}
-			}
-			result.PublishedThisBatch = published
-			result.Log = append(result.Log, fmt.Sprintf("publish: %d articles written (local git)", published))
-			if s.cfg.Hugo.AutoCommit && published > 0 {
-				if err := pub.GitCommit(fmt.Sprintf("Add %d new articles", published)); err != nil {
-					fmt.Printf("Warning: git commit failed: %v\n", err)
-				}
-			}
+// replacesNearDuplicate reports whether an item of source takes the place of
+// its near-duplicate dup: only a stored, unpublished article of a
+// lower-priority source gives way. Items pending in this fetch come from
+// sources fetched earlier, so they are never lower priority.
+func (s *Service) replacesNearDuplicate(dup *titleEntry, source config.SourceConfig) bool {
+	if dup.stored == nil || dup.stored.IsPublished() {
+		return false
+	}
+	for _, src := range s.cfg.Sources {
+		if src.Name == dup.source {
+			return src.Priority < source.Priority
 		}
 	}
+	// a source removed from the config has the default priority
+	return source.Priority > 0
+}
 
-	return result, nil
+// pendingScrape is a new feed item waiting to be scraped and saved
+type pendingScrape struct {
+	source         string
+	article        *models.Article
+	i, n           int   // position in the source's feed, for logs
+	useFeedContent bool  // sources[].use_feed_content
+	replaces       int64 // stored near-duplicate of a lower-priority source, deleted when this one is saved
 }
 
-// Publish publishes translated articles to Hugo blog
-func (s *Service) Publish(limit int) (*PublishResult, error) {
-	articles, err := s.store.GetUnpublishedArticles(limit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get articles: %w", err)
-	}
+// scrapeOutcome is the per-article result of a scrape worker
+type scrapeOutcome struct {
+	log       []string
+	saved     bool
+	notHTML   bool  // link is not an HTML page (fetcher.ErrNotHTML)
+	duplicate bool  // redirected to an article we already have
+	err       error // why the article was not saved
+}
 
-	result := &PublishResult{
-		Total: len(articles),
-		Log:   []string{},
+// scrapeNew scrapes and saves new articles with up to scraper.concurrency
+// workers. Per host, scraper.per_host_concurrency requests run at once and
+// starts are scraper.per_host_delay_ms apart, so several sources are
+// scraped in parallel while each site still sees a polite crawler. A failed
+// scrape is only a warning: the article is saved for a later rescrape.
+func (s *Service) scrapeNew(ctx context.Context, scraper *fetcher.ArticleScraper, pending []pendingScrape, result *FetchResult, newBySource map[string]int) {
+	if len(pending) == 0 {
+		return
 	}
+	concurrency := s.cfg.Scraper.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	limiter := fetcher.NewHostLimiter(s.cfg.Scraper.PerHostConcurrency, time.Duration(s.cfg.Scraper.PerHostDelayMs)*time.Millisecond)
+	result.Log = append(result.Log, fmt.Sprintf("scraping %d new articles, concurrency %d", len(pending), concurrency))
+	s.printf("\nScraping %d new articles (concurrency %d)\n", len(pending), concurrency)
 
-	if len(articles) == 0 {
-		result.Log = append(result.Log, "No articles pending publish.")
-		result.Log = append(result.Log, "Translated articles are published automatically in the Translate step, so Publish often sees 0 when the pipeline runs translate then publish in sequence.")
-		return result, nil
+	urls := make([]string, len(pending))
+	for k, p := range pending {
+		urls[k] = p.article.SourceURL
 	}
 
-	result.Log = append(result.Log, fmt.Sprintf("articles to publish: %d", len(articles)))
-	fmt.Printf("Articles to publish: %d\n\n", len(articles))
+	// Each worker writes only its own slot; outcomes are aggregated in feed order
+	outcomes := make([]scrapeOutcome, len(pending))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, k := range fetcher.InterleaveByHost(urls) {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(k int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			outcomes[k] = s.scrapeOne(ctx, scraper, limiter, pending[k])
+		}(k)
+	}
+	wg.Wait()
 
-	ghPub := publisher.NewGitHubPublisher(&s.cfg.Hugo)
-	if ghPub.IsAvailable() {
-		result.Log = append(result.Log, "method: GitHub API")
-		fmt.Println("Publishing via GitHub API...")
-		if err := ghPub.PublishMultiple(articles); err != nil {
-			result.Log = append(result.Log, fmt.Sprintf("ERROR: %v", err))
-			fmt.Printf("  ✗ GitHub publish error: %v\n", err)
-			result.Errors = len(articles)
-			return result, nil
-		}
-		for _, a := range articles {
-			a.PublishedToHugo = true
-			if err := s.store.UpdateArticle(a); err != nil {
-				result.Log = append(result.Log, fmt.Sprintf("  id=%d error: %v", a.ID, err))
-				fmt.Printf("  ✗ Error updating article status (id=%d): %v\n", a.ID, err)
-				result.Errors++
-				continue
-			}
-			result.Published++
-			result.Log = append(result.Log, fmt.Sprintf("  published: %s", a.TitleRU))
+	for k, o := range outcomes {
+		result.Log = append(result.Log, o.log...)
+		if o.notHTML {
+			result.NotHTML++
+			result.Articles = append(result.Articles, articleOutcome(pending[k].article, OutcomeNotHTML, o.err))
+			continue
 		}
-		result.Log = append(result.Log, fmt.Sprintf("done: %d published", result.Published))
-		fmt.Printf("  ✓ Published %d articles to GitHub\n", result.Published)
-	} else {
-		result.Log = append(result.Log, "method: local git")
-		fmt.Println("GITHUB_TOKEN not set, using local git publisher...")
-		pub := publisher.NewHugoPublisher(&s.cfg.Hugo)
-
-		for i, article := range articles {
-			fmt.Printf("[%d/%d] Publishing: %s\n", i+1, len(articles), article.TitleRU)
-			if err := pub.Publish(article); err != nil {
-				result.Log = append(result.Log, fmt.Sprintf("[%d/%d] ERROR: %v", i+1, len(articles), err))
-				fmt.Printf("  ✗ Error: %v\n", err)
-				result.Errors++
-				continue
-			}
-
-			article.PublishedToHugo = true
-			if err := s.store.UpdateArticle(article); err != nil {
-				result.Log = append(result.Log, fmt.Sprintf("[%d/%d] error update: %v", i+1, len(articles), err))
-				fmt.Printf("  ✗ Error updating status: %v\n", err)
-				result.Errors++
-				continue
-			}
-
-			result.Published++
-			result.Log = append(result.Log, fmt.Sprintf("[%d/%d] OK: %s", i+1, len(articles), article.TitleRU))
-			fmt.Printf("  ✓ Published\n")
+		if o.duplicate {
+			result.SkippedArticles++
+			continue
 		}
-		result.Log = append(result.Log, fmt.Sprintf("done: %d published, %d errors", result.Published, result.Errors))
-
-		if s.cfg.Hugo.AutoCommit && result.Published > 0 {
-			if err := pub.GitCommit(fmt.Sprintf("Add %d new articles", result.Published)); err != nil {
-				fmt.Printf("Warning: git commit failed: %v\n", err)
-			}
+		if !o.saved {
+			result.Errors++
+			result.Articles = append(result.Articles, articleOutcome(pending[k].article, OutcomeFailed, o.err))
+			continue
 		}
+		result.NewArticles++
+		result.Articles = append(result.Articles, articleOutcome(pending[k].article, OutcomeSaved, nil))
+		newBySource[pending[k].source]++
 	}
-
-	fmt.Printf("\nPublished %d of %d articles (errors: %d)\n", result.Published, result.Total, result.Errors)
-	return result, nil
 }
 
-// Run executes the full pipeline: fetch -> translate -> publish
-func (s *Service) Run() (*PipelineResult, error) {
-	result := &PipelineResult{}
+// scrapeOne saves a single new article, with the full content embedded in
+// the feed when there is enough of it (see fetcher.ApplyFeedContent), else
+// scraped from its page after waiting for its turn at the host
+func (s *Service) scrapeOne(ctx context.Context, scraper *fetcher.ArticleScraper, limiter *fetcher.HostLimiter, p pendingScrape) scrapeOutcome {
+	var out scrapeOutcome
+	article := p.article
+	message := "scraped and saved"
+	if scraper.ApplyFeedContent(article, p.useFeedContent) {
+		s.printf("  [%s %d/%d] Using feed content: %s\n", p.source, p.i+1, p.n, article.Title)
+		out.log = append(out.log, fmt.Sprintf("  [%s %d/%d] feed content used, scrape skipped", p.source, p.i+1, p.n))
+		message = "saved from feed content"
+	} else if !s.scrapePage(ctx, scraper, limiter, p, &out) {
+		return out
+	}
 
-	fmt.Println("=== Step 1: Fetching new articles ===")
-	fetchResult, err := s.Fetch()
+	s.autoTagger.Apply(article)
+	var err error
+	if p.replaces != 0 {
+		err = s.store.ReplaceArticle(p.replaces, article)
+	} else {
+		err = s.store.InsertArticle(article)
+	}
 	if err != nil {
-		fmt.Printf("Fetch error: %v\n", err)
+		out.log = append(out.log, fmt.Sprintf("  [%s %d/%d] error save: %v", p.source, p.i+1, p.n, err))
+		s.printf("    ✗ Error saving %s: %v\n", article.Title, err)
+		out.err = err
+		return out
 	}
-	result.Fetch = fetchResult
+	out.saved = true
+	out.log = append(out.log, fmt.Sprintf("  [%s %d/%d] saved: %s", p.source, p.i+1, p.n, article.Title))
+	if p.replaces != 0 {
+		out.log = append(out.log, fmt.Sprintf("  [%s %d/%d] replaced near-duplicate #%d", p.source, p.i+1, p.n, p.replaces))
+	}
+	s.printf("    ✓ Saved: %s\n", article.Title)
+	s.publishFetched(p.source, article, p.i, p.n, message)
+	return out
+}
 
-	fmt.Println("\n=== Step 2: Translating articles ===")
-	translateResult, err := s.Translate(s.cfg.Schedule.TranslateBatch)
+// scrapePage scrapes the page of a new article for scrapeOne. A failed
+// scrape is only a warning and the article is still to be saved; it returns
+// false when the article must not be saved (blocked link, not HTML, or a
+// redirect to an article we already have), with out filled in.
+func (s *Service) scrapePage(ctx context.Context, scraper *fetcher.ArticleScraper, limiter *fetcher.HostLimiter, p pendingScrape, out *scrapeOutcome) bool {
+	article := p.article
+	release, err := limiter.Acquire(ctx, article.SourceURL)
 	if err != nil {
-		fmt.Printf("Translate error: %v\n", err)
+		out.log = append(out.log, fmt.Sprintf("  [%s %d/%d] error scrape: %v", p.source, p.i+1, p.n, err))
+		out.err = err
+		return false
 	}
-	result.Translate = translateResult
-
-	fmt.Println("\n=== Step 3: Publishing to Hugo ===")
-	publishResult, err := s.Publish(100)
+	s.printf("  [%s %d/%d] Scraping: %s\n", p.source, p.i+1, p.n, article.Title)
+	_, scrapeSpan := tracing.Start(ctx, "scrape")
+	scrapeSpan.SetAttr("source", p.source)
+	scrapeSpan.SetAttr("url", article.SourceURL)
+	scraped, err := scraper.ScrapeArticle(article)
 	if err != nil {
-		fmt.Printf("Publish error: %v\n", err)
+		scrapeSpan.RecordError(err)
+		s.printf("    ✗ Warning: failed to scrape %s: %v\n", article.SourceURL, err)
 	}
-	result.Publish = publishResult
+	scrapeSpan.SetAttr("content.bytes", len(article.Content))
+	scrapeSpan.End()
+	release()
+	if errors.Is(err, httpclient.ErrBlockedURL) {
+		// Not kept for a later rescrape: the link itself is refused
+		out.log = append(out.log, fmt.Sprintf("  [%s %d/%d] blocked: %v", p.source, p.i+1, p.n, err))
+		out.err = err
+		return false
+	}
+	if errors.Is(err, fetcher.ErrNotHTML) {
+		// A PDF or an app link has no article text, now or on a rescrape
+		out.log = append(out.log, fmt.Sprintf("  [%s %d/%d] skipped, not HTML: %v", p.source, p.i+1, p.n, err))
+		out.err = err
+		out.notHTML = true
+		return false
+	}
+	if err != nil && article.Content == "" {
+		// Saved from the feed alone; rescrape tries the page again
+		article.Status = models.StatusNew
+	}
+	if scraped != nil && scraped.Redirect != "" {
+		out.log = append(out.log, fmt.Sprintf("  [%s %d/%d] %s", p.source, p.i+1, p.n, scraped.Redirect))
+	}
+	if article.FeedURL != "" {
+		// Another feed item may already have led to the final URL
+		exists, err := s.store.ArticleExists(article.SourceURL)
+		if err == nil && exists {
+			out.log = append(out.log, fmt.Sprintf("  [%s %d/%d] skipped, already stored: %s", p.source, p.i+1, p.n, article.Title))
+			out.duplicate = true
+			return false
+		}
+	}
+	return true
+}
 
-	return result, nil
+// publishFetched reports a stored (new or updated) feed item on the event bus
+func (s *Service) publishFetched(source string, article *models.Article, i, n int, message string) {
+	s.events.Publish(events.Event{
+		Type: events.ArticleFinished, Step: "fetch", Source: source,
+		ArticleID: article.ID, Title: article.Title, Message: message, Index: i + 1, Total: n,
+	})
+}
+
+// updateExisting re-scrapes a stored article whose feed entry was updated
+// after the version we hold (sources[].update_existing). Updates changing
+// less than scraper.update_min_change of the paragraphs are only recorded,
+// so cosmetic edits do not cause re-translation churn; real ones replace the
+// content and send the article back to translation and re-publishing.
+// Reports whether the stored article changed.
+func (s *Service) updateExisting(scraper *fetcher.ArticleScraper, source config.SourceConfig, fresh *models.Article) (bool, error) {
+	if fresh.SourceUpdatedAt == nil {
+		return false, nil
+	}
+	existing, err := s.store.GetArticleByURL(fresh.SourceURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Deleted (prune) since ArticleExists: nothing to update
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	seen := existing.FetchedAt
+	if existing.SourceUpdatedAt != nil {
+		seen = *existing.SourceUpdatedAt
+	}
+	if !fresh.SourceUpdatedAt.After(seen) {
+		return false, nil
+	}
+
+	if !scraper.ApplyFeedContent(fresh, source.UseFeedContent) {
+		if _, err := scraper.ScrapeArticle(fresh); err != nil {
+			// Record the version anyway so a broken page is not re-scraped on every fetch
+			_ = s.store.SetSourceUpdatedAt(existing.ID, *fresh.SourceUpdatedAt)
+			return false, fmt.Errorf("scrape: %w", err)
+		}
+	}
+	if fresh.Content == "" || contentChange(existing.Content, fresh.Content) < s.cfg.Scraper.UpdateMinChange {
+		return false, s.store.SetSourceUpdatedAt(existing.ID, *fresh.SourceUpdatedAt)
+	}
+
+	if err := s.takeSourceVersion(existing, fresh); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// takeSourceVersion replaces the stored article with a newer version from
+// its source and sends it back to translation and re-publishing
+func (s *Service) takeSourceVersion(existing, fresh *models.Article) error {
+	existing.Title = fresh.Title
+	existing.Description = fresh.Description
+	existing.Content = fresh.Content
+	existing.RawContent = fresh.RawContent
+	if fresh.Author != "" {
+		existing.Author = fresh.Author
+	}
+	if fresh.Category != "" {
+		existing.Category = fresh.Category
+		existing.Tags = fresh.Tags
+	}
+	if fresh.ImageURL != "" || len(fresh.ImageURLs) > 0 {
+		existing.ImageURL = fresh.ImageURL
+		existing.ImageURLs = fresh.ImageURLs
+	}
+	existing.SourceUpdatedAt = fresh.SourceUpdatedAt
+	existing.Status = models.StatusScraped
+	s.autoTagger.Apply(existing)
+	return s.store.UpdateFromSource(existing)
+}
+
+// contentChange is the share of paragraphs added or removed between two
+// versions of an article body (0 = same paragraphs, 1 = nothing in common)
+func contentChange(old, new string) float64 {
+	paragraphs := func(text string) map[string]bool {
+		set := make(map[string]bool)
+		for _, p := range strings.Split(text, "\n\n") {
+			if p = strings.TrimSpace(p); p != "" {
+				set[p] = true
+			}
+		}
+		return set
+	}
+	before, after := paragraphs(old), paragraphs(new)
+	if len(before) == 0 && len(after) == 0 {
+		return 0
+	}
+	changed := 0
+	for p := range after {
+		if !before[p] {
+			changed++
+		}
+	}
+	for p := range before {
+		if !after[p] {
+			changed++
+		}
+	}
+	return float64(changed) / float64(len(before)+len(after))
+}
+
+// FetchPreview parses the enabled sources' feeds (or only sourceName's) and
+// lists the articles a real Fetch would ingest. Nothing is scraped or written
+// to the DB.
+func (s *Service) FetchPreview(sourceName string) (*FetchPreviewResult, error) {
+	sources, err := s.fetchSources(sourceName)
+	if err != nil {
+		return nil, err
+	}
+	rssFetcher := fetcher.NewRSSFetcher(httpclient.Transport(&s.cfg.Network, httpclient.DestFeeds))
+	rssFetcher.SetSlugMaxLength(s.cfg.Hugo.SlugMaxLength)
+
+	result := &FetchPreviewResult{
+		New:         []FetchPreviewItem{},
+		FeedResults: []fetcher.FeedResult{},
+	}
+	seen := make(map[string]bool)
+
+	for _, source := range sources {
+		exclude := fetcher.NewExcludeFilter(s.cfg.Exclude, source.Exclude)
+		for _, feedURL := range source.Feeds {
+			articles, err := rssFetcher.FetchFeed(feedURL, source.Name, feedAuth(source), feedFields(source))
+			if err != nil {
+				result.FeedResults = append(result.FeedResults, fetcher.FeedResult{URL: config.RedactURL(feedURL), Source: source.Name, Error: err.Error()})
+				continue
+			}
+			result.FeedResults = append(result.FeedResults, fetcher.FeedResult{URL: config.RedactURL(feedURL), Source: source.Name, Articles: len(articles)})
+
+			for _, article := range articles {
+				// Same article often appears in several feeds of one source
+				if seen[article.SourceURL] {
+					continue
+				}
+				seen[article.SourceURL] = true
+				if exclude.Match(article) != "" {
+					result.Filtered++
+					continue
+				}
+
+				exists, err := s.store.ArticleExists(article.SourceURL)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check article: %w", err)
+				}
+				if exists {
+					result.Existing++
+					continue
+				}
+				result.New = append(result.New, FetchPreviewItem{
+					Source:      source.Name,
+					Feed:        feedURL,
+					Title:       article.Title,
+					URL:         article.SourceURL,
+					PublishedAt: article.PublishedAt,
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Translate translates untranslated articles
+func (s *Service) Translate(limit int) (*TranslateResult, error) {
+	return s.translate(limit, nil)
+}
+
+// jobTranslate is the job kind of bulk translate runs
+const jobTranslate = "translate"
+
+// TranslateResume continues the last translate job that did not finish
+// (crashed process, failed translator): it translates the articles of the
+// job still waiting for translation and keeps counting progress in the same
+// job record. A job another process is still running is refused.
+func (s *Service) TranslateResume() (*TranslateResult, error) {
+	job, err := s.store.GetLatestJob(jobTranslate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("no translate job to resume")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last translate job: %w", err)
+	}
+	if job.Status == storage.JobDone {
+		return nil, fmt.Errorf("last translate job #%d finished, nothing to resume", job.ID)
+	}
+	if job.Live() {
+		return nil, fmt.Errorf("translate job #%d is still running", job.ID)
+	}
+	if err := s.store.ResumeJob(job); err != nil {
+		return nil, fmt.Errorf("failed to resume job #%d: %w", job.ID, err)
+	}
+	s.printf("Resuming translate job #%d: %d of %d done\n", job.ID, job.Processed, job.Total)
+	return s.translate(job.Remaining(), job)
+}
+
+// GetJob returns a job record with its progress
+func (s *Service) GetJob(id int64) (*storage.Job, error) {
+	return s.store.GetJob(id)
+}
+
+// translate runs a translate batch of up to limit articles, checkpointing
+// progress in job (a new one is created when job is nil). A resumed job
+// takes its own articles that are still untranslated; limit applies only to
+// jobs saved before they recorded them.
+func (s *Service) translate(limit int, job *storage.Job) (*TranslateResult, error) {
+	ctx, span := tracing.Start(context.Background(), "translate")
+	defer span.End()
+
+	var articles []*models.Article
+	var err error
+	if job != nil && len(job.Items) > 0 {
+		articles, err = s.store.GetUntranslatedArticlesByID(job.Items)
+	} else {
+		articles, err = s.store.GetUntranslatedArticles(limit, s.cfg.Translator.Order, s.cfg.Translator.SourcePriority)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles: %w", err)
+	}
+
+	result := &TranslateResult{
+		Total: len(articles),
+		Log:   []string{},
+	}
+
+	if len(articles) == 0 {
+		if job != nil {
+			job.Total = job.Processed
+			s.finishJob(job, storage.JobDone, "")
+			result.JobID = job.ID
+		}
+		return result, nil
+	}
+
+	if job == nil {
+		ids := make([]int64, len(articles))
+		for i, a := range articles {
+			ids[i] = a.ID
+		}
+		if job, err = s.store.CreateJob(jobTranslate, len(articles), ids, nil); err != nil {
+			// Progress tracking is a convenience; translate anyway
+			s.printf("Warning: %v\n", err)
+		}
+	} else {
+		// Articles translated elsewhere in the meantime shrink the job
+		job.Total = job.Processed + len(articles)
+	}
+	if job != nil {
+		result.JobID = job.ID
+		result.Log = append(result.Log, fmt.Sprintf("job: #%d (%d of %d done)", job.ID, job.Processed, job.Total))
+	}
+
+	trans, err := s.translator(ctx)
+	if err != nil {
+		s.finishJob(job, storage.JobFailed, err.Error())
+		return nil, err
+	}
+	post, err := s.postProcessor()
+	if err != nil {
+		s.finishJob(job, storage.JobFailed, err.Error())
+		return nil, err
+	}
+	// Translators with a batch endpoint get all titles in a few requests;
+	// titles missing from the batch fall back to per-article calls
+	var titles []string
+	if bt, ok := trans.(translator.BatchTranslator); ok {
+		titles = s.batchTranslateTitles(ctx, bt, articles, result)
+	}
+	trans = &tracedTranslator{Translator: trans}
+	span.SetAttr("translator.provider", s.cfg.Translator.Provider)
+	span.SetAttr("articles.total", len(articles))
+
+	result.Log = append(result.Log, "translator: "+trans.Name())
+	result.Log = append(result.Log, fmt.Sprintf("articles to translate: %d", len(articles)))
+	s.events.Publish(events.Event{Type: events.StepStarted, Step: "translate", Total: len(articles), Message: "translator: " + trans.Name()})
+	s.printf("Using translator: %s\n", trans.Name())
+	s.printf("Articles to translate: %d\n\n", len(articles))
+
+	totalStart := time.Now()
+	n := len(articles)
+	concurrency := s.translateConcurrency()
+	if concurrency > 1 {
+		result.Log = append(result.Log, fmt.Sprintf("concurrency: %d", concurrency))
+	}
+
+	// Each worker writes only its own slot, so outcomes are aggregated
+	// afterwards in the original order without extra locking.
+	outcomes := make([]translateOutcome, n)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var jobMu sync.Mutex
+	var baseElapsed int64
+	if job != nil {
+		baseElapsed = job.ElapsedMs
+	}
+	for i, article := range articles {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, article *models.Article) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			var title string
+			if titles != nil {
+				title = titles[i]
+			}
+			s.events.Publish(events.Event{Type: events.ArticleStarted, Step: "translate", ArticleID: article.ID, Title: article.Title, Index: i + 1, Total: n})
+			outcomes[i] = s.translateArticle(ctx, trans, post, article, title, i, n)
+			done := events.Event{Type: events.ArticleFinished, Step: "translate", ArticleID: article.ID, Title: article.Title, Index: i + 1, Total: n}
+			if err := outcomes[i].err; err != nil {
+				done.Error = err.Error()
+			} else {
+				done.Message = article.TitleRU
+			}
+			s.events.Publish(done)
+			if job != nil {
+				jobMu.Lock()
+				job.Processed++
+				if outcomes[i].err != nil {
+					job.Errors++
+				}
+				job.ElapsedMs = baseElapsed + time.Since(totalStart).Milliseconds()
+				if err := s.store.UpdateJobProgress(job); err != nil {
+					s.printf("Warning: failed to checkpoint job #%d: %v\n", job.ID, err)
+				}
+				jobMu.Unlock()
+			}
+		}(i, article)
+	}
+	wg.Wait()
+	s.finishJob(job, storage.JobDone, "")
+
+	// Collect translated articles for batch publish
+	var translatedArticles []*models.Article
+	for i, o := range outcomes {
+		result.Log = append(result.Log, o.log...)
+		result.TranslatedChars += o.chars
+		if o.err != nil {
+			result.Errors++
+			result.LastError = o.err.Error()
+			result.Articles = append(result.Articles, articleOutcome(articles[i], OutcomeFailed, o.err))
+			continue
+		}
+		article := articles[i]
+		result.Translated++
+		result.Articles = append(result.Articles, articleOutcome(article, OutcomeTranslated, nil))
+		result.TranslatedArticles = append(result.TranslatedArticles, TranslatedArticleSummary{
+			ID: article.ID, Title: article.Title, TitleRU: article.TitleRU,
+		})
+		translatedArticles = append(translatedArticles, article)
+	}
+
+	if result.TranslatedChars > 0 {
+		s.recordTranslatedChars(result.TranslatedChars)
+	}
+
+	totalElapsed := time.Since(totalStart).Round(time.Second)
+	result.Log = append(result.Log, fmt.Sprintf("done: %d translated, %d errors, %d chars sent, total time %s", result.Translated, result.Errors, result.TranslatedChars, totalElapsed))
+	s.events.Publish(events.Event{Type: events.StepFinished, Step: "translate", Total: result.Total, Counts: map[string]int{
+		"translated": result.Translated, "errors": result.Errors,
+	}})
+	s.printf("\nTranslated %d of %d articles (errors: %d) in %s\n",
+		result.Translated, result.Total, result.Errors, totalElapsed)
+
+	// Publish all translated articles (same request — so "Publish" step later will see 0 pending)
+	if len(translatedArticles) > 0 {
+		published := &PublishResult{Total: len(translatedArticles), Log: []string{}}
+		err := s.publishArticles(ctx, translatedArticles, published, "")
+		result.Log = append(result.Log, published.Log...)
+		setPublishOutcomes(result.Articles, published.Articles)
+		result.PublishedThisBatch = published.Published
+		result.PublishSkipped = published.Skipped
+		result.PublishAttempts = published.Attempts
+		result.PublishCommits = published.Commits
+		switch {
+		case published.Errors > 0 && published.Published > 0:
+			result.PublishStatus = PublishPartial
+		case published.Errors > 0:
+			result.PublishStatus = PublishFailed
+		case published.Published > 0:
+			result.PublishStatus = PublishDone
+		}
+		if err != nil {
+			result.PublishError = err.Error()
+		}
+	}
+
+	return result, nil
+}
+
+// Publish publishes translated articles to Hugo blog. With refresh=true,
+// already published articles rendered with a different formatter fingerprint
+// (config or format change) are re-rendered too, within the same limit.
+func (s *Service) Publish(limit int, refresh bool) (*PublishResult, error) {
+	ctx, span := tracing.Start(context.Background(), "publish")
+	defer span.End()
+
+	// A scheduled run asks for exactly the cap, an unlimited run (-1) for more
+	capReached := false
+	if maxPublish := s.cfg.Schedule.MaxPublishPerRun; maxPublish > 0 && (limit < 0 || limit >= maxPublish) {
+		limit = maxPublish
+		capReached = true
+	}
+
+	articles, err := s.store.GetUnpublishedArticles(limit, s.cfg.Hugo.RequireReview, s.publishCutoff())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles: %w", err)
+	}
+
+	var stale int
+	if refresh && (limit < 0 || len(articles) < limit) {
+		remaining := -1
+		if limit >= 0 {
+			remaining = limit - len(articles)
+		}
+		staleArticles, err := s.store.GetStaleRenderedArticles(s.renderFingerprint(), remaining)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stale articles: %w", err)
+		}
+		stale = len(staleArticles)
+		articles = append(articles, staleArticles...)
+	}
+
+	result := &PublishResult{
+		Total:     len(articles),
+		Refreshed: stale,
+		Log:       []string{},
+	}
+	if refresh {
+		result.Log = append(result.Log, fmt.Sprintf("refresh: %d published articles have a stale render fingerprint", stale))
+	}
+	// Only report the cap when it actually cut the batch short
+	if capReached && len(articles) == limit {
+		result.CapReached = true
+		result.Log = append(result.Log, fmt.Sprintf("cap reached: max_publish_per_run=%d", limit))
+	}
+
+	if len(articles) == 0 {
+		result.Log = append(result.Log, "No articles pending publish.")
+		result.Log = append(result.Log, "Translated articles are published automatically in the Translate step, so Publish often sees 0 when the pipeline runs translate then publish in sequence.")
+		return result, nil
+	}
+
+	s.publishArticles(ctx, articles, result, "")
+	return result, nil
+}
+
+// PublishRange re-renders and publishes every translated article whose
+// published_at falls in [from, to), whether or not it was published before,
+// in one commit named after the range. Use ParseDateRange for from/to.
+func (s *Service) PublishRange(from, to time.Time) (*PublishResult, error) {
+	ctx, span := tracing.Start(context.Background(), "publish")
+	defer span.End()
+
+	articles, err := s.store.GetTranslatedArticlesBetween(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles: %w", err)
+	}
+
+	label := from.Format("2006-01-02")
+	if last := to.AddDate(0, 0, -1); last.After(from) {
+		label += ".." + last.Format("2006-01-02")
+	}
+	result := &PublishResult{Total: len(articles), Log: []string{}}
+	if len(articles) == 0 {
+		result.Log = append(result.Log, fmt.Sprintf("No translated articles dated %s.", label))
+		return result, nil
+	}
+
+	s.publishArticles(ctx, articles, result, fmt.Sprintf("Update %d articles from %s", len(articles), label))
+	return result, nil
+}
+
+// ParseDateRange parses a publish date filter: a day (2024-06-01) or an
+// inclusive range of days (2024-06-01..2024-06-03), in the frontmatter
+// timezone. Returns [from, to) with to the midnight after the last day.
+func (s *Service) ParseDateRange(value string) (from, to time.Time, err error) {
+	loc := time.UTC
+	if tz := s.cfg.Hugo.Formatter.Timezone; tz != "" {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
+		}
+	}
+
+	first, last, isRange := strings.Cut(strings.TrimSpace(value), "..")
+	if !isRange {
+		last = first
+	}
+	from, err = time.ParseInLocation("2006-01-02", strings.TrimSpace(first), loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD", value)
+	}
+	end, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(last), loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD", value)
+	}
+	if end.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date range %q: end is before start", value)
+	}
+	return from, end.AddDate(0, 0, 1), nil
+}
+
+// publishArticles writes articles to the blog (GitHub API, or local files
+// plus git commit), marks them published and records the outcome in result.
+// commitMessage "" means "Add N new articles". Returns the last publish
+// error, already counted in result; Publish and the auto-publish after
+// translate both go through here.
+func (s *Service) publishArticles(ctx context.Context, articles []*models.Article, result *PublishResult, commitMessage string) error {
+	articles, held := s.holdPartialTranslations(articles, &result.Log)
+	articles, unreviewed := s.holdUnreviewed(articles, &result.Log)
+	held = append(held, unreviewed...)
+	articles, recent := s.holdRecent(articles, &result.Log)
+	held = append(held, recent...)
+	result.Skipped = len(held)
+	for _, a := range held {
+		result.Articles = append(result.Articles, articleOutcome(a, OutcomeSkipped, nil))
+	}
+	s.disambiguateSlugs(articles, &result.Log)
+	result.Log = append(result.Log, fmt.Sprintf("articles to publish: %d", len(articles)))
+	s.printf("Articles to publish: %d\n\n", len(articles))
+	s.events.Publish(events.Event{Type: events.StepStarted, Step: "publish", Total: len(articles)})
+	defer func() {
+		s.events.Publish(events.Event{Type: events.StepFinished, Step: "publish", Total: len(articles), Counts: map[string]int{
+			"published": result.Published, "errors": result.Errors, "skipped": result.Skipped,
+		}})
+	}()
+	if len(articles) == 0 {
+		return nil
+	}
+
+	var lastErr error
+	ghPub := publisher.NewGitHubPublisher(&s.cfg.Hugo, httpclient.Transport(&s.cfg.Network, httpclient.DestGitHub))
+	if ghPub.IsAvailable() {
+		result.Log = append(result.Log, "method: GitHub API")
+		s.printf("Publishing via GitHub API...\n")
+		attempts, commits, pushed, err := s.publishWithRetry(ctx, ghPub, articles, commitMessage, &result.Log)
+		result.Attempts = attempts
+		result.Commits = commits
+		if err != nil {
+			result.Log = append(result.Log, fmt.Sprintf("ERROR: %v", err))
+			s.printf("  ✗ GitHub publish error: %v\n", err)
+			s.events.Publish(events.Event{Type: events.Error, Step: "publish", Message: "GitHub publish", Error: err.Error()})
+			failed := articles
+			if len(pushed) > 0 {
+				// the earlier chained commits stay in the branch
+				if markErr := s.markPublished(pushed); markErr != nil {
+					result.Log = append(result.Log, fmt.Sprintf("ERROR (status update): %v", markErr))
+					s.printf("  ✗ Error updating article status: %v\n", markErr)
+				} else {
+					for _, a := range pushed {
+						result.Published++
+						result.Articles = append(result.Articles, articleOutcome(a, OutcomePublished, nil))
+						result.Log = append(result.Log, fmt.Sprintf("  published: %s", a.TitleRU))
+					}
+					failed = withoutArticles(articles, pushed)
+				}
+			}
+			result.Errors = len(failed)
+			for _, a := range failed {
+				result.Articles = append(result.Articles, articleOutcome(a, OutcomeFailed, err))
+			}
+			return err
+		}
+		if err := s.markPublished(articles); err != nil {
+			result.Log = append(result.Log, fmt.Sprintf("ERROR (status update): %v", err))
+			s.printf("  ✗ Error updating article status: %v\n", err)
+			s.events.Publish(events.Event{Type: events.Error, Step: "publish", Message: "status update", Error: err.Error()})
+			result.Errors = len(articles)
+			for _, a := range articles {
+				result.Articles = append(result.Articles, articleOutcome(a, OutcomeFailed, err))
+			}
+			return err
+		}
+		for i, a := range articles {
+			result.Published++
+			result.Articles = append(result.Articles, articleOutcome(a, OutcomePublished, nil))
+			result.Log = append(result.Log, fmt.Sprintf("  published: %s", a.TitleRU))
+			s.events.Publish(events.Event{Type: events.ArticleFinished, Step: "publish", ArticleID: a.ID, Title: a.TitleRU, Index: i + 1, Total: len(articles)})
+		}
+		result.Log = append(result.Log, fmt.Sprintf("done: %d published in %d commit(s)", result.Published, result.Commits))
+		s.printf("  ✓ Published %d articles to GitHub in %d commit(s)\n", result.Published, result.Commits)
+	} else {
+		result.Log = append(result.Log, "method: local git")
+		s.printf("GITHUB_TOKEN not set, using local git publisher...\n")
+		pub := publisher.NewHugoPublisher(&s.cfg.Hugo)
+
+		var written []*models.Article
+		for i, article := range articles {
+			s.printf("[%d/%d] Publishing: %s\n", i+1, len(articles), article.TitleRU)
+			done := events.Event{Type: events.ArticleFinished, Step: "publish", ArticleID: article.ID, Title: article.TitleRU, Index: i + 1, Total: len(articles)}
+			if err := pub.Publish(article); err != nil {
+				result.Log = append(result.Log, fmt.Sprintf("[%d/%d] ERROR: %v", i+1, len(articles), err))
+				s.printf("  ✗ Error: %v\n", err)
+				result.Errors++
+				result.Articles = append(result.Articles, articleOutcome(article, OutcomeFailed, err))
+				lastErr = err
+				done.Error = err.Error()
+				s.events.Publish(done)
+				continue
+			}
+
+			written = append(written, article)
+			result.Log = append(result.Log, fmt.Sprintf("[%d/%d] OK: %s", i+1, len(articles), article.TitleRU))
+			s.printf("  ✓ Published\n")
+			s.events.Publish(done)
+		}
+		if err := s.markPublished(written); err != nil {
+			result.Log = append(result.Log, fmt.Sprintf("error update: %v", err))
+			s.printf("  ✗ Error updating status: %v\n", err)
+			lastErr = err
+			result.Errors += len(written)
+			for _, a := range written {
+				result.Articles = append(result.Articles, articleOutcome(a, OutcomeFailed, err))
+			}
+		} else {
+			result.Published = len(written)
+			for _, a := range written {
+				result.Articles = append(result.Articles, articleOutcome(a, OutcomePublished, nil))
+			}
+		}
+		result.Log = append(result.Log, fmt.Sprintf("done: %d published, %d errors", result.Published, result.Errors))
+
+		if s.cfg.Hugo.AutoCommit && result.Published > 0 {
+			message := commitMessage
+			if message == "" {
+				message = fmt.Sprintf("Add %d new articles", result.Published)
+			}
+			if err := pub.GitCommit(message); err != nil {
+				s.printf("Warning: git commit failed: %v\n", err)
+			}
+		}
+	}
+
+	s.printf("\nPublished %d of %d articles (errors: %d)\n", result.Published, result.Total, result.Errors)
+	return lastErr
+}
+
+// holdPartialTranslations drops articles missing title_ru or content_ru
+// when hugo.require_full_translation is on, so a failed title (or content)
+// translation never goes out in English. Unpublished ones are sent back to
+// the translation queue instead of blocking the publish queue. Returns the
+// articles to publish and the held ones.
+func (s *Service) holdPartialTranslations(articles []*models.Article, log *[]string) (ready, held []*models.Article) {
+	if !s.cfg.Hugo.RequireFullTranslation {
+		return articles, nil
+	}
+	ready = articles[:0:0]
+	for _, a := range articles {
+		var missing []string
+		if strings.TrimSpace(a.TitleRU) == "" {
+			missing = append(missing, "title_ru")
+		}
+		if strings.TrimSpace(a.ContentRU) == "" {
+			missing = append(missing, "content_ru")
+		}
+		if len(missing) == 0 {
+			ready = append(ready, a)
+			continue
+		}
+		held = append(held, a)
+		*log = append(*log, fmt.Sprintf("  skipped (no %s): #%d %s", strings.Join(missing, ", "), a.ID, a.Title))
+		s.printf("  - Skipped #%d, no %s: %s\n", a.ID, strings.Join(missing, ", "), a.Title)
+		if a.Status == models.StatusTranslated {
+			if err := s.store.SetArticleStatus(a.ID, models.StatusErrored); err != nil {
+				s.printf("  ✗ Error re-queueing #%d for translation: %v\n", a.ID, err)
+			}
+		}
+	}
+	return ready, held
+}
+
+// holdUnreviewed drops articles not marked reviewed when hugo.require_review
+// is on. They keep their status and are published once an editor marks them.
+// Already published articles (re-renders) are not held back.
+func (s *Service) holdUnreviewed(articles []*models.Article, log *[]string) (ready, held []*models.Article) {
+	if !s.cfg.Hugo.RequireReview {
+		return articles, nil
+	}
+	ready = articles[:0:0]
+	for _, a := range articles {
+		if a.Reviewed || a.Status == models.StatusPublished {
+			ready = append(ready, a)
+			continue
+		}
+		held = append(held, a)
+		*log = append(*log, fmt.Sprintf("  skipped (not reviewed): #%d %s", a.ID, a.Title))
+		s.printf("  - Skipped #%d, not reviewed: %s\n", a.ID, a.Title)
+	}
+	return ready, held
+}
+
+// publishCutoff is the latest fetch time an article may have to be published
+// under schedule.publish_delay; zero when there is no delay
+func (s *Service) publishCutoff() time.Time {
+	if s.cfg.Schedule.PublishDelayDuration <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-s.cfg.Schedule.PublishDelayDuration)
+}
+
+// holdRecent drops articles fetched less than schedule.publish_delay ago, so
+// corrections the source makes right after posting land before we publish.
+// They stay translated and go out with a later publish run. Already
+// published articles (re-renders) are not held back.
+func (s *Service) holdRecent(articles []*models.Article, log *[]string) (ready, held []*models.Article) {
+	cutoff := s.publishCutoff()
+	if cutoff.IsZero() {
+		return articles, nil
+	}
+	ready = articles[:0:0]
+	for _, a := range articles {
+		if !a.FetchedAt.After(cutoff) || a.Status == models.StatusPublished {
+			ready = append(ready, a)
+			continue
+		}
+		held = append(held, a)
+		wait := a.FetchedAt.Sub(cutoff).Round(time.Minute)
+		*log = append(*log, fmt.Sprintf("  skipped (publish delay, %s left): #%d %s", wait, a.ID, a.Title))
+		s.printf("  - Skipped #%d, publish delay (%s left): %s\n", a.ID, wait, a.Title)
+	}
+	return ready, held
+}
+
+// disambiguateSlugs gives articles of one publish batch that would be
+// written to the same file (same slug in the same month) distinct slugs, so
+// the second GitHub PUT does not silently replace the first. The article
+// already published (else the oldest) keeps its slug; the others get their
+// ID appended, saved so later re-publishes write the same file.
+func (s *Service) disambiguateSlugs(articles []*models.Article, log *[]string) {
+	if s.cfg.Hugo.DuplicateSlugs == "off" || len(articles) < 2 {
+		return
+	}
+	f := formatter.NewMarkdownFormatter(&s.cfg.Hugo.Formatter)
+	var paths []string
+	byPath := make(map[string][]*models.Article)
+	for _, a := range articles {
+		p := f.GetFilePath(a, "")
+		if _, ok := byPath[p]; !ok {
+			paths = append(paths, p)
+		}
+		byPath[p] = append(byPath[p], a)
+	}
+
+	for _, p := range paths {
+		group := byPath[p]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].IsPublished() != group[j].IsPublished() {
+				return group[i].IsPublished()
+			}
+			return group[i].ID < group[j].ID
+		})
+		renamed := make([]string, 0, len(group)-1)
+		for _, a := range group[1:] {
+			slug := fmt.Sprintf("%s-%d", a.Slug, a.ID)
+			if err := s.store.SetSlug(a.ID, slug); err != nil {
+				s.printf("  ✗ Error saving slug of #%d: %v\n", a.ID, err)
+			}
+			a.Slug = slug
+			renamed = append(renamed, fmt.Sprintf("#%d -> %s", a.ID, slug))
+		}
+		msg := fmt.Sprintf("WARNING: slug collision at %s: #%d keeps it, renamed %s", filepath.ToSlash(p), group[0].ID, strings.Join(renamed, ", "))
+		*log = append(*log, msg)
+		s.printf("  %s\n", msg)
+	}
+}
+
+// Run executes the full pipeline: fetch -> translate -> publish
+func (s *Service) Run() (*PipelineResult, error) {
+	result := &PipelineResult{}
+	s.events.Publish(events.Event{Type: events.StepStarted, Step: "run"})
+	defer s.events.Publish(events.Event{Type: events.StepFinished, Step: "run"})
+
+	s.printf("=== Step 1: Fetching new articles ===\n")
+	fetchResult, err := s.Fetch("")
+	if err != nil {
+		s.printf("Fetch error: %v\n", err)
+	}
+	result.Fetch = fetchResult
+
+	if s.cfg.Scraper.RecheckDays > 0 {
+		s.printf("\n=== Re-checking recent articles for source changes ===\n")
+		recheckResult, err := s.RecheckContent()
+		if err != nil {
+			s.printf("Recheck error: %v\n", err)
+		}
+		result.Recheck = recheckResult
+	}
+
+	s.printf("\n=== Step 2: Translating articles ===\n")
+	translateResult, err := s.Translate(s.cfg.Schedule.TranslateBatch)
+	if err != nil {
+		s.printf("Translate error: %v\n", err)
+	}
+	result.Translate = translateResult
+
+	s.printf("\n=== Step 3: Publishing to Hugo ===\n")
+	publishLimit := s.cfg.Schedule.MaxPublishPerRun
+	if publishLimit <= 0 {
+		publishLimit = -1 // SQLite: no LIMIT
+	}
+	publishResult, err := s.Publish(publishLimit, false)
+	if err != nil {
+		s.printf("Publish error: %v\n", err)
+	}
+	result.Publish = publishResult
+
+	return result, nil
 }
 
 // Stats returns database statistics
 func (s *Service) Stats() (*StatsResult, error) {
 	total, translated, published, err := s.store.GetStats()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stats: %w", err)
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	chars, since, err := s.store.GetCounter(storage.CounterTranslatedChars)
+	if err != nil {
+		return nil, err
+	}
+	month, _, err := s.store.GetCounter(storage.MonthlyCounter(storage.CounterTranslatedChars, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := s.Sources()
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatsResult{
+		Total:                total,
+		Translated:           translated,
+		Published:            published,
+		Pending:              total - translated,
+		Unpublished:          translated - published,
+		TranslatedChars:      chars,
+		TranslatedCharsMonth: month,
+		TranslatedCharsSince: since,
+		Sources:              sources,
+	}, nil
+}
+
+// Timeseries returns per-day article counts for the last days days (see
+// storage.GetDailyCounts for the metrics)
+func (s *Service) Timeseries(metric string, days int) ([]storage.DayCount, error) {
+	return s.store.GetDailyCounts(metric, days, time.Now())
+}
+
+// Pull pulls/updates blog repository
+func (s *Service) Pull() error {
+	pub := publisher.NewHugoPublisher(&s.cfg.Hugo)
+	return pub.GitPull()
+}
+
+// Push pushes changes to blog repository
+func (s *Service) Push() error {
+	pub := publisher.NewHugoPublisher(&s.cfg.Hugo)
+	return pub.GitPush()
+}
+
+// sourceVelocityWindow is the window for SourceStatus.RecentNew
+const sourceVelocityWindow = 24 * time.Hour
+
+// recordSourceFetch moves the source's fetch watermark; failures are only
+// logged since the articles themselves are already saved
+func (s *Service) recordSourceFetch(source string, newCount int) {
+	if err := s.store.RecordSourceFetch(source, newCount, time.Now()); err != nil {
+		s.printf("Warning: %v\n", err)
+	}
+}
+
+// recordSourceFailure counts a fetch in which every feed of the source
+// failed and disables the source once schedule.disable_after_failures is
+// reached
+func (s *Service) recordSourceFailure(source string, fetchErr error, result *FetchResult) {
+	failures, err := s.store.RecordSourceFailure(source, fetchErr.Error(), time.Now())
+	if err != nil {
+		s.printf("Warning: %v\n", err)
+		return
+	}
+	limit := s.cfg.Schedule.DisableAfterFailures
+	if limit <= 0 || failures < limit {
+		return
+	}
+	if err := s.store.DisableSource(source, time.Now()); err != nil {
+		s.printf("Warning: %v\n", err)
+		return
+	}
+	result.Disabled = append(result.Disabled, source)
+	msg := fmt.Sprintf("disabled after %d failed fetches in a row", failures)
+	result.Log = append(result.Log, "  "+msg)
+	s.printf("Warning: %s %s; re-enable with enable-source %s\n", source, msg, source)
+	s.events.Publish(events.Event{Type: events.Error, Step: "fetch", Source: source, Message: msg, Error: fetchErr.Error()})
+}
+
+// EnableSource re-enables a source disabled after repeated failures and
+// resets its failure count; reports whether it was disabled
+func (s *Service) EnableSource(name string) (bool, error) {
+	for _, source := range s.cfg.Sources {
+		if source.Name == name {
+			return s.store.EnableSource(name)
+		}
+	}
+	return false, fmt.Errorf("%w: %s", ErrUnknownSource, name)
+}
+
+// Sources returns the configured sources with their fetch watermarks
+// (never-fetched sources have a nil LastFetchedAt)
+func (s *Service) Sources() ([]SourceStatus, error) {
+	watermarks, err := s.store.GetSourceWatermarks(time.Now().Add(-sourceVelocityWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source watermarks: %w", err)
+	}
+	byName := make(map[string]storage.SourceWatermark, len(watermarks))
+	for _, w := range watermarks {
+		byName[w.Source] = w
+	}
+
+	result := make([]SourceStatus, 0, len(s.cfg.Sources))
+	for _, src := range s.cfg.Sources {
+		feeds := make([]string, 0, len(src.Feeds))
+		for _, f := range src.Feeds {
+			feeds = append(feeds, config.RedactURL(f))
+		}
+		w := byName[src.Name]
+		result = append(result, SourceStatus{
+			Name:          src.Name,
+			Enabled:       src.Enabled,
+			Priority:      src.Priority,
+			Feeds:         feeds,
+			LastFetchedAt: w.LastFetchedAt,
+			LastNew:       w.LastNew,
+			TotalNew:      w.TotalNew,
+			RecentNew:     w.RecentNew,
+
+			ConsecutiveFailures: w.ConsecutiveFailures,
+			LastError:           w.LastError,
+			LastErrorAt:         w.LastErrorAt,
+			AutoDisabled:        w.AutoDisabledAt != nil,
+			AutoDisabledAt:      w.AutoDisabledAt,
+		})
+	}
+	return result, nil
+}
+
+// feedCheckTimeout bounds each request of CheckFeeds
+const feedCheckTimeout = 30 * time.Second
+
+// CheckFeeds parses every feed of the enabled sources and reports which are
+// down (HTTP error, unreachable, not a feed, empty) and which have gone
+// quiet: a feed whose newest item is older than staleDays, or a source
+// that was fetched in that window without producing a new article (from
+// its fetch watermark). Sources marked critical that are fully down are
+// listed in CriticalDown.
+func (s *Service) CheckFeeds(staleDays int) (*CheckFeedsResult, error) {
+	since := time.Now().AddDate(0, 0, -staleDays)
+	watermarks, err := s.store.GetSourceWatermarks(since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source watermarks: %w", err)
+	}
+	byName := make(map[string]storage.SourceWatermark, len(watermarks))
+	for _, w := range watermarks {
+		byName[w.Source] = w
+	}
+
+	rssFetcher := fetcher.NewRSSFetcher(httpclient.Transport(&s.cfg.Network, httpclient.DestFeeds))
+	rssFetcher.SetTimeout(feedCheckTimeout)
+
+	result := &CheckFeedsResult{StaleDays: staleDays, Sources: []SourceFeedCheck{}}
+	for _, source := range s.cfg.Sources {
+		if !source.Enabled {
+			continue
+		}
+		check := SourceFeedCheck{Name: source.Name, Critical: source.Critical, Down: true}
+		auth := feedAuth(source)
+		for _, feedURL := range source.Feeds {
+			fc := rssFetcher.CheckFeed(feedURL, auth)
+			if fc.Down() {
+				result.FeedsDown++
+			} else {
+				check.Down = false
+				if fc.Newest != nil && fc.Newest.Before(since) {
+					result.StaleFeeds++
+				}
+			}
+			check.Feeds = append(check.Feeds, fc)
+		}
+
+		w := byName[source.Name]
+		check.LastFetchedAt = w.LastFetchedAt
+		check.RecentNew = w.RecentNew
+		check.Stale = w.LastFetchedAt != nil && w.LastFetchedAt.After(since) && w.RecentNew == 0
+		if check.Down && source.Critical {
+			result.CriticalDown = append(result.CriticalDown, source.Name)
+		}
+		result.Sources = append(result.Sources, check)
+	}
+	return result, nil
+}
+
+// Rescrape re-scrapes articles without content (status new or stub).
+// An article is only saved when the new scrape actually improved it (more
+// content, or a category where there was none). Otherwise its
+// rescrape_attempts counter is bumped; after scraper.max_rescrape_attempts
+// it is no longer retried and is reported as permanently failed.
+func (s *Service) Rescrape() (*RescrapeResult, error) {
+	maxAttempts := s.cfg.Scraper.MaxRescrapeAttempts
+	articles, err := s.store.GetArticlesToRescrape(maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles: %w", err)
+	}
+
+	result := &RescrapeResult{
+		Total: len(articles),
+	}
+
+	if len(articles) == 0 {
+		return result, nil
+	}
+
+	scraper := s.newScraper(&s.cfg.Scraper)
+
+	for _, article := range articles {
+		s.printf("  Re-scraping: %s\n", article.Title)
+		oldContent, oldCategory := article.Content, article.Category
+
+		if _, err := scraper.ScrapeArticle(article); err != nil {
+			s.printf("  Warning: failed to scrape: %v\n", err)
+			result.Errors++
+			s.recordRescrapeFailure(article, result)
+			continue
+		}
+
+		improved := len(article.Content) > len(oldContent) || (oldCategory == "" && article.Category != "")
+		if !improved {
+			s.printf("  No improvement after re-scrape: %s (content: %d chars)\n", article.Title, len(article.Content))
+			result.Unchanged++
+			s.recordRescrapeFailure(article, result)
+			continue
+		}
+
+		article.RescrapeAttempts = 0
+		if (article.Status == models.StatusStub || article.Status == models.StatusNew) && article.Content != "" {
+			article.Status = models.StatusScraped
+		}
+		s.autoTagger.Apply(article)
+		if err := s.store.UpdateArticle(article); err != nil {
+			s.printf("  Error saving article: %v\n", err)
+			result.Errors++
+			continue
+		}
+
+		result.Rescraped++
+		s.printf("  Re-scraped: %s (content: %d -> %d chars)\n", article.Title, len(oldContent), len(article.Content))
+
+		time.Sleep(1 * time.Second)
+	}
+
+	return result, nil
+}
+
+// ErrRecheckDisabled is returned by RecheckContent when scraper.recheck_days is 0
+var ErrRecheckDisabled = errors.New("content re-check is disabled (scraper.recheck_days: 0)")
+
+// RecheckContent re-scrapes up to scraper.recheck_batch articles fetched in
+// the last scraper.recheck_days days and not checked within
+// scraper.recheck_interval_hours, one request per host at a time with
+// scraper.per_host_delay_ms between them. An article whose page text hash
+// differs from the stored one by at least scraper.update_min_change of the
+// paragraphs takes the new version and goes back to translation and
+// re-publishing; the others are only marked checked.
+func (s *Service) RecheckContent() (*RecheckResult, error) {
+	cfg := &s.cfg.Scraper
+	if cfg.RecheckDays <= 0 {
+		return nil, ErrRecheckDisabled
+	}
+	now := time.Now()
+	articles, err := s.store.GetArticlesForRecheck(
+		now.AddDate(0, 0, -cfg.RecheckDays),
+		now.Add(-time.Duration(cfg.RecheckIntervalHours)*time.Hour),
+		cfg.RecheckBatch,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles: %w", err)
+	}
+
+	result := &RecheckResult{Total: len(articles)}
+	if len(articles) == 0 {
+		return result, nil
+	}
+	s.events.Publish(events.Event{Type: events.StepStarted, Step: "recheck", Total: len(articles)})
+	scraper := s.newScraper(cfg)
+	limiter := fetcher.NewHostLimiter(1, time.Duration(cfg.PerHostDelayMs)*time.Millisecond)
+
+	for _, article := range articles {
+		release, err := limiter.Acquire(context.Background(), article.SourceURL)
+		if err != nil {
+			return result, err
+		}
+		outcome, err := s.recheckArticle(scraper, article)
+		release()
+		switch {
+		case err != nil:
+			s.printf("  ✗ Re-check failed: %s: %v\n", article.SourceURL, err)
+			result.Errors++
+		case outcome == recheckChanged:
+			s.printf("  Changed at source, queued for re-translation: %s\n", article.Title)
+			result.Changed++
+			result.ChangedIDs = append(result.ChangedIDs, article.ID)
+		case outcome == recheckMinor:
+			s.printf("  Minor change at source, kept: %s\n", article.Title)
+			result.Minor++
+		default:
+			result.Unchanged++
+		}
+		if err := s.store.SetContentChecked(article.ID, time.Now()); err != nil {
+			s.printf("  Warning: %v\n", err)
+		}
+	}
+
+	s.events.Publish(events.Event{Type: events.StepFinished, Step: "recheck", Counts: map[string]int{
+		"changed": result.Changed, "minor": result.Minor, "unchanged": result.Unchanged, "errors": result.Errors,
+	}})
+	return result, nil
+}
+
+// Outcomes of recheckArticle
+type recheckOutcome int
+
+const (
+	recheckUnchanged recheckOutcome = iota
+	recheckMinor                    // hash differs, change below scraper.update_min_change
+	recheckChanged                  // new version taken
+)
+
+// recheckArticle scrapes the article's page again and takes the new version
+// when its hash differs from the stored one and enough of it changed (see
+// contentChange), so a fixed typo does not re-translate the whole article
+func (s *Service) recheckArticle(scraper *fetcher.ArticleScraper, article *models.Article) (recheckOutcome, error) {
+	fresh := &models.Article{
+		SourceURL:       article.SourceURL,
+		SourceSite:      article.SourceSite,
+		Title:           article.Title,
+		Description:     article.Description,
+		Author:          article.Author,
+		Category:        article.Category,
+		Tags:            article.Tags,
+		SourceUpdatedAt: article.SourceUpdatedAt,
+	}
+	if _, err := scraper.ScrapeArticle(fresh); err != nil {
+		return recheckUnchanged, err
+	}
+	if fresh.Content == "" {
+		return recheckUnchanged, errors.New("page has no content")
+	}
+
+	stored := article.ContentHash
+	if stored == "" {
+		stored = article.SourceContentHash()
+	}
+	current := fresh.SourceContentHash()
+	if article.RawContent == "" || fresh.RawContent == "" {
+		// Raw text missing on one side (saved before it was kept, or
+		// scraper.keep_raw_content off): compare the cleaned content
+		stored = models.HashText(article.Content)
+		current = models.HashText(fresh.Content)
+	}
+	if current == stored {
+		return recheckUnchanged, nil
+	}
+	if contentChange(article.Content, fresh.Content) < s.cfg.Scraper.UpdateMinChange {
+		return recheckMinor, nil
+	}
+	if err := s.takeSourceVersion(article, fresh); err != nil {
+		return recheckUnchanged, err
+	}
+	return recheckChanged, nil
+}
+
+// recordRescrapeFailure bumps the article's attempt counter and adds it to
+// the permanently failed list once it reaches the configured maximum
+func (s *Service) recordRescrapeFailure(article *models.Article, result *RescrapeResult) {
+	attempts, err := s.store.IncrementRescrapeAttempts(article.ID)
+	if err != nil {
+		s.printf("  Error recording rescrape attempt (id=%d): %v\n", article.ID, err)
+		return
+	}
+	if maxAttempts := s.cfg.Scraper.MaxRescrapeAttempts; maxAttempts > 0 && attempts >= maxAttempts {
+		s.printf("  Giving up after %d attempts: %s\n", attempts, article.SourceURL)
+		result.PermanentlyFailed = append(result.PermanentlyFailed, RescrapeFailure{
+			ID: article.ID, Title: article.Title, URL: article.SourceURL, Attempts: attempts,
+		})
+	}
+}
+
+// Outcomes of a Reprocess step
+const (
+	StepOK      = "ok"
+	StepFailed  = "failed"
+	StepSkipped = "skipped"
+)
+
+// ReprocessStep is what one step of Reprocess did
+type ReprocessStep struct {
+	Step   string `json:"step"`   // scrape, translate, publish
+	Status string `json:"status"` // StepOK, StepFailed or StepSkipped
+	Error  string `json:"error,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ReprocessResult holds the steps of a single-article Reprocess
+type ReprocessResult struct {
+	ID      int64                `json:"id"`
+	Title   string               `json:"title"`
+	TitleRU string               `json:"title_ru,omitempty"`
+	Status  models.ArticleStatus `json:"status"`  // article status afterwards
+	Success bool                 `json:"success"` // every step ran and none failed
+	Steps   []ReprocessStep      `json:"steps"`
+	Log     []string             `json:"log,omitempty"`
+}
+
+// Reprocess runs the whole pipeline for one article: it scrapes the source
+// page again, translates the article from scratch and publishes it. A failed
+// step is reported and the article keeps a consistent status: a failed
+// scrape translates the content already stored, a failed translation leaves
+// an unpublished article errored (retried by the next batch) and a published
+// one with its previous translation. The error is only for a missing article.
+func (s *Service) Reprocess(id int64) (*ReprocessResult, error) {
+	ctx, span := tracing.Start(context.Background(), "reprocess")
+	defer span.End()
+	span.SetAttr("article.id", id)
+
+	article, err := s.store.GetArticleByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("article %d: %w", id, err)
+	}
+	result := &ReprocessResult{ID: article.ID, Title: article.Title}
+	step := func(name, status string, err error, detail string) {
+		st := ReprocessStep{Step: name, Status: status, Detail: detail}
+		if err != nil {
+			st.Error = err.Error()
+		}
+		result.Steps = append(result.Steps, st)
+		s.printf("  %s: %s %s\n", name, status, strings.TrimSpace(st.Error+" "+detail))
+	}
+
+	// Scrape: only saved when it yields content, so a broken page never
+	// wipes what we have
+	scraper := s.newScraper(&s.cfg.Scraper)
+	oldContent := article.Content
+	fresh := *article
+	if _, err := scraper.ScrapeArticle(&fresh); err != nil {
+		step("scrape", StepFailed, err, "")
+	} else if fresh.Content == "" {
+		step("scrape", StepFailed, fmt.Errorf("no content extracted"), "")
+	} else {
+		if article.TaxonomyOverridden {
+			// The store keeps hand-set taxonomy; so must the copy we publish
+			fresh.Category, fresh.Tags = article.Category, article.Tags
+		}
+		*article = fresh
+		article.RescrapeAttempts = 0
+		if article.Status == models.StatusStub || article.Status == models.StatusNew {
+			article.Status = models.StatusScraped
+		}
+		s.autoTagger.Apply(article)
+		err := s.store.UpdateArticle(article)
+		if err == nil && article.Content != oldContent && article.Reviewed {
+			// Changed text needs another look (hugo.require_review)
+			if _, err = s.store.SetReviewed([]int64{article.ID}, false); err == nil {
+				article.Reviewed = false
+			}
+		}
+		if err != nil {
+			step("scrape", StepFailed, fmt.Errorf("save: %w", err), "")
+		} else {
+			step("scrape", StepOK, nil, fmt.Sprintf("%d -> %d chars", utf8.RuneCountInString(oldContent), utf8.RuneCountInString(article.Content)))
+		}
+	}
+
+	// Translate from scratch
+	translated := false
+	if strings.TrimSpace(article.Content) == "" {
+		step("translate", StepSkipped, nil, "no content to translate")
+	} else if trans, err := s.translator(ctx); err != nil {
+		step("translate", StepFailed, err, "")
+	} else if post, err := s.postProcessor(); err != nil {
+		step("translate", StepFailed, err, "")
+	} else {
+		article.TitleRU, article.ContentRU, article.ContentTruncated = "", "", false
+		if !article.IsPublished() {
+			article.Status = models.StatusScraped
+		}
+		out := s.translateArticle(ctx, &tracedTranslator{Translator: trans}, post, article, "", 0, 1)
+		result.Log = append(result.Log, out.log...)
+		if out.chars > 0 {
+			s.recordTranslatedChars(out.chars)
+		}
+		if out.err != nil {
+			step("translate", StepFailed, out.err, "")
+			// translateArticle saved only the status; reload what is stored
+			if stored, err := s.store.GetArticleByID(id); err == nil {
+				article = stored
+			}
+		} else {
+			translated = true
+			step("translate", StepOK, nil, article.TitleRU)
+		}
+	}
+
+	// Publish (re-renders an already published article)
+	if !translated {
+		step("publish", StepSkipped, nil, "not translated")
+	} else {
+		pub := &PublishResult{Total: 1, Log: []string{}}
+		s.publishArticles(ctx, []*models.Article{article}, pub, fmt.Sprintf("Reprocess article %d", article.ID))
+		result.Log = append(result.Log, pub.Log...)
+		switch {
+		case pub.Published > 0:
+			step("publish", StepOK, nil, "")
+		case pub.Skipped > 0:
+			step("publish", StepSkipped, nil, "held back (see log)")
+		default:
+			var err error
+			if len(pub.Articles) > 0 && pub.Articles[0].Error != "" {
+				err = errors.New(pub.Articles[0].Error)
+			} else {
+				err = fmt.Errorf("not published")
+			}
+			step("publish", StepFailed, err, "")
+		}
+	}
+
+	result.TitleRU = article.TitleRU
+	result.Status = article.Status
+	result.Success = true
+	for _, st := range result.Steps {
+		if st.Status != StepOK {
+			result.Success = false
+		}
+	}
+	return result, nil
+}
+
+// MaxTranslateTestChars caps the text TranslateTest accepts
+const MaxTranslateTestChars = 5000
+
+// ErrTranslateTestInput is returned by TranslateTest for an empty or too
+// long text and for overrides the provider does not support
+var ErrTranslateTestInput = errors.New("invalid translate test input")
+
+// TranslateTestRequest is a one-off translation for prompt tuning. Prompt
+// and Temperature override the configured ones for this call only (ollama
+// and openrouter); with Title the text goes through the title prompt.
+type TranslateTestRequest struct {
+	Text        string   `json:"text"`
+	Title       bool     `json:"title"`
+	Prompt      string   `json:"prompt"`
+	Temperature *float64 `json:"temperature"`
+}
+
+// TranslateTestResult is the outcome of TranslateTest
+type TranslateTestResult struct {
+	Provider    string  `json:"provider"`
+	Model       string  `json:"model,omitempty"`
+	Temperature float64 `json:"temperature,omitempty"` // effective temperature (ollama, openrouter)
+	Output      string  `json:"output"`                // after translator.postprocess
+	RawOutput   string  `json:"raw_output,omitempty"`  // translator output when post-processing changed it
+	InputChars  int     `json:"input_chars"`
+	OutputChars int     `json:"output_chars"`
+	DurationMs  int64   `json:"duration_ms"` // translator call only
+}
+
+// TranslateTest translates req.Text with the configured translator (plus
+// the one-off overrides) and post-processing. Nothing is read from or
+// written to the DB, and the shared translator is left untouched.
+func (s *Service) TranslateTest(ctx context.Context, req TranslateTestRequest) (*TranslateTestResult, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: text is empty", ErrTranslateTestInput)
+	}
+	if n := utf8.RuneCountInString(text); n > MaxTranslateTestChars {
+		return nil, fmt.Errorf("%w: text is %d characters, at most %d allowed", ErrTranslateTestInput, n, MaxTranslateTestChars)
+	}
+
+	tc := s.cfg.Translator
+	result := &TranslateTestResult{Provider: tc.Provider, Model: s.translatorModel()}
+	var prompt, titlePrompt *string
+	var temperature *float64
+	switch tc.Provider {
+	case "ollama":
+		prompt, titlePrompt, temperature = &tc.Ollama.Prompt, &tc.Ollama.TitlePrompt, &tc.Ollama.Temperature
+	case "openrouter":
+		prompt, titlePrompt, temperature = &tc.OpenRouter.Prompt, &tc.OpenRouter.TitlePrompt, &tc.OpenRouter.Temperature
+	default:
+		if req.Prompt != "" || req.Temperature != nil {
+			return nil, fmt.Errorf("%w: prompt and temperature overrides need the ollama or openrouter provider, not %s", ErrTranslateTestInput, tc.Provider)
+		}
+	}
+	if req.Prompt != "" {
+		if req.Title {
+			*titlePrompt = req.Prompt
+		} else {
+			*prompt = req.Prompt
+		}
+	}
+	if req.Temperature != nil {
+		if t := *req.Temperature; t < 0 || t > 2 {
+			return nil, fmt.Errorf("%w: temperature must be between 0 and 2, got %g", ErrTranslateTestInput, t)
+		}
+		*temperature = *req.Temperature
+	}
+	if temperature != nil {
+		result.Temperature = *temperature
+	}
+
+	trans, err := s.createTranslator(&tc)
+	if err != nil {
+		return nil, err
+	}
+	post, err := s.postProcessor()
+	if err != nil {
+		return nil, err
+	}
+
+	translate := trans.Translate
+	if req.Title {
+		translate = trans.TranslateTitle
+	}
+	start := time.Now()
+	raw, err := translate(ctx, text)
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", trans.Name(), err)
+	}
+
+	result.Output = post.Apply(raw)
+	if result.Output != raw {
+		result.RawOutput = raw
+	}
+	result.InputChars = utf8.RuneCountInString(text)
+	result.OutputChars = utf8.RuneCountInString(result.Output)
+	return result, nil
+}
+
+// Regenerate renders every published article (or every translated one when
+// includeUnpublished is set) from the DB into outDir, mirroring the blog repo
+// layout (<content_dir>/posts/YYYY/MM/slug.md) plus the posts index.
+// Nothing is committed and publish flags are left untouched.
+func (s *Service) Regenerate(outDir string, includeUnpublished bool) (*RegenerateResult, error) {
+	if outDir == "" {
+		return nil, fmt.Errorf("output directory is required")
+	}
+
+	articles, err := s.store.GetTranslatedArticles(!includeUnpublished)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles: %w", err)
+	}
+
+	result := &RegenerateResult{
+		Total:  len(articles),
+		OutDir: outDir,
+	}
+
+	hugoCfg := s.cfg.Hugo
+	hugoCfg.Path = outDir
+	hugoCfg.AutoCommit = false
+	pub := publisher.NewHugoPublisher(&hugoCfg)
+
+	var written []*models.Article
+	for i, article := range articles {
+		if err := pub.Publish(article); err != nil {
+			s.printf("[%d/%d] ✗ Error: %v\n", i+1, len(articles), err)
+			result.Errors++
+			continue
+		}
+		written = append(written, article)
+		result.Written++
+	}
+
+	if len(written) > 0 {
+		indexPaths, err := pub.WriteIndex(written, s.cfg.Hugo.Index.Title)
+		if err != nil {
+			s.printf("✗ Error writing index: %v\n", err)
+			result.Errors++
+		}
+		if len(indexPaths) > 0 {
+			result.IndexPath = indexPaths[0]
+			result.IndexPages = len(indexPaths)
+		}
+	}
+
+	return result, nil
+}
+
+// PruneCandidates returns the articles Prune would delete for the cutoff
+func (s *Service) PruneCandidates(cutoff time.Time, publishedOnly bool) ([]*models.Article, error) {
+	return s.store.GetArticlesBefore(cutoff, publishedOnly)
+}
+
+// Prune deletes articles published before cutoff. With publishedOnly=true
+// unpublished articles are kept. With deleteFiles=true the matching markdown
+// files are removed from the blog repo first (GitHub API or local git); if
+// that fails the DB is left untouched so the files are not orphaned.
+func (s *Service) Prune(cutoff time.Time, publishedOnly, deleteFiles bool) (*PruneResult, error) {
+	result := &PruneResult{Cutoff: cutoff, Log: []string{}}
+
+	if deleteFiles {
+		articles, err := s.store.GetArticlesBefore(cutoff, publishedOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get articles: %w", err)
+		}
+		var published []*models.Article
+		for _, a := range articles {
+			if a.IsPublished() {
+				published = append(published, a)
+			}
+		}
+		if len(published) > 0 {
+			ghPub := publisher.NewGitHubPublisher(&s.cfg.Hugo, httpclient.Transport(&s.cfg.Network, httpclient.DestGitHub))
+			var n int
+			if ghPub.IsAvailable() {
+				n, err = ghPub.DeleteMultiple(published)
+			} else {
+				n, err = publisher.NewHugoPublisher(&s.cfg.Hugo).Remove(published)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete repo files (%d deleted): %w", n, err)
+			}
+			result.FilesDeleted = n
+			result.Log = append(result.Log, fmt.Sprintf("repo files deleted: %d", n))
+		}
+	}
+
+	deleted, err := s.store.DeleteArticlesBefore(cutoff, publishedOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete articles: %w", err)
+	}
+	result.Deleted = deleted
+	result.Log = append(result.Log, fmt.Sprintf("articles deleted: %d (published before %s)", deleted, cutoff.Format("2006-01-02")))
+	return result, nil
+}
+
+// VerifyPublished checks that every article flagged as published has its
+// file in the blog repo (GitHub API or the local checkout). With reset=true
+// the missing ones go back to "translated" so the next publish writes them.
+func (s *Service) VerifyPublished(reset bool) (*VerifyResult, error) {
+	articles, err := s.store.GetTranslatedArticles(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get published articles: %w", err)
+	}
+	result := &VerifyResult{Checked: len(articles), Missing: []MissingArticle{}}
+	if len(articles) == 0 {
+		return result, nil
+	}
+
+	var missing []publisher.MissingFile
+	ghPub := publisher.NewGitHubPublisher(&s.cfg.Hugo, httpclient.Transport(&s.cfg.Network, httpclient.DestGitHub))
+	if ghPub.IsAvailable() {
+		result.Target = "github"
+		missing, err = ghPub.Verify(articles)
+	} else {
+		result.Target = "local"
+		missing, err = publisher.NewHugoPublisher(&s.cfg.Hugo).Verify(articles)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify published files: %w", err)
+	}
+
+	for _, m := range missing {
+		title := m.Article.TitleRU
+		if title == "" {
+			title = m.Article.Title
+		}
+		result.Missing = append(result.Missing, MissingArticle{ID: m.Article.ID, Title: title, Path: m.Path})
+		if !reset {
+			continue
+		}
+		if err := s.store.SetArticleStatus(m.Article.ID, models.StatusTranslated); err != nil {
+			return result, fmt.Errorf("failed to reset article %d: %w", m.Article.ID, err)
+		}
+		result.Reset++
+	}
+	return result, nil
+}
+
+// feedFields returns the category/tags field mapping of a source (nil = defaults)
+func feedFields(source config.SourceConfig) *fetcher.FieldMapping {
+	if source.CategoryField == "" && source.TagsField == "" {
+		return nil
+	}
+	return &fetcher.FieldMapping{Category: source.CategoryField, Tags: source.TagsField}
+}
+
+// feedAuth builds the feed credentials of a source, expanding ${VAR}
+// references so secrets can stay in the environment. Returns nil when none.
+func feedAuth(source config.SourceConfig) *fetcher.FeedAuth {
+	auth := &fetcher.FeedAuth{
+		Username: os.ExpandEnv(source.Username),
+		Password: os.ExpandEnv(source.Password),
+	}
+	if len(source.Headers) > 0 {
+		auth.Headers = make(map[string]string, len(source.Headers))
+		for name, value := range source.Headers {
+			auth.Headers[name] = os.ExpandEnv(value)
+		}
+	}
+	if auth.IsEmpty() {
+		return nil
+	}
+	return auth
+}
+
+// feedPreviewTimeout bounds a PreviewFeed request, including redirects
+const feedPreviewTimeout = 15 * time.Second
+
+// PreviewFeed parses an arbitrary feed URL without saving anything and
+// returns its first limit items as they would map to articles, optionally
+// with a category/tags field mapping to try (fields may be nil). The URL comes
+// from the API, so internal addresses are refused (httpclient.ErrBlockedURL).
+func (s *Service) PreviewFeed(feedURL string, limit int, fields *fetcher.FieldMapping) (*FeedPreviewResult, error) {
+	u, err := url.Parse(strings.TrimSpace(feedURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", httpclient.ErrBlockedURL, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), feedPreviewTimeout)
+	defer cancel()
+	if err := httpclient.CheckPublicURL(ctx, u); err != nil {
+		return nil, err
+	}
+
+	rssFetcher := fetcher.NewRSSFetcher(httpclient.PublicTransport(&s.cfg.Network, httpclient.DestFeeds))
+	rssFetcher.SetTimeout(feedPreviewTimeout)
+	rssFetcher.SetSlugMaxLength(s.cfg.Hugo.SlugMaxLength)
+	articles, err := rssFetcher.FetchFeed(u.String(), SourceNameFromURL(u.String()), nil, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FeedPreviewResult{
+		URL:   u.String(),
+		Total: len(articles),
+		Items: []FeedPreviewItem{},
+	}
+	for _, a := range articles {
+		if len(result.Items) >= limit {
+			break
+		}
+		result.Items = append(result.Items, FeedPreviewItem{
+			Title:       a.Title,
+			URL:         a.SourceURL,
+			PublishedAt: a.PublishedAt,
+			Author:      a.Author,
+			ImageURL:    a.ImageURL,
+			Category:    a.Category,
+			Tags:        a.Tags,
+			Slug:        a.Slug,
+		})
+	}
+	return result, nil
+}
+
+// CleanContent re-runs the content cleanup (boilerplate, trailing related
+// articles, cutoff markers) over the stored raw text of the given articles,
+// or of every article of source (all sources when empty) when ids is empty.
+// No network access. With requeue, changed articles are re-translated and
+// re-published; with dryRun nothing is saved.
+func (s *Service) CleanContent(ids []int64, source string, requeue, dryRun bool) (*CleanContentResult, error) {
+	var articles []*models.Article
+	if len(ids) > 0 {
+		for _, id := range ids {
+			article, err := s.store.GetArticleByID(id)
+			if err == nil {
+				article.RawContent, err = s.store.GetRawContent(id)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("article %d: %w", id, err)
+			}
+			articles = append(articles, article)
+		}
+	} else {
+		var err error
+		if articles, err = s.store.GetArticlesWithRawContent(source); err != nil {
+			return nil, fmt.Errorf("failed to get articles: %w", err)
+		}
+	}
+
+	scraper := fetcher.NewArticleScraper(&s.cfg.Scraper, nil)
+	result := &CleanContentResult{Requeued: requeue, DryRun: dryRun}
+	for _, article := range articles {
+		if article.RawContent == "" {
+			result.NoRaw++
+			continue
+		}
+		result.Checked++
+		content := scraper.CleanContent(article.RawContent, article.SourceSite)
+		if content == "" || content == article.Content {
+			continue
+		}
+		result.Changed++
+		result.Articles = append(result.Articles, CleanContentChange{
+			ID: article.ID, Title: article.Title, Before: utf8.RuneCountInString(article.Content), After: utf8.RuneCountInString(content),
+		})
+		if dryRun {
+			continue
+		}
+		if err := s.store.UpdateContent(article.ID, content, requeue); err != nil {
+			return result, fmt.Errorf("failed to update article %d: %w", article.ID, err)
+		}
 	}
+	return result, nil
+}
 
-	return &StatsResult{
-		Total:       total,
-		Translated:  translated,
-		Published:   published,
-		Pending:     total - translated,
-		Unpublished: translated - published,
-	}, nil
+// RawContent returns an article's stored raw text with its cleaned content
+// and a re-clean by the current rules, for checking cleanup changes before
+// clean-content. No network access, nothing saved.
+func (s *Service) RawContent(id int64) (*RawContentView, error) {
+	article, err := s.store.GetArticleByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if article.RawContent, err = s.store.GetRawContent(id); err != nil {
+		return nil, err
+	}
+	view := &RawContentView{
+		ID:         article.ID,
+		Title:      article.Title,
+		HasRaw:     article.RawContent != "",
+		RawContent: article.RawContent,
+		Content:    article.Content,
+		RawChars:   utf8.RuneCountInString(article.RawContent),
+		Chars:      utf8.RuneCountInString(article.Content),
+	}
+	if view.HasRaw {
+		view.Recleaned = fetcher.NewArticleScraper(&s.cfg.Scraper, nil).CleanContent(article.RawContent, article.SourceSite)
+		view.Changed = view.Recleaned != "" && view.Recleaned != article.Content
+	}
+	return view, nil
 }
 
-// Pull pulls/updates blog repository
-func (s *Service) Pull() error {
-	pub := publisher.NewHugoPublisher(&s.cfg.Hugo)
-	return pub.GitPull()
+// Derived data rebuilt by Reindex
+const (
+	ReindexStatus  = "status"  // unknown/empty statuses inferred again, published flag synced with the status
+	ReindexImages  = "images"  // image list without blanks and duplicates, cover set from it when missing
+	ReindexTags    = "tags"    // hugo.formatter.auto_tags applied to articles stored before the rules
+	ReindexIndexes = "indexes" // SQLite REINDEX + ANALYZE after the articles
+)
+
+// ReindexKinds lists the rebuilds Reindex knows, in the order they run
+func ReindexKinds() []string {
+	return []string{ReindexStatus, ReindexImages, ReindexTags, ReindexIndexes}
 }
 
-// Push pushes changes to blog repository
-func (s *Service) Push() error {
-	pub := publisher.NewHugoPublisher(&s.cfg.Hugo)
-	return pub.GitPush()
+// jobReindex is the job kind of Reindex runs
+const jobReindex = "reindex"
+
+// defaultReindexBatch is the reindex batch size when none is given
+const defaultReindexBatch = 500
+
+// ReindexResult reports what Reindex changed
+type ReindexResult struct {
+	JobID   int64          `json:"job_id"`
+	Resumed bool           `json:"resumed"`
+	Kinds   []string       `json:"kinds"`
+	Checked int            `json:"checked"` // articles looked at in this run
+	Changed map[string]int `json:"changed"` // articles updated per rebuild
+	Total   int            `json:"total"`   // articles the job covers, resumed runs included
+	Done    int            `json:"done"`
 }
 
-// Rescrape re-scrapes articles that have empty content
-func (s *Service) Rescrape() (*RescrapeResult, error) {
-	articles, err := s.store.GetArticlesWithEmptyContent()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get articles: %w", err)
+// Reindex recomputes derived columns for every article after an upgrade,
+// walking the table in ID order in batches of batchSize (0 = 500). kinds
+// selects the rebuilds (see ReindexKinds; empty = all). Progress is
+// checkpointed in a job after every batch; resume continues the last
+// unfinished reindex job after the last article it finished, with the
+// rebuilds that job was started with.
+func (s *Service) Reindex(kinds []string, batchSize int, resume bool) (*ReindexResult, error) {
+	if len(kinds) == 0 {
+		kinds = ReindexKinds()
 	}
-
-	result := &RescrapeResult{
-		Total: len(articles),
+	for _, k := range kinds {
+		if !slices.Contains(ReindexKinds(), k) {
+			return nil, fmt.Errorf("unknown rebuild %q (expected one of: %s)", k, strings.Join(ReindexKinds(), ", "))
+		}
+	}
+	if batchSize <= 0 {
+		batchSize = defaultReindexBatch
 	}
 
-	if len(articles) == 0 {
-		return result, nil
+	var job *storage.Job
+	var err error
+	result := &ReindexResult{Kinds: kinds, Changed: make(map[string]int)}
+	if resume {
+		job, err = s.store.GetLatestJob(jobReindex)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("no reindex job to resume")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last reindex job: %w", err)
+		}
+		if job.Status == storage.JobDone {
+			return nil, fmt.Errorf("last reindex job #%d finished, nothing to resume", job.ID)
+		}
+		if job.Live() {
+			return nil, fmt.Errorf("reindex job #%d is still running", job.ID)
+		}
+		if err := s.store.ResumeJob(job); err != nil {
+			return nil, fmt.Errorf("failed to resume job #%d: %w", job.ID, err)
+		}
+		// jobs from before params were stored run the kinds asked for now
+		if len(job.Params) > 0 {
+			kinds = job.Params
+			result.Kinds = kinds
+		}
+		result.Resumed = true
+		s.printf("Resuming reindex job #%d after article #%d: %d of %d done\n", job.ID, job.Cursor, job.Processed, job.Total)
 	}
 
-	scraper := fetcher.NewArticleScraper()
+	status, images, tags := slices.Contains(kinds, ReindexStatus), slices.Contains(kinds, ReindexImages), slices.Contains(kinds, ReindexTags)
+	cursor := int64(0)
+	if job != nil {
+		cursor = job.Cursor
+	}
+	var remaining int
+	if status || images || tags {
+		if remaining, err = s.store.CountArticlesAfterID(cursor); err != nil {
+			return nil, err
+		}
+	}
+	if job == nil {
+		if job, err = s.store.CreateJob(jobReindex, remaining, nil, kinds); err != nil {
+			return nil, err
+		}
+	} else {
+		// Articles added or deleted since the interruption change the total
+		job.Total = job.Processed + remaining
+	}
+	result.JobID = job.ID
 
-	for _, article := range articles {
-		fmt.Printf("  Re-scraping: %s\n", article.Title)
-		if err := scraper.ScrapeArticle(article); err != nil {
-			fmt.Printf("  Warning: failed to scrape: %v\n", err)
-			result.Errors++
-			continue
+	for status || images || tags {
+		start := time.Now()
+		articles, err := s.store.GetArticlesAfterID(job.Cursor, batchSize)
+		if err != nil {
+			s.finishJob(job, storage.JobFailed, err.Error())
+			return result, fmt.Errorf("failed to get articles: %w", err)
+		}
+		if len(articles) == 0 {
+			break
+		}
+		for _, a := range articles {
+			changed := false
+			if status && rebuildStatus(a) {
+				result.Changed[ReindexStatus]++
+				changed = true
+			}
+			if images && rebuildImages(a) {
+				result.Changed[ReindexImages]++
+				changed = true
+			}
+			if tags && s.autoTagger.Apply(a) {
+				result.Changed[ReindexTags]++
+				changed = true
+			}
+			if changed {
+				if err := s.store.UpdateDerived(a); err != nil {
+					s.finishJob(job, storage.JobFailed, err.Error())
+					return result, err
+				}
+			}
 		}
+		result.Checked += len(articles)
+		job.Processed += len(articles)
+		job.Cursor = articles[len(articles)-1].ID
+		job.ElapsedMs += time.Since(start).Milliseconds()
+		if err := s.store.UpdateJobProgress(job); err != nil {
+			s.printf("Warning: failed to checkpoint job #%d: %v\n", job.ID, err)
+		}
+		s.printf("  %d/%d articles (up to #%d)\n", job.Processed, job.Total, job.Cursor)
+	}
 
-		if article.Content == "" {
-			fmt.Printf("  Still empty after re-scrape: %s\n", article.Title)
-			result.Errors++
-			continue
+	if slices.Contains(kinds, ReindexIndexes) {
+		s.printf("Rebuilding indexes...\n")
+		if err := s.store.RebuildIndexes(); err != nil {
+			s.finishJob(job, storage.JobFailed, err.Error())
+			return result, err
 		}
+	}
+	s.finishJob(job, storage.JobDone, "")
+	result.Total, result.Done = job.Total, job.Processed
+	return result, nil
+}
 
-		if err := s.store.UpdateArticle(article); err != nil {
-			fmt.Printf("  Error saving article: %v\n", err)
-			result.Errors++
-			continue
+// rebuildStatus infers an unknown or empty status from the article fields
+// and syncs the published flag with the status; reports whether it changed
+// anything
+func rebuildStatus(a *models.Article) bool {
+	changed := false
+	if !a.Status.Valid() {
+		a.Status = a.InferStatus()
+		changed = true
+	}
+	if a.PublishedToHugo != (a.Status == models.StatusPublished) {
+		changed = true
+	}
+	return changed
+}
+
+// rebuildImages drops blank and repeated image URLs and takes the cover from
+// the list when it is missing; reports whether it changed anything
+func rebuildImages(a *models.Article) bool {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, u := range a.ImageURLs {
+		if u = strings.TrimSpace(u); u != "" && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
 		}
+	}
+	changed := !slices.Equal(urls, a.ImageURLs)
+	a.ImageURLs = urls
+	if a.ImageURL == "" && len(urls) > 0 {
+		a.ImageURL = urls[0]
+		changed = true
+	}
+	return changed
+}
 
-		result.Rescraped++
-		fmt.Printf("  Re-scraped: %s (content: %d chars)\n", article.Title, len(article.Content))
+// DiscoverFeeds finds and validates the feeds a site advertises
+func (s *Service) DiscoverFeeds(siteURL string) ([]fetcher.DiscoveredFeed, error) {
+	rssFetcher := fetcher.NewRSSFetcher(httpclient.Transport(&s.cfg.Network, httpclient.DestFeeds))
+	return rssFetcher.Discover(siteURL)
+}
 
-		time.Sleep(1 * time.Second)
+// SourceNameFromURL derives a source name from a site URL
+// (https://www.rideapart.com/ -> rideapart)
+func SourceNameFromURL(siteURL string) string {
+	u, err := url.Parse(siteURL)
+	if err != nil || u.Hostname() == "" {
+		return "new-source"
 	}
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	if i := strings.LastIndex(host, "."); i > 0 {
+		host = host[:i]
+	}
+	return strings.ReplaceAll(host, ".", "-")
+}
 
-	return result, nil
+// ScrapeDebug re-fetches an article's source page and reports what the
+// scraper extracted and why. The article in the DB is not modified.
+func (s *Service) ScrapeDebug(id int64) (*fetcher.ScrapeDebug, error) {
+	article, err := s.store.GetArticleByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("article not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("article %d: %w", id, err)
+	}
+
+	scraper := s.newScraper(&s.cfg.Scraper)
+	return scraper.Debug(article.SourceURL, article.SourceSite)
+}
+
+// ListArticles returns recent articles filtered by status
+// (all, untranslated, translated, unpublished, published)
+func (s *Service) ListArticles(status string, limit int) ([]*models.Article, error) {
+	return s.store.GetArticlesByStatus(status, limit)
 }
 
 // Articles returns recent articles
@@ -495,35 +2866,626 @@ func (s *Service) Articles(limit int) ([]*interface{}, error) {
 	return result, nil
 }
 
-func (s *Service) createTranslator() (translator.Translator, error) {
+// markPublished flags all articles as published with the current render
+// fingerprint in a single transaction and mirrors that on the in-memory
+// structs only once it succeeded. With hugo.stable_permalinks the file path
+// of articles published for the first time is saved as well.
+func (s *Service) markPublished(articles []*models.Article) error {
+	ids := make([]int64, 0, len(articles))
+	for _, a := range articles {
+		ids = append(ids, a.ID)
+	}
+	fingerprint := s.renderFingerprint()
+	if err := s.store.MarkPublished(ids, fingerprint); err != nil {
+		return err
+	}
+	for _, a := range articles {
+		a.PublishedToHugo = true
+		a.Status = models.StatusPublished
+		a.RenderFingerprint = fingerprint
+	}
+	if !s.cfg.Hugo.StablePermalinks {
+		return nil
+	}
+	f := formatter.NewMarkdownFormatter(&s.cfg.Hugo.Formatter)
+	for _, a := range articles {
+		if a.PublishedPath != "" {
+			continue
+		}
+		p := f.PostPath(a)
+		if err := s.store.SetPublishedPath(a.ID, p); err != nil {
+			return fmt.Errorf("failed to save path of #%d: %w", a.ID, err)
+		}
+		a.PublishedPath = p
+	}
+	return nil
+}
+
+// renderFingerprint is the fingerprint of the configured markdown formatter
+func (s *Service) renderFingerprint() string {
+	return formatter.NewMarkdownFormatter(&s.cfg.Hugo.Formatter).Fingerprint()
+}
+
+// translateOutcome is the per-article result of a translation worker
+type translateOutcome struct {
+	log   []string
+	err   error
+	chars int64 // runes sent to the translator in successful calls
+}
+
+// translateArticle translates and saves a single article. It is called
+// concurrently from Translate, so it touches only its own article.
+// A non-empty pretranslatedTitle (from a batch request) is used as is.
+// post fixes up both translations before they are validated.
+func (s *Service) translateArticle(ctx context.Context, trans translator.Translator, post *translator.PostProcessor, article *models.Article, pretranslatedTitle string, i, n int) translateOutcome {
+	var out translateOutcome
+	articleStart := time.Now()
+	out.log = append(out.log, fmt.Sprintf("[%d/%d] %s", i+1, n, article.Title))
+	s.printf("[%d/%d] Translating: %s\n", i+1, n, article.Title)
+
+	ctx, span := tracing.Start(ctx, "translate.article")
+	defer span.End()
+	span.SetAttr("article.id", article.ID)
+
+	fail := func(stage string, err error) translateOutcome {
+		out.log = append(out.log, fmt.Sprintf("[%d/%d] ERROR (%s): %s", i+1, n, stage, err.Error()))
+		out.err = err
+		s.printf("  ✗ [%d/%d] Error (%s): %v\n", i+1, n, stage, err)
+		span.RecordError(err)
+		if article.NeedsTranslation() && stage != "save" {
+			if serr := s.store.SetArticleStatus(article.ID, models.StatusErrored); serr != nil {
+				s.printf("  ✗ [%d/%d] Error saving status: %v\n", i+1, n, serr)
+			}
+		}
+		return out
+	}
+
+	titleRU := pretranslatedTitle
+	var err error
+	if titleRU == "" {
+		titleRU, err = trans.TranslateTitle(ctx, article.Title)
+	}
+	if err == nil {
+		out.chars += int64(utf8.RuneCountInString(article.Title))
+		titleRU = post.Apply(titleRU)
+		err = validateTranslation(article.Title, titleRU, 0)
+	}
+	if err != nil {
+		return fail("title", err)
+	}
+
+	var contentRU string
+	truncated := false
+	if article.Content != "" {
+		var content string
+		content, truncated = truncateAtParagraph(article.Content, s.cfg.Translator.MaxContentChars)
+		contentRU, err = trans.Translate(ctx, content)
+		if err == nil {
+			out.chars += int64(utf8.RuneCountInString(content))
+			contentRU = post.Apply(contentRU)
+			err = validateTranslation(content, contentRU, s.cfg.Translator.MinOutputRatio)
+		}
+		if err != nil {
+			return fail("content", err)
+		}
+		if truncated {
+			contentRU += "\n\n" + truncatedNote
+			out.log = append(out.log, fmt.Sprintf("[%d/%d] content truncated to %d chars", i+1, n, s.cfg.Translator.MaxContentChars))
+		}
+	}
+
+	article.TitleRU = titleRU
+	// Re-slug from the translation only before the first publish, so
+	// existing post URLs never change
+	if s.cfg.Hugo.SlugSource == "translated" && !article.IsPublished() && article.TranslatedAt == nil {
+		if sl := slugify.MakeMax(titleRU, s.cfg.Hugo.SlugMaxLength); sl != "" {
+			article.Slug = sl
+		}
+	}
+	if article.Content != "" {
+		article.ContentRU = contentRU
+		article.ContentTruncated = truncated
+	}
+	now := time.Now()
+	article.TranslatedAt = &now
+	if !article.IsPublished() && article.ContentRU != "" {
+		article.Status = models.StatusTranslated
+	}
+	article.Translator = s.cfg.Translator.Provider
+	article.TranslatorModel = s.translatorModel()
+	// The translation may hold keywords the original did not
+	s.autoTagger.Apply(article)
+
+	if err := s.store.UpdateArticle(article); err != nil {
+		return fail("save", err)
+	}
+
+	elapsed := time.Since(articleStart).Round(time.Second)
+	out.log = append(out.log, fmt.Sprintf("[%d/%d] OK: %s (%s)", i+1, n, article.TitleRU, elapsed))
+	s.printf("  ✓ [%d/%d] Перевод: %s (%s)\n", i+1, n, article.TitleRU, elapsed)
+	return out
+}
+
+// titleBatchSize caps how many titles go into one batch request
+const titleBatchSize = 50
+
+// finishJob closes a job record; failures are only logged (job may be nil)
+func (s *Service) finishJob(job *storage.Job, status, errMsg string) {
+	if job == nil {
+		return
+	}
+	if err := s.store.FinishJob(job, status, errMsg); err != nil {
+		s.printf("Warning: failed to finish job #%d: %v\n", job.ID, err)
+	}
+}
+
+// batchTranslateTitles translates all article titles via the batch endpoint.
+// The returned slice is aligned with articles; entries of failed batches are
+// empty so those articles translate their title individually.
+func (s *Service) batchTranslateTitles(ctx context.Context, bt translator.BatchTranslator, articles []*models.Article, result *TranslateResult) []string {
+	ctx, span := tracing.Start(ctx, "translator.title_batch")
+	defer span.End()
+	span.SetAttr("titles", len(articles))
+
+	titles := make([]string, len(articles))
+	for start := 0; start < len(articles); start += titleBatchSize {
+		end := start + titleBatchSize
+		if end > len(articles) {
+			end = len(articles)
+		}
+		batch := make([]string, 0, end-start)
+		for _, a := range articles[start:end] {
+			batch = append(batch, a.Title)
+		}
+
+		translated, err := bt.TranslateBatch(ctx, batch)
+		if err != nil {
+			span.RecordError(err)
+			result.Log = append(result.Log, fmt.Sprintf("title batch %d-%d failed, translating one by one: %v", start+1, end, err))
+			continue
+		}
+		copy(titles[start:end], translated)
+	}
+	result.Log = append(result.Log, fmt.Sprintf("titles translated in batches of up to %d", titleBatchSize))
+	return titles
+}
+
+// recordTranslatedChars adds to the cumulative and the current month's
+// translated_chars counters; failures only warn, the translation itself is saved
+func (s *Service) recordTranslatedChars(chars int64) {
+	for _, name := range []string{
+		storage.CounterTranslatedChars,
+		storage.MonthlyCounter(storage.CounterTranslatedChars, time.Now()),
+	} {
+		if err := s.store.AddCounter(name, chars); err != nil {
+			s.printf("Warning: %v\n", err)
+		}
+	}
+}
+
+// validateTranslation rejects empty output and output shorter than minRatio
+// of the input (in characters), so a confused model does not mark the
+// article translated forever. minRatio <= 0 only checks for empty output.
+func validateTranslation(input, output string, minRatio float64) error {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return fmt.Errorf("translator returned empty output")
+	}
+	if minRatio <= 0 {
+		return nil
+	}
+	in := utf8.RuneCountInString(strings.TrimSpace(input))
+	out := utf8.RuneCountInString(output)
+	if in > 0 && float64(out) < float64(in)*minRatio {
+		return fmt.Errorf("translation too short: %d chars for %d input chars (min ratio %.2f)", out, in, minRatio)
+	}
+	return nil
+}
+
+// translateConcurrency returns translator.concurrency, or a per-provider
+// default when unset: a local Ollama model serves one request at a time,
+// hosted APIs tolerate a few in parallel.
+func (s *Service) translateConcurrency() int {
+	if c := s.cfg.Translator.Concurrency; c > 0 {
+		return c
+	}
+	switch s.cfg.Translator.Provider {
+	case "deepl", "google":
+		return 4
+	case "libretranslate", "openrouter":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// postProcessor builds the fixes for translator output from
+// translator.postprocess
+func (s *Service) postProcessor() (*translator.PostProcessor, error) {
+	cfg := s.cfg.Translator.PostProcess
+	rules := make([]translator.PostRule, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		rules[i] = translator.PostRule{Pattern: r.Pattern, Replace: r.Replace}
+	}
+	post, err := translator.NewPostProcessor(cfg.Builtin, rules)
+	if err != nil {
+		return nil, fmt.Errorf("translator.postprocess: %w", err)
+	}
+	return post, nil
+}
+
+// tracedTranslator wraps a Translator with a span per call
+type tracedTranslator struct {
+	translator.Translator
+}
+
+func (t *tracedTranslator) Translate(ctx context.Context, text string) (string, error) {
+	return t.trace(ctx, "translator.translate", text, t.Translator.Translate)
+}
+
+func (t *tracedTranslator) TranslateTitle(ctx context.Context, title string) (string, error) {
+	return t.trace(ctx, "translator.title", title, t.Translator.TranslateTitle)
+}
+
+func (t *tracedTranslator) trace(ctx context.Context, name, input string, fn func(context.Context, string) (string, error)) (string, error) {
+	ctx, span := tracing.Start(ctx, name)
+	defer span.End()
+	span.SetAttr("translator.name", t.Name())
+	span.SetAttr("input.bytes", len(input))
+
+	output, err := fn(ctx, input)
+	span.SetAttr("output.bytes", len(output))
+	span.RecordError(err)
+	return output, err
+}
+
+// publishMultipleTraced pushes articles via the GitHub API inside a span
+// and returns the number of commits created and the articles in the branch
+func publishMultipleTraced(ctx context.Context, ghPub *publisher.GitHubPublisher, articles []*models.Article, message string) (int, []*models.Article, error) {
+	_, span := tracing.Start(ctx, "github.publish")
+	defer span.End()
+	span.SetAttr("articles", len(articles))
+
+	commits, pushed, err := ghPub.PublishMultiple(articles, message)
+	span.SetAttr("commits", commits)
+	span.RecordError(err)
+	return commits, pushed, err
+}
+
+// withoutArticles returns the articles of all not in exclude
+func withoutArticles(all, exclude []*models.Article) []*models.Article {
+	skip := make(map[*models.Article]bool, len(exclude))
+	for _, a := range exclude {
+		skip[a] = true
+	}
+	var rest []*models.Article
+	for _, a := range all {
+		if !skip[a] {
+			rest = append(rest, a)
+		}
+	}
+	return rest
+}
+
+// publishWithRetry pushes articles to GitHub, retrying a failed attempt up to
+// hugo.publish_retries times with doubling delays. A single commit is atomic;
+// with hugo.max_files_per_commit a failure can leave the first commits of a
+// batch in the branch, and the retry skips the files they already carry.
+// Returns the number of attempts made, of commits created over all of
+// them and the articles in the branch after the last one (all of them on
+// success; those of the commits that landed on failure).
+func (s *Service) publishWithRetry(ctx context.Context, ghPub *publisher.GitHubPublisher, articles []*models.Article, message string, log *[]string) (int, int, []*models.Article, error) {
+	delay := time.Duration(s.cfg.Hugo.PublishRetryDelaySec) * time.Second
+	total := 0
+	var pushed []*models.Article
+	for attempt := 1; ; attempt++ {
+		commits, landed, err := publishMultipleTraced(ctx, ghPub, articles, message)
+		total += commits
+		pushed = append(withoutArticles(pushed, landed), landed...)
+		if err == nil || attempt > s.cfg.Hugo.PublishRetries {
+			return attempt, total, pushed, err
+		}
+		*log = append(*log, fmt.Sprintf("publish attempt %d failed: %v, retrying in %s", attempt, err, delay))
+		s.printf("  ✗ GitHub publish attempt %d failed: %v (retrying in %s)\n", attempt, err, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, total, pushed, err
+		}
+		delay *= 2
+	}
+}
+
+// truncatedNote is appended to translations of truncated articles
+const truncatedNote = "*Статья сокращена — полную версию читайте в источнике.*"
+
+// truncateAtParagraph cuts text to at most maxChars characters, ending on a
+// paragraph boundary when possible. maxChars <= 0 disables truncation.
+func truncateAtParagraph(text string, maxChars int) (string, bool) {
+	if maxChars <= 0 || utf8.RuneCountInString(text) <= maxChars {
+		return text, false
+	}
+
+	var kept []string
+	size := 0
+	for _, p := range strings.Split(text, "\n\n") {
+		n := utf8.RuneCountInString(p)
+		if len(kept) > 0 {
+			n += 2 // separator
+		}
+		if size+n > maxChars {
+			break
+		}
+		kept = append(kept, p)
+		size += n
+	}
+	if len(kept) > 0 {
+		return strings.Join(kept, "\n\n"), true
+	}
+
+	// First paragraph alone is too long: cut at the last space before the limit
+	runes := []rune(text)[:maxChars]
+	cut := string(runes)
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…", true
+}
+
+// translatorModel returns the model configured for the active provider.
+// DeepL, LibreTranslate and Google have no model selection, so they return "".
+func (s *Service) translatorModel() string {
 	switch s.cfg.Translator.Provider {
 	case "ollama":
-		return translator.NewOllamaTranslator(
-			s.cfg.Translator.Ollama.Host,
-			s.cfg.Translator.Ollama.Model,
-			s.cfg.Translator.Ollama.Prompt,
-			s.cfg.Translator.Ollama.TitlePrompt,
-			s.cfg.Translator.Ollama.Temperature,
-			s.cfg.Translator.Ollama.TopP,
-			s.cfg.Translator.Ollama.NumCtx,
-		), nil
+		return s.cfg.Translator.Ollama.Model
+	case "openrouter":
+		return s.cfg.Translator.OpenRouter.Model
+	default:
+		return ""
+	}
+}
+
+// translatorCache holds the translator shared by translate batches, so the
+// provider client (and e.g. the DeepL glossary lookup) is set up once and
+// its connection checked once instead of on every call
+type translatorCache struct {
+	mu      sync.Mutex
+	key     string // translator and network config trans was built from
+	trans   translator.Translator
+	checked bool       // CheckConnection passed for trans
+	check   *connCheck // check in flight, shared by concurrent callers
+}
+
+// connCheck is one CheckConnection run; err is set before done is closed
+type connCheck struct {
+	done chan struct{}
+	err  error
+}
+
+// translator returns the shared translator, building it on first use and
+// again when the translator or network config changed. With
+// translator.check_connection the provider is checked once per instance:
+// concurrent callers wait for the same check instead of each hitting the
+// provider. A failed check is not remembered, so the next batch retries it.
+func (s *Service) translator(ctx context.Context) (translator.Translator, error) {
+	c := &s.translators
+	c.mu.Lock()
+	trans, err := s.sharedTranslatorLocked()
+	if err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+	checker, ok := trans.(translator.ConnectionChecker)
+	if !s.cfg.Translator.CheckConnection || !ok || c.checked {
+		c.mu.Unlock()
+		return trans, nil
+	}
+
+	check := c.check
+	if check == nil {
+		check = &connCheck{done: make(chan struct{})}
+		c.check = check
+		c.mu.Unlock()
+
+		s.printf("Checking translator connection (%s)...\n", trans.Name())
+		check.err = checker.CheckConnection(ctx)
+		c.mu.Lock()
+		if c.check == check {
+			c.check = nil
+			c.checked = check.err == nil
+		}
+		c.mu.Unlock()
+		close(check.done)
+	} else {
+		c.mu.Unlock()
+		select {
+		case <-check.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if check.err != nil {
+		return nil, fmt.Errorf("translator %s unavailable: %w", trans.Name(), check.err)
+	}
+	return trans, nil
+}
+
+// sharedTranslatorLocked returns s.translators.trans, rebuilding it when the
+// translator or network config changed; the caller holds s.translators.mu
+func (s *Service) sharedTranslatorLocked() (translator.Translator, error) {
+	c := &s.translators
+	key := fmt.Sprintf("%#v|%#v", s.cfg.Translator, s.cfg.Network)
+	if c.trans == nil || c.key != key {
+		trans, err := s.createTranslator(&s.cfg.Translator)
+		if err != nil {
+			return nil, err
+		}
+		c.trans, c.key, c.checked, c.check = trans, key, false, nil
+	}
+	return c.trans, nil
+}
+
+// translatorStatusTimeout bounds one provider check made for TranslatorStatus
+const translatorStatusTimeout = 10 * time.Second
+
+// TranslatorStatus is the configured translator and the outcome of the
+// last check that it is reachable
+type TranslatorStatus struct {
+	Provider  string     `json:"provider"`
+	Name      string     `json:"name,omitempty"`  // as the translator reports itself
+	Model     string     `json:"model,omitempty"` // ollama and openrouter
+	Reachable *bool      `json:"reachable"`       // nil = the provider has no connection check
+	Error     string     `json:"error,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	// DeepL: characters used this billing period, the account's limit and
+	// what is left of it
+	QuotaUsed  int64 `json:"quota_used,omitempty"`
+	QuotaLimit int64 `json:"quota_limit,omitempty"`
+	QuotaLeft  int64 `json:"quota_left,omitempty"`
+}
+
+// translatorStatusCache holds the last TranslatorStatus so status requests
+// don't hit the provider more than once per translator.status_ttl_sec
+type translatorStatusCache struct {
+	mu     sync.Mutex
+	key    string // translator and network config status was checked with
+	status *TranslatorStatus
+}
+
+// TranslatorStatus reports the active translator and whether it answered a
+// connection check (DeepL: a usage request, which also gives the quota).
+// Checks are cached for translator.status_ttl_sec; concurrent callers wait
+// for the same check.
+func (s *Service) TranslatorStatus(ctx context.Context) (*TranslatorStatus, error) {
+	cache := &s.translatorStatus
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	key := fmt.Sprintf("%#v|%#v", s.cfg.Translator, s.cfg.Network)
+	ttl := time.Duration(s.cfg.Translator.StatusTTLSec) * time.Second
+	if st := cache.status; st != nil && cache.key == key && (st.CheckedAt == nil || time.Since(*st.CheckedAt) < ttl) {
+		copied := *st
+		return &copied, nil
+	}
+
+	s.translators.mu.Lock()
+	trans, err := s.sharedTranslatorLocked()
+	s.translators.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TranslatorStatus{
+		Provider: s.cfg.Translator.Provider,
+		Name:     trans.Name(),
+		Model:    s.translatorModel(),
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, translatorStatusTimeout)
+	defer cancel()
+	var checkErr error
+	switch t := trans.(type) {
+	case translator.UsageReporter:
+		var usage *translator.Usage
+		if usage, checkErr = t.Usage(checkCtx); checkErr == nil {
+			status.QuotaUsed, status.QuotaLimit = usage.Characters, usage.Limit
+			if usage.Limit > 0 {
+				status.QuotaLeft = max(usage.Limit-usage.Characters, 0)
+			}
+		}
+	case translator.ConnectionChecker:
+		checkErr = t.CheckConnection(checkCtx)
+	default:
+		cache.key, cache.status = key, status
+		copied := *status
+		return &copied, nil
+	}
+	if checkErr != nil && ctx.Err() != nil {
+		// The caller went away: that says nothing about the provider
+		return nil, ctx.Err()
+	}
+	reachable := checkErr == nil
+	checkedAt := time.Now()
+	status.Reachable, status.CheckedAt = &reachable, &checkedAt
+	if checkErr != nil {
+		status.Error = checkErr.Error()
+	}
+
+	cache.key, cache.status = key, status
+	copied := *status
+	return &copied, nil
+}
+
+// createTranslator builds the translator described by tc (normally
+// &s.cfg.Translator) and hands it the glossary when the provider supports one
+func (s *Service) createTranslator(tc *config.TranslatorConfig) (translator.Translator, error) {
+	trans, err := s.newProviderTranslator(tc)
+	if err != nil {
+		return nil, err
+	}
+	if len(tc.Glossary) > 0 {
+		if gt, ok := trans.(translator.GlossaryTranslator); ok {
+			gt.SetGlossary(s.glossary())
+		}
+	}
+	return trans, nil
+}
+
+// glossary converts translator.glossary to the translator type
+func (s *Service) glossary() translator.Glossary {
+	g := make(translator.Glossary, 0, len(s.cfg.Translator.Glossary))
+	for _, term := range s.cfg.Translator.Glossary {
+		g = append(g, translator.GlossaryEntry{Source: term.Source, Target: term.Target})
+	}
+	return g
+}
+
+func (s *Service) newProviderTranslator(tc *config.TranslatorConfig) (translator.Translator, error) {
+	transport := httpclient.Transport(&s.cfg.Network, httpclient.DestTranslator)
+	switch tc.Provider {
+	case "ollama":
+		ollama := translator.NewOllamaTranslator(
+			tc.Ollama.Host,
+			tc.Ollama.Model,
+			tc.Ollama.Prompt,
+			tc.Ollama.TitlePrompt,
+			tc.Ollama.Temperature,
+			tc.Ollama.TopP,
+			tc.Ollama.NumCtx,
+			transport,
+		)
+		ollama.SetEndpoint(tc.Ollama.Endpoint)
+		return ollama, nil
 	case "deepl":
 		return translator.NewDeepLTranslator(
-			s.cfg.Translator.DeepL.APIKey,
-			s.cfg.Translator.DeepL.Free,
+			tc.DeepL.APIKey,
+			tc.DeepL.Free,
+			tc.DeepL.Formality,
+			tc.DeepL.GlossaryID,
+			transport,
 		), nil
 	case "libretranslate":
-		return translator.NewLibreTranslateTranslator(s.cfg.Translator.LibreTranslate.Host), nil
+		return translator.NewLibreTranslateTranslator(tc.LibreTranslate.Host, transport), nil
+	case "google":
+		return translator.NewGoogleTranslator(
+			tc.Google.APIKey,
+			tc.Google.CredentialsFile,
+			tc.Google.ProjectID,
+			tc.Google.Location,
+			transport,
+		)
 	case "openrouter":
 		return translator.NewOpenRouterTranslator(
-			s.cfg.Translator.OpenRouter.BaseURL,
-			s.cfg.Translator.OpenRouter.Model,
-			s.cfg.Translator.OpenRouter.APIKey,
-			s.cfg.Translator.OpenRouter.Prompt,
-			s.cfg.Translator.OpenRouter.TitlePrompt,
-			s.cfg.Translator.OpenRouter.Temperature,
+			tc.OpenRouter.BaseURL,
+			tc.OpenRouter.Model,
+			tc.OpenRouter.APIKey,
+			tc.OpenRouter.Prompt,
+			tc.OpenRouter.TitlePrompt,
+			tc.OpenRouter.Temperature,
+			transport,
 		), nil
 	default:
-		return nil, fmt.Errorf("unknown translator provider: %s", s.cfg.Translator.Provider)
+		return nil, fmt.Errorf("unknown translator provider: %s", tc.Provider)
 	}
 }
@@ -0,0 +1,1504 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+	"moto-news/internal/slugify"
+	"moto-news/internal/storage"
+	"moto-news/internal/translator"
+)
+
+// newTestService returns a service on a fresh database with progress output
+// discarded; cfg may be nil for the zero config
+func newTestService(t *testing.T, cfg *config.Config) *Service {
+	t.Helper()
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	st, err := storage.NewSQLiteStorage(&config.DatabaseConfig{Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	s := NewService(cfg, st)
+	s.SetProgress(func(string, ...any) {})
+	return s
+}
+
+// feedItem is an item of the feed served by newTestSite
+type feedItem struct {
+	title, link, extra string // extra is raw XML added to the <item>
+}
+
+// newTestSite serves an RSS feed of items at /feed and the article pages
+// (path -> HTML, served as text/html) of the site; pages may be changed
+// between requests
+func newTestSite(t *testing.T, items func(base string) []feedItem, pages map[string]string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0"?><rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/"><channel><title>Test</title>`)
+		for _, it := range items(srv.URL) {
+			fmt.Fprintf(&b, "<item><title>%s</title><link>%s</link><pubDate>%s</pubDate>%s</item>",
+				it.title, it.link, time.Now().UTC().Format(time.RFC1123Z), it.extra)
+		}
+		b.WriteString("</channel></rss>")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(b.String()))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		html, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(html))
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// articlePage is an article page with its body in JSON-LD
+func articlePage(body string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `<html><head><script type="application/ld+json">{"@type":"NewsArticle","articleBody":"` +
+		r.Replace(body) + `"}</script></head><body></body></html>`
+}
+
+// fetchConfig is a config with one enabled source per feed URL, allowed to
+// scrape httptest servers
+func fetchConfig(feeds ...string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Scraper.URLPolicy = "scheme"
+	for i, feed := range feeds {
+		cfg.Sources = append(cfg.Sources, config.SourceConfig{Name: fmt.Sprintf("source%d", i+1), Feeds: []string{feed}, Enabled: true})
+	}
+	return cfg
+}
+
+func TestHoldRecentPublishDelay(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Schedule.PublishDelayDuration = 2 * time.Hour
+	s := newTestService(t, cfg)
+
+	now := time.Now()
+	old := &models.Article{ID: 1, Title: "old", FetchedAt: now.Add(-3 * time.Hour), Status: models.StatusTranslated}
+	fresh := &models.Article{ID: 2, Title: "fresh", FetchedAt: now.Add(-10 * time.Minute), Status: models.StatusTranslated}
+	republish := &models.Article{ID: 3, Title: "republish", FetchedAt: now, Status: models.StatusPublished}
+
+	var log []string
+	ready, held := s.holdRecent([]*models.Article{old, fresh, republish}, &log)
+	if len(ready) != 2 || ready[0] != old || ready[1] != republish {
+		t.Errorf("ready = %v, want the old article and the re-render", ready)
+	}
+	if len(held) != 1 || held[0] != fresh {
+		t.Errorf("held = %v, want the freshly fetched article", held)
+	}
+	if len(log) != 1 {
+		t.Errorf("log = %q, want one skipped line", log)
+	}
+
+	cfg.Schedule.PublishDelayDuration = 0
+	ready, held = s.holdRecent([]*models.Article{old, fresh}, &log)
+	if len(ready) != 2 || len(held) != 0 {
+		t.Errorf("without a delay ready=%d held=%d, want 2 and 0", len(ready), len(held))
+	}
+}
+
+func TestFetchSkipsPDFAndFollowsPermanentRedirects(t *testing.T) {
+	srv := newTestSite(t, func(base string) []feedItem {
+		return []feedItem{
+			{title: "Brochure", link: base + "/brochure.pdf"},
+			{title: "Moved", link: base + "/old"},
+		}
+	}, map[string]string{"/article": articlePage("The new bike has more power.")})
+	mux := srv.Config.Handler.(*http.ServeMux)
+	mux.HandleFunc("/brochure.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7"))
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/older", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/older", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/article", http.StatusPermanentRedirect)
+	})
+
+	s := newTestService(t, fetchConfig(srv.URL+"/feed"))
+	result, err := s.Fetch("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NotHTML != 1 || result.NewArticles != 1 || result.Errors != 0 {
+		t.Fatalf("not_html=%d new=%d errors=%d, want 1, 1, 0\n%s", result.NotHTML, result.NewArticles, result.Errors, strings.Join(result.Log, "\n"))
+	}
+	if !strings.Contains(strings.Join(result.Log, "\n"), "moved permanently") {
+		t.Errorf("log does not report the redirect:\n%s", strings.Join(result.Log, "\n"))
+	}
+
+	a, err := s.store.GetArticleByURL(srv.URL + "/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.SourceURL != srv.URL+"/article" || a.FeedURL != srv.URL+"/old" {
+		t.Errorf("stored SourceURL=%s FeedURL=%s", a.SourceURL, a.FeedURL)
+	}
+
+	// The next fetch recognizes the feed link of the moved article
+	result, err = s.Fetch("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NewArticles != 0 || result.SkippedArticles != 1 {
+		t.Errorf("second fetch: new=%d skipped=%d, want 0 and 1", result.NewArticles, result.SkippedArticles)
+	}
+}
+
+func TestCleanContentReprocessesStoredRaw(t *testing.T) {
+	s := newTestService(t, nil)
+	now := time.Now()
+	stale := &models.Article{
+		SourceURL: "https://example.com/a", SourceSite: "example", Title: "A", Slug: "a",
+		RawContent:  "Ducati&#8217;s new Panigale is lighter.\n\nSubscribe to our newsletter",
+		Content:     "Ducati&#8217;s new Panigale is lighter.\n\nSubscribe to our newsletter",
+		PublishedAt: now, FetchedAt: now,
+	}
+	noRaw := &models.Article{
+		SourceURL: "https://example.com/b", SourceSite: "example", Title: "B", Slug: "b",
+		Content: "Kept as is.", PublishedAt: now, FetchedAt: now,
+	}
+	for _, a := range []*models.Article{stale, noRaw} {
+		if err := s.store.InsertArticle(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dry, err := s.CleanContent(nil, "", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.Checked != 1 || dry.Changed != 1 {
+		t.Fatalf("dry run: checked=%d changed=%d, want 1 and 1", dry.Checked, dry.Changed)
+	}
+	if got, _ := s.store.GetArticleByID(stale.ID); got.Content != stale.Content {
+		t.Errorf("dry run saved the content")
+	}
+
+	result, err := s.CleanContent(nil, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Changed != 1 {
+		t.Fatalf("changed = %d, want 1", result.Changed)
+	}
+	got, _ := s.store.GetArticleByID(stale.ID)
+	if got.Content != "Ducati’s new Panigale is lighter." {
+		t.Errorf("content = %q", got.Content)
+	}
+
+	// By id: articles without raw content are reported, not changed
+	result, err = s.CleanContent([]int64{stale.ID, noRaw.ID}, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Checked != 1 || result.Changed != 0 || result.NoRaw != 1 {
+		t.Errorf("by id: checked=%d changed=%d no_raw=%d, want 1, 0, 1", result.Checked, result.Changed, result.NoRaw)
+	}
+}
+
+func TestRecheckContent(t *testing.T) {
+	paragraphs := make([]string, 12)
+	for i := range paragraphs {
+		paragraphs[i] = fmt.Sprintf("Paragraph %d of the Street Triple review, long enough to read like text.", i+1)
+	}
+	body := strings.Join(paragraphs, "\n\n")
+	pages := map[string]string{
+		"/same":  articlePage(body),
+		"/typo":  articlePage(body),
+		"/moved": articlePage(body),
+	}
+	srv := newTestSite(t, func(base string) []feedItem {
+		return []feedItem{{title: "Same", link: base + "/same"}, {title: "Typo", link: base + "/typo"}, {title: "Moved", link: base + "/moved"}}
+	}, pages)
+
+	cfg := fetchConfig(srv.URL + "/feed")
+	cfg.Scraper.KeepRawContent = true
+	cfg.Scraper.RecheckDays = 7
+	cfg.Scraper.RecheckBatch = 10
+	cfg.Scraper.UpdateMinChange = 0.1
+	s := newTestService(t, cfg)
+	if result, err := s.Fetch(""); err != nil || result.NewArticles != 3 {
+		t.Fatalf("fetch: %+v, %v", result, err)
+	}
+
+	// One paragraph of twelve edited: below update_min_change
+	pages["/typo"] = articlePage(strings.Replace(body, "Paragraph 5 of", "Paragraph 5 in", 1))
+	pages["/moved"] = articlePage("Triumph has delayed the Street Triple, the company said today.\n\n" +
+		"Supplier problems are to blame and there is no new date yet.\n\n" + strings.Join(paragraphs[2:], "\n\n"))
+
+	result, err := s.RecheckContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 3 || result.Changed != 1 || result.Minor != 1 || result.Unchanged != 1 || result.Errors != 0 {
+		t.Fatalf("result = %+v, want 1 changed, 1 minor, 1 unchanged", result)
+	}
+
+	moved, _ := s.store.GetArticleByURL(srv.URL + "/moved")
+	if len(result.ChangedIDs) != 1 || result.ChangedIDs[0] != moved.ID {
+		t.Errorf("changed ids = %v, want [%d]", result.ChangedIDs, moved.ID)
+	}
+	if !strings.HasPrefix(moved.Content, "Triumph has delayed") || moved.Status != models.StatusScraped {
+		t.Errorf("changed article not updated: status %s, content %.40q", moved.Status, moved.Content)
+	}
+	typo, _ := s.store.GetArticleByURL(srv.URL + "/typo")
+	if !strings.Contains(typo.Content, "Paragraph 5 of") {
+		t.Errorf("minor change was taken: %q", typo.Content)
+	}
+
+	// Checked articles wait for scraper.recheck_interval_hours
+	cfg.Scraper.RecheckIntervalHours = 24
+	if result, err = s.RecheckContent(); err != nil || result.Total != 0 {
+		t.Errorf("second run: %+v, %v; want nothing due", result, err)
+	}
+}
+
+// newFailingGitHub serves the Git Trees API of owner/repo and fails the
+// failTree-th (1-based) tree request
+func newFailingGitHub(t *testing.T, failTree int) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	head, trees := "c0", 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, "/api/v3/repos/owner/repo")
+		switch {
+		case r.Method == "GET" && path == "/git/ref/heads/main":
+			fmt.Fprintf(w, `{"object":{"sha":%q}}`, head)
+		case r.Method == "GET" && strings.HasPrefix(path, "/git/commits/"):
+			fmt.Fprintf(w, `{"sha":%q,"tree":{"sha":"base"}}`, strings.TrimPrefix(path, "/git/commits/"))
+		case r.Method == "POST" && path == "/git/trees":
+			trees++
+			if trees == failTree {
+				http.Error(w, `{"message":"server error"}`, http.StatusBadGateway)
+				return
+			}
+			fmt.Fprintf(w, `{"sha":"t%d"}`, trees)
+		case r.Method == "POST" && path == "/git/commits":
+			fmt.Fprintf(w, `{"sha":"c%d"}`, trees)
+		case r.Method == "PATCH" && path == "/git/refs/heads/main":
+			head = fmt.Sprintf("c%d", trees)
+			fmt.Fprintf(w, `{"object":{"sha":%q}}`, head)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPublishMarksArticlesOfLandedCommits(t *testing.T) {
+	srv := newFailingGitHub(t, 2)
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	cfg := &config.Config{Hugo: config.HugoConfig{
+		ContentDir:        "content",
+		GitRepo:           "owner/repo",
+		APIBaseURL:        srv.URL,
+		MaxFilesPerCommit: 1,
+	}}
+	s := newTestService(t, cfg)
+	published := time.Now().Add(-time.Hour)
+	for i := 1; i <= 3; i++ {
+		a := &models.Article{
+			SourceURL:    fmt.Sprintf("https://example.com/%d", i),
+			Title:        fmt.Sprintf("Article %d", i),
+			TitleRU:      fmt.Sprintf("Статья %d", i),
+			ContentRU:    "Текст.",
+			Slug:         fmt.Sprintf("article-%d", i),
+			PublishedAt:  published.Add(time.Duration(i) * time.Minute),
+			FetchedAt:    published,
+			TranslatedAt: &published,
+			Status:       models.StatusTranslated,
+		}
+		if err := s.store.InsertArticle(a); err != nil {
+			t.Fatalf("InsertArticle: %v", err)
+		}
+	}
+
+	result, err := s.Publish(10, false)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if result.Published != 1 || result.Errors != 2 {
+		t.Errorf("published = %d, errors = %d, want 1 and 2", result.Published, result.Errors)
+	}
+	pending, err := s.store.GetUnpublishedArticles(10, false, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Errorf("%d articles left to publish, want 2", len(pending))
+	}
+}
+
+func TestTranslateAutoPublishPartial(t *testing.T) {
+	srv := newFailingGitHub(t, 2)
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	cfg := &config.Config{Hugo: config.HugoConfig{
+		ContentDir:        "content",
+		GitRepo:           "owner/repo",
+		APIBaseURL:        srv.URL,
+		MaxFilesPerCommit: 1,
+	}}
+	useTestTranslator(t, cfg)
+	s := newTestService(t, cfg)
+	for i := 1; i <= 3; i++ {
+		insertScraped(t, s, fmt.Sprintf("https://example.com/%d", i))
+	}
+
+	result, err := s.Translate(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Translated != 3 {
+		t.Fatalf("translated %d articles, want 3 (last error %q)", result.Translated, result.LastError)
+	}
+	if result.PublishStatus != PublishPartial || result.PublishedThisBatch != 1 || result.PublishError == "" || result.PublishAttempts != 1 {
+		t.Errorf("publish status=%q published=%d error=%q attempts=%d, want partial, 1, the error and 1",
+			result.PublishStatus, result.PublishedThisBatch, result.PublishError, result.PublishAttempts)
+	}
+	outcomes := map[string]int{}
+	for _, a := range result.Articles {
+		outcomes[a.Publish]++
+		if (a.Publish == OutcomeFailed) != (a.PublishError != "") {
+			t.Errorf("article %d: publish=%q publish_error=%q", a.ID, a.Publish, a.PublishError)
+		}
+	}
+	if outcomes[OutcomePublished] != 1 || outcomes[OutcomeFailed] != 2 {
+		t.Errorf("publish outcomes = %v, want 1 published and 2 failed", outcomes)
+	}
+	if pending, _ := s.store.GetUnpublishedArticles(10, false, time.Time{}); len(pending) != 2 {
+		t.Errorf("%d articles left to publish, want 2", len(pending))
+	}
+}
+
+func TestFetchFailedScrapeIsNewUntilRescraped(t *testing.T) {
+	pages := map[string]string{}
+	srv := newTestSite(t, func(base string) []feedItem {
+		return []feedItem{{title: "Down", link: base + "/down"}}
+	}, pages)
+
+	s := newTestService(t, fetchConfig(srv.URL+"/feed"))
+	if _, err := s.Fetch(""); err != nil {
+		t.Fatal(err)
+	}
+	a, err := s.store.GetArticleByURL(srv.URL + "/down")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Status != models.StatusNew {
+		t.Fatalf("status after a failed scrape = %q, want new", a.Status)
+	}
+
+	pages["/down"] = articlePage("The page is back with the full story.")
+	result, err := s.Rescrape()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || result.Rescraped != 1 {
+		t.Fatalf("rescrape total=%d rescraped=%d, want 1 and 1", result.Total, result.Rescraped)
+	}
+	a, _ = s.store.GetArticleByID(a.ID)
+	if a.Status != models.StatusScraped || a.Content == "" {
+		t.Errorf("after rescrape status=%q content=%q", a.Status, a.Content)
+	}
+}
+
+// useTestTranslator points cfg at a LibreTranslate stand-in that returns
+// every text prefixed with "RU "
+func useTestTranslator(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	useTranslatorReply(t, cfg, func(text string) string { return "RU " + text })
+}
+
+// useTranslatorReply points cfg at a fake LibreTranslate that answers each
+// text with reply(text)
+func useTranslatorReply(t *testing.T, cfg *config.Config, reply func(string) string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Q any `json:"q"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var out any
+		switch q := req.Q.(type) {
+		case string:
+			out = reply(q)
+		case []any:
+			texts := make([]string, len(q))
+			for i, text := range q {
+				texts[i] = reply(fmt.Sprint(text))
+			}
+			out = texts
+		}
+		json.NewEncoder(w).Encode(map[string]any{"translatedText": out})
+	}))
+	t.Cleanup(srv.Close)
+	cfg.Translator.Provider = "libretranslate"
+	cfg.Translator.LibreTranslate.Host = srv.URL
+}
+
+// insertScraped stores a scraped article waiting for translation
+func insertScraped(t *testing.T, s *Service, url string) *models.Article {
+	t.Helper()
+	now := time.Now()
+	a := &models.Article{
+		SourceURL:   url,
+		SourceSite:  "example.com",
+		Title:       "Title of " + url,
+		Content:     "The text of " + url + ".",
+		PublishedAt: now,
+		FetchedAt:   now,
+	}
+	if err := s.store.InsertArticle(a); err != nil {
+		t.Fatalf("InsertArticle: %v", err)
+	}
+	return a
+}
+
+func TestTranslateConcurrencyBound(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Translator.Concurrency = 3
+	var inFlight, peak atomic.Int32
+	useTranslatorReply(t, cfg, func(text string) string {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return "RU " + text
+	})
+	s := newTestService(t, cfg)
+	for i := 0; i < 10; i++ {
+		insertScraped(t, s, fmt.Sprintf("https://example.com/%d", i))
+	}
+
+	result, err := s.Translate(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Translated != 10 {
+		t.Fatalf("translated %d articles, want 10 (last error %q)", result.Translated, result.LastError)
+	}
+	if p := peak.Load(); p > 3 || p < 2 {
+		t.Errorf("peak in-flight translations = %d, want 2..3 with translator.concurrency 3", p)
+	}
+}
+
+func TestTranslateResumeSticksToJobArticles(t *testing.T) {
+	cfg := &config.Config{}
+	useTestTranslator(t, cfg)
+	s := newTestService(t, cfg)
+	done := insertScraped(t, s, "https://example.com/done")
+	left := insertScraped(t, s, "https://example.com/left")
+	other := insertScraped(t, s, "https://example.com/other")
+
+	job, err := s.store.CreateJob(jobTranslate, 2, []int64{done.ID, left.ID}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.TranslateResume(); err == nil {
+		t.Fatal("resumed a job that is still running")
+	}
+	job.Processed = 1
+	if err := s.store.FinishJob(job, storage.JobFailed, "translator down"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.store.SetArticleStatus(done.ID, models.StatusTranslated); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.TranslateResume()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.JobID != job.ID || result.Translated != 1 {
+		t.Fatalf("job=%d translated=%d, want job %d and 1 article", result.JobID, result.Translated, job.ID)
+	}
+	if got, _ := s.store.GetArticleByID(left.ID); got.Status != models.StatusTranslated || got.TitleRU != "RU "+left.Title {
+		t.Errorf("job article: status=%q title_ru=%q", got.Status, got.TitleRU)
+	}
+	if got, _ := s.store.GetArticleByID(other.ID); got.Status != models.StatusScraped {
+		t.Errorf("article outside the job was translated (status %q)", got.Status)
+	}
+	if got, _ := s.store.GetJob(job.ID); got.Status != storage.JobDone || got.Processed != 2 {
+		t.Errorf("job status=%q processed=%d, want done and 2", got.Status, got.Processed)
+	}
+}
+
+func TestRegenerateIndexTitle(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Hugo.ContentDir = "content"
+	cfg.Hugo.Index.Title = "Moto News"
+	s := newTestService(t, cfg)
+	a := insertScraped(t, s, "https://example.com/published")
+	a.TitleRU, a.ContentRU, a.Slug = "Статья", "Текст.", "published"
+	if err := s.store.UpdateArticle(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.store.MarkPublished([]int64{a.ID}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.Regenerate(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Written != 1 || result.IndexPath == "" {
+		t.Fatalf("written=%d index=%q, want 1 article and an index", result.Written, result.IndexPath)
+	}
+	index, err := os.ReadFile(result.IndexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(index), "# Moto News\n") {
+		t.Errorf("index starts with %q, want the configured title", strings.SplitN(string(index), "\n", 2)[0])
+	}
+}
+
+func TestFetchFeedContentSharesTheSavePath(t *testing.T) {
+	var body strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&body, "<p>Paragraph %d of the full story, embedded in the feed item.</p>", i)
+	}
+	extra := "<category>Reviews</category><category>Reviews</category><content:encoded><![CDATA[" + body.String() + "]]></content:encoded>"
+	srv := newTestSite(t, func(base string) []feedItem {
+		return []feedItem{{title: "Full", link: base + "/full", extra: extra}}
+	}, map[string]string{})
+
+	// Both sources list the item; only the first saves it, and no page is scraped
+	s := newTestService(t, fetchConfig(srv.URL+"/feed", srv.URL+"/feed"))
+	result, err := s.Fetch("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := strings.Join(result.Log, "\n")
+	if result.NewArticles != 1 || result.SkippedArticles != 1 || result.Errors != 0 {
+		t.Fatalf("new=%d skipped=%d errors=%d, want 1, 1, 0\n%s", result.NewArticles, result.SkippedArticles, result.Errors, log)
+	}
+	if !strings.Contains(log, "[source1 1/1] feed content used") {
+		t.Errorf("log does not show the feed content path:\n%s", log)
+	}
+
+	a, err := s.store.GetArticleByURL(srv.URL + "/full")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Status != models.StatusScraped || !strings.HasPrefix(a.Content, "Paragraph 1 of") {
+		t.Errorf("status=%q content=%.40q", a.Status, a.Content)
+	}
+	if a.Category != "Reviews" || len(a.Tags) != 1 {
+		t.Errorf("category=%q tags=%q, want Reviews once", a.Category, a.Tags)
+	}
+}
+
+func TestFetchNearDuplicateKeepsHigherPrioritySource(t *testing.T) {
+	page := map[string]string{"/story": articlePage("The full story of the new bike.")}
+	low := newTestSite(t, func(base string) []feedItem {
+		return []feedItem{{title: "Ducati unveils new Panigale V4 R for 2027", link: base + "/story"}}
+	}, page)
+	high := newTestSite(t, func(base string) []feedItem {
+		return []feedItem{{title: "Ducati Unveils the New 2027 Panigale V4 R", link: base + "/story"}}
+	}, page)
+
+	cfg := fetchConfig(low.URL+"/feed", high.URL+"/feed")
+	cfg.Sources[1].Priority = 10
+	cfg.Schedule.DedupTitleSimilarity = 0.6
+	cfg.Schedule.DedupWindowHours = 48
+
+	stored := func(s *Service) []*models.Article {
+		t.Helper()
+		articles, err := s.store.GetAllArticles(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return articles
+	}
+
+	// In one fetch the higher-priority source is read first and wins
+	s := newTestService(t, cfg)
+	result, err := s.Fetch("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := strings.Join(result.Log, "\n")
+	if result.NewArticles != 1 || result.SkippedArticles != 1 || !strings.Contains(log, "near-duplicate of") {
+		t.Fatalf("new=%d skipped=%d, want 1 and 1\n%s", result.NewArticles, result.SkippedArticles, log)
+	}
+	if articles := stored(s); len(articles) != 1 || articles[0].SourceSite != "source2" {
+		t.Fatalf("stored %d articles, want only the one of source2", len(articles))
+	}
+
+	// An unpublished article of the lower-priority source, fetched earlier,
+	// gives way to the higher-priority one
+	s = newTestService(t, cfg)
+	if _, err := s.Fetch("source1"); err != nil {
+		t.Fatal(err)
+	}
+	result, err = s.Fetch("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log = strings.Join(result.Log, "\n")
+	if result.NewArticles != 1 || !strings.Contains(log, "replaced near-duplicate") {
+		t.Fatalf("new=%d, want the source2 article to replace the stored one\n%s", result.NewArticles, log)
+	}
+	if articles := stored(s); len(articles) != 1 || articles[0].SourceSite != "source2" {
+		t.Fatalf("stored %d articles, want only the one of source2", len(articles))
+	}
+
+	// Later fetches keep skipping the lower-priority copy
+	result, err = s.Fetch("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NewArticles != 0 || len(stored(s)) != 1 {
+		t.Errorf("new=%d stored=%d after a refetch, want 0 and 1", result.NewArticles, len(stored(s)))
+	}
+}
+
+func TestFetchNearDuplicateKeptWhenReplacementFails(t *testing.T) {
+	low := newTestSite(t, func(base string) []feedItem {
+		return []feedItem{{title: "Ducati unveils new Panigale V4 R for 2027", link: base + "/story"}}
+	}, map[string]string{"/story": articlePage("The full story of the new bike.")})
+	// the higher-priority copy links to a PDF, which is not saved
+	pdf := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4"))
+	}))
+	t.Cleanup(pdf.Close)
+	high := newTestSite(t, func(string) []feedItem {
+		return []feedItem{{title: "Ducati Unveils the New 2027 Panigale V4 R", link: pdf.URL + "/story.pdf"}}
+	}, nil)
+
+	cfg := fetchConfig(low.URL+"/feed", high.URL+"/feed")
+	cfg.Sources[1].Priority = 10
+	cfg.Schedule.DedupTitleSimilarity = 0.6
+	cfg.Schedule.DedupWindowHours = 48
+	s := newTestService(t, cfg)
+	if _, err := s.Fetch("source1"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.Fetch("source2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NewArticles != 0 || result.NotHTML != 1 {
+		t.Fatalf("new=%d not_html=%d, want the PDF skipped\n%s", result.NewArticles, result.NotHTML, strings.Join(result.Log, "\n"))
+	}
+	articles, err := s.store.GetAllArticles(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(articles) != 1 || articles[0].SourceSite != "source1" {
+		t.Errorf("stored %d articles, want the lower-priority one kept", len(articles))
+	}
+}
+
+func TestAutoTagsAreStored(t *testing.T) {
+	srv := newTestSite(t, func(base string) []feedItem {
+		return []feedItem{
+			{title: "Zero unveils an electric naked bike", link: base + "/zero"},
+			{title: "Every rider loves events", link: base + "/every"},
+		}
+	}, map[string]string{
+		"/zero":  articlePage("The new model has a 200 km range."),
+		"/every": articlePage("Eleven events this season."),
+	})
+	cfg := fetchConfig(srv.URL + "/feed")
+	useTestTranslator(t, cfg)
+	cfg.Hugo.Formatter.AutoTags = []config.AutoTagConfig{
+		{Tag: "Электромотоциклы", Keywords: []string{"electric", "ev"}},
+		// the test translator prefixes "RU ", so only translations match
+		{Tag: "Переведено", Keywords: []string{"ru"}},
+	}
+	s := newTestService(t, cfg)
+
+	if _, err := s.Fetch(""); err != nil {
+		t.Fatal(err)
+	}
+	tags := func(path string) []string {
+		t.Helper()
+		a, err := s.store.GetArticleByURL(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a.Tags
+	}
+	if got := tags("/zero"); !slices.Equal(got, []string{"Электромотоциклы"}) {
+		t.Errorf("after fetch: zero tags = %q, want the electric tag", got)
+	}
+	if got := tags("/every"); len(got) != 0 {
+		t.Errorf("after fetch: every tags = %q, want none", got)
+	}
+
+	if _, err := s.Translate(10); err != nil {
+		t.Fatal(err)
+	}
+	if got := tags("/zero"); !slices.Equal(got, []string{"Электромотоциклы", "Переведено"}) {
+		t.Errorf("after translation: zero tags = %q", got)
+	}
+	if got := tags("/every"); !slices.Equal(got, []string{"Переведено"}) {
+		t.Errorf("after translation: every tags = %q", got)
+	}
+}
+
+func TestReindexTagsAddsAutoTags(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Hugo.Formatter.AutoTags = []config.AutoTagConfig{{Tag: "MotoGP", Keywords: []string{"motogp"}}}
+	s := newTestService(t, cfg)
+	a := insertScraped(t, s, "https://example.com/motogp-test")
+	a.Content = "MotoGP teams tested in Jerez."
+	b := insertScraped(t, s, "https://example.com/other")
+	if err := s.store.UpdateArticle(a); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.Reindex([]string{ReindexTags}, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Changed[ReindexTags] != 1 {
+		t.Errorf("changed = %v, want one article tagged", result.Changed)
+	}
+	if got, _ := s.store.GetArticleByID(a.ID); !slices.Equal(got.Tags, []string{"MotoGP"}) {
+		t.Errorf("tags = %q, want MotoGP", got.Tags)
+	}
+	if got, _ := s.store.GetArticleByID(b.ID); len(got.Tags) != 0 {
+		t.Errorf("untouched article got tags %q", got.Tags)
+	}
+}
+
+func TestReindexResumeKeepsKinds(t *testing.T) {
+	s := newTestService(t, nil)
+	insertScraped(t, s, "https://example.com/a")
+	job, err := s.store.CreateJob(jobReindex, 1, nil, []string{ReindexTags})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.store.FinishJob(job, storage.JobFailed, "interrupted"); err != nil {
+		t.Fatal(err)
+	}
+
+	// no kinds given: the resumed run does only what the job was started with
+	result, err := s.Reindex(nil, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Resumed || result.JobID != job.ID || !slices.Equal(result.Kinds, []string{ReindexTags}) {
+		t.Errorf("resumed = %v, job = %d, kinds = %q; want job #%d with kinds [tags]", result.Resumed, result.JobID, result.Kinds, job.ID)
+	}
+}
+
+func TestHoldPartialTranslations(t *testing.T) {
+	cfg := &config.Config{}
+	s := newTestService(t, cfg)
+	full := insertScraped(t, s, "https://example.com/full")
+	full.TitleRU, full.ContentRU, full.Status = "Заголовок", "Текст.", models.StatusTranslated
+	titleOnly := insertScraped(t, s, "https://example.com/title-only")
+	titleOnly.TitleRU, titleOnly.Status = "Только заголовок", models.StatusTranslated
+	for _, a := range []*models.Article{full, titleOnly} {
+		if err := s.store.UpdateArticle(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var log []string
+	ready, held := s.holdPartialTranslations([]*models.Article{full, titleOnly}, &log)
+	if len(ready) != 2 || len(held) != 0 {
+		t.Errorf("guard off: ready=%d held=%d, want 2 and 0", len(ready), len(held))
+	}
+
+	cfg.Hugo.RequireFullTranslation = true
+	ready, held = s.holdPartialTranslations([]*models.Article{full, titleOnly}, &log)
+	if len(ready) != 1 || ready[0] != full || len(held) != 1 || held[0] != titleOnly {
+		t.Fatalf("guard on: ready=%v held=%v, want the full translation ready and the title-only one held", ready, held)
+	}
+	if len(log) != 1 || !strings.Contains(log[0], "no content_ru") {
+		t.Errorf("log = %q", log)
+	}
+	if got, _ := s.store.GetArticleByID(titleOnly.ID); got.Status != models.StatusErrored {
+		t.Errorf("title-only status = %q, want it re-queued for translation", got.Status)
+	}
+}
+
+func TestTranslateRejectsEmptyOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		ratio    float64
+		reply    func(string) string
+		wantDone bool
+	}{
+		{"empty", 0, func(string) string { return "" }, false},
+		{"whitespace", 0, func(string) string { return " \n\t" }, false},
+		{"too short", 0.3, func(text string) string { return text[:1] }, false},
+		{"short with the ratio off", 0, func(text string) string { return text[:1] }, true},
+		{"full", 0.3, func(text string) string { return "RU " + text }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Translator.MinOutputRatio = tt.ratio
+			useTranslatorReply(t, cfg, tt.reply)
+			s := newTestService(t, cfg)
+			a := insertScraped(t, s, "https://example.com/story")
+
+			result, err := s.Translate(10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := s.store.GetArticleByID(a.ID)
+			if tt.wantDone {
+				if result.Translated != 1 || result.Errors != 0 || got.Status != models.StatusTranslated {
+					t.Errorf("translated=%d errors=%d status=%q, want the article translated", result.Translated, result.Errors, got.Status)
+				}
+				return
+			}
+			if result.Translated != 0 || result.Errors != 1 || result.LastError == "" {
+				t.Errorf("translated=%d errors=%d last_error=%q, want one error", result.Translated, result.Errors, result.LastError)
+			}
+			if got.Status == models.StatusTranslated || got.TitleRU != "" || got.ContentRU != "" {
+				t.Errorf("status=%q title_ru=%q content_ru=%q, want the article left for retry", got.Status, got.TitleRU, got.ContentRU)
+			}
+		})
+	}
+}
+
+func TestTranslatedCharsCounter(t *testing.T) {
+	cfg := &config.Config{}
+	useTestTranslator(t, cfg)
+	s := newTestService(t, cfg)
+	if stats, err := s.Stats(); err != nil || stats.TranslatedChars != 0 || stats.TranslatedCharsSince != nil {
+		t.Fatalf("fresh database: stats=%+v err=%v, want no chars and no since", stats, err)
+	}
+
+	a := &models.Article{SourceURL: "https://example.com/a", SourceSite: "example.com",
+		Title: "Ducati 1199 — обзор", Content: "Мотоцикл «Panigale» едет 300 км/ч.", PublishedAt: time.Now(), FetchedAt: time.Now()}
+	if err := s.store.InsertArticle(a); err != nil {
+		t.Fatal(err)
+	}
+	b := insertScraped(t, s, "https://example.com/b")
+	// runes, not bytes: DeepL bills characters
+	want := int64(utf8.RuneCountInString(a.Title) + utf8.RuneCountInString(a.Content) +
+		utf8.RuneCountInString(b.Title) + utf8.RuneCountInString(b.Content))
+
+	result, err := s.Translate(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TranslatedChars != want {
+		t.Errorf("run: translated_chars = %d, want %d", result.TranslatedChars, want)
+	}
+
+	c := insertScraped(t, s, "https://example.com/c")
+	want += int64(utf8.RuneCountInString(c.Title) + utf8.RuneCountInString(c.Content))
+	if _, err := s.Translate(10); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := s.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TranslatedChars != want || stats.TranslatedCharsMonth != want || stats.TranslatedCharsSince == nil {
+		t.Errorf("stats: total=%d month=%d since=%v, want %d, %d and a since", stats.TranslatedChars, stats.TranslatedCharsMonth, stats.TranslatedCharsSince, want, want)
+	}
+}
+
+func TestPublishRefreshStaleFingerprints(t *testing.T) {
+	srv := newFailingGitHub(t, 0)
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	cfg := &config.Config{Hugo: config.HugoConfig{ContentDir: "content", GitRepo: "owner/repo", APIBaseURL: srv.URL}}
+	s := newTestService(t, cfg)
+	published := time.Now().Add(-time.Hour)
+	for i := 1; i <= 2; i++ {
+		a := &models.Article{
+			SourceURL:    fmt.Sprintf("https://example.com/%d", i),
+			Title:        fmt.Sprintf("Article %d", i),
+			TitleRU:      fmt.Sprintf("Статья %d", i),
+			ContentRU:    "Текст.",
+			Slug:         fmt.Sprintf("article-%d", i),
+			PublishedAt:  published,
+			FetchedAt:    published,
+			TranslatedAt: &published,
+			Status:       models.StatusTranslated,
+		}
+		if err := s.store.InsertArticle(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	publish := func(refresh bool) *PublishResult {
+		t.Helper()
+		result, err := s.Publish(10, refresh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if result := publish(false); result.Published != 2 {
+		t.Fatalf("first publish: published = %d, want 2", result.Published)
+	}
+	if result := publish(true); result.Total != 0 || result.Refreshed != 0 {
+		t.Errorf("unchanged formatter: total=%d refreshed=%d, want nothing to refresh", result.Total, result.Refreshed)
+	}
+
+	cfg.Hugo.Formatter.DefaultImage = "/images/placeholder.jpg"
+	if result := publish(false); result.Total != 0 {
+		t.Errorf("changed formatter without refresh: total = %d, want 0", result.Total)
+	}
+	if result := publish(true); result.Refreshed != 2 || result.Published != 2 {
+		t.Errorf("changed formatter: refreshed=%d published=%d, want 2 and 2", result.Refreshed, result.Published)
+	}
+	stale, err := s.store.GetStaleRenderedArticles(s.renderFingerprint(), 10)
+	if err != nil || len(stale) != 0 {
+		t.Errorf("after the refresh %d articles are stale (%v), want 0", len(stale), err)
+	}
+}
+
+func TestFetchUpdatesSourceWatermark(t *testing.T) {
+	pages := map[string]string{
+		"/a": articlePage("The first story of the day."),
+		"/b": articlePage("The second story of the day."),
+		"/c": articlePage("A later story."),
+	}
+	paths := []string{"/a", "/b"}
+	srv := newTestSite(t, func(base string) []feedItem {
+		items := make([]feedItem, len(paths))
+		for i, p := range paths {
+			items[i] = feedItem{title: "Story " + p, link: base + p}
+		}
+		return items
+	}, pages)
+	s := newTestService(t, fetchConfig(srv.URL+"/feed", srv.URL+"/missing"))
+
+	watermark := func() (SourceStatus, SourceStatus) {
+		t.Helper()
+		sources, err := s.Sources()
+		if err != nil || len(sources) != 2 {
+			t.Fatalf("sources = %+v (%v)", sources, err)
+		}
+		return sources[0], sources[1]
+	}
+	if w, _ := watermark(); w.LastFetchedAt != nil {
+		t.Fatalf("never fetched source has a watermark: %+v", w)
+	}
+
+	before := time.Now()
+	if _, err := s.Fetch(""); err != nil {
+		t.Fatal(err)
+	}
+	w, failed := watermark()
+	if w.LastFetchedAt == nil || w.LastFetchedAt.Before(before) || w.LastNew != 2 || w.TotalNew != 2 || w.RecentNew != 2 {
+		t.Errorf("first fetch: %+v, want a watermark after the fetch start with 2 new", w)
+	}
+	if failed.LastFetchedAt != nil || failed.ConsecutiveFailures != 1 {
+		t.Errorf("failing source: last_fetched_at=%v failures=%d, want no watermark and 1 failure", failed.LastFetchedAt, failed.ConsecutiveFailures)
+	}
+
+	first := *w.LastFetchedAt
+	paths = append(paths, "/c")
+	if _, err := s.Fetch(""); err != nil {
+		t.Fatal(err)
+	}
+	w, _ = watermark()
+	if w.LastFetchedAt == nil || !w.LastFetchedAt.After(first) || w.LastNew != 1 || w.TotalNew != 3 || w.RecentNew != 3 {
+		t.Errorf("second fetch: %+v, want the watermark moved with 1 new of 3", w)
+	}
+}
+
+func TestVerifyPublishedResetsMissingFiles(t *testing.T) {
+	cfg := &config.Config{Hugo: config.HugoConfig{Path: t.TempDir(), ContentDir: "content"}}
+	s := newTestService(t, cfg)
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var articles []*models.Article
+	for i := 1; i <= 2; i++ {
+		a := &models.Article{
+			SourceURL:    fmt.Sprintf("https://example.com/%d", i),
+			Title:        fmt.Sprintf("Article %d", i),
+			TitleRU:      fmt.Sprintf("Статья %d", i),
+			ContentRU:    "Текст.",
+			Slug:         fmt.Sprintf("article-%d", i),
+			PublishedAt:  published,
+			FetchedAt:    published,
+			TranslatedAt: &published,
+			Status:       models.StatusPublished,
+		}
+		if err := s.store.InsertArticle(a); err != nil {
+			t.Fatal(err)
+		}
+		articles = append(articles, a)
+	}
+	// only the first file made it to the blog
+	dir := filepath.Join(cfg.Hugo.Path, "content", "posts", "2026", "03")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "article-1.md"), []byte("---\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.VerifyPublished(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Target != "local" || result.Checked != 2 || len(result.Missing) != 1 || result.Missing[0].ID != articles[1].ID || result.Reset != 0 {
+		t.Fatalf("report = %+v, want article 2 missing and nothing reset", result)
+	}
+	if got, _ := s.store.GetArticleByID(articles[1].ID); got.Status != models.StatusPublished {
+		t.Errorf("status without reset = %q, want published", got.Status)
+	}
+
+	if result, err = s.VerifyPublished(true); err != nil || result.Reset != 1 {
+		t.Fatalf("reset = %+v (%v), want 1", result, err)
+	}
+	if got, _ := s.store.GetArticleByID(articles[1].ID); got.Status != models.StatusTranslated {
+		t.Errorf("status after reset = %q, want translated", got.Status)
+	}
+	if got, _ := s.store.GetArticleByID(articles[0].ID); got.Status != models.StatusPublished {
+		t.Errorf("present file: status = %q, want published", got.Status)
+	}
+}
+
+func TestLongTitlesGetDistinctFiles(t *testing.T) {
+	cfg := &config.Config{Hugo: config.HugoConfig{Path: t.TempDir(), ContentDir: "content"}}
+	s := newTestService(t, cfg)
+	// the titles only differ after the slug limit
+	prefix := "Ducati unveils the new Panigale V4 R with winglets, a titanium exhaust and a lighter frame for the 2027 season"
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var articles []*models.Article
+	for i, ending := range []string{"in Milan", "at EICMA"} {
+		title := prefix + " " + ending
+		a := &models.Article{
+			SourceURL:    fmt.Sprintf("https://example.com/%d", i+1),
+			Title:        title,
+			TitleRU:      title,
+			ContentRU:    "Текст.",
+			Slug:         slugify.MakeMax(title, 0),
+			PublishedAt:  published,
+			FetchedAt:    published,
+			TranslatedAt: &published,
+			Status:       models.StatusTranslated,
+		}
+		if err := s.store.InsertArticle(a); err != nil {
+			t.Fatal(err)
+		}
+		articles = append(articles, a)
+	}
+	slug := articles[0].Slug
+	if len(slug) > slugify.MaxLength || !strings.HasPrefix(slugify.MakeMax(prefix, 1000), slug+"-") || articles[1].Slug != slug {
+		t.Fatalf("slugs %q and %q, want one slug of at most %d characters ending on a whole word", slug, articles[1].Slug, slugify.MaxLength)
+	}
+
+	result, err := s.Publish(10, false)
+	if err != nil || result.Published != 2 {
+		t.Fatalf("published = %+v (%v), want 2", result, err)
+	}
+	want := []string{slug, fmt.Sprintf("%s-%d", slug, articles[1].ID)}
+	for i, a := range articles {
+		got, _ := s.store.GetArticleByID(a.ID)
+		if got.Slug != want[i] {
+			t.Errorf("article %d: slug = %q, want %q", i+1, got.Slug, want[i])
+		}
+		if _, err := os.Stat(filepath.Join(cfg.Hugo.Path, "content", "posts", "2026", "03", want[i]+".md")); err != nil {
+			t.Errorf("article %d: %v", i+1, err)
+		}
+	}
+}
+
+func TestFetchExcludeFilters(t *testing.T) {
+	srv := newTestSite(t, func(base string) []feedItem {
+		return []feedItem{
+			{title: "Win a helmet in our SWEEPSTAKES", link: base + "/sweepstakes"},
+			{title: "Harley-Davidson recalls the Pan America", link: base + "/harley"},
+			{title: "Honda updates the Africa Twin", link: base + "/honda", extra: "<description>Sweeping changes for 2026</description>"},
+		}
+	}, map[string]string{"/honda": articlePage("Honda gave the Africa Twin a new colour.")})
+	cfg := fetchConfig(srv.URL + "/feed")
+	cfg.Exclude = []string{"sweepstakes"}
+	cfg.Sources[0].Exclude = []string{"re:^harley"}
+	s := newTestService(t, cfg)
+
+	result, err := s.Fetch("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Filtered != 2 || result.NewArticles != 1 {
+		t.Fatalf("filtered=%d new=%d, want 2 and 1\n%s", result.Filtered, result.NewArticles, strings.Join(result.Log, "\n"))
+	}
+	for path, want := range map[string]bool{"/sweepstakes": false, "/harley": false, "/honda": true} {
+		if _, err := s.store.GetArticleByURL(srv.URL + path); (err == nil) != want {
+			t.Errorf("%s stored = %v, want %v", path, err == nil, want)
+		}
+	}
+}
+
+func TestDisambiguateSlugsKeepsPublishedFile(t *testing.T) {
+	cfg := &config.Config{}
+	s := newTestService(t, cfg)
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	insert := func(url, slug string, status models.ArticleStatus, day int) *models.Article {
+		a := &models.Article{SourceURL: url, Title: slug, Slug: slug, PublishedAt: published.AddDate(0, 0, day),
+			FetchedAt: published, Status: status}
+		if err := s.store.InsertArticle(a); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	fresh := insert("https://example.com/1", "new-ninja", models.StatusTranslated, 0)
+	live := insert("https://example.com/2", "new-ninja", models.StatusPublished, 1)
+	other := insert("https://example.com/3", "other", models.StatusTranslated, 0)
+	nextMonth := insert("https://example.com/4", "new-ninja", models.StatusTranslated, 31)
+
+	var log []string
+	s.disambiguateSlugs([]*models.Article{fresh, live, other, nextMonth}, &log)
+	// the published article keeps its file even though it is newer
+	want := map[*models.Article]string{fresh: fmt.Sprintf("new-ninja-%d", fresh.ID), live: "new-ninja", other: "other", nextMonth: "new-ninja"}
+	for a, slug := range want {
+		stored, err := s.store.GetArticleByID(a.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Slug != slug || stored.Slug != slug {
+			t.Errorf("#%d: slug = %q, stored %q, want %q", a.ID, a.Slug, stored.Slug, slug)
+		}
+	}
+	if len(log) != 1 || !strings.Contains(log[0], "slug collision") || !strings.Contains(log[0], want[fresh]) {
+		t.Errorf("log = %q, want one collision warning naming %s", log, want[fresh])
+	}
+
+	cfg.Hugo.DuplicateSlugs = "off"
+	again := insert("https://example.com/5", "new-ninja", models.StatusTranslated, 0)
+	log = nil
+	s.disambiguateSlugs([]*models.Article{live, again}, &log)
+	if again.Slug != "new-ninja" || len(log) != 0 {
+		t.Errorf("off: slug = %q, log = %q, want both untouched", again.Slug, log)
+	}
+}
+
+func TestPublishRequireReview(t *testing.T) {
+	cfg := &config.Config{Hugo: config.HugoConfig{Path: t.TempDir(), ContentDir: "content", RequireReview: true}}
+	s := newTestService(t, cfg)
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var articles []*models.Article
+	for i, slug := range []string{"reviewed", "waiting"} {
+		a := &models.Article{SourceURL: fmt.Sprintf("https://example.com/%d", i+1), Title: slug, TitleRU: slug, ContentRU: "Текст.",
+			Slug: slug, PublishedAt: published, FetchedAt: published, TranslatedAt: &published, Status: models.StatusTranslated}
+		if err := s.store.InsertArticle(a); err != nil {
+			t.Fatal(err)
+		}
+		articles = append(articles, a)
+	}
+	if _, err := s.store.SetReviewed([]int64{articles[0].ID}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.Publish(10, false)
+	if err != nil || result.Published != 1 {
+		t.Fatalf("published = %+v (%v), want only the reviewed article", result, err)
+	}
+	for i, want := range []models.ArticleStatus{models.StatusPublished, models.StatusTranslated} {
+		if got, _ := s.store.GetArticleByID(articles[i].ID); got.Status != want {
+			t.Errorf("%s: status = %s, want %s", articles[i].Slug, got.Status, want)
+		}
+	}
+
+	// marked by an editor, it goes out with the next run
+	if _, err := s.store.SetReviewed([]int64{articles[1].ID}, true); err != nil {
+		t.Fatal(err)
+	}
+	if result, err = s.Publish(10, false); err != nil || result.Published != 1 {
+		t.Fatalf("after review: published = %+v (%v), want 1", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Hugo.Path, "content", "posts", "2026", "03", "waiting.md")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTranslatorSharesOneConnectionCheck(t *testing.T) {
+	var checks, fail atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/languages" {
+			checks.Add(1)
+			time.Sleep(50 * time.Millisecond) // long enough for every caller to arrive
+			if fail.Load() != 0 {
+				http.Error(w, "down", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`[{"code":"ru"}]`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"translatedText": "Привет"})
+	}))
+	defer srv.Close()
+	cfg := &config.Config{}
+	cfg.Translator.Provider = "libretranslate"
+	cfg.Translator.LibreTranslate.Host = srv.URL
+	cfg.Translator.CheckConnection = true
+	s := newTestService(t, cfg)
+
+	var wg sync.WaitGroup
+	instances := make([]translator.Translator, 8)
+	for i := range instances {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			trans, err := s.translator(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := trans.Translate(context.Background(), "Hello"); err != nil {
+				t.Error(err)
+			}
+			instances[i] = trans
+		}(i)
+	}
+	wg.Wait()
+	if n := checks.Load(); n != 1 {
+		t.Errorf("%d connection checks for 8 concurrent callers, want 1", n)
+	}
+	for _, trans := range instances[1:] {
+		if trans != instances[0] {
+			t.Fatal("callers got different translator instances")
+		}
+	}
+
+	// a config change builds a new translator and checks it again; a
+	// failed check is retried by the next caller
+	fail.Store(1)
+	cfg.Translator.Order = "oldest"
+	if _, err := s.translator(context.Background()); err == nil {
+		t.Error("failed check not reported")
+	}
+	fail.Store(0)
+	trans, err := s.translator(context.Background())
+	if err != nil || trans == instances[0] {
+		t.Errorf("after the config change: %v, same instance = %v", err, trans == instances[0])
+	}
+	if n := checks.Load(); n != 3 {
+		t.Errorf("%d connection checks in total, want 3", n)
+	}
+}
+
+func TestStablePermalinksKeepPublishedFile(t *testing.T) {
+	cfg := &config.Config{Hugo: config.HugoConfig{Path: t.TempDir(), ContentDir: "content", StablePermalinks: true}}
+	cfg.Hugo.Formatter.StablePermalinks = true
+	s := newTestService(t, cfg)
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := &models.Article{SourceURL: "https://example.com/1", Title: "New Ninja", TitleRU: "Новый Ninja", ContentRU: "Текст.",
+		Slug: "new-ninja", PublishedAt: published, FetchedAt: published, TranslatedAt: &published, Status: models.StatusTranslated}
+	if err := s.store.InsertArticle(a); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := s.Publish(10, false); err != nil || result.Published != 1 {
+		t.Fatalf("published = %+v (%v), want 1", result, err)
+	}
+	posts := filepath.Join(cfg.Hugo.Path, "content", "posts")
+	stored, _ := s.store.GetArticleByID(a.ID)
+	if stored.PublishedPath != "posts/2026/03/new-ninja.md" {
+		t.Fatalf("published path = %q", stored.PublishedPath)
+	}
+
+	// the title changes, the article is re-slugged and goes out again
+	if err := s.store.SetSlug(a.ID, "ninja-500-renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.store.SetTaxonomy(a.ID, "Reviews", nil); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := s.Publish(10, false); err != nil || result.Published != 1 {
+		t.Fatalf("re-publish = %+v (%v), want 1", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(posts, "2026", "03", "ninja-500-renamed.md")); !os.IsNotExist(err) {
+		t.Errorf("re-publish wrote a file at the new slug (%v)", err)
+	}
+	content, err := os.ReadFile(filepath.Join(posts, "2026", "03", "new-ninja.md"))
+	if err != nil || !strings.Contains(string(content), "- Reviews") {
+		t.Errorf("published file not rewritten in place (%v):\n%s", err, content)
+	}
+}
+
+func TestFetchAutoDisablesFailingSource(t *testing.T) {
+	srv := newTestSite(t, func(base string) []feedItem {
+		return []feedItem{{title: "Story", link: base + "/a"}}
+	}, map[string]string{"/a": articlePage("The only story of the day.")})
+	cfg := fetchConfig(srv.URL+"/feed", srv.URL+"/missing")
+	cfg.Schedule.DisableAfterFailures = 3
+	s := newTestService(t, cfg)
+
+	failing := func() SourceStatus {
+		t.Helper()
+		sources, err := s.Sources()
+		if err != nil || len(sources) != 2 {
+			t.Fatalf("sources = %+v (%v)", sources, err)
+		}
+		return sources[1]
+	}
+	for i := 1; i <= 3; i++ {
+		result, err := s.Fetch("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := failing()
+		if w.ConsecutiveFailures != i || w.LastError == "" {
+			t.Errorf("fetch %d: failures=%d last_error=%q, want %d and an error", i, w.ConsecutiveFailures, w.LastError, i)
+		}
+		if disabled := i == 3; w.AutoDisabled != disabled || (len(result.Disabled) == 1) != disabled {
+			t.Errorf("fetch %d: auto_disabled=%v result.Disabled=%v, want disabled only at the threshold", i, w.AutoDisabled, result.Disabled)
+		}
+	}
+
+	// disabled sources are skipped until re-enabled by hand
+	if _, err := s.Fetch(""); err != nil {
+		t.Fatal(err)
+	}
+	if w := failing(); w.ConsecutiveFailures != 3 || !w.AutoDisabled {
+		t.Errorf("after disabling: failures=%d auto_disabled=%v, want the source skipped", w.ConsecutiveFailures, w.AutoDisabled)
+	}
+	if _, err := s.Fetch("source2"); !errors.Is(err, ErrSourceDisabled) {
+		t.Errorf("fetching the disabled source: err = %v, want ErrSourceDisabled", err)
+	}
+
+	if enabled, err := s.EnableSource("source2"); err != nil || !enabled {
+		t.Fatalf("EnableSource = %v (%v), want true", enabled, err)
+	}
+	if w := failing(); w.ConsecutiveFailures != 0 || w.AutoDisabled {
+		t.Errorf("re-enabled: failures=%d auto_disabled=%v, want a reset", w.ConsecutiveFailures, w.AutoDisabled)
+	}
+
+	// one failure, then a working feed resets the count
+	if _, err := s.Fetch("source2"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Sources[1].Feeds = []string{srv.URL + "/feed"}
+	if _, err := s.Fetch("source2"); err != nil {
+		t.Fatal(err)
+	}
+	if w := failing(); w.ConsecutiveFailures != 0 || w.LastFetchedAt == nil {
+		t.Errorf("after a successful fetch: failures=%d last_fetched_at=%v, want 0 and a watermark", w.ConsecutiveFailures, w.LastFetchedAt)
+	}
+}
+
+func TestTranslatorStatus(t *testing.T) {
+	var checks, down atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		checks.Add(1)
+		if down.Load() != 0 {
+			http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"models":[{"name":"llama3"}]}`))
+	}))
+	defer srv.Close()
+	cfg := &config.Config{}
+	cfg.Translator.Provider = "ollama"
+	cfg.Translator.Ollama.Host = srv.URL
+	cfg.Translator.Ollama.Model = "llama3"
+	cfg.Translator.StatusTTLSec = 300
+	s := newTestService(t, cfg)
+
+	status := func() *TranslatorStatus {
+		t.Helper()
+		st, err := s.TranslatorStatus(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st
+	}
+	st := status()
+	if st.Provider != "ollama" || st.Model != "llama3" || st.Reachable == nil || !*st.Reachable || st.Error != "" || st.CheckedAt == nil {
+		t.Fatalf("reachable translator: %+v", st)
+	}
+
+	// within the TTL the last check is reused, even though the provider is
+	// down by now
+	down.Store(1)
+	if st := status(); !*st.Reachable || checks.Load() != 1 {
+		t.Errorf("cached status: reachable=%v after %d checks, want the first check reused", *st.Reachable, checks.Load())
+	}
+
+	// no TTL: every request asks the provider
+	cfg.Translator.StatusTTLSec = 0
+	st = status()
+	if st.Reachable == nil || *st.Reachable || !strings.Contains(st.Error, "503") || checks.Load() != 2 {
+		t.Errorf("unreachable translator: %+v after %d checks, want reachable false with the error", st, checks.Load())
+	}
+	down.Store(0)
+	if st := status(); !*st.Reachable || st.Error != "" || checks.Load() != 3 {
+		t.Errorf("back up: %+v after %d checks, want reachable again", st, checks.Load())
+	}
+}
+
+func TestPublishCapReached(t *testing.T) {
+	cfg := &config.Config{Hugo: config.HugoConfig{Path: t.TempDir(), ContentDir: "content"}}
+	cfg.Schedule.MaxPublishPerRun = 2
+	s := newTestService(t, cfg)
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= 5; i++ {
+		a := &models.Article{SourceURL: fmt.Sprintf("https://example.com/%d", i), Title: "T", TitleRU: "Т", ContentRU: "Текст.",
+			Slug: fmt.Sprintf("article-%d", i), PublishedAt: published, FetchedAt: published, TranslatedAt: &published, Status: models.StatusTranslated}
+		if err := s.store.InsertArticle(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the scheduled run asks for exactly the cap, the unlimited one for all
+	for _, limit := range []int{2, -1, 10} {
+		result, err := s.Publish(limit, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if limit == 10 {
+			if result.Published != 1 || result.CapReached {
+				t.Errorf("limit %d: published=%d cap_reached=%v, want the last article without the cap", limit, result.Published, result.CapReached)
+			}
+			continue
+		}
+		if result.Published != 2 || !result.CapReached {
+			t.Errorf("limit %d: published=%d cap_reached=%v, want 2 and the cap reported", limit, result.Published, result.CapReached)
+		}
+	}
+}
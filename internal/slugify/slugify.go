@@ -0,0 +1,71 @@
+// Package slugify builds ASCII URL slugs from article titles in any script.
+package slugify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gosimple/slug"
+)
+
+// MaxLength is the default longest slug; longer slugs are cut at a hyphen
+const MaxLength = 80
+
+// ruTranslit maps Russian Cyrillic to Latin (ISO 9 / GOST 7.79 system B,
+// simplified to plain ASCII as used in Russian URLs)
+var ruTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	// Ukrainian/Belarusian letters that show up in East European sources
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// Transliterate converts Cyrillic letters to Latin and leaves the rest as is
+func Transliterate(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if lat, ok := ruTranslit[r]; ok {
+			sb.WriteString(lat)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Make returns an ASCII slug for title, transliterating Cyrillic first.
+// The result may be empty when the title has no letters or digits;
+// use Fallback then.
+func Make(title string) string {
+	return MakeMax(title, MaxLength)
+}
+
+// MakeMax is Make with a custom length limit (<= 0 means MaxLength). Long
+// slugs end on a whole word; a word is only cut when the first one alone
+// fills more than half the limit.
+func MakeMax(title string, maxLen int) string {
+	if maxLen <= 0 {
+		maxLen = MaxLength
+	}
+	s := slug.Make(Transliterate(title))
+	if len(s) > maxLen {
+		if s[maxLen] == '-' {
+			// The limit falls right after a word
+			s = s[:maxLen]
+		} else {
+			s = s[:maxLen]
+			if i := strings.LastIndex(s, "-"); i > maxLen/2 {
+				s = s[:i]
+			}
+		}
+	}
+	return strings.Trim(s, "-")
+}
+
+// Fallback is the slug used when a title yields no usable characters
+func Fallback(id int64) string {
+	return fmt.Sprintf("article-%d", id)
+}
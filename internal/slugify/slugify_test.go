@@ -0,0 +1,41 @@
+package slugify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		title, want string
+	}{
+		{"New Ducati Panigale V4", "new-ducati-panigale-v4"},
+		{"Новый мотоцикл Ducati", "novyy-mototsikl-ducati"},
+		{"Щука и ёж: съезд", "shchuka-i-yozh-sezd"},
+		{"Їжак і ґанок", "yizhak-i-ganok"},
+		{"«Урал» — 2027!", "ural-2027"},
+		{"!!! — ???", ""},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.title); got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestMakeMax(t *testing.T) {
+	title := "Мотоцикл года: полный обзор новинок европейских производителей"
+	got := MakeMax(title, 30)
+	if len(got) > 30 || strings.HasSuffix(got, "-") || !strings.HasPrefix(Make(title), got+"-") {
+		t.Errorf("MakeMax = %q, want at most 30 characters ending on a whole word", got)
+	}
+	if got := MakeMax(strings.Repeat("a", 50)+" b", 20); got != strings.Repeat("a", 20) {
+		t.Errorf("a first word over half the limit is cut: got %q", got)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	if got := Fallback(42); got != "article-42" {
+		t.Errorf("Fallback(42) = %q", got)
+	}
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Counter names
+const (
+	CounterTranslatedChars = "translated_chars"
+)
+
+// MonthlyCounter returns the per-month key of a counter (translated_chars:2026-02)
+func MonthlyCounter(name string, t time.Time) string {
+	return fmt.Sprintf("%s:%s", name, t.UTC().Format("2006-01"))
+}
+
+// AddCounter increments a named counter, creating it on first use
+func (s *SQLiteStorage) AddCounter(name string, delta int64) error {
+	_, err := s.db.Exec(`
+	INSERT INTO counters (name, value, since, updated_at) VALUES (?, ?, ?, ?)
+	ON CONFLICT(name) DO UPDATE SET value = value + excluded.value, updated_at = excluded.updated_at
+	`, name, delta, time.Now(), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update counter %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetCounter returns a counter's value and when it started counting.
+// A counter that was never incremented is 0 with a nil since.
+func (s *SQLiteStorage) GetCounter(name string) (int64, *time.Time, error) {
+	var value int64
+	var since sql.NullTime
+	err := s.db.QueryRow(`SELECT value, since FROM counters WHERE name = ?`, name).Scan(&value, &since)
+	if err == sql.ErrNoRows {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read counter %s: %w", name, err)
+	}
+	if !since.Valid {
+		return value, nil, nil
+	}
+	return value, &since.Time, nil
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Job statuses
+const (
+	JobRunning     = "running"
+	JobDone        = "done"
+	JobFailed      = "failed"
+	JobInterrupted = "interrupted" // found stale (see JobStaleAfter) when a newer job of the same kind started
+)
+
+// JobStaleAfter is how long a running job may go without a checkpoint
+// before it is taken for the leftover of a crashed process
+const JobStaleAfter = 30 * time.Minute
+
+// Job is a persisted record of a long-running operation (e.g. a bulk
+// translate), checkpointed after every item so progress survives a crash
+type Job struct {
+	ID         int64      `json:"id"`
+	Kind       string     `json:"kind"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Errors     int        `json:"errors"`
+	ElapsedMs  int64      `json:"elapsed_ms"`            // time spent on processed items, summed over resumed runs
+	Cursor     int64      `json:"cursor,omitempty"`      // last article ID done, for jobs that walk the articles table in ID order (reindex)
+	Items      []int64    `json:"-"`                     // articles the job covers, in order (translate)
+	Params     []string   `json:"params,omitempty"`      // options the job was started with, reused on resume (reindex: the rebuilds)
+	ETASeconds int64      `json:"eta_seconds,omitempty"` // see ETA; filled when the job is read
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Remaining returns how many items the job has left
+func (j *Job) Remaining() int {
+	if r := j.Total - j.Processed; r > 0 {
+		return r
+	}
+	return 0
+}
+
+// ETA estimates the time left from the average time per processed item
+// (0 when nothing was processed yet or the job is not running)
+func (j *Job) ETA() time.Duration {
+	if j.Status != JobRunning || j.Processed == 0 {
+		return 0
+	}
+	perItem := time.Duration(j.ElapsedMs) * time.Millisecond / time.Duration(j.Processed)
+	return perItem * time.Duration(j.Remaining())
+}
+
+// Live reports whether the job is running and checkpointed recently enough
+// to belong to a process that is still at it
+func (j *Job) Live() bool {
+	return j.Status == JobRunning && time.Since(j.UpdatedAt) < JobStaleAfter
+}
+
+const jobColumns = `id, kind, status, total, processed, errors, elapsed_ms, cursor, items, params, error, started_at, updated_at, finished_at`
+
+// CreateJob starts a job of the given kind over items with params (both may
+// be nil). Jobs of
+// the same kind left running without a checkpoint for JobStaleAfter (a
+// crashed process) are marked interrupted; live ones are left alone.
+func (s *SQLiteStorage) CreateJob(kind string, total int, items []int64, params []string) (*Job, error) {
+	now := time.Now()
+	// julianday() compares instants, whatever offset updated_at was stored with
+	if _, err := s.db.Exec(`UPDATE jobs SET status = ?, updated_at = ? WHERE kind = ? AND status = ? AND julianday(updated_at) < julianday(?)`,
+		JobInterrupted, now, kind, JobRunning, now.Add(-JobStaleAfter).UTC()); err != nil {
+		return nil, fmt.Errorf("failed to close stale %s jobs: %w", kind, err)
+	}
+	var itemsJSON string
+	if len(items) > 0 {
+		data, err := json.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+		itemsJSON = string(data)
+	}
+	var paramsJSON string
+	if len(params) > 0 {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		paramsJSON = string(data)
+	}
+	res, err := s.db.Exec(`INSERT INTO jobs (kind, status, total, items, params, started_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		kind, JobRunning, total, itemsJSON, paramsJSON, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s job: %w", kind, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &Job{ID: id, Kind: kind, Status: JobRunning, Total: total, Items: items, Params: params, StartedAt: now, UpdatedAt: now}, nil
+}
+
+// ResumeJob marks an unfinished job running again
+func (s *SQLiteStorage) ResumeJob(job *Job) error {
+	job.Status = JobRunning
+	job.UpdatedAt = time.Now()
+	_, err := s.db.Exec(`UPDATE jobs SET status = ?, error = '', updated_at = ? WHERE id = ?`,
+		job.Status, job.UpdatedAt, job.ID)
+	return err
+}
+
+// UpdateJobProgress checkpoints the job's counters
+func (s *SQLiteStorage) UpdateJobProgress(job *Job) error {
+	job.UpdatedAt = time.Now()
+	_, err := s.db.Exec(`UPDATE jobs SET total = ?, processed = ?, errors = ?, elapsed_ms = ?, cursor = ?, updated_at = ? WHERE id = ?`,
+		job.Total, job.Processed, job.Errors, job.ElapsedMs, job.Cursor, job.UpdatedAt, job.ID)
+	return err
+}
+
+// FinishJob records the final status (JobDone or JobFailed) and counters
+func (s *SQLiteStorage) FinishJob(job *Job, status, errMsg string) error {
+	now := time.Now()
+	job.Status = status
+	job.Error = errMsg
+	job.UpdatedAt = now
+	job.FinishedAt = &now
+	_, err := s.db.Exec(`UPDATE jobs SET status = ?, error = ?, total = ?, processed = ?, errors = ?, elapsed_ms = ?, cursor = ?, updated_at = ?, finished_at = ? WHERE id = ?`,
+		job.Status, job.Error, job.Total, job.Processed, job.Errors, job.ElapsedMs, job.Cursor, now, now, job.ID)
+	return err
+}
+
+// GetJob returns a job by ID (sql.ErrNoRows if it does not exist)
+func (s *SQLiteStorage) GetJob(id int64) (*Job, error) {
+	return scanJob(s.db.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id))
+}
+
+// GetLatestJob returns the most recent job of a kind (sql.ErrNoRows if none)
+func (s *SQLiteStorage) GetLatestJob(kind string) (*Job, error) {
+	return scanJob(s.db.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE kind = ? ORDER BY id DESC LIMIT 1`, kind))
+}
+
+func scanJob(row *sql.Row) (*Job, error) {
+	var j Job
+	var items, params string
+	var errMsg sql.NullString
+	var finished sql.NullTime
+	if err := row.Scan(&j.ID, &j.Kind, &j.Status, &j.Total, &j.Processed, &j.Errors, &j.ElapsedMs, &j.Cursor,
+		&items, &params, &errMsg, &j.StartedAt, &j.UpdatedAt, &finished); err != nil {
+		return nil, err
+	}
+	if items != "" {
+		if err := json.Unmarshal([]byte(items), &j.Items); err != nil {
+			return nil, fmt.Errorf("job %d items: %w", j.ID, err)
+		}
+	}
+	if params != "" {
+		if err := json.Unmarshal([]byte(params), &j.Params); err != nil {
+			return nil, fmt.Errorf("job %d params: %w", j.ID, err)
+		}
+	}
+	j.Error = errMsg.String
+	if finished.Valid {
+		j.FinishedAt = &finished.Time
+	}
+	j.ETASeconds = int64(j.ETA().Seconds())
+	return &j, nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCreateJobInterruptsOnlyStaleJobs(t *testing.T) {
+	s := newTestStorage(t)
+	stale, err := s.CreateJob("translate", 2, []int64{3, 1}, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.db.Exec(`UPDATE jobs SET updated_at = ? WHERE id = ?`, time.Now().Add(-2*JobStaleAfter), stale.ID); err != nil {
+		t.Fatal(err)
+	}
+	live, err := s.CreateJob("translate", 1, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateJob("translate", 1, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetJob(stale.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != JobInterrupted {
+		t.Errorf("stale job status = %q, want %q", got.Status, JobInterrupted)
+	}
+	if !slices.Equal(got.Items, []int64{3, 1}) || !slices.Equal(got.Params, []string{"a", "b"}) {
+		t.Errorf("items = %v, params = %q; want [3 1] and [a b]", got.Items, got.Params)
+	}
+	if got, err = s.GetJob(live.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != JobRunning || !got.Live() {
+		t.Errorf("live job status = %q, live = %v; want it still running", got.Status, got.Live())
+	}
+}
+
+func TestGetUntranslatedArticlesByID(t *testing.T) {
+	s := newTestStorage(t)
+	a := insertTestArticle(t, s, "https://example.com/a", nil)
+	b := insertTestArticle(t, s, "https://example.com/b", translated)
+	c := insertTestArticle(t, s, "https://example.com/c", nil)
+	insertTestArticle(t, s, "https://example.com/d", nil)
+
+	got, err := s.GetUntranslatedArticlesByID([]int64{c.ID, b.ID, a.ID, 999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := articleIDs(got); !slices.Equal(ids, []int64{c.ID, a.ID}) {
+		t.Errorf("got %v, want %v", ids, []int64{c.ID, a.ID})
+	}
+}
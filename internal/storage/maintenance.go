@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// TableInfo is the row count of one table
+type TableInfo struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// IndexInfo describes one index; Bytes is -1 when SQLite was built without
+// the dbstat virtual table
+type IndexInfo struct {
+	Name  string `json:"name"`
+	Table string `json:"table"`
+	Bytes int64  `json:"bytes"`
+}
+
+// DBInfo is a housekeeping snapshot of the database
+type DBInfo struct {
+	PageSize      int64       `json:"page_size"`
+	PageCount     int64       `json:"page_count"`
+	FreePages     int64       `json:"free_pages"`
+	Tables        []TableInfo `json:"tables"`
+	Indexes       []IndexInfo `json:"indexes"`
+	OldestArticle string      `json:"oldest_article"` // published_at, empty when there are no articles
+	NewestArticle string      `json:"newest_article"`
+}
+
+// Info reports page usage, row counts per table, index sizes and the
+// published_at range of articles
+func (s *SQLiteStorage) Info() (*DBInfo, error) {
+	info := &DBInfo{}
+	if err := s.db.QueryRow("PRAGMA page_size").Scan(&info.PageSize); err != nil {
+		return nil, fmt.Errorf("failed to read page_size: %w", err)
+	}
+	if err := s.db.QueryRow("PRAGMA page_count").Scan(&info.PageCount); err != nil {
+		return nil, fmt.Errorf("failed to read page_count: %w", err)
+	}
+	if err := s.db.QueryRow("PRAGMA freelist_count").Scan(&info.FreePages); err != nil {
+		return nil, fmt.Errorf("failed to read freelist_count: %w", err)
+	}
+
+	tables, err := s.schemaNames("table")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range tables {
+		var count int64
+		// Table names come from sqlite_master, not from user input
+		if err := s.db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, name)).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", name, err)
+		}
+		info.Tables = append(info.Tables, TableInfo{Name: name, Rows: count})
+	}
+
+	rows, err := s.db.Query(`SELECT name, tbl_name FROM sqlite_master WHERE type = 'index' ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		idx := IndexInfo{Bytes: -1}
+		if err := rows.Scan(&idx.Name, &idx.Table); err != nil {
+			return nil, err
+		}
+		info.Indexes = append(info.Indexes, idx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range info.Indexes {
+		var size sql.NullInt64
+		// dbstat is optional in SQLite builds; leave -1 when unavailable
+		if err := s.db.QueryRow(`SELECT SUM(pgsize) FROM dbstat WHERE name = ?`, info.Indexes[i].Name).Scan(&size); err != nil {
+			break
+		}
+		if size.Valid {
+			info.Indexes[i].Bytes = size.Int64
+		}
+	}
+
+	var oldest, newest sql.NullString
+	if err := s.db.QueryRow(`SELECT MIN(published_at), MAX(published_at) FROM articles`).Scan(&oldest, &newest); err != nil {
+		return nil, fmt.Errorf("failed to read article dates: %w", err)
+	}
+	info.OldestArticle = oldest.String
+	info.NewestArticle = newest.String
+
+	return info, nil
+}
+
+// InUse reports whether another connection appears to hold the database.
+// A WAL checkpoint that cannot complete means readers or writers are active.
+func (s *SQLiteStorage) InUse() (bool, error) {
+	var busy, logFrames, checkpointed int
+	if err := s.db.QueryRow("PRAGMA wal_checkpoint(PASSIVE)").Scan(&busy, &logFrames, &checkpointed); err != nil {
+		return false, fmt.Errorf("failed to checkpoint: %w", err)
+	}
+	return busy != 0 || (logFrames > 0 && checkpointed < logFrames), nil
+}
+
+// Vacuum rebuilds the database file to reclaim free pages
+func (s *SQLiteStorage) Vacuum() error {
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("vacuum failed: %w", err)
+	}
+	// Fold the WAL back so the size on disk reflects the result
+	_, _ = s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	return nil
+}
+
+func (s *SQLiteStorage) schemaNames(kind string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT name FROM sqlite_master WHERE type = ? AND name NOT LIKE 'sqlite_%' ORDER BY name`, kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %ss: %w", kind, err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
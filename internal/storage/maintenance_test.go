@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"moto-news/internal/models"
+)
+
+func TestInfoAndVacuum(t *testing.T) {
+	s := newTestStorage(t)
+	for i := 0; i < 50; i++ {
+		insertTestArticle(t, s, fmt.Sprintf("https://example.com/%d", i), func(a *models.Article) {
+			a.Content = strings.Repeat("Long article text. ", 500)
+			a.PublishedAt = time.Date(2026, 1, 1+i%28, 0, 0, 0, 0, time.UTC)
+		})
+	}
+
+	info, err := s.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make(map[string]int64)
+	for _, table := range info.Tables {
+		rows[table.Name] = table.Rows
+	}
+	if rows["articles"] != 50 {
+		t.Errorf("articles rows = %d, want 50 (tables %v)", rows["articles"], info.Tables)
+	}
+	if info.PageSize <= 0 || info.PageCount <= 0 || len(info.Indexes) == 0 {
+		t.Errorf("page_size=%d page_count=%d indexes=%d", info.PageSize, info.PageCount, len(info.Indexes))
+	}
+	if !strings.HasPrefix(info.OldestArticle, "2026-01-01") || !strings.HasPrefix(info.NewestArticle, "2026-01-28") {
+		t.Errorf("article range %q..%q", info.OldestArticle, info.NewestArticle)
+	}
+
+	if _, err := s.DeleteArticlesBefore(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), false); err != nil {
+		t.Fatal(err)
+	}
+	before, err := s.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.FreePages == 0 {
+		t.Fatal("no free pages after deleting every article")
+	}
+	if inUse, err := s.InUse(); err != nil || inUse {
+		t.Errorf("InUse = %v, %v on a database nobody else has open", inUse, err)
+	}
+	if err := s.Vacuum(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := s.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.FreePages != 0 || after.PageCount >= before.PageCount {
+		t.Errorf("after vacuum: free=%d pages=%d, before: free=%d pages=%d", after.FreePages, after.PageCount, before.FreePages, before.PageCount)
+	}
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"fmt"
+
+	"moto-news/internal/models"
+)
+
+// GetArticlesAfterID returns up to limit articles with an ID above afterID,
+// in ID order, for walking the whole table in batches
+func (s *SQLiteStorage) GetArticlesAfterID(afterID int64, limit int) ([]*models.Article, error) {
+	query := `
+	SELECT ` + articleColumns + `
+	FROM articles
+	WHERE id > ?
+	ORDER BY id
+	LIMIT ?
+	`
+	return s.scanArticles(query, afterID, limit)
+}
+
+// CountArticlesAfterID counts the articles with an ID above afterID
+func (s *SQLiteStorage) CountArticlesAfterID(afterID int64) (int, error) {
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM articles WHERE id > ?`, afterID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count articles: %w", err)
+	}
+	return n, nil
+}
+
+// UpdateDerived saves the columns derived from the rest of the article:
+// status with its published flag, the cover image, the image list and the
+// tags (unless set by hand)
+func (s *SQLiteStorage) UpdateDerived(article *models.Article) error {
+	article.PublishedToHugo = article.Status == models.StatusPublished
+	_, err := s.db.Exec(`UPDATE articles SET status = ?, published_to_mkdocs = ?, image_url = ?, image_urls = ?,
+		tags = CASE WHEN taxonomy_overridden THEN tags ELSE ? END WHERE id = ?`,
+		article.Status, article.PublishedToHugo, article.ImageURL, article.ImageURLsJSON(), article.TagsJSON(), article.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update article %d: %w", article.ID, err)
+	}
+	return nil
+}
+
+// RebuildIndexes rebuilds all indexes and refreshes the query planner
+// statistics
+func (s *SQLiteStorage) RebuildIndexes() error {
+	if _, err := s.db.Exec("REINDEX"); err != nil {
+		return fmt.Errorf("reindex failed: %w", err)
+	}
+	if _, err := s.db.Exec("ANALYZE"); err != nil {
+		return fmt.Errorf("analyze failed: %w", err)
+	}
+	return nil
+}
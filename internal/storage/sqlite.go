@@ -1,32 +1,91 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	"moto-news/internal/config"
 	"moto-news/internal/models"
+	"moto-news/internal/slugify"
 )
 
 type SQLiteStorage struct {
 	db *sql.DB
 }
 
-func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+// articleColumns is the column list shared by every article SELECT; the order
+// must match scanArticleRow. raw_content is left out (whole pages, needed by
+// few callers): see GetRawContent.
+const articleColumns = `id, source_url, source_site, title, title_ru, description, content, content_ru,
+		author, category, tags, image_url, image_urls, published_at, fetched_at, translated_at,
+		published_to_mkdocs, slug, translator, translator_model, content_truncated, rescrape_attempts, render_fingerprint, status, featured, source_updated_at, taxonomy_overridden, reviewed, published_path, content_hash, content_checked_at, feed_url`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// NewSQLiteStorage opens the database with the configured pragmas.
+// journal_mode and busy_timeout are passed through the DSN so go-sqlite3
+// applies them to every pooled connection, not just the first one.
+func NewSQLiteStorage(cfg *config.DatabaseConfig) (*SQLiteStorage, error) {
+	db, err := sql.Open("sqlite3", buildDSN(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
 	storage := &SQLiteStorage{db: db}
 	if err := storage.migrate(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
 	return storage, nil
 }
 
+// buildDSN appends go-sqlite3 connection parameters to the database path,
+// after any query the path already has (file:test.db?cache=shared).
+// Transactions begin IMMEDIATE: they take the write lock up front, where
+// busy_timeout applies, instead of failing with SQLITE_BUSY when a read
+// lock cannot be upgraded while another connection writes.
+func buildDSN(cfg *config.DatabaseConfig) string {
+	params := url.Values{}
+	params.Set("_txlock", "immediate")
+	if cfg.JournalMode != "" {
+		params.Set("_journal_mode", cfg.JournalMode)
+	}
+	if cfg.BusyTimeoutMs > 0 {
+		params.Set("_busy_timeout", fmt.Sprintf("%d", cfg.BusyTimeoutMs))
+	}
+	sep := "?"
+	if strings.Contains(cfg.Path, "?") {
+		sep = "&"
+	}
+	return cfg.Path + sep + params.Encode()
+}
+
 func (s *SQLiteStorage) migrate() error {
 	query := `
 	CREATE TABLE IF NOT EXISTS articles (
@@ -53,12 +112,119 @@ func (s *SQLiteStorage) migrate() error {
 	CREATE INDEX IF NOT EXISTS idx_articles_translated ON articles(translated_at);
 	CREATE INDEX IF NOT EXISTS idx_articles_published ON articles(published_to_mkdocs);
 	CREATE INDEX IF NOT EXISTS idx_articles_fetched ON articles(fetched_at);
+	CREATE INDEX IF NOT EXISTS idx_articles_published_at ON articles(published_at);
 	`
 	if _, err := s.db.Exec(query); err != nil {
 		return err
 	}
 	// Add image_urls column if missing (migration for existing DBs)
 	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN image_urls TEXT DEFAULT '[]'`)
+	// Which translator produced content_ru (provider name + model)
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN translator TEXT DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN translator_model TEXT DEFAULT ''`)
+	_, _ = s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_articles_translator ON articles(translator)`)
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN content_truncated BOOLEAN DEFAULT FALSE`)
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN rescrape_attempts INTEGER DEFAULT 0`)
+	// Formatter fingerprint the published file was rendered with (see publish --refresh)
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN render_fingerprint TEXT DEFAULT ''`)
+	// Pipeline state (see models.ArticleStatus); existing rows are backfilled below
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN status TEXT DEFAULT ''`)
+	// Featured articles are translated and published ahead of the backlog
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN featured BOOLEAN DEFAULT FALSE`)
+	// Feed <updated> time of the version we hold (sources[].update_existing)
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN source_updated_at DATETIME`)
+	// Category/tags set by hand (PUT /api/article/:id); rescrapes keep them
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN taxonomy_overridden BOOLEAN DEFAULT FALSE`)
+	// Extracted text before cleanup, re-cleaned offline by clean-content
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN raw_content TEXT DEFAULT ''`)
+	// Checked by an editor (hugo.require_review); reset when the source changes
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN reviewed BOOLEAN DEFAULT FALSE`)
+	// File path frozen at first publish (hugo.stable_permalinks)
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN published_path TEXT DEFAULT ''`)
+	if _, err := s.db.Exec(`UPDATE articles SET status = CASE
+		WHEN published_to_mkdocs = TRUE THEN 'published'
+		WHEN content_ru != '' THEN 'translated'
+		WHEN content != '' THEN 'scraped'
+		ELSE 'stub'
+	END WHERE status = '' OR status IS NULL`); err != nil {
+		return fmt.Errorf("backfill status: %w", err)
+	}
+	_, _ = s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_articles_status ON articles(status)`)
+	// Named cumulative counters (e.g. translated_chars for DeepL quota)
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS counters (
+		name TEXT PRIMARY KEY,
+		value INTEGER NOT NULL DEFAULT 0,
+		since DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME
+	)`); err != nil {
+		return err
+	}
+	// Per-source fetch watermark (see RecordSourceFetch)
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS source_watermarks (
+		source TEXT PRIMARY KEY,
+		last_fetched_at DATETIME,
+		last_new INTEGER NOT NULL DEFAULT 0,
+		total_new INTEGER NOT NULL DEFAULT 0
+	)`); err != nil {
+		return err
+	}
+	// Checkpointed long-running jobs (see CreateJob)
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		status TEXT NOT NULL,
+		total INTEGER NOT NULL DEFAULT 0,
+		processed INTEGER NOT NULL DEFAULT 0,
+		errors INTEGER NOT NULL DEFAULT 0,
+		elapsed_ms INTEGER NOT NULL DEFAULT 0,
+		error TEXT,
+		started_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		finished_at DATETIME
+	)`); err != nil {
+		return err
+	}
+	_, _ = s.db.Exec(`ALTER TABLE jobs ADD COLUMN cursor INTEGER NOT NULL DEFAULT 0`)
+	// JSON list of the article IDs a translate job covers, so a resume
+	// works through the same articles
+	_, _ = s.db.Exec(`ALTER TABLE jobs ADD COLUMN items TEXT NOT NULL DEFAULT ''`)
+	// JSON list of the options a job was started with (the rebuilds of a
+	// reindex job), so a resume runs the same way
+	_, _ = s.db.Exec(`ALTER TABLE jobs ADD COLUMN params TEXT NOT NULL DEFAULT ''`)
+	// Fetches in a row in which every feed of the source failed, the last
+	// error, and when the source was disabled for it (see RecordSourceFailure)
+	_, _ = s.db.Exec(`ALTER TABLE source_watermarks ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0`)
+	_, _ = s.db.Exec(`ALTER TABLE source_watermarks ADD COLUMN last_error TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE source_watermarks ADD COLUMN last_error_at DATETIME`)
+	_, _ = s.db.Exec(`ALTER TABLE source_watermarks ADD COLUMN auto_disabled_at DATETIME`)
+	// Hash of the scraped text (models.Article.SourceContentHash) and the last
+	// re-scrape that compared it against the source (RecheckContent)
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN content_hash TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN content_checked_at DATETIME`)
+	// Feed link of an article whose page permanently redirected to source_url;
+	// ArticleExists matches it so the feed item is not fetched again as new
+	_, _ = s.db.Exec(`ALTER TABLE articles ADD COLUMN feed_url TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_articles_feed_url ON articles(feed_url)`)
+	// Write counter bumped by triggers on every change to the tables behind
+	// /api/articles and /api/stats, whichever process makes it (see
+	// DataSignature)
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS write_seq (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		seq INTEGER NOT NULL DEFAULT 0
+	)`); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`INSERT OR IGNORE INTO write_seq (id, seq) VALUES (1, 0)`); err != nil {
+		return err
+	}
+	for _, table := range []string{"articles", "counters", "source_watermarks"} {
+		for _, op := range []string{"INSERT", "UPDATE", "DELETE"} {
+			if _, err := s.db.Exec(fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_%s_seq AFTER %s ON %s
+				BEGIN UPDATE write_seq SET seq = seq + 1 WHERE id = 1; END`, table, strings.ToLower(op), op, table)); err != nil {
+				return fmt.Errorf("write_seq trigger on %s: %w", table, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -66,10 +232,11 @@ func (s *SQLiteStorage) Close() error {
 	return s.db.Close()
 }
 
-// ArticleExists checks if an article with the given URL already exists
+// ArticleExists checks if an article with the given URL (or feed link, see
+// models.Article.FeedURL) already exists
 func (s *SQLiteStorage) ArticleExists(sourceURL string) (bool, error) {
 	var count int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM articles WHERE source_url = ?", sourceURL).Scan(&count)
+	err := s.db.QueryRow("SELECT COUNT(*) FROM articles WHERE source_url = ? OR feed_url = ?", sourceURL, sourceURL).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -78,14 +245,49 @@ func (s *SQLiteStorage) ArticleExists(sourceURL string) (bool, error) {
 
 // InsertArticle inserts a new article, returns error if URL already exists
 func (s *SQLiteStorage) InsertArticle(article *models.Article) error {
+	return insertArticle(s.db, article)
+}
+
+// ReplaceArticle inserts article and deletes the article oldID in one
+// transaction, so the old one is only gone once the new one is stored (a
+// missing oldID is not an error)
+func (s *SQLiteStorage) ReplaceArticle(oldID int64, article *models.Article) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertArticle(tx, article); err != nil {
+		article.ID = 0
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM articles WHERE id = ?`, oldID); err != nil {
+		article.ID = 0
+		return fmt.Errorf("failed to delete article %d: %w", oldID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		article.ID = 0
+		return err
+	}
+	return nil
+}
+
+func insertArticle(db execer, article *models.Article) error {
+	if article.Status == "" {
+		article.Status = article.InferStatus()
+	}
+	article.PublishedToHugo = article.Status == models.StatusPublished
+	article.ContentHash = article.SourceContentHash()
 	query := `
 	INSERT INTO articles (
 		source_url, source_site, title, title_ru, description, content, content_ru,
 		author, category, tags, image_url, image_urls, published_at, fetched_at, translated_at,
-		published_to_mkdocs, slug
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		published_to_mkdocs, slug, translator, translator_model, content_truncated, render_fingerprint, status, source_updated_at, raw_content,
+		content_hash, feed_url
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	result, err := s.db.Exec(query,
+	result, err := db.Exec(query,
 		article.SourceURL,
 		article.SourceSite,
 		article.Title,
@@ -103,6 +305,15 @@ func (s *SQLiteStorage) InsertArticle(article *models.Article) error {
 		models.PtrToNullTime(article.TranslatedAt),
 		article.PublishedToHugo,
 		article.Slug,
+		article.Translator,
+		article.TranslatorModel,
+		article.ContentTruncated,
+		article.RenderFingerprint,
+		article.Status,
+		models.PtrToNullTime(article.SourceUpdatedAt),
+		article.RawContent,
+		article.ContentHash,
+		article.FeedURL,
 	)
 	if err != nil {
 		return err
@@ -112,11 +323,33 @@ func (s *SQLiteStorage) InsertArticle(article *models.Article) error {
 		return err
 	}
 	article.ID = id
+
+	// Titles without any Latin/Cyrillic letters produce no slug
+	if article.Slug == "" {
+		article.Slug = slugify.Fallback(id)
+		if _, err := db.Exec("UPDATE articles SET slug = ? WHERE id = ?", article.Slug, id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// UpdateArticle updates an existing article
+// UpdateArticle updates an existing article. Stored raw content is only
+// replaced by non-empty RawContent, so updates made with
+// scraper.keep_raw_content off (or from a copy loaded without it) keep it.
+// When a rescrape moved
+// SourceURL (permanent redirect) to a URL another article is stored under,
+// the link is not moved: article keeps its stored SourceURL and FeedURL
+// instead of failing on the unique source_url.
 func (s *SQLiteStorage) UpdateArticle(article *models.Article) error {
+	if article.Status == "" {
+		article.Status = article.InferStatus()
+	}
+	if err := s.keepURLIfTaken(article); err != nil {
+		return err
+	}
+	article.PublishedToHugo = article.Status == models.StatusPublished
+	article.ContentHash = article.SourceContentHash()
 	query := `
 	UPDATE articles SET
 		title_ru = ?,
@@ -125,10 +358,20 @@ func (s *SQLiteStorage) UpdateArticle(article *models.Article) error {
 		published_to_mkdocs = ?,
 		slug = ?,
 		content = ?,
-		tags = ?,
-		category = ?,
+		tags = CASE WHEN taxonomy_overridden THEN tags ELSE ? END,
+		category = CASE WHEN taxonomy_overridden THEN category ELSE ? END,
 		image_url = ?,
-		image_urls = ?
+		image_urls = ?,
+		translator = ?,
+		translator_model = ?,
+		content_truncated = ?,
+		rescrape_attempts = ?,
+		render_fingerprint = ?,
+		status = ?,
+		raw_content = CASE WHEN ? = '' THEN raw_content ELSE ? END,
+		content_hash = CASE WHEN ? = '' AND raw_content != '' THEN content_hash ELSE ? END,
+		source_url = ?,
+		feed_url = ?
 	WHERE id = ?
 	`
 	_, err := s.db.Exec(query,
@@ -142,67 +385,241 @@ func (s *SQLiteStorage) UpdateArticle(article *models.Article) error {
 		article.Category,
 		article.ImageURL,
 		article.ImageURLsJSON(),
+		article.Translator,
+		article.TranslatorModel,
+		article.ContentTruncated,
+		article.RescrapeAttempts,
+		article.RenderFingerprint,
+		article.Status,
+		article.RawContent, article.RawContent,
+		article.RawContent, article.ContentHash,
+		article.SourceURL,
+		article.FeedURL,
 		article.ID,
 	)
 	return err
 }
 
-// GetArticleByURL retrieves an article by its source URL
+// keepURLIfTaken resets article's SourceURL and FeedURL to the stored ones
+// when another row already holds its SourceURL
+func (s *SQLiteStorage) keepURLIfTaken(article *models.Article) error {
+	var taken int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM articles WHERE source_url = ? AND id <> ?", article.SourceURL, article.ID).Scan(&taken)
+	if err != nil || taken == 0 {
+		return err
+	}
+	return s.db.QueryRow("SELECT source_url, feed_url FROM articles WHERE id = ?", article.ID).Scan(&article.SourceURL, &article.FeedURL)
+}
+
+// markPublishedChunk keeps the IN (...) list under SQLite's variable limit
+const markPublishedChunk = 500
+
+// MarkPublished sets the published flag and the render fingerprint on all ids
+// in one transaction: either every article is marked or none is (e.g. when an
+// id no longer exists).
+func (s *SQLiteStorage) MarkPublished(ids []int64, fingerprint string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	unique := make(map[int64]bool, len(ids))
+	var marked int64
+	for start := 0; start < len(ids); start += markPublishedChunk {
+		end := start + markPublishedChunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk := ids[start:end]
+
+		args := make([]interface{}, len(chunk))
+		for i, id := range chunk {
+			args[i] = id
+			unique[id] = true
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chunk)), ",")
+		args = append([]interface{}{fingerprint}, args...)
+		res, err := tx.Exec("UPDATE articles SET published_to_mkdocs = TRUE, status = 'published', render_fingerprint = ? WHERE id IN ("+placeholders+")", args...)
+		if err != nil {
+			return fmt.Errorf("failed to mark published: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		marked += n
+	}
+
+	if marked < int64(len(unique)) {
+		return fmt.Errorf("failed to mark published: %d of %d articles not found", int64(len(unique))-marked, len(unique))
+	}
+	return tx.Commit()
+}
+
+// GetArticleByURL retrieves an article by its source URL or feed link
+// (sql.ErrNoRows if none; other errors are database failures)
 func (s *SQLiteStorage) GetArticleByURL(sourceURL string) (*models.Article, error) {
 	query := `
-	SELECT id, source_url, source_site, title, title_ru, description, content, content_ru,
-		author, category, tags, image_url, image_urls, published_at, fetched_at, translated_at,
-		published_to_mkdocs, slug
-	FROM articles WHERE source_url = ?
+	SELECT ` + articleColumns + `
+	FROM articles WHERE source_url = ? OR feed_url = ?
+	ORDER BY source_url = ? DESC LIMIT 1
 	`
-	return s.scanArticle(s.db.QueryRow(query, sourceURL))
+	return s.scanArticle(s.db.QueryRow(query, sourceURL, sourceURL, sourceURL))
 }
 
-// GetArticleByID retrieves an article by its ID
+// GetArticleByID retrieves an article by its ID (sql.ErrNoRows if it does
+// not exist; other errors are database failures)
 func (s *SQLiteStorage) GetArticleByID(id int64) (*models.Article, error) {
 	query := `
-	SELECT id, source_url, source_site, title, title_ru, description, content, content_ru,
-		author, category, tags, image_url, image_urls, published_at, fetched_at, translated_at,
-		published_to_mkdocs, slug
+	SELECT ` + articleColumns + `
 	FROM articles WHERE id = ?
 	`
 	return s.scanArticle(s.db.QueryRow(query, id))
 }
 
-// GetUntranslatedArticles returns articles that need translation
-func (s *SQLiteStorage) GetUntranslatedArticles(limit int) ([]*models.Article, error) {
+// GetAdjacentArticles returns the articles published right before (prev) and
+// right after (next) the given one; ties on published_at are broken by id.
+// A missing neighbour is nil. With sameSource=true only articles from the
+// same source site are considered.
+func (s *SQLiteStorage) GetAdjacentArticles(article *models.Article, sameSource bool) (prev, next *models.ArticleLink, err error) {
+	const base = `
+	SELECT id, title, title_ru, slug, published_at
+	FROM articles
+	WHERE %s AND (? = FALSE OR source_site = ?)
+	ORDER BY %s
+	LIMIT 1
+	`
+	// julianday() compares instants, whatever offset published_at was stored with
+	at := article.PublishedAt.UTC()
+	prev, err = s.queryArticleLink(fmt.Sprintf(base,
+		"(julianday(published_at) < julianday(?) OR (julianday(published_at) = julianday(?) AND id < ?))",
+		"julianday(published_at) DESC, id DESC"),
+		at, at, article.ID, sameSource, article.SourceSite)
+	if err != nil {
+		return nil, nil, err
+	}
+	next, err = s.queryArticleLink(fmt.Sprintf(base,
+		"(julianday(published_at) > julianday(?) OR (julianday(published_at) = julianday(?) AND id > ?))",
+		"julianday(published_at) ASC, id ASC"),
+		at, at, article.ID, sameSource, article.SourceSite)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prev, next, nil
+}
+
+// queryArticleLink returns nil (not an error) when no row matches
+func (s *SQLiteStorage) queryArticleLink(query string, args ...interface{}) (*models.ArticleLink, error) {
+	var link models.ArticleLink
+	err := s.db.QueryRow(query, args...).Scan(&link.ID, &link.Title, &link.TitleRU, &link.Slug, &link.PublishedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// GetUntranslatedArticles returns articles that need translation: featured
+// ones first, then those of sourcePriority (in that order), then by order:
+// "newest" (default), "oldest" or "random" (translator.order)
+func (s *SQLiteStorage) GetUntranslatedArticles(limit int, order string, sourcePriority []string) ([]*models.Article, error) {
+	var args []interface{}
+	orderBy := "featured DESC"
+	if len(sourcePriority) > 0 {
+		var sb strings.Builder
+		sb.WriteString(", CASE source_site")
+		for i, source := range sourcePriority {
+			sb.WriteString(fmt.Sprintf(" WHEN ? THEN %d", i))
+			args = append(args, source)
+		}
+		sb.WriteString(fmt.Sprintf(" ELSE %d END", len(sourcePriority)))
+		orderBy += sb.String()
+	}
+	// julianday() orders instants, whatever offset published_at was stored with
+	switch order {
+	case "oldest":
+		orderBy += ", julianday(published_at) ASC"
+	case "random":
+		orderBy += ", RANDOM()"
+	default:
+		orderBy += ", julianday(published_at) DESC"
+	}
+
 	query := `
-	SELECT id, source_url, source_site, title, title_ru, description, content, content_ru,
-		author, category, tags, image_url, image_urls, published_at, fetched_at, translated_at,
-		published_to_mkdocs, slug
+	SELECT ` + articleColumns + `
 	FROM articles 
-	WHERE content != '' AND content_ru = ''
-	ORDER BY published_at DESC
+	WHERE status IN ('scraped', 'errored')
+	ORDER BY ` + orderBy + `
 	LIMIT ?
 	`
-	return s.scanArticles(query, limit)
+	return s.scanArticles(query, append(args, limit)...)
 }
 
-// GetUnpublishedArticles returns translated articles that haven't been published
-func (s *SQLiteStorage) GetUnpublishedArticles(limit int) ([]*models.Article, error) {
+// GetUntranslatedArticlesByID returns the articles of ids still waiting for
+// translation (status scraped or errored), in the order of ids
+func (s *SQLiteStorage) GetUntranslatedArticlesByID(ids []int64) ([]*models.Article, error) {
+	byID := make(map[int64]*models.Article, len(ids))
+	for start := 0; start < len(ids); start += markPublishedChunk {
+		end := min(start+markPublishedChunk, len(ids))
+		chunk := ids[start:end]
+
+		args := make([]interface{}, len(chunk))
+		for i, id := range chunk {
+			args[i] = id
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chunk)), ",")
+		articles, err := s.scanArticles(`
+	SELECT `+articleColumns+`
+	FROM articles
+	WHERE status IN ('scraped', 'errored') AND id IN (`+placeholders+`)
+	`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range articles {
+			byID[a.ID] = a
+		}
+	}
+
+	var articles []*models.Article
+	for _, id := range ids {
+		if a, ok := byID[id]; ok {
+			articles = append(articles, a)
+			delete(byID, id)
+		}
+	}
+	return articles, nil
+}
+
+// GetUnpublishedArticles returns translated articles that haven't been
+// published, featured ones first. With reviewedOnly=true articles not yet
+// marked reviewed are left out (hugo.require_review); a non-zero
+// fetchedBefore leaves out articles fetched after it (schedule.publish_delay).
+func (s *SQLiteStorage) GetUnpublishedArticles(limit int, reviewedOnly bool, fetchedBefore time.Time) ([]*models.Article, error) {
+	// julianday() compares instants, whatever offset fetched_at and
+	// published_at were stored with
 	query := `
-	SELECT id, source_url, source_site, title, title_ru, description, content, content_ru,
-		author, category, tags, image_url, image_urls, published_at, fetched_at, translated_at,
-		published_to_mkdocs, slug
+	SELECT ` + articleColumns + `
 	FROM articles 
-	WHERE content_ru != '' AND published_to_mkdocs = FALSE
-	ORDER BY published_at DESC
+	WHERE status = 'translated' AND (? = FALSE OR reviewed = TRUE)
+		AND (? = FALSE OR julianday(fetched_at) <= julianday(?))
+	ORDER BY featured DESC, julianday(published_at) DESC
 	LIMIT ?
 	`
-	return s.scanArticles(query, limit)
+	return s.scanArticles(query, reviewedOnly, !fetchedBefore.IsZero(), fetchedBefore.UTC(), limit)
 }
 
 // GetRecentArticles returns the most recent articles
 func (s *SQLiteStorage) GetRecentArticles(limit int) ([]*models.Article, error) {
 	query := `
-	SELECT id, source_url, source_site, title, title_ru, description, content, content_ru,
-		author, category, tags, image_url, image_urls, published_at, fetched_at, translated_at,
-		published_to_mkdocs, slug
+	SELECT ` + articleColumns + `
 	FROM articles 
 	ORDER BY fetched_at DESC
 	LIMIT ?
@@ -213,38 +630,404 @@ func (s *SQLiteStorage) GetRecentArticles(limit int) ([]*models.Article, error)
 // GetRecentlyTranslatedArticles returns articles translated most recently (by translated_at DESC)
 func (s *SQLiteStorage) GetRecentlyTranslatedArticles(limit int) ([]*models.Article, error) {
 	query := `
-	SELECT id, source_url, source_site, title, title_ru, description, content, content_ru,
-		author, category, tags, image_url, image_urls, published_at, fetched_at, translated_at,
-		published_to_mkdocs, slug
+	SELECT ` + articleColumns + `
+	FROM articles 
+	WHERE translated_at IS NOT NULL AND ` + translatedWhere + `
+	ORDER BY translated_at DESC
+	LIMIT ?
+	`
+	return s.scanArticles(query, limit)
+}
+
+// GetArticlesByTranslator returns up to limit translated articles produced
+// by the given translator provider (e.g. "libretranslate"), newest
+// translation first, narrowed to status as in GetArticlesByStatus ("" = all)
+func (s *SQLiteStorage) GetArticlesByTranslator(name, status string, limit int) ([]*models.Article, error) {
+	if status == "" {
+		status = "all"
+	}
+	where, ok := statusFilters[status]
+	if !ok {
+		return nil, fmt.Errorf("unknown status %q (expected one of: %s)", status, strings.Join(ArticleStatuses(), ", "))
+	}
+	query := `
+	SELECT ` + articleColumns + `
 	FROM articles 
-	WHERE translated_at IS NOT NULL AND content_ru != ''
+	WHERE translator = ? AND ` + translatedWhere + ` AND ` + where + `
 	ORDER BY translated_at DESC
 	LIMIT ?
 	`
+	return s.scanArticles(query, name, limit)
+}
+
+// translatedWhere matches articles that have a translation, published or not
+const translatedWhere = "status IN ('translated', 'published')"
+
+// statusFilters maps article status names to WHERE clauses: the grouped
+// filters plus every models.ArticleStatus value
+var statusFilters = map[string]string{
+	"all":          "1 = 1",
+	"untranslated": "status NOT IN ('translated', 'published')",
+	"translated":   translatedWhere,
+	"unpublished":  "status = 'translated'",
+	"published":    "status = 'published'",
+	"new":          "status = 'new'",
+	"scraped":      "status = 'scraped'",
+	"errored":      "status = 'errored'",
+	"stub":         "status = 'stub'",
+	"reviewed":     "reviewed = TRUE",
+	"unreviewed":   "status != 'published' AND reviewed = FALSE", // still waiting for an editor
+}
+
+// ArticleStatuses lists the status names accepted by GetArticlesByStatus
+func ArticleStatuses() []string {
+	return []string{"all", "untranslated", "translated", "unpublished", "published", "new", "scraped", "errored", "stub", "reviewed", "unreviewed"}
+}
+
+// GetArticlesByStatus returns the most recently fetched articles matching a
+// status filter (see ArticleStatuses); "" means all
+func (s *SQLiteStorage) GetArticlesByStatus(status string, limit int) ([]*models.Article, error) {
+	if status == "" {
+		status = "all"
+	}
+	where, ok := statusFilters[status]
+	if !ok {
+		return nil, fmt.Errorf("unknown status %q (expected one of: %s)", status, strings.Join(ArticleStatuses(), ", "))
+	}
+	query := `
+	SELECT ` + articleColumns + `
+	FROM articles 
+	WHERE ` + where + `
+	ORDER BY fetched_at DESC
+	LIMIT ?
+	`
 	return s.scanArticles(query, limit)
 }
 
-// GetArticlesWithEmptyContent returns articles where content is empty or too short (scraping failed/incomplete).
+// GetTranslatedArticles returns every translated article, oldest first.
+// With publishedOnly=true only articles already published to Hugo are returned.
+func (s *SQLiteStorage) GetTranslatedArticles(publishedOnly bool) ([]*models.Article, error) {
+	query := `
+	SELECT ` + articleColumns + `
+	FROM articles 
+	WHERE ` + translatedWhere + ` AND (? = FALSE OR status = 'published')
+	ORDER BY published_at ASC
+	`
+	return s.scanArticles(query, publishedOnly)
+}
+
+// GetArticlesToRescrape returns articles still without content: status new
+// (the scrape failed) or stub (it found no text). Articles that already had
+// maxAttempts unsuccessful rescrapes are skipped (maxAttempts <= 0 = no limit).
 // Limited to 500 rows to avoid unbounded memory usage.
-func (s *SQLiteStorage) GetArticlesWithEmptyContent() ([]*models.Article, error) {
+func (s *SQLiteStorage) GetArticlesToRescrape(maxAttempts int) ([]*models.Article, error) {
 	query := `
-	SELECT id, source_url, source_site, title, title_ru, description, content, content_ru,
-		author, category, tags, image_url, image_urls, published_at, fetched_at, translated_at,
-		published_to_mkdocs, slug
+	SELECT ` + articleColumns + `
 	FROM articles 
-	WHERE content = '' OR content IS NULL OR LENGTH(content) < 1000 OR category = ''
+	WHERE status IN (?, ?)
+		AND (? <= 0 OR rescrape_attempts < ?)
 	ORDER BY fetched_at DESC
 	LIMIT 500
 	`
-	return s.scanArticles(query)
+	return s.scanArticles(query, models.StatusNew, models.StatusStub, maxAttempts, maxAttempts)
+}
+
+// GetArticlesWithRawContent returns articles that kept their raw extracted
+// text (all sources when source is empty), newest first, RawContent loaded
+func (s *SQLiteStorage) GetArticlesWithRawContent(source string) ([]*models.Article, error) {
+	query := `
+	SELECT ` + articleColumns + `
+	FROM articles
+	WHERE raw_content != '' AND (? = '' OR source_site = ?)
+	ORDER BY fetched_at DESC
+	`
+	articles, err := s.scanArticles(query, source, source)
+	if err != nil {
+		return nil, err
+	}
+	return articles, s.loadRawContent(articles)
+}
+
+// GetRawContent returns the raw extracted text stored for an article (""
+// when none was kept)
+func (s *SQLiteStorage) GetRawContent(id int64) (string, error) {
+	var raw string
+	err := s.db.QueryRow("SELECT raw_content FROM articles WHERE id = ?", id).Scan(&raw)
+	return raw, err
+}
+
+// loadRawContent fills RawContent of articles read with articleColumns
+func (s *SQLiteStorage) loadRawContent(articles []*models.Article) error {
+	for _, a := range articles {
+		raw, err := s.GetRawContent(a.ID)
+		if err != nil {
+			return fmt.Errorf("raw content of article %d: %w", a.ID, err)
+		}
+		a.RawContent = raw
+	}
+	return nil
+}
+
+// UpdateContent replaces an article's cleaned content. With requeue, a
+// translated or published article goes back to scraped to be re-translated
+// and re-published.
+func (s *SQLiteStorage) UpdateContent(id int64, content string, requeue bool) error {
+	// The hash only follows content for rows without raw content (see
+	// models.Article.SourceContentHash)
+	hash := models.HashText(content)
+	if !requeue {
+		_, err := s.db.Exec(`
+		UPDATE articles SET
+			content = ?,
+			content_hash = CASE WHEN raw_content = '' THEN ? ELSE content_hash END
+		WHERE id = ?
+		`, content, hash, id)
+		return err
+	}
+	_, err := s.db.Exec(`
+	UPDATE articles SET
+		content = ?,
+		content_hash = CASE WHEN raw_content = '' THEN ? ELSE content_hash END,
+		status = CASE WHEN status IN (?, ?, ?) THEN ? ELSE status END,
+		published_to_mkdocs = FALSE
+	WHERE id = ?
+	`, content, hash, models.StatusTranslated, models.StatusPublished, models.StatusErrored, models.StatusScraped, id)
+	return err
+}
+
+// SetArticleStatus moves an article to the given pipeline status
+func (s *SQLiteStorage) SetArticleStatus(id int64, status models.ArticleStatus) error {
+	_, err := s.db.Exec("UPDATE articles SET status = ?, published_to_mkdocs = ? WHERE id = ?", status, status == models.StatusPublished, id)
+	return err
+}
+
+// UpdateFromSource stores a changed version of an article from its source:
+// the source fields and the new content. The translation is kept until it
+// is redone; status should be set by the caller (scraped = re-translate).
+func (s *SQLiteStorage) UpdateFromSource(article *models.Article) error {
+	article.ContentHash = article.SourceContentHash()
+	_, err := s.db.Exec(`
+	UPDATE articles SET
+		title = ?,
+		description = ?,
+		content = ?,
+		raw_content = ?,
+		author = ?,
+		category = CASE WHEN taxonomy_overridden THEN category ELSE ? END,
+		tags = CASE WHEN taxonomy_overridden THEN tags ELSE ? END,
+		image_url = ?,
+		image_urls = ?,
+		source_updated_at = ?,
+		status = ?,
+		published_to_mkdocs = ?,
+		reviewed = FALSE,
+		content_hash = ?
+	WHERE id = ?
+	`,
+		article.Title,
+		article.Description,
+		article.Content,
+		article.RawContent,
+		article.Author,
+		article.Category,
+		article.TagsJSON(),
+		article.ImageURL,
+		article.ImageURLsJSON(),
+		models.PtrToNullTime(article.SourceUpdatedAt),
+		article.Status,
+		article.Status == models.StatusPublished,
+		article.ContentHash,
+		article.ID,
+	)
+	return err
+}
+
+// SetSourceUpdatedAt records that the source version updated at t was seen
+// (used when an update was too small to take over)
+func (s *SQLiteStorage) SetSourceUpdatedAt(id int64, t time.Time) error {
+	_, err := s.db.Exec("UPDATE articles SET source_updated_at = ? WHERE id = ?", t, id)
+	return err
+}
+
+// GetArticlesForRecheck returns up to limit articles with content fetched at
+// or after fetchedSince whose last re-check (or fetch, if never checked) was
+// before checkedBefore, longest unchecked first, RawContent loaded
+func (s *SQLiteStorage) GetArticlesForRecheck(fetchedSince, checkedBefore time.Time, limit int) ([]*models.Article, error) {
+	query := `
+	SELECT ` + articleColumns + `
+	FROM articles
+	WHERE content != '' AND julianday(fetched_at) >= julianday(?)
+		AND julianday(COALESCE(content_checked_at, fetched_at)) < julianday(?)
+	ORDER BY julianday(COALESCE(content_checked_at, fetched_at)) ASC
+	LIMIT ?
+	`
+	articles, err := s.scanArticles(query, fetchedSince.UTC(), checkedBefore.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return articles, s.loadRawContent(articles)
+}
+
+// SetContentChecked records a re-check of the article against its source
+func (s *SQLiteStorage) SetContentChecked(id int64, at time.Time) error {
+	_, err := s.db.Exec("UPDATE articles SET content_checked_at = ? WHERE id = ?", at, id)
+	return err
+}
+
+// SetSlug changes an article's slug (and so its file name)
+func (s *SQLiteStorage) SetSlug(id int64, slug string) error {
+	_, err := s.db.Exec("UPDATE articles SET slug = ? WHERE id = ?", slug, id)
+	return err
+}
+
+// SetPublishedPath freezes an article's file path; a path already frozen
+// is kept
+func (s *SQLiteStorage) SetPublishedPath(id int64, path string) error {
+	_, err := s.db.Exec("UPDATE articles SET published_path = ? WHERE id = ? AND published_path = ''", path, id)
+	return err
+}
+
+// SetFeatured sets or clears the featured flag; sql.ErrNoRows when the
+// article does not exist
+func (s *SQLiteStorage) SetFeatured(id int64, featured bool) error {
+	res, err := s.db.Exec("UPDATE articles SET featured = ? WHERE id = ?", featured, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetReviewed sets or clears the reviewed flag on all ids and returns how
+// many articles exist among them
+func (s *SQLiteStorage) SetReviewed(ids []int64, reviewed bool) (int64, error) {
+	var updated int64
+	for start := 0; start < len(ids); start += markPublishedChunk {
+		end := start + markPublishedChunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk := ids[start:end]
+
+		args := make([]interface{}, 0, len(chunk)+1)
+		args = append(args, reviewed)
+		for _, id := range chunk {
+			args = append(args, id)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chunk)), ",")
+		res, err := s.db.Exec("UPDATE articles SET reviewed = ? WHERE id IN ("+placeholders+")", args...)
+		if err != nil {
+			return updated, err
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			updated += n
+		}
+	}
+	return updated, nil
+}
+
+// SetTaxonomy sets the category and tags by hand and marks them overridden so
+// rescrapes and source updates keep them. A published article goes back to
+// translated to be re-published with the new taxonomy. sql.ErrNoRows when
+// the article does not exist.
+func (s *SQLiteStorage) SetTaxonomy(id int64, category string, tags []string) error {
+	a := &models.Article{Tags: tags}
+	res, err := s.db.Exec(`
+	UPDATE articles SET
+		category = ?,
+		tags = ?,
+		taxonomy_overridden = TRUE,
+		status = CASE WHEN status = ? THEN ? ELSE status END,
+		published_to_mkdocs = FALSE
+	WHERE id = ?
+	`, category, a.TagsJSON(), models.StatusPublished, models.StatusTranslated, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// IncrementRescrapeAttempts records an unsuccessful rescrape and returns the new count
+func (s *SQLiteStorage) IncrementRescrapeAttempts(id int64) (int, error) {
+	if _, err := s.db.Exec("UPDATE articles SET rescrape_attempts = rescrape_attempts + 1 WHERE id = ?", id); err != nil {
+		return 0, err
+	}
+	var attempts int
+	err := s.db.QueryRow("SELECT rescrape_attempts FROM articles WHERE id = ?", id).Scan(&attempts)
+	return attempts, err
+}
+
+// GetStaleRenderedArticles returns published articles rendered with a
+// formatter fingerprint other than the given one, oldest first
+func (s *SQLiteStorage) GetStaleRenderedArticles(fingerprint string, limit int) ([]*models.Article, error) {
+	query := `
+	SELECT ` + articleColumns + `
+	FROM articles 
+	WHERE status = 'published' AND render_fingerprint != ?
+	ORDER BY published_at ASC
+	LIMIT ?
+	`
+	return s.scanArticles(query, fingerprint, limit)
+}
+
+// GetArticlesBefore returns articles published before t, oldest first.
+// With publishedOnly=true only articles already published to Hugo are returned.
+func (s *SQLiteStorage) GetArticlesBefore(t time.Time, publishedOnly bool) ([]*models.Article, error) {
+	// julianday() compares instants, whatever offset published_at was stored with
+	query := `
+	SELECT ` + articleColumns + `
+	FROM articles 
+	WHERE julianday(published_at) < julianday(?) AND (? = FALSE OR status = 'published')
+	ORDER BY julianday(published_at) ASC
+	`
+	return s.scanArticles(query, t.UTC(), publishedOnly)
+}
+
+// GetTranslatedArticlesBetween returns translated articles (published or
+// not) with published_at in [from, to), oldest first
+func (s *SQLiteStorage) GetTranslatedArticlesBetween(from, to time.Time) ([]*models.Article, error) {
+	query := `
+	SELECT ` + articleColumns + `
+	FROM articles 
+	WHERE julianday(published_at) >= julianday(?) AND julianday(published_at) < julianday(?) AND ` + translatedWhere + `
+	ORDER BY julianday(published_at) ASC
+	`
+	return s.scanArticles(query, from.UTC(), to.UTC())
+}
+
+// DeleteArticlesBefore deletes articles published before t and returns how
+// many rows were removed. With publishedOnly=true unpublished articles are kept.
+func (s *SQLiteStorage) DeleteArticlesBefore(t time.Time, publishedOnly bool) (int, error) {
+	res, err := s.db.Exec(`DELETE FROM articles WHERE julianday(published_at) < julianday(?) AND (? = FALSE OR status = 'published')`, t.UTC(), publishedOnly)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// GetArticlesFetchedSince returns the articles fetched at or after t, oldest
+// first
+func (s *SQLiteStorage) GetArticlesFetchedSince(t time.Time) ([]*models.Article, error) {
+	// julianday() compares instants, whatever offset fetched_at was stored with
+	query := `
+	SELECT ` + articleColumns + `
+	FROM articles
+	WHERE julianday(fetched_at) >= julianday(?)
+	ORDER BY julianday(fetched_at) ASC, id ASC
+	`
+	return s.scanArticles(query, t.UTC())
 }
 
 // GetAllArticles returns all articles (with optional limit)
 func (s *SQLiteStorage) GetAllArticles(limit int) ([]*models.Article, error) {
 	query := `
-	SELECT id, source_url, source_site, title, title_ru, description, content, content_ru,
-		author, category, tags, image_url, image_urls, published_at, fetched_at, translated_at,
-		published_to_mkdocs, slug
+	SELECT ` + articleColumns + `
 	FROM articles 
 	ORDER BY fetched_at DESC
 	LIMIT ?
@@ -252,24 +1035,62 @@ func (s *SQLiteStorage) GetAllArticles(limit int) ([]*models.Article, error) {
 	return s.scanArticles(query, limit)
 }
 
+// DataSignature is a cheap fingerprint of the articles, counters and source
+// watermarks: the article count, the highest article ID and the write
+// counter. It changes whenever any of them does, so an unchanged signature
+// means a listing or the stats need not be rendered again.
+func (s *SQLiteStorage) DataSignature() (string, error) {
+	var count, maxID, seq int64
+	err := s.db.QueryRow(`SELECT (SELECT COUNT(*) FROM articles), (SELECT COALESCE(MAX(id), 0) FROM articles),
+		(SELECT seq FROM write_seq WHERE id = 1)`).Scan(&count, &maxID, &seq)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d-%d", count, maxID, seq), nil
+}
+
 // GetStats returns storage statistics
 func (s *SQLiteStorage) GetStats() (total, translated, published int, err error) {
 	err = s.db.QueryRow("SELECT COUNT(*) FROM articles").Scan(&total)
 	if err != nil {
 		return
 	}
-	err = s.db.QueryRow("SELECT COUNT(*) FROM articles WHERE content_ru != ''").Scan(&translated)
+	err = s.db.QueryRow("SELECT COUNT(*) FROM articles WHERE " + translatedWhere).Scan(&translated)
 	if err != nil {
 		return
 	}
-	err = s.db.QueryRow("SELECT COUNT(*) FROM articles WHERE published_to_mkdocs = TRUE").Scan(&published)
+	err = s.db.QueryRow("SELECT COUNT(*) FROM articles WHERE status = 'published'").Scan(&published)
 	return
 }
 
 func (s *SQLiteStorage) scanArticle(row *sql.Row) (*models.Article, error) {
+	return scanArticleRow(row)
+}
+
+func (s *SQLiteStorage) scanArticles(query string, args ...interface{}) ([]*models.Article, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []*models.Article
+	for rows.Next() {
+		article, err := scanArticleRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+
+	return articles, rows.Err()
+}
+
+// scanArticleRow scans one row selected with articleColumns
+func scanArticleRow(row rowScanner) (*models.Article, error) {
 	var article models.Article
 	var tags, imageURLs string
-	var translatedAt sql.NullTime
+	var translatedAt, sourceUpdatedAt, contentCheckedAt sql.NullTime
 	var publishedAt time.Time
 
 	err := row.Scan(
@@ -291,6 +1112,20 @@ func (s *SQLiteStorage) scanArticle(row *sql.Row) (*models.Article, error) {
 		&translatedAt,
 		&article.PublishedToHugo,
 		&article.Slug,
+		&article.Translator,
+		&article.TranslatorModel,
+		&article.ContentTruncated,
+		&article.RescrapeAttempts,
+		&article.RenderFingerprint,
+		&article.Status,
+		&article.Featured,
+		&sourceUpdatedAt,
+		&article.TaxonomyOverridden,
+		&article.Reviewed,
+		&article.PublishedPath,
+		&article.ContentHash,
+		&contentCheckedAt,
+		&article.FeedURL,
 	)
 	if err != nil {
 		return nil, err
@@ -298,56 +1133,10 @@ func (s *SQLiteStorage) scanArticle(row *sql.Row) (*models.Article, error) {
 
 	article.PublishedAt = publishedAt
 	article.TranslatedAt = models.NullTimeToPtr(translatedAt)
+	article.SourceUpdatedAt = models.NullTimeToPtr(sourceUpdatedAt)
+	article.ContentCheckedAt = models.NullTimeToPtr(contentCheckedAt)
 	article.ParseTags(tags)
 	article.ParseImageURLs(imageURLs)
 
 	return &article, nil
 }
-
-func (s *SQLiteStorage) scanArticles(query string, args ...interface{}) ([]*models.Article, error) {
-	rows, err := s.db.Query(query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var articles []*models.Article
-	for rows.Next() {
-		var article models.Article
-		var tags, imageURLs string
-		var translatedAt sql.NullTime
-		var publishedAt time.Time
-
-		err := rows.Scan(
-			&article.ID,
-			&article.SourceURL,
-			&article.SourceSite,
-			&article.Title,
-			&article.TitleRU,
-			&article.Description,
-			&article.Content,
-			&article.ContentRU,
-			&article.Author,
-			&article.Category,
-			&tags,
-			&article.ImageURL,
-			&imageURLs,
-			&publishedAt,
-			&article.FetchedAt,
-			&translatedAt,
-			&article.PublishedToHugo,
-			&article.Slug,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		article.PublishedAt = publishedAt
-		article.TranslatedAt = models.NullTimeToPtr(translatedAt)
-		article.ParseTags(tags)
-		article.ParseImageURLs(imageURLs)
-		articles = append(articles, &article)
-	}
-
-	return articles, rows.Err()
-}
@@ -0,0 +1,682 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"moto-news/internal/config"
+	"moto-news/internal/models"
+)
+
+func newTestStorage(t *testing.T) *SQLiteStorage {
+	t.Helper()
+	s, err := NewSQLiteStorage(&config.DatabaseConfig{Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// insertTestArticle saves an article with the fields every test needs;
+// mutate adjusts it before the insert
+func insertTestArticle(t *testing.T, s *SQLiteStorage, url string, mutate func(*models.Article)) *models.Article {
+	t.Helper()
+	now := time.Now().UTC()
+	a := &models.Article{
+		SourceURL:   url,
+		SourceSite:  "example.com",
+		Title:       "Title " + url,
+		Content:     "Content of " + url,
+		Slug:        "slug-" + filepath.Base(url),
+		PublishedAt: now,
+		FetchedAt:   now,
+	}
+	if mutate != nil {
+		mutate(a)
+	}
+	if err := s.InsertArticle(a); err != nil {
+		t.Fatalf("InsertArticle(%s): %v", url, err)
+	}
+	return a
+}
+
+func articleIDs(articles []*models.Article) []int64 {
+	ids := make([]int64, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+func translated(a *models.Article) {
+	now := time.Now().UTC()
+	a.TitleRU = "Заголовок"
+	a.ContentRU = "Текст"
+	a.TranslatedAt = &now
+	a.Status = models.StatusTranslated
+}
+
+func TestGetUnpublishedArticlesPublishDelay(t *testing.T) {
+	s := newTestStorage(t)
+	old := insertTestArticle(t, s, "https://example.com/old", func(a *models.Article) {
+		translated(a)
+		a.FetchedAt = time.Now().Add(-3 * time.Hour)
+	})
+	recent := insertTestArticle(t, s, "https://example.com/recent", translated)
+
+	all, err := s.GetUnpublishedArticles(10, false, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("without a delay got %v, want both articles", articleIDs(all))
+	}
+
+	ready, err := s.GetUnpublishedArticles(10, false, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ready) != 1 || ready[0].ID != old.ID {
+		t.Fatalf("with a 1h delay got %v, want only #%d (#%d is held back)", articleIDs(ready), old.ID, recent.ID)
+	}
+}
+
+func TestUpdateArticleRedirectToStoredURL(t *testing.T) {
+	s := newTestStorage(t)
+	target := insertTestArticle(t, s, "https://example.com/final", nil)
+	moved := insertTestArticle(t, s, "https://example.com/short", nil)
+
+	// A rescrape followed a permanent redirect to the URL of another article
+	moved.FeedURL = moved.SourceURL
+	moved.SourceURL = target.SourceURL
+	moved.Content = "rescraped content"
+	if err := s.UpdateArticle(moved); err != nil {
+		t.Fatalf("UpdateArticle: %v", err)
+	}
+	if moved.SourceURL != "https://example.com/short" || moved.FeedURL != "" {
+		t.Errorf("SourceURL=%s FeedURL=%s, want the stored link kept", moved.SourceURL, moved.FeedURL)
+	}
+	got, err := s.GetArticleByID(moved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SourceURL != "https://example.com/short" || got.Content != "rescraped content" {
+		t.Errorf("stored SourceURL=%s Content=%q, want the link kept and the content updated", got.SourceURL, got.Content)
+	}
+
+	// A free final URL is taken over, with the feed link kept for lookups
+	moved.FeedURL = moved.SourceURL
+	moved.SourceURL = "https://example.com/new-home"
+	if err := s.UpdateArticle(moved); err != nil {
+		t.Fatal(err)
+	}
+	byFeed, err := s.GetArticleByURL("https://example.com/short")
+	if err != nil || byFeed.ID != moved.ID || byFeed.SourceURL != "https://example.com/new-home" {
+		t.Errorf("GetArticleByURL(feed link) = %+v, %v", byFeed, err)
+	}
+}
+
+func TestRawContentReadWrite(t *testing.T) {
+	s := newTestStorage(t)
+	a := insertTestArticle(t, s, "https://example.com/raw", func(a *models.Article) {
+		a.RawContent = "Raw &amp; <b>unclean</b> text"
+		a.Content = "Raw & unclean text"
+	})
+	got, err := s.GetArticleByID(a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RawContent != "" {
+		t.Errorf("GetArticleByID loaded the raw content")
+	}
+	if raw, err := s.GetRawContent(a.ID); err != nil || raw != a.RawContent {
+		t.Errorf("GetRawContent = %q, %v; want %q", raw, err, a.RawContent)
+	}
+	if got.ContentHash != models.HashText(a.RawContent) {
+		t.Errorf("ContentHash follows the content, want the raw text")
+	}
+
+	// An update without raw content (keep_raw_content off) keeps it
+	got.Content = "Rescraped text"
+	if err := s.UpdateArticle(got); err != nil {
+		t.Fatal(err)
+	}
+	after, err := s.GetArticleByID(a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := s.GetRawContent(a.ID); raw != a.RawContent || after.Content != "Rescraped text" {
+		t.Errorf("after update: RawContent=%q Content=%q, want raw kept and content updated", raw, after.Content)
+	}
+	if after.ContentHash != models.HashText(a.RawContent) {
+		t.Errorf("after update: ContentHash no longer matches the kept raw text")
+	}
+
+	// New raw content replaces it
+	after.RawContent = "New raw"
+	if err := s.UpdateArticle(after); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := s.GetRawContent(a.ID)
+	if got, _ := s.GetArticleByID(a.ID); raw != "New raw" || got.ContentHash != models.HashText("New raw") {
+		t.Errorf("RawContent=%q after update with raw, want %q", raw, "New raw")
+	}
+
+	list, err := s.GetArticlesWithRawContent("")
+	if err != nil || len(list) != 1 || list[0].RawContent != "New raw" {
+		t.Errorf("GetArticlesWithRawContent = %v, %v; want the article with its raw content", list, err)
+	}
+}
+
+func TestDeleteArticlesBefore(t *testing.T) {
+	s := newTestStorage(t)
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	old := insertTestArticle(t, s, "https://example.com/old", func(a *models.Article) {
+		a.PublishedAt = cutoff.AddDate(0, -2, 0)
+	})
+	// 02:00 MSK on Jan 1 is 23:00 UTC on Dec 31: before the cutoff, though
+	// its text sorts after it
+	offset := insertTestArticle(t, s, "https://example.com/offset", func(a *models.Article) {
+		a.PublishedAt = time.Date(2024, 1, 1, 2, 0, 0, 0, moscow)
+		a.Status = models.StatusPublished
+	})
+	recent := insertTestArticle(t, s, "https://example.com/recent", func(a *models.Article) {
+		a.PublishedAt = cutoff.Add(time.Hour)
+		a.Status = models.StatusPublished
+	})
+
+	candidates, err := s.GetArticlesBefore(cutoff, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := articleIDs(candidates); len(ids) != 2 || ids[0] != old.ID || ids[1] != offset.ID {
+		t.Fatalf("GetArticlesBefore = %v, want [%d %d]", ids, old.ID, offset.ID)
+	}
+
+	n, err := s.DeleteArticlesBefore(cutoff, true)
+	if err != nil || n != 1 {
+		t.Fatalf("published only: deleted %d (%v), want 1", n, err)
+	}
+	if _, err := s.GetArticleByID(offset.ID); err == nil {
+		t.Errorf("published article before the cutoff is still there")
+	}
+	if _, err := s.GetArticleByID(old.ID); err != nil {
+		t.Errorf("unpublished article was deleted with publishedOnly")
+	}
+
+	n, err = s.DeleteArticlesBefore(cutoff, false)
+	if err != nil || n != 1 {
+		t.Fatalf("deleted %d (%v), want 1", n, err)
+	}
+	if _, err := s.GetArticleByID(recent.ID); err != nil {
+		t.Errorf("article after the cutoff was deleted: %v", err)
+	}
+}
+
+func TestGetTranslatedArticlesBetween(t *testing.T) {
+	s := newTestStorage(t)
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	in := insertTestArticle(t, s, "https://example.com/in", func(a *models.Article) {
+		translated(a)
+		a.PublishedAt = day.Add(12 * time.Hour)
+	})
+	// 01:00 MSK on June 2 is still June 1 in UTC
+	inOffset := insertTestArticle(t, s, "https://example.com/in-offset", func(a *models.Article) {
+		translated(a)
+		a.PublishedAt = time.Date(2024, 6, 2, 1, 0, 0, 0, moscow)
+	})
+	insertTestArticle(t, s, "https://example.com/next-day", func(a *models.Article) {
+		translated(a)
+		a.PublishedAt = day.AddDate(0, 0, 1)
+	})
+	insertTestArticle(t, s, "https://example.com/untranslated", func(a *models.Article) {
+		a.PublishedAt = day.Add(time.Hour)
+	})
+
+	got, err := s.GetTranslatedArticlesBetween(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := articleIDs(got); len(ids) != 2 || ids[0] != in.ID || ids[1] != inOffset.ID {
+		t.Errorf("GetTranslatedArticlesBetween = %v, want [%d %d]", ids, in.ID, inOffset.ID)
+	}
+}
+
+func TestGetAdjacentArticles(t *testing.T) {
+	s := newTestStorage(t)
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	first := insertTestArticle(t, s, "https://example.com/1", func(a *models.Article) { a.PublishedAt = base })
+	// 14:00 MSK is 11:00 UTC: the earliest, though its text sorts last
+	earliest := insertTestArticle(t, s, "https://example.com/0", func(a *models.Article) {
+		a.PublishedAt = time.Date(2024, 6, 1, 14, 0, 0, 0, moscow)
+	})
+	other := insertTestArticle(t, s, "https://other.com/2", func(a *models.Article) {
+		a.PublishedAt = base.Add(time.Hour)
+		a.SourceSite = "other.com"
+	})
+	last := insertTestArticle(t, s, "https://example.com/3", func(a *models.Article) { a.PublishedAt = base.Add(2 * time.Hour) })
+
+	prev, next, err := s.GetAdjacentArticles(first, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev == nil || prev.ID != earliest.ID || next == nil || next.ID != other.ID {
+		t.Errorf("all sources: prev=%+v next=%+v, want #%d and #%d", prev, next, earliest.ID, other.ID)
+	}
+
+	_, next, err = s.GetAdjacentArticles(first, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == nil || next.ID != last.ID {
+		t.Errorf("same source: next=%+v, want #%d", next, last.ID)
+	}
+
+	prev, _, err = s.GetAdjacentArticles(earliest, false)
+	if err != nil || prev != nil {
+		t.Errorf("first article: prev=%+v (%v), want nil", prev, err)
+	}
+	_, next, err = s.GetAdjacentArticles(last, false)
+	if err != nil || next != nil {
+		t.Errorf("last article: next=%+v (%v), want nil", next, err)
+	}
+}
+
+func TestGetArticlesToRescrape(t *testing.T) {
+	s := newTestStorage(t)
+	failed := insertTestArticle(t, s, "https://example.com/failed", func(a *models.Article) {
+		a.Content = ""
+		a.Status = models.StatusNew
+	})
+	stub := insertTestArticle(t, s, "https://example.com/stub", func(a *models.Article) { a.Content = "" })
+	insertTestArticle(t, s, "https://example.com/short", func(a *models.Article) { a.Category = "" })
+	insertTestArticle(t, s, "https://example.com/translated", translated)
+	if stub.Status != models.StatusStub {
+		t.Fatalf("empty article inserted as %q, want stub", stub.Status)
+	}
+
+	got, err := s.GetArticlesToRescrape(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := articleIDs(got); len(ids) != 2 || ids[0]+ids[1] != failed.ID+stub.ID {
+		t.Errorf("got %v, want the new and the stub article", ids)
+	}
+
+	for range 2 {
+		if _, err := s.IncrementRescrapeAttempts(stub.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err = s.GetArticlesToRescrape(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := articleIDs(got); len(ids) != 1 || ids[0] != failed.ID {
+		t.Errorf("after two attempts on the stub got %v, want only %d", ids, failed.ID)
+	}
+}
+
+func TestBuildDSN(t *testing.T) {
+	tests := []struct {
+		cfg  config.DatabaseConfig
+		want string
+	}{
+		{config.DatabaseConfig{Path: "news.db"}, "news.db?_txlock=immediate"},
+		{config.DatabaseConfig{Path: "news.db", JournalMode: "WAL", BusyTimeoutMs: 5000}, "news.db?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"},
+		{config.DatabaseConfig{Path: "file:news.db?cache=shared", JournalMode: "WAL"}, "file:news.db?cache=shared&_journal_mode=WAL&_txlock=immediate"},
+	}
+	for _, tt := range tests {
+		if got := buildDSN(&tt.cfg); got != tt.want {
+			t.Errorf("buildDSN(%+v) = %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestOpenPathWithQuery(t *testing.T) {
+	path := "file:" + filepath.Join(t.TempDir(), "test.db") + "?cache=shared"
+	s, err := NewSQLiteStorage(&config.DatabaseConfig{Path: path, JournalMode: "WAL", BusyTimeoutMs: 1000})
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage(%s): %v", path, err)
+	}
+	defer s.Close()
+	var mode string
+	if err := s.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatal(err)
+	}
+	if mode != "wal" {
+		t.Errorf("journal_mode = %q, want wal", mode)
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	s, err := NewSQLiteStorage(&config.DatabaseConfig{
+		Path:          filepath.Join(t.TempDir(), "test.db"),
+		JournalMode:   "WAL",
+		BusyTimeoutMs: 5000,
+		MaxOpenConns:  4,
+	})
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage: %v", err)
+	}
+	defer s.Close()
+
+	const workers, perWorker = 8, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			var ids []int64
+			for i := 0; i < perWorker; i++ {
+				now := time.Now().UTC()
+				a := &models.Article{
+					SourceURL:   fmt.Sprintf("https://example.com/%d-%d", w, i),
+					SourceSite:  "example.com",
+					Title:       "Title",
+					Content:     "Content",
+					Slug:        fmt.Sprintf("slug-%d-%d", w, i),
+					PublishedAt: now,
+					FetchedAt:   now,
+				}
+				translated(a)
+				if err := s.InsertArticle(a); err != nil {
+					errs <- fmt.Errorf("InsertArticle: %w", err)
+					return
+				}
+				ids = append(ids, a.ID)
+				if err := s.MarkPublished(ids, "fp"); err != nil {
+					errs <- fmt.Errorf("MarkPublished: %w", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	total, _, published, err := s.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != workers*perWorker || published != workers*perWorker {
+		t.Errorf("total = %d, published = %d, want %d", total, published, workers*perWorker)
+	}
+}
+
+func TestReplaceArticle(t *testing.T) {
+	s := newTestStorage(t)
+	old := insertTestArticle(t, s, "https://example.com/old", nil)
+	taken := insertTestArticle(t, s, "https://example.com/taken", nil)
+
+	// a failed insert (URL already stored) keeps the old article
+	clash := &models.Article{SourceURL: taken.SourceURL, SourceSite: "example.com", Title: "Clash", Slug: "clash"}
+	if err := s.ReplaceArticle(old.ID, clash); err == nil {
+		t.Fatal("replaced with an article whose URL is taken")
+	}
+	if _, err := s.GetArticleByID(old.ID); err != nil {
+		t.Fatalf("old article after a failed replace: %v", err)
+	}
+
+	fresh := &models.Article{SourceURL: "https://example.com/new", SourceSite: "example.com", Title: "New", Slug: "new"}
+	if err := s.ReplaceArticle(old.ID, fresh); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetArticleByID(old.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("old article after the replace: err = %v, want it deleted", err)
+	}
+	if _, err := s.GetArticleByID(fresh.ID); err != nil {
+		t.Errorf("new article: %v", err)
+	}
+}
+
+func TestInsertArticleFallbackSlug(t *testing.T) {
+	s := newTestStorage(t)
+	a := insertTestArticle(t, s, "https://example.com/symbols", func(a *models.Article) { a.Slug = "" })
+	want := fmt.Sprintf("article-%d", a.ID)
+	if a.Slug != want {
+		t.Errorf("slug = %q, want %q", a.Slug, want)
+	}
+	if got, _ := s.GetArticleByID(a.ID); got.Slug != want {
+		t.Errorf("stored slug = %q, want %q", got.Slug, want)
+	}
+}
+
+func TestMarkPublishedAllOrNothing(t *testing.T) {
+	s := newTestStorage(t)
+	var ids []int64
+	for i := 0; i < markPublishedChunk+3; i++ {
+		a := insertTestArticle(t, s, fmt.Sprintf("https://example.com/%d", i), translated)
+		ids = append(ids, a.ID)
+	}
+	status := func(id int64) models.ArticleStatus {
+		t.Helper()
+		a, err := s.GetArticleByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a.Status
+	}
+
+	// A missing ID in the last chunk rolls back the chunks before it
+	if err := s.MarkPublished(append(ids[:len(ids):len(ids)], 999999), "fp"); err == nil {
+		t.Fatal("expected an error for a missing article")
+	}
+	for _, id := range []int64{ids[0], ids[len(ids)-1]} {
+		if got := status(id); got != models.StatusTranslated {
+			t.Errorf("article %d status = %q after a failed MarkPublished, want translated", id, got)
+		}
+	}
+
+	// Repeated IDs are fine; every article is marked with the fingerprint
+	if err := s.MarkPublished(append(ids, ids[0]), "fp"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int64{ids[0], ids[len(ids)-1]} {
+		a, _ := s.GetArticleByID(id)
+		if a.Status != models.StatusPublished || !a.PublishedToHugo || a.RenderFingerprint != "fp" {
+			t.Errorf("article %d: status=%q published=%v fingerprint=%q", id, a.Status, a.PublishedToHugo, a.RenderFingerprint)
+		}
+	}
+}
+
+func TestFeaturedArticlesFirst(t *testing.T) {
+	s := newTestStorage(t)
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	// 14:00 in UTC+5 is 09:00 UTC: the oldest, though its text sorts last
+	oldest := func(a *models.Article) {
+		a.PublishedAt = time.Date(2024, 6, 1, 14, 0, 0, 0, time.FixedZone("", 5*3600))
+	}
+	middle := func(a *models.Article) { a.PublishedAt = base }
+	newest := func(a *models.Article) { a.PublishedAt = base.Add(time.Hour) }
+
+	var scraped, ready []*models.Article
+	for i, at := range []func(*models.Article){newest, oldest, middle} {
+		scraped = append(scraped, insertTestArticle(t, s, fmt.Sprintf("https://example.com/s%d", i), func(a *models.Article) {
+			at(a)
+			a.Content, a.Status = "Text.", models.StatusScraped
+		}))
+		ready = append(ready, insertTestArticle(t, s, fmt.Sprintf("https://example.com/t%d", i), func(a *models.Article) {
+			at(a)
+			translated(a)
+		}))
+	}
+	// featured ahead of the newer article
+	for _, a := range []*models.Article{scraped[2], ready[2]} {
+		if err := s.SetFeatured(a.ID, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.GetUnpublishedArticles(10, false, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := articleIDs([]*models.Article{ready[2], ready[0], ready[1]}); !slices.Equal(articleIDs(got), want) {
+		t.Errorf("publish queue = %v, want %v", articleIDs(got), want)
+	}
+	for order, want := range map[string][]*models.Article{
+		"newest": {scraped[2], scraped[0], scraped[1]},
+		"oldest": {scraped[2], scraped[1], scraped[0]},
+	} {
+		got, err := s.GetUntranslatedArticles(10, order, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(articleIDs(got), articleIDs(want)) {
+			t.Errorf("translate queue (%s) = %v, want %v", order, articleIDs(got), articleIDs(want))
+		}
+	}
+}
+
+func TestGetUntranslatedArticlesOrder(t *testing.T) {
+	s := newTestStorage(t)
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	var byAge []*models.Article // oldest first
+	for i, site := range []string{"a.com", "b.com", "a.com", "b.com"} {
+		byAge = append(byAge, insertTestArticle(t, s, fmt.Sprintf("https://%s/%d", site, i), func(a *models.Article) {
+			a.SourceSite, a.Status = site, models.StatusScraped
+			a.PublishedAt = base.Add(time.Duration(i) * time.Hour)
+		}))
+	}
+	// translated articles never come back
+	insertTestArticle(t, s, "https://a.com/done", translated)
+
+	tests := []struct {
+		order    string
+		priority []string
+		want     []*models.Article
+	}{
+		{"newest", nil, []*models.Article{byAge[3], byAge[2]}},
+		{"", nil, []*models.Article{byAge[3], byAge[2]}},
+		{"oldest", nil, []*models.Article{byAge[0], byAge[1]}},
+		{"oldest", []string{"b.com"}, []*models.Article{byAge[1], byAge[3]}},
+		{"newest", []string{"a.com"}, []*models.Article{byAge[2], byAge[0]}},
+	}
+	for _, tt := range tests {
+		got, err := s.GetUntranslatedArticles(2, tt.order, tt.priority)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(articleIDs(got), articleIDs(tt.want)) {
+			t.Errorf("order %q, priority %v = %v, want %v", tt.order, tt.priority, articleIDs(got), articleIDs(tt.want))
+		}
+	}
+
+	// random draws from the same set: all four untranslated articles
+	got, err := s.GetUntranslatedArticles(10, "random", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := articleIDs(got)
+	slices.Sort(ids)
+	if !slices.Equal(ids, articleIDs(byAge)) {
+		t.Errorf("random = %v, want the ids %v in any order", articleIDs(got), articleIDs(byAge))
+	}
+}
+
+func TestImageURLsRoundTrip(t *testing.T) {
+	s := newTestStorage(t)
+	images := []string{"https://cdn.example.com/cover.jpg", "https://cdn.example.com/a.jpg?w=1&h=2", "https://cdn.example.com/b.jpg"}
+	a := insertTestArticle(t, s, "https://example.com/gallery", func(a *models.Article) {
+		a.ImageURL, a.ImageURLs = images[0], images
+	})
+	none := insertTestArticle(t, s, "https://example.com/plain", nil)
+
+	got, err := s.GetArticleByID(a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.ImageURLs, images) || got.ImageURL != images[0] {
+		t.Errorf("images = %q (cover %q), want %q", got.ImageURLs, got.ImageURL, images)
+	}
+	if got, err = s.GetArticleByID(none.ID); err != nil || len(got.ImageURLs) != 0 {
+		t.Errorf("no images: %q (%v)", got.ImageURLs, err)
+	}
+}
+
+func TestGetArticleNotFoundVsError(t *testing.T) {
+	s := newTestStorage(t)
+	a := insertTestArticle(t, s, "https://example.com/a", nil)
+
+	if got, err := s.GetArticleByID(a.ID); err != nil || got.SourceURL != a.SourceURL {
+		t.Fatalf("GetArticleByID(%d) = %+v (%v)", a.ID, got, err)
+	}
+	if _, err := s.GetArticleByID(a.ID + 1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("missing id: err = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.GetArticleByURL("https://example.com/missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("missing url: err = %v, want sql.ErrNoRows", err)
+	}
+
+	// a broken database is an error, not a missing article
+	if _, err := s.db.Exec("DROP TABLE articles"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetArticleByID(a.ID); err == nil || errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("broken database by id: err = %v, want a database error", err)
+	}
+	if _, err := s.GetArticleByURL(a.SourceURL); err == nil || errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("broken database by url: err = %v, want a database error", err)
+	}
+}
+
+func TestGetArticlesByTranslator(t *testing.T) {
+	s := newTestStorage(t)
+	var deepl []*models.Article
+	for i := 0; i < 3; i++ {
+		deepl = append(deepl, insertTestArticle(t, s, fmt.Sprintf("https://example.com/deepl-%d", i), func(a *models.Article) {
+			translated(a)
+			at := time.Now().UTC().Add(time.Duration(i) * time.Minute)
+			a.TranslatedAt = &at
+			a.Translator = "deepl"
+		}))
+	}
+	insertTestArticle(t, s, "https://example.com/ollama", func(a *models.Article) {
+		translated(a)
+		a.Translator = "ollama"
+	})
+	if err := s.MarkPublished([]int64{deepl[0].ID}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		status string
+		limit  int
+		want   []int64
+	}{
+		{"", 10, []int64{deepl[2].ID, deepl[1].ID, deepl[0].ID}},
+		{"", 2, []int64{deepl[2].ID, deepl[1].ID}},
+		{"published", 10, []int64{deepl[0].ID}},
+		{"unpublished", 1, []int64{deepl[2].ID}},
+	}
+	for _, tt := range tests {
+		got, err := s.GetArticlesByTranslator("deepl", tt.status, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := articleIDs(got); !slices.Equal(ids, tt.want) {
+			t.Errorf("status %q, limit %d: ids = %v, want %v", tt.status, tt.limit, ids, tt.want)
+		}
+	}
+	if _, err := s.GetArticlesByTranslator("deepl", "bogus", 10); err == nil {
+		t.Error("unknown status: want an error")
+	}
+}
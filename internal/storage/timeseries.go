@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeseriesColumns maps GetDailyCounts metrics to the timestamp that dates
+// an article
+var timeseriesColumns = map[string]string{
+	"fetched":    "fetched_at",
+	"translated": "translated_at",
+	"published":  "published_at", // publication date at the source
+}
+
+// TimeseriesMetrics lists the metrics accepted by GetDailyCounts
+func TimeseriesMetrics() []string {
+	return []string{"fetched", "translated", "published"}
+}
+
+// DayCount is the number of articles dated one day (UTC)
+type DayCount struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Count int    `json:"count"`
+}
+
+// GetDailyCounts counts articles per UTC day of the metric's timestamp over
+// the last days days up to now, oldest first. Days without articles are
+// included with Count 0 so charts get a continuous axis.
+func (s *SQLiteStorage) GetDailyCounts(metric string, days int, now time.Time) ([]DayCount, error) {
+	column, ok := timeseriesColumns[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q (expected one of: %s)", metric, strings.Join(TimeseriesMetrics(), ", "))
+	}
+	if days < 1 {
+		days = 1
+	}
+	now = now.UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(days - 1))
+
+	// The range check on the raw column uses its index; date() normalizes
+	// stored offsets to UTC for the buckets
+	rows, err := s.db.Query(`
+	SELECT date(`+column+`) AS day, COUNT(*)
+	FROM articles
+	WHERE `+column+` >= ?
+	GROUP BY day
+	`, from.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count %s articles: %w", metric, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var day *string
+		var n int
+		if err := rows.Scan(&day, &n); err != nil {
+			return nil, err
+		}
+		if day != nil {
+			counts[*day] = n
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	series := make([]DayCount, 0, days)
+	for d := from; !d.After(now); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		series = append(series, DayCount{Date: date, Count: counts[date]})
+	}
+	return series, nil
+}
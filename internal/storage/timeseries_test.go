@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"moto-news/internal/models"
+)
+
+func TestGetDailyCounts(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Date(2024, 6, 10, 15, 0, 0, 0, time.UTC)
+	fetched := []time.Time{
+		now.Add(-time.Hour),
+		now.Add(-14 * time.Hour), // 01:00 the same day
+		now.AddDate(0, 0, -2),
+		// 02:00 on June 9 at UTC+5 is 21:00 on June 8 in UTC
+		time.Date(2024, 6, 9, 2, 0, 0, 0, time.FixedZone("", 5*3600)),
+		now.AddDate(0, 0, -10), // before the range
+	}
+	for i, at := range fetched {
+		insertTestArticle(t, s, fmt.Sprintf("https://example.com/%d", i), func(a *models.Article) {
+			a.FetchedAt = at
+			if i == 0 {
+				translated(a)
+				a.TranslatedAt = &at
+			}
+		})
+	}
+
+	got, err := s.GetDailyCounts("fetched", 4, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DayCount{{"2024-06-07", 0}, {"2024-06-08", 2}, {"2024-06-09", 0}, {"2024-06-10", 2}}
+	if !slices.Equal(got, want) {
+		t.Errorf("fetched = %v, want %v", got, want)
+	}
+
+	// articles never translated have no date for the metric
+	got, err = s.GetDailyCounts("translated", 2, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []DayCount{{"2024-06-09", 0}, {"2024-06-10", 1}}; !slices.Equal(got, want) {
+		t.Errorf("translated = %v, want %v", got, want)
+	}
+
+	if _, err := s.GetDailyCounts("liked", 7, now); err == nil {
+		t.Error("unknown metric accepted")
+	}
+}
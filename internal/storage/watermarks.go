@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SourceWatermark is the per-source fetch watermark: when a source was last
+// fetched and how many new articles it has been producing
+type SourceWatermark struct {
+	Source        string     `json:"source"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	LastNew       int        `json:"last_new"`   // new articles saved by the last fetch
+	TotalNew      int64      `json:"total_new"`  // new articles since tracking began
+	RecentNew     int        `json:"recent_new"` // articles fetched since the window start (see GetSourceWatermarks)
+
+	ConsecutiveFailures int        `json:"consecutive_failures"` // fetches in a row in which every feed failed
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	AutoDisabledAt      *time.Time `json:"auto_disabled_at,omitempty"` // set by DisableSource, cleared by EnableSource
+}
+
+// RecordSourceFetch moves a source's watermark to at, adds newCount to its
+// totals and resets its consecutive failures
+func (s *SQLiteStorage) RecordSourceFetch(source string, newCount int, at time.Time) error {
+	_, err := s.db.Exec(`
+	INSERT INTO source_watermarks (source, last_fetched_at, last_new, total_new) VALUES (?, ?, ?, ?)
+	ON CONFLICT(source) DO UPDATE SET
+		last_fetched_at = excluded.last_fetched_at,
+		last_new = excluded.last_new,
+		total_new = total_new + excluded.last_new,
+		consecutive_failures = 0
+	`, source, at, newCount, newCount)
+	if err != nil {
+		return fmt.Errorf("failed to update watermark for %s: %w", source, err)
+	}
+	return nil
+}
+
+// RecordSourceFailure records a fetch in which every feed of the source
+// failed and returns the number of such fetches in a row
+func (s *SQLiteStorage) RecordSourceFailure(source, errMsg string, at time.Time) (int, error) {
+	var failures int
+	err := s.db.QueryRow(`
+	INSERT INTO source_watermarks (source, consecutive_failures, last_error, last_error_at) VALUES (?, 1, ?, ?)
+	ON CONFLICT(source) DO UPDATE SET
+		consecutive_failures = consecutive_failures + 1,
+		last_error = excluded.last_error,
+		last_error_at = excluded.last_error_at
+	RETURNING consecutive_failures
+	`, source, errMsg, at).Scan(&failures)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record failure of %s: %w", source, err)
+	}
+	return failures, nil
+}
+
+// DisableSource marks a source disabled until EnableSource is called
+func (s *SQLiteStorage) DisableSource(source string, at time.Time) error {
+	_, err := s.db.Exec(`
+	INSERT INTO source_watermarks (source, auto_disabled_at) VALUES (?, ?)
+	ON CONFLICT(source) DO UPDATE SET auto_disabled_at = excluded.auto_disabled_at
+	`, source, at)
+	if err != nil {
+		return fmt.Errorf("failed to disable %s: %w", source, err)
+	}
+	return nil
+}
+
+// EnableSource clears a source's disabled mark and its failure count;
+// reports whether the source was disabled
+func (s *SQLiteStorage) EnableSource(source string) (bool, error) {
+	res, err := s.db.Exec(`
+	UPDATE source_watermarks SET auto_disabled_at = NULL, consecutive_failures = 0
+	WHERE source = ? AND auto_disabled_at IS NOT NULL
+	`, source)
+	if err != nil {
+		return false, fmt.Errorf("failed to enable %s: %w", source, err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetDisabledSources returns the names of the sources disabled by
+// DisableSource
+func (s *SQLiteStorage) GetDisabledSources() (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT source FROM source_watermarks WHERE auto_disabled_at IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	disabled := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		disabled[name] = true
+	}
+	return disabled, rows.Err()
+}
+
+// GetSourceWatermarks returns every tracked source, by name. RecentNew counts
+// the source's articles fetched at or after since.
+func (s *SQLiteStorage) GetSourceWatermarks(since time.Time) ([]SourceWatermark, error) {
+	rows, err := s.db.Query(`
+	SELECT w.source, w.last_fetched_at, w.last_new, w.total_new,
+		(SELECT COUNT(*) FROM articles a WHERE a.source_site = w.source AND a.fetched_at >= ?),
+		w.consecutive_failures, w.last_error, w.last_error_at, w.auto_disabled_at
+	FROM source_watermarks w
+	ORDER BY w.source
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []SourceWatermark
+	for rows.Next() {
+		var w SourceWatermark
+		var last, lastError, disabled sql.NullTime
+		if err := rows.Scan(&w.Source, &last, &w.LastNew, &w.TotalNew, &w.RecentNew,
+			&w.ConsecutiveFailures, &w.LastError, &lastError, &disabled); err != nil {
+			return nil, err
+		}
+		if last.Valid {
+			w.LastFetchedAt = &last.Time
+		}
+		if lastError.Valid {
+			w.LastErrorAt = &lastError.Time
+		}
+		if disabled.Valid {
+			w.AutoDisabledAt = &disabled.Time
+		}
+		result = append(result, w)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,440 @@
+// Package tracing records pipeline spans and exports them to an
+// OpenTelemetry collector over OTLP/HTTP (JSON encoding). It is a no-op
+// until Init is called with an endpoint, so instrumented code costs nothing
+// when tracing is not configured.
+//
+// Besides the endpoint, the standard OTEL_SDK_DISABLED, OTEL_SERVICE_NAME,
+// OTEL_TRACES_SAMPLER / OTEL_TRACES_SAMPLER_ARG and
+// OTEL_EXPORTER_OTLP_HEADERS / OTEL_EXPORTER_OTLP_TRACES_HEADERS variables
+// are honored.
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"moto-news/internal/config"
+)
+
+const (
+	batchSize     = 100
+	flushInterval = 5 * time.Second
+)
+
+var (
+	mu       sync.Mutex
+	exporter *otlpExporter
+)
+
+type spanKey struct{}
+
+// Span is a single timed operation. A nil *Span is valid and ignores all calls.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	sampled  bool // unsampled spans only carry the trace to their children
+	name     string
+	start    time.Time
+	end      time.Time
+	attrs    map[string]any
+	errMsg   string
+}
+
+// Init enables export when an endpoint is configured (tracing.otlp_endpoint,
+// or the standard OTEL_EXPORTER_OTLP_TRACES_ENDPOINT / OTEL_EXPORTER_OTLP_ENDPOINT
+// env vars) and OTEL_SDK_DISABLED is not true. Export problems are reported
+// through warn (may be nil). Returns a shutdown func that flushes pending
+// spans.
+func Init(cfg *config.TracingConfig, warn func(format string, args ...any)) func() {
+	endpoint := tracesEndpoint(cfg.OTLPEndpoint)
+	if endpoint == "" || strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return func() {}
+	}
+	if warn == nil {
+		warn = func(string, ...any) {}
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = cfg.ServiceName
+	}
+	if serviceName == "" {
+		serviceName = "moto-news"
+	}
+	sample, err := samplerFromEnv()
+	if err != nil {
+		warn("Warning: tracing: %v, sampling every trace\n", err)
+	}
+
+	e := &otlpExporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		headers:     headersFromEnv(),
+		sample:      sample,
+		warn:        warn,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		spans:       make(chan *Span, batchSize*10),
+		done:        make(chan struct{}),
+	}
+	go e.loop()
+
+	mu.Lock()
+	exporter = e
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		exporter = nil
+		mu.Unlock()
+		close(e.spans)
+		<-e.done
+	}
+}
+
+// tracesEndpoint resolves the full /v1/traces URL
+func tracesEndpoint(configured string) string {
+	if configured != "" {
+		return withTracesPath(configured)
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); v != "" {
+		return v
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); v != "" {
+		return withTracesPath(v)
+	}
+	return ""
+}
+
+func withTracesPath(base string) string {
+	base = strings.TrimSuffix(base, "/")
+	if strings.HasSuffix(base, "/v1/traces") {
+		return base
+	}
+	return base + "/v1/traces"
+}
+
+// sampler decides whether a new root span (and so its trace) is exported.
+// Child spans follow their parent unless the sampler ignores parents.
+type sampler struct {
+	ratio       float64 // share of root traces kept
+	parentBased bool
+}
+
+// samplerFromEnv reads OTEL_TRACES_SAMPLER / OTEL_TRACES_SAMPLER_ARG; the
+// default is parentbased_always_on. Unknown values sample everything.
+func samplerFromEnv() (sampler, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_SAMPLER")))
+	ratio := 1.0
+	if strings.HasSuffix(name, "traceidratio") {
+		if arg := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); arg != "" {
+			r, err := strconv.ParseFloat(arg, 64)
+			if err != nil || r < 0 || r > 1 {
+				return sampler{ratio: 1, parentBased: true}, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG %q is not a ratio between 0 and 1", arg)
+			}
+			ratio = r
+		}
+	}
+	switch name {
+	case "", "parentbased_always_on":
+		return sampler{ratio: 1, parentBased: true}, nil
+	case "always_on":
+		return sampler{ratio: 1}, nil
+	case "always_off":
+		return sampler{ratio: 0}, nil
+	case "parentbased_always_off":
+		return sampler{ratio: 0, parentBased: true}, nil
+	case "traceidratio":
+		return sampler{ratio: ratio}, nil
+	case "parentbased_traceidratio":
+		return sampler{ratio: ratio, parentBased: true}, nil
+	}
+	return sampler{ratio: 1, parentBased: true}, fmt.Errorf("unsupported OTEL_TRACES_SAMPLER %q", name)
+}
+
+// sampled reports whether a span of traceID under parent (may be nil) is
+// exported. The ratio test uses the low 8 bytes of the trace ID, so every
+// span of a trace gets the same answer.
+func (s sampler) sampled(traceID [16]byte, parent *Span) bool {
+	if parent != nil && s.parentBased {
+		return parent.sampled
+	}
+	if s.ratio >= 1 {
+		return true
+	}
+	return float64(binary.BigEndian.Uint64(traceID[8:])>>1) < s.ratio*math.MaxInt64
+}
+
+// headersFromEnv parses OTEL_EXPORTER_OTLP_HEADERS and the traces-specific
+// variable (which wins per key): comma-separated key=value pairs with
+// URL-encoded values
+func headersFromEnv() map[string]string {
+	headers := make(map[string]string)
+	for _, env := range []string{"OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_TRACES_HEADERS"} {
+		for _, pair := range strings.Split(os.Getenv(env), ",") {
+			key, value, ok := strings.Cut(pair, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				continue
+			}
+			if decoded, err := url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+				value = decoded
+			}
+			headers[key] = value
+		}
+	}
+	return headers
+}
+
+// Start begins a span as a child of the span in ctx (if any). It returns a
+// nil span when tracing is off or no random ID could be drawn.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	mu.Lock()
+	e := exporter
+	mu.Unlock()
+	if e == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		name:  name,
+		start: time.Now(),
+		attrs: make(map[string]any),
+	}
+	parent, _ := ctx.Value(spanKey{}).(*Span)
+	if parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else if _, err := rand.Read(span.traceID[:]); err != nil {
+		return ctx, nil
+	}
+	if _, err := rand.Read(span.spanID[:]); err != nil {
+		return ctx, nil
+	}
+	span.sampled = e.sample.sampled(span.traceID, parent)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttr sets a span attribute (string, bool, int, int64 or float64)
+func (s *Span) SetAttr(key string, value any) {
+	if s == nil || !s.sampled {
+		return
+	}
+	s.attrs[key] = value
+}
+
+// RecordError marks the span as failed. nil errors are ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.errMsg = err.Error()
+}
+
+// End finishes the span and queues it for export
+func (s *Span) End() {
+	if s == nil || !s.sampled {
+		return
+	}
+	s.end = time.Now()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if exporter == nil {
+		return
+	}
+	select {
+	case exporter.spans <- s:
+	default:
+		// Queue full — drop rather than block the pipeline
+	}
+}
+
+// --- OTLP/HTTP JSON exporter ---
+
+type otlpExporter struct {
+	endpoint    string
+	serviceName string
+	headers     map[string]string
+	sample      sampler
+	warn        func(format string, args ...any)
+	client      *http.Client
+	spans       chan *Span
+	done        chan struct{}
+}
+
+// OTLP JSON encoding of an ExportTraceServiceRequest (the parts we send);
+// 64-bit integers are strings, IDs are hex
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes"`
+		Status            *otlpStatus    `json:"status,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+const (
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+func (e *otlpExporter) loop() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	for {
+		select {
+		case span, ok := <-e.spans:
+			if !ok {
+				e.export(batch)
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) >= batchSize {
+				e.export(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			e.export(batch)
+			batch = nil
+		}
+	}
+}
+
+func (e *otlpExporter) export(batch []*Span) {
+	if len(batch) == 0 {
+		return
+	}
+
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, s := range batch {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.attrs),
+		}
+		if s.parentID != ([8]byte{}) {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		if s.errMsg != "" {
+			span.Status = &otlpStatus{Code: statusCodeError, Message: s.errMsg}
+		}
+		spans = append(spans, span)
+	}
+
+	payload := otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes(map[string]any{"service.name": e.serviceName})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "moto-news"}, Spans: spans}},
+	}}}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		e.warn("Warning: tracing: failed to encode spans: %v\n", err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		e.warn("Warning: tracing: export failed: %v\n", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		e.warn("Warning: tracing: export failed: %v\n", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		e.warn("Warning: tracing: collector returned status %d\n", resp.StatusCode)
+	}
+}
+
+// otlpAttributes converts attributes to OTLP KeyValues, sorted by key
+func otlpAttributes(attrs map[string]any) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for k, v := range attrs {
+		var value otlpAnyValue
+		switch val := v.(type) {
+		case string:
+			value.StringValue = &val
+		case bool:
+			value.BoolValue = &val
+		case int:
+			s := strconv.Itoa(val)
+			value.IntValue = &s
+		case int64:
+			s := strconv.FormatInt(val, 10)
+			value.IntValue = &s
+		case float64:
+			value.DoubleValue = &val
+		default:
+			s := fmt.Sprint(val)
+			value.StringValue = &s
+		}
+		out = append(out, otlpKeyValue{Key: k, Value: value})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
@@ -0,0 +1,213 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"moto-news/internal/config"
+)
+
+// collector is a fake OTLP/HTTP endpoint that keeps the posted requests
+type collector struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []otlpRequest
+	headers  []http.Header
+	status   int // response status, 200 when unset
+}
+
+func newCollector(t *testing.T) *collector {
+	t.Helper()
+	c := &collector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.requests = append(c.requests, req)
+		c.headers = append(c.headers, r.Header.Clone())
+		if c.status != 0 {
+			w.WriteHeader(c.status)
+		}
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+// spans returns the exported spans by name
+func (c *collector) spans() map[string]otlpSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]otlpSpan)
+	for _, req := range c.requests {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					out[s.Name] = s
+				}
+			}
+		}
+	}
+	return out
+}
+
+func attr(s otlpSpan, key string) string {
+	for _, kv := range s.Attributes {
+		if kv.Key != key {
+			continue
+		}
+		switch v := kv.Value; {
+		case v.StringValue != nil:
+			return *v.StringValue
+		case v.IntValue != nil:
+			return *v.IntValue
+		case v.BoolValue != nil:
+			return fmt.Sprint(*v.BoolValue)
+		case v.DoubleValue != nil:
+			return fmt.Sprint(*v.DoubleValue)
+		}
+	}
+	return ""
+}
+
+// clearOTELEnv keeps the runner's OTEL variables out of a test
+func clearOTELEnv(t *testing.T) {
+	for _, env := range []string{"OTEL_SDK_DISABLED", "OTEL_SERVICE_NAME", "OTEL_TRACES_SAMPLER", "OTEL_TRACES_SAMPLER_ARG",
+		"OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_TRACES_HEADERS", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"} {
+		t.Setenv(env, "")
+	}
+}
+
+func TestExportSpans(t *testing.T) {
+	clearOTELEnv(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer%20secret")
+	c := newCollector(t)
+	shutdown := Init(&config.TracingConfig{OTLPEndpoint: c.URL, ServiceName: "news-test"}, nil)
+
+	ctx, root := Start(context.Background(), "fetch")
+	root.SetAttr("source", "RideApart")
+	root.SetAttr("articles.new", 3)
+	_, child := Start(ctx, "scrape")
+	child.SetAttr("content.bytes", int64(1024))
+	child.RecordError(errors.New("page not found"))
+	child.End()
+	root.End()
+	shutdown()
+
+	spans := c.spans()
+	fetch, scrape := spans["fetch"], spans["scrape"]
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want fetch and scrape", len(spans))
+	}
+	if len(fetch.TraceID) != 32 || len(fetch.SpanID) != 16 || fetch.ParentSpanID != "" {
+		t.Errorf("root IDs: trace %q span %q parent %q", fetch.TraceID, fetch.SpanID, fetch.ParentSpanID)
+	}
+	if scrape.TraceID != fetch.TraceID || scrape.ParentSpanID != fetch.SpanID || scrape.SpanID == fetch.SpanID {
+		t.Errorf("child: trace %q parent %q span %q, want trace %q under span %q", scrape.TraceID, scrape.ParentSpanID, scrape.SpanID, fetch.TraceID, fetch.SpanID)
+	}
+	if attr(fetch, "source") != "RideApart" || attr(fetch, "articles.new") != "3" || attr(scrape, "content.bytes") != "1024" {
+		t.Errorf("attributes: fetch %+v, scrape %+v", fetch.Attributes, scrape.Attributes)
+	}
+	if fetch.Status != nil {
+		t.Errorf("root status = %+v, want unset", fetch.Status)
+	}
+	if scrape.Status == nil || scrape.Status.Code != statusCodeError || scrape.Status.Message != "page not found" {
+		t.Errorf("child status = %+v, want an error with the message", scrape.Status)
+	}
+
+	req := c.requests[0].ResourceSpans[0]
+	if len(req.Resource.Attributes) != 1 || *req.Resource.Attributes[0].Value.StringValue != "news-test" {
+		t.Errorf("resource = %+v, want service.name news-test", req.Resource.Attributes)
+	}
+	if got := c.headers[0].Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want the header from OTEL_EXPORTER_OTLP_HEADERS", got)
+	}
+
+	// after shutdown spans are not recorded
+	if _, span := Start(context.Background(), "late"); span != nil {
+		t.Error("Start returned a span after shutdown")
+	}
+}
+
+func TestSamplerFromEnv(t *testing.T) {
+	clearOTELEnv(t)
+	c := newCollector(t)
+	t.Setenv("OTEL_TRACES_SAMPLER", "always_off")
+	shutdown := Init(&config.TracingConfig{OTLPEndpoint: c.URL}, nil)
+	ctx, root := Start(context.Background(), "fetch")
+	_, child := Start(ctx, "scrape")
+	child.End()
+	root.End()
+	shutdown()
+	if n := len(c.spans()); n != 0 {
+		t.Errorf("always_off exported %d spans", n)
+	}
+
+	var traceID [16]byte
+	half := sampler{ratio: 0.5, parentBased: true}
+	traceID[8] = 0x10 // low half of the ID space
+	if !half.sampled(traceID, nil) {
+		t.Error("ratio 0.5 dropped a trace ID in the low half")
+	}
+	traceID[8] = 0xf0
+	if half.sampled(traceID, nil) {
+		t.Error("ratio 0.5 kept a trace ID in the high half")
+	}
+	if !half.sampled(traceID, &Span{sampled: true}) {
+		t.Error("parent-based sampler dropped the child of a sampled span")
+	}
+
+	t.Setenv("OTEL_TRACES_SAMPLER", "parentbased_traceidratio")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "2")
+	if s, err := samplerFromEnv(); err == nil || s.ratio != 1 {
+		t.Errorf("ratio 2: sampler %+v, err %v; want an error and full sampling", s, err)
+	}
+}
+
+func TestExportFailureWarns(t *testing.T) {
+	clearOTELEnv(t)
+	c := newCollector(t)
+	c.status = http.StatusServiceUnavailable
+	var mu sync.Mutex
+	var warnings []string
+	shutdown := Init(&config.TracingConfig{OTLPEndpoint: c.URL}, func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	})
+	_, span := Start(context.Background(), "publish")
+	span.End()
+	shutdown()
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "status 503") {
+		t.Errorf("warnings = %q, want the collector status", warnings)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	clearOTELEnv(t)
+	if _, span := Start(context.Background(), "fetch"); span != nil {
+		t.Error("Start returned a span without Init")
+	}
+	c := newCollector(t)
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	shutdown := Init(&config.TracingConfig{OTLPEndpoint: c.URL}, nil)
+	defer shutdown()
+	if _, span := Start(context.Background(), "fetch"); span != nil {
+		t.Error("OTEL_SDK_DISABLED=true still records spans")
+	}
+}
@@ -3,12 +3,15 @@ package translator
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,12 +22,49 @@ type DeepLTranslator struct {
 	apiKey string
 	host   string
 	client *http.Client
+
+	formality string // deepl.formality: "default", "more", "less", "prefer_more" or "prefer_less"
+
+	glossary      Glossary
+	glossaryMu    sync.Mutex
+	glossaryID    string // created/looked up on first use
+	fixedGlossary string // deepl.glossary_id: a glossary managed in DeepL, used instead of translator.glossary
+}
+
+// DeepL language codes of this translator
+const (
+	deeplSourceLang = "EN"
+	deeplTargetLang = "RU"
+)
+
+// deeplFormalityTargets are the target languages DeepL accepts formality
+// for; other targets reject the request unless a prefer_* value is used
+var deeplFormalityTargets = map[string]bool{
+	"DE": true, "FR": true, "IT": true, "ES": true, "NL": true, "PL": true,
+	"PT-BR": true, "PT-PT": true, "JA": true, "RU": true,
 }
 
 type deeplRequest struct {
 	Text       []string `json:"text"`
 	TargetLang string   `json:"target_lang"`
 	SourceLang string   `json:"source_lang,omitempty"`
+	GlossaryID string   `json:"glossary_id,omitempty"`
+	Formality  string   `json:"formality,omitempty"`
+}
+
+type deeplGlossary struct {
+	GlossaryID string `json:"glossary_id"`
+	Name       string `json:"name"`
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+}
+
+type deeplGlossaryRequest struct {
+	Name          string `json:"name"`
+	SourceLang    string `json:"source_lang"`
+	TargetLang    string `json:"target_lang"`
+	Entries       string `json:"entries"`
+	EntriesFormat string `json:"entries_format"`
 }
 
 type deeplResponse struct {
@@ -39,7 +79,10 @@ type deeplTranslation struct {
 // NewDeepLTranslator creates a DeepL translator.
 // apiKey can be empty — will fall back to DEEPL_API_KEY env var.
 // free=true uses the free API endpoint (api-free.deepl.com).
-func NewDeepLTranslator(apiKey string, free bool) *DeepLTranslator {
+// formality is a deepl.formality value ("" = default); glossaryID, when
+// set, names an existing DeepL glossary to translate with.
+// transport may be nil (default transport).
+func NewDeepLTranslator(apiKey string, free bool, formality, glossaryID string, transport http.RoundTripper) *DeepLTranslator {
 	if apiKey == "" {
 		apiKey = os.Getenv("DEEPL_API_KEY")
 	}
@@ -50,10 +93,13 @@ func NewDeepLTranslator(apiKey string, free bool) *DeepLTranslator {
 	}
 
 	return &DeepLTranslator{
-		apiKey: apiKey,
-		host:   host,
+		apiKey:        apiKey,
+		host:          host,
+		formality:     formality,
+		fixedGlossary: glossaryID,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: transport,
+			Timeout:   30 * time.Second,
 		},
 	}
 }
@@ -67,6 +113,16 @@ func (t *DeepLTranslator) IsAvailable() bool {
 	return t.apiKey != ""
 }
 
+// SetGlossary makes translations use a DeepL glossary with these terms. The
+// glossary is created on first use and reused across runs: its name carries
+// a hash of the entries, so changing the terms creates a new one.
+func (t *DeepLTranslator) SetGlossary(g Glossary) {
+	t.glossaryMu.Lock()
+	defer t.glossaryMu.Unlock()
+	t.glossary = g.sorted()
+	t.glossaryID = ""
+}
+
 // Translate translates article content EN -> RU
 func (t *DeepLTranslator) Translate(ctx context.Context, text string) (string, error) {
 	return t.translate(ctx, text)
@@ -82,13 +138,12 @@ func (t *DeepLTranslator) translate(ctx context.Context, text string) (string, e
 		return "", fmt.Errorf("DeepL API key not configured (set DEEPL_API_KEY env var or deepl.api_key in config)")
 	}
 
-	reqBody := deeplRequest{
-		Text:       []string{text},
-		TargetLang: "RU",
-		SourceLang: "EN",
+	glossaryID, err := t.ensureGlossary(ctx)
+	if err != nil {
+		return "", err
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
+	jsonBody, err := json.Marshal(t.request(text, glossaryID))
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
@@ -130,30 +185,152 @@ func (t *DeepLTranslator) translate(ctx context.Context, text string) (string, e
 	return strings.TrimSpace(result.Translations[0].Text), nil
 }
 
+// request builds the /v2/translate body for one text
+func (t *DeepLTranslator) request(text, glossaryID string) deeplRequest {
+	return deeplRequest{
+		Text:       []string{text},
+		TargetLang: deeplTargetLang,
+		SourceLang: deeplSourceLang,
+		GlossaryID: glossaryID,
+		Formality:  deeplFormality(t.formality, deeplTargetLang),
+	}
+}
+
+// deeplFormality is the formality value to send for target: omitted for
+// "default" and for targets without formality support, where DeepL
+// rejects more/less (prefer_* falls back silently, so it is kept)
+func deeplFormality(formality, target string) string {
+	switch {
+	case formality == "" || formality == "default":
+		return ""
+	case strings.HasPrefix(formality, "prefer_"), deeplFormalityTargets[strings.ToUpper(target)]:
+		return formality
+	default:
+		return ""
+	}
+}
+
+// ensureGlossary returns the id of the DeepL glossary for t.glossary, looking
+// up an existing one by name or creating it; "" when there is no glossary.
+// A configured deepl.glossary_id is used as is.
+func (t *DeepLTranslator) ensureGlossary(ctx context.Context) (string, error) {
+	if t.fixedGlossary != "" {
+		return t.fixedGlossary, nil
+	}
+	t.glossaryMu.Lock()
+	defer t.glossaryMu.Unlock()
+	if len(t.glossary) == 0 || t.glossaryID != "" {
+		return t.glossaryID, nil
+	}
+
+	var entries strings.Builder
+	for _, e := range t.glossary {
+		fmt.Fprintf(&entries, "%s\t%s\n", e.Source, e.target())
+	}
+	sum := sha256.Sum256([]byte(entries.String()))
+	name := "moto-news-" + hex.EncodeToString(sum[:6])
+
+	var existing struct {
+		Glossaries []deeplGlossary `json:"glossaries"`
+	}
+	if err := t.doJSON(ctx, "GET", "/v2/glossaries", nil, &existing); err != nil {
+		return "", fmt.Errorf("DeepL: list glossaries: %w", err)
+	}
+	for _, g := range existing.Glossaries {
+		if g.Name == name && strings.EqualFold(g.SourceLang, "en") && strings.EqualFold(g.TargetLang, "ru") {
+			t.glossaryID = g.GlossaryID
+			return t.glossaryID, nil
+		}
+	}
+
+	var created deeplGlossary
+	err := t.doJSON(ctx, "POST", "/v2/glossaries", deeplGlossaryRequest{
+		Name:          name,
+		SourceLang:    "en",
+		TargetLang:    "ru",
+		Entries:       entries.String(),
+		EntriesFormat: "tsv",
+	}, &created)
+	if err != nil {
+		return "", fmt.Errorf("DeepL: create glossary: %w", err)
+	}
+	t.glossaryID = created.GlossaryID
+	return t.glossaryID, nil
+}
+
+// doJSON sends an authenticated request with an optional JSON body and
+// decodes a 2xx JSON response into out
+func (t *DeepLTranslator) doJSON(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.host+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+t.apiKey)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
 // CheckConnection verifies the DeepL API is reachable and the key is valid
 func (t *DeepLTranslator) CheckConnection(ctx context.Context) error {
+	_, err := t.Usage(ctx)
+	return err
+}
+
+// Usage returns the characters translated this billing period and the
+// account's limit (GET /v2/usage)
+func (t *DeepLTranslator) Usage(ctx context.Context) (*Usage, error) {
 	if !t.IsAvailable() {
-		return fmt.Errorf("DeepL API key not configured")
+		return nil, fmt.Errorf("DeepL API key not configured")
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", t.host+"/v2/usage", nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req.Header.Set("Authorization", "DeepL-Auth-Key "+t.apiKey)
 
 	resp, err := t.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("cannot connect to DeepL API: %w", err)
+		return nil, fmt.Errorf("cannot connect to DeepL API: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("DeepL returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("DeepL returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var usage struct {
+		CharacterCount int64 `json:"character_count"`
+		CharacterLimit int64 `json:"character_limit"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&usage); err != nil {
+		return nil, fmt.Errorf("failed to parse DeepL usage: %w", err)
 	}
 	// Drain remaining body for connection reuse
 	io.Copy(io.Discard, resp.Body)
 
-	return nil
+	return &Usage{Characters: usage.CharacterCount, Limit: usage.CharacterLimit}, nil
 }
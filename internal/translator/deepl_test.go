@@ -0,0 +1,72 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDeepLRequestFormality(t *testing.T) {
+	tests := []struct {
+		formality, glossary string
+		want                string
+	}{
+		{"more", "", `"formality":"more"`},
+		{"prefer_less", "", `"formality":"prefer_less"`},
+		{"default", "", ""},
+		{"", "", ""},
+		{"less", "gls-123", `"glossary_id":"gls-123"`},
+	}
+	for _, tt := range tests {
+		tr := NewDeepLTranslator("key", true, tt.formality, tt.glossary, nil)
+		body, err := json.Marshal(tr.request("Hello", tt.glossary))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.want == "" {
+			if strings.Contains(string(body), "formality") {
+				t.Errorf("formality %q: %s, want no formality field", tt.formality, body)
+			}
+			continue
+		}
+		if !strings.Contains(string(body), tt.want) {
+			t.Errorf("formality %q, glossary %q: %s, want %s", tt.formality, tt.glossary, body, tt.want)
+		}
+	}
+
+	// targets without formality support only get the prefer_* values
+	if got := deeplFormality("more", "EN-US"); got != "" {
+		t.Errorf("more for EN-US = %q, want it left out", got)
+	}
+	if got := deeplFormality("prefer_more", "EN-US"); got != "prefer_more" {
+		t.Errorf("prefer_more for EN-US = %q, want it kept", got)
+	}
+}
+
+func TestDeepLSendsFormalityAndGlossary(t *testing.T) {
+	var got deeplRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/translate" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(deeplResponse{Translations: []deeplTranslation{{Text: "Привет"}}})
+	}))
+	defer srv.Close()
+
+	tr := NewDeepLTranslator("key", true, "more", "gls-123", nil)
+	tr.host = srv.URL
+	// a translator.glossary is ignored in favour of deepl.glossary_id: no
+	// glossary lookup or creation requests
+	tr.SetGlossary(Glossary{{Source: "Ninja"}})
+	if _, err := tr.Translate(context.Background(), "Hello"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Formality != "more" || got.GlossaryID != "gls-123" || got.TargetLang != "RU" {
+		t.Errorf("request = %+v, want formality more and glossary gls-123", got)
+	}
+}
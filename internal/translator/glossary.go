@@ -0,0 +1,155 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// GlossaryEntry is a fixed translation for a term. An empty Target (or one
+// equal to Source) means the term is kept untranslated.
+type GlossaryEntry struct {
+	Source string
+	Target string
+}
+
+// Glossary is the list of terms with fixed translations (translator.glossary).
+// Terms are matched case-sensitively on word boundaries.
+type Glossary []GlossaryEntry
+
+// GlossaryTranslator is implemented by translators that can honour a glossary
+type GlossaryTranslator interface {
+	SetGlossary(g Glossary)
+}
+
+// glossaryPlaceholder matches the tokens inserted by Protect, tolerating the
+// case changes and spacing machine translation sometimes introduces
+var glossaryPlaceholder = regexp.MustCompile(`(?i)XGLS\s*(\d+)\s*X`)
+
+func (e GlossaryEntry) target() string {
+	if e.Target == "" {
+		return e.Source
+	}
+	return e.Target
+}
+
+// sorted returns the entries longest source first, so "KTM 790 Adventure"
+// is matched before "KTM"
+func (g Glossary) sorted() Glossary {
+	out := make(Glossary, 0, len(g))
+	for _, e := range g {
+		if e.Source != "" {
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return len(out[i].Source) > len(out[j].Source)
+	})
+	return out
+}
+
+// PromptSection renders the glossary as instructions for an LLM system
+// prompt; empty for an empty glossary
+func (g Glossary) PromptSection() string {
+	entries := g.sorted()
+	if len(entries) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Glossary. Always render these terms exactly as given; terms mapped to themselves must stay untranslated and must not be transliterated:\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "- %s → %s\n", e.Source, e.target())
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// appendPromptSection adds section to a system prompt, separated by a blank line
+func appendPromptSection(prompt, section string) string {
+	switch {
+	case section == "":
+		return prompt
+	case prompt == "":
+		return section
+	default:
+		return prompt + "\n\n" + section
+	}
+}
+
+// Protect replaces glossary terms in text with placeholders that machine
+// translation leaves alone. Restore puts the target terms back.
+func (g Glossary) Protect(text string) (string, []string) {
+	var terms []string
+	for _, e := range g.sorted() {
+		placeholder := "XGLS" + strconv.Itoa(len(terms)) + "X"
+		replaced := replaceTerm(text, e.Source, placeholder)
+		if replaced != text {
+			text = replaced
+			terms = append(terms, e.target())
+		}
+	}
+	return text, terms
+}
+
+// Restore replaces the placeholders inserted by Protect with their terms
+func (g Glossary) Restore(text string, terms []string) string {
+	if len(terms) == 0 {
+		return text
+	}
+	return glossaryPlaceholder.ReplaceAllStringFunc(text, func(m string) string {
+		i, err := strconv.Atoi(glossaryPlaceholder.FindStringSubmatch(m)[1])
+		if err != nil || i >= len(terms) {
+			return m
+		}
+		return terms[i]
+	})
+}
+
+// Apply replaces source terms left in text with their target terms
+func (g Glossary) Apply(text string) string {
+	for _, e := range g.sorted() {
+		if e.target() != e.Source {
+			text = replaceTerm(text, e.Source, e.target())
+		}
+	}
+	return text
+}
+
+// replaceTerm replaces whole-word occurrences of term in text with repl
+func replaceTerm(text, term, repl string) string {
+	if term == "" {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for from := 0; ; {
+		i := strings.Index(text[from:], term)
+		if i < 0 {
+			break
+		}
+		i += from
+		end := i + len(term)
+		from = end
+		if r, _ := utf8.DecodeLastRuneInString(text[:i]); i > 0 && isWordRune(r) {
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(r) {
+			continue
+		}
+		b.WriteString(text[last:i])
+		b.WriteString(repl)
+		last = end
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,90 @@
+package translator
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testGlossary = Glossary{
+	{Source: "KTM"},
+	{Source: "KTM 790 Adventure"},
+	{Source: "TFT dash", Target: "TFT-дисплей"},
+	{Source: "Öhlins", Target: "Öhlins"},
+}
+
+func TestGlossaryProtectRestore(t *testing.T) {
+	text := "The KTM 790 Adventure has Öhlins forks and a TFT dash; KTM says KTMs and SKTM differ."
+	protected, terms := testGlossary.Protect(text)
+
+	for _, term := range []string{"KTM 790 Adventure", "TFT dash", "Öhlins"} {
+		if strings.Contains(protected, term) {
+			t.Errorf("%q left in %q", term, protected)
+		}
+	}
+	// word boundaries: KTMs and SKTM are other words
+	if !strings.Contains(protected, "KTMs and SKTM") {
+		t.Errorf("partial words replaced: %q", protected)
+	}
+	// longest source first, so the model name is one term
+	if want := []string{"KTM 790 Adventure", "TFT-дисплей", "Öhlins", "KTM"}; !slices.Equal(terms, want) {
+		t.Errorf("terms = %q, want %q", terms, want)
+	}
+
+	// machine translation may change the case and spacing of placeholders
+	translated := strings.NewReplacer("XGLS0X", "xgls 0 x", "XGLS1X", "Xgls1X").Replace(protected)
+	want := "The KTM 790 Adventure has Öhlins forks and a TFT-дисплей; KTM says KTMs and SKTM differ."
+	if got := testGlossary.Restore(translated, terms); got != want {
+		t.Errorf("restored = %q, want %q", got, want)
+	}
+	if got := testGlossary.Restore("XGLS9X", terms); got != "XGLS9X" {
+		t.Errorf("unknown placeholder restored to %q", got)
+	}
+}
+
+func TestGlossaryApply(t *testing.T) {
+	got := testGlossary.Apply("Новый TFT dash и TFT dashboard")
+	if want := "Новый TFT-дисплей и TFT dashboard"; got != want {
+		t.Errorf("Apply = %q, want %q", got, want)
+	}
+	if got := Glossary(nil).Apply("TFT dash"); got != "TFT dash" {
+		t.Errorf("empty glossary changed the text: %q", got)
+	}
+}
+
+func TestGlossaryPromptSection(t *testing.T) {
+	if got := Glossary(nil).PromptSection(); got != "" {
+		t.Errorf("empty glossary prompt = %q", got)
+	}
+	section := testGlossary.PromptSection()
+	for _, line := range []string{"- KTM 790 Adventure → KTM 790 Adventure", "- TFT dash → TFT-дисплей"} {
+		if !strings.Contains(section, line) {
+			t.Errorf("prompt section has no %q:\n%s", line, section)
+		}
+	}
+	if got := appendPromptSection("Translate.", section); !strings.HasPrefix(got, "Translate.\n\nGlossary.") {
+		t.Errorf("prompt = %q", got)
+	}
+}
+
+func TestLibreTranslateGlossary(t *testing.T) {
+	var sent []any
+	tr := newLibreTranslateServer(t, func(q any) any {
+		sent = append(sent, q)
+		// a translation that lowercases the placeholders
+		return strings.ReplaceAll(strings.ReplaceAll(q.(string), "XGLS", "xgls"), "forks", "вилки")
+	})
+	tr.SetGlossary(testGlossary)
+
+	got, err := tr.Translate(context.Background(), "Öhlins forks on the KTM 790 Adventure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Öhlins вилки on the KTM 790 Adventure"; got != want {
+		t.Errorf("translation = %q, want %q", got, want)
+	}
+	if strings.Contains(sent[0].(string), "KTM") {
+		t.Errorf("glossary term sent to the translator: %q", sent[0])
+	}
+}
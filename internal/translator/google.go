@@ -0,0 +1,403 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	googleHost       = "https://translation.googleapis.com"
+	googleTokenURI   = "https://oauth2.googleapis.com/token"
+	googleTokenScope = "https://www.googleapis.com/auth/cloud-translation"
+	// googleMaxBatch is the most texts sent in one request (v3 allows 1024
+	// segments, v2 128)
+	googleMaxBatch = 100
+)
+
+// GoogleTranslator uses Google Cloud Translation. With an API key
+// (GOOGLE_TRANSLATE_API_KEY) it calls the v2 "basic" endpoint; with a
+// service account (GOOGLE_APPLICATION_CREDENTIALS) it calls v3, which needs
+// a project id (taken from the credentials file when not configured).
+type GoogleTranslator struct {
+	apiKey   string
+	project  string
+	location string
+	creds    *googleCredentials // nil in API key mode
+	host     string             // API base URL, googleHost outside tests
+	client   *http.Client
+	glossary Glossary
+
+	tokenMu     sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// googleCredentials is the part of a service account key file we need
+type googleCredentials struct {
+	Type        string `json:"type"`
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+
+	key *rsa.PrivateKey
+}
+
+type googleV2Request struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+}
+
+type googleV2Response struct {
+	Data struct {
+		Translations []struct {
+			TranslatedText string `json:"translatedText"`
+		} `json:"translations"`
+	} `json:"data"`
+}
+
+type googleV3Request struct {
+	Contents           []string `json:"contents"`
+	SourceLanguageCode string   `json:"sourceLanguageCode"`
+	TargetLanguageCode string   `json:"targetLanguageCode"`
+	MimeType           string   `json:"mimeType"`
+}
+
+type googleV3Response struct {
+	Translations []struct {
+		TranslatedText string `json:"translatedText"`
+	} `json:"translations"`
+}
+
+// NewGoogleTranslator creates a Google Cloud Translation client.
+// apiKey falls back to GOOGLE_TRANSLATE_API_KEY, credentialsFile to
+// GOOGLE_APPLICATION_CREDENTIALS; an API key wins when both are set.
+// location defaults to "global". transport may be nil (default transport).
+func NewGoogleTranslator(apiKey, credentialsFile, project, location string, transport http.RoundTripper) (*GoogleTranslator, error) {
+	if apiKey == "" {
+		apiKey = os.Getenv("GOOGLE_TRANSLATE_API_KEY")
+	}
+	if credentialsFile == "" {
+		credentialsFile = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if location == "" {
+		location = "global"
+	}
+
+	t := &GoogleTranslator{
+		apiKey:   apiKey,
+		project:  project,
+		location: location,
+		host:     googleHost,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Minute,
+		},
+	}
+	if apiKey != "" || credentialsFile == "" {
+		return t, nil
+	}
+
+	creds, err := loadGoogleCredentials(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	t.creds = creds
+	if t.project == "" {
+		t.project = creds.ProjectID
+	}
+	if t.project == "" {
+		return nil, fmt.Errorf("google: project_id is required for the v3 API (set translator.google.project_id)")
+	}
+	return t, nil
+}
+
+func loadGoogleCredentials(path string) (*googleCredentials, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("google: failed to read credentials: %w", err)
+	}
+	var creds googleCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("google: failed to parse credentials %s: %w", path, err)
+	}
+	if creds.Type != "service_account" {
+		return nil, fmt.Errorf("google: %s is not a service account key (type %q)", path, creds.Type)
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = googleTokenURI
+	}
+
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("google: no private key in %s", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("google: invalid private key in %s: %w", path, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("google: private key in %s is not RSA", path)
+	}
+	creds.key = key
+	return &creds, nil
+}
+
+func (t *GoogleTranslator) Name() string {
+	return "Google Translate"
+}
+
+// IsAvailable returns true if an API key or service account is configured
+func (t *GoogleTranslator) IsAvailable() bool {
+	return t.apiKey != "" || t.creds != nil
+}
+
+// SetGlossary makes glossary terms survive translation: they are swapped for
+// placeholders before the request and replaced with the target terms after
+func (t *GoogleTranslator) SetGlossary(g Glossary) {
+	t.glossary = g
+}
+
+// Translate translates article content EN -> RU
+func (t *GoogleTranslator) Translate(ctx context.Context, text string) (string, error) {
+	return t.translate(ctx, text)
+}
+
+// TranslateTitle translates a title EN -> RU
+func (t *GoogleTranslator) TranslateTitle(ctx context.Context, title string) (string, error) {
+	return t.translate(ctx, title)
+}
+
+func (t *GoogleTranslator) translate(ctx context.Context, text string) (string, error) {
+	results, err := t.TranslateBatch(ctx, []string{text})
+	if err != nil {
+		return "", err
+	}
+	if results[0] == "" && strings.TrimSpace(text) != "" {
+		return "", fmt.Errorf("google returned empty translation for non-empty input")
+	}
+	return results[0], nil
+}
+
+// TranslateBatch translates several texts, up to googleMaxBatch per request
+func (t *GoogleTranslator) TranslateBatch(ctx context.Context, texts []string) ([]string, error) {
+	if !t.IsAvailable() {
+		return nil, fmt.Errorf("Google Translate not configured (set GOOGLE_TRANSLATE_API_KEY or GOOGLE_APPLICATION_CREDENTIALS)")
+	}
+
+	results := make([]string, 0, len(texts))
+	for start := 0; start < len(texts); start += googleMaxBatch {
+		end := min(start+googleMaxBatch, len(texts))
+		protected := make([]string, end-start)
+		terms := make([][]string, end-start)
+		for i, text := range texts[start:end] {
+			protected[i], terms[i] = t.glossary.Protect(text)
+		}
+
+		translated, err := t.post(ctx, protected)
+		if err != nil {
+			return nil, err
+		}
+		if len(translated) != len(protected) {
+			return nil, fmt.Errorf("google returned %d translations for %d texts", len(translated), len(protected))
+		}
+		for i := range translated {
+			results = append(results, strings.TrimSpace(t.glossary.Apply(t.glossary.Restore(translated[i], terms[i]))))
+		}
+	}
+	return results, nil
+}
+
+// post translates texts with v2 (API key) or v3 (service account)
+func (t *GoogleTranslator) post(ctx context.Context, texts []string) ([]string, error) {
+	var out []string
+	if t.apiKey != "" {
+		var resp googleV2Response
+		endpoint := t.host + "/language/translate/v2?key=" + url.QueryEscape(t.apiKey)
+		req := googleV2Request{Q: texts, Source: "en", Target: "ru", Format: "text"}
+		if err := t.doJSON(ctx, "POST", endpoint, req, &resp); err != nil {
+			return nil, err
+		}
+		for _, tr := range resp.Data.Translations {
+			out = append(out, tr.TranslatedText)
+		}
+		return out, nil
+	}
+
+	var resp googleV3Response
+	req := googleV3Request{Contents: texts, SourceLanguageCode: "en", TargetLanguageCode: "ru", MimeType: "text/plain"}
+	if err := t.doJSON(ctx, "POST", t.v3URL(":translateText"), req, &resp); err != nil {
+		return nil, err
+	}
+	for _, tr := range resp.Translations {
+		out = append(out, tr.TranslatedText)
+	}
+	return out, nil
+}
+
+func (t *GoogleTranslator) v3URL(method string) string {
+	return fmt.Sprintf("%s/v3/projects/%s/locations/%s%s", t.host, url.PathEscape(t.project), url.PathEscape(t.location), method)
+}
+
+// doJSON sends a request with an optional JSON body, authenticated with
+// the service account token in v3 mode, and decodes the JSON response
+func (t *GoogleTranslator) doJSON(ctx context.Context, method, endpoint string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if t.creds != nil {
+		token, err := t.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// The v2 URL carries the API key; keep it out of logs
+		return fmt.Errorf("google request failed: %w", redactKey(err, t.apiKey))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return fmt.Errorf("google: access denied (check the API key or service account roles): %s", string(respBody))
+		case http.StatusTooManyRequests:
+			return fmt.Errorf("google: quota exceeded: %s", string(respBody))
+		default:
+			return fmt.Errorf("google returned status %d: %s", resp.StatusCode, string(respBody))
+		}
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode google response: %w", err)
+	}
+	return nil
+}
+
+// accessToken returns a cached OAuth token for the service account,
+// exchanging a freshly signed JWT when it is about to expire
+func (t *GoogleTranslator) accessToken(ctx context.Context) (string, error) {
+	t.tokenMu.Lock()
+	defer t.tokenMu.Unlock()
+	if t.token != "" && time.Until(t.tokenExpiry) > time.Minute {
+		return t.token, nil
+	}
+
+	assertion, err := t.creds.signJWT(time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", t.creds.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("google token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("google token endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode google token: %w", err)
+	}
+	t.token = token.AccessToken
+	t.tokenExpiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return t.token, nil
+}
+
+// signJWT builds the RS256-signed assertion for the OAuth JWT bearer flow
+func (c *googleCredentials) signJWT(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   c.ClientEmail,
+		"scope": googleTokenScope,
+		"aud":   c.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, c.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("google: failed to sign token request: %w", err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// redactKey masks the API key in an error (url.Error includes the URL)
+func redactKey(err error, key string) error {
+	escaped := url.QueryEscape(key)
+	if key == "" || !strings.Contains(err.Error(), escaped) {
+		return err
+	}
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), escaped, "REDACTED"))
+}
+
+// CheckConnection verifies the credentials by listing supported languages
+func (t *GoogleTranslator) CheckConnection(ctx context.Context) error {
+	if !t.IsAvailable() {
+		return fmt.Errorf("Google Translate not configured")
+	}
+	endpoint := t.v3URL("/supportedLanguages")
+	if t.apiKey != "" {
+		endpoint = t.host + "/language/translate/v2/languages?key=" + url.QueryEscape(t.apiKey)
+	}
+	if err := t.doJSON(ctx, "GET", endpoint, nil, nil); err != nil {
+		return fmt.Errorf("cannot connect to Google Translate: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package translator
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGoogle serves the token endpoint and both Translation API versions,
+// answering each text with "RU " + text
+type fakeGoogle struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu         sync.Mutex
+	tokens     int // token exchanges
+	paths      []string
+	batches    []int // texts per translate request
+	claims     map[string]any
+	auth       string // last Authorization header
+	apiKey     string // last v2 key parameter
+	dropResult bool   // answer one translation short
+}
+
+func newFakeGoogle(t *testing.T) *fakeGoogle {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeGoogle{key: key}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeGoogle) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths = append(f.paths, r.URL.Path)
+
+	switch {
+	case r.URL.Path == "/token":
+		if err := f.verifyAssertion(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		f.tokens++
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token-1", "expires_in": 3600})
+	case r.URL.Path == "/language/translate/v2":
+		f.apiKey = r.URL.Query().Get("key")
+		f.auth = r.Header.Get("Authorization")
+		var req googleV2Request
+		json.NewDecoder(r.Body).Decode(&req)
+		var resp googleV2Response
+		for _, q := range f.reply(req.Q) {
+			resp.Data.Translations = append(resp.Data.Translations, struct {
+				TranslatedText string `json:"translatedText"`
+			}{q})
+		}
+		json.NewEncoder(w).Encode(resp)
+	case strings.HasSuffix(r.URL.Path, ":translateText"):
+		f.auth = r.Header.Get("Authorization")
+		var req googleV3Request
+		json.NewDecoder(r.Body).Decode(&req)
+		var resp googleV3Response
+		for _, q := range f.reply(req.Contents) {
+			resp.Translations = append(resp.Translations, struct {
+				TranslatedText string `json:"translatedText"`
+			}{q})
+		}
+		json.NewEncoder(w).Encode(resp)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeGoogle) reply(texts []string) []string {
+	f.batches = append(f.batches, len(texts))
+	out := make([]string, len(texts))
+	for i, text := range texts {
+		out[i] = "RU " + text
+	}
+	if f.dropResult {
+		out = out[1:]
+	}
+	return out
+}
+
+// verifyAssertion checks the JWT bearer grant against the service account
+// public key and records its claims
+func (f *fakeGoogle) verifyAssertion(r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	if got := r.PostForm.Get("grant_type"); got != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		return fmt.Errorf("grant_type %q", got)
+	}
+	parts := strings.Split(r.PostForm.Get("assertion"), ".")
+	if len(parts) != 3 {
+		return errors.New("assertion is not a JWT")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&f.key.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+		return err
+	}
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(claims, &f.claims)
+}
+
+// credentialsFile writes a service account key for the fake's token endpoint
+func (f *fakeGoogle) credentialsFile(t *testing.T) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(f.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   "moto-project",
+		"client_email": "news@moto-project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    f.URL + "/token",
+	})
+	path := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGoogleV3ServiceAccount(t *testing.T) {
+	t.Setenv("GOOGLE_TRANSLATE_API_KEY", "")
+	f := newFakeGoogle(t)
+	tr, err := NewGoogleTranslator("", f.credentialsFile(t), "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.host = f.URL
+
+	texts := make([]string, googleMaxBatch*2+5)
+	for i := range texts {
+		texts[i] = "text"
+	}
+	got, err := tr.TranslateBatch(context.Background(), texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(texts) || got[0] != "RU text" {
+		t.Fatalf("got %d translations (first %q), want %d", len(got), got[0], len(texts))
+	}
+	if _, err := tr.Translate(context.Background(), "Hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []int{googleMaxBatch, googleMaxBatch, 5, 1}; !slices.Equal(f.batches, want) {
+		t.Errorf("batches = %v, want %v", f.batches, want)
+	}
+	if f.tokens != 1 {
+		t.Errorf("token exchanges = %d, want 1 (cached)", f.tokens)
+	}
+	if f.auth != "Bearer token-1" {
+		t.Errorf("Authorization = %q, want the exchanged token", f.auth)
+	}
+	if f.claims["iss"] != "news@moto-project.iam.gserviceaccount.com" || f.claims["aud"] != f.URL+"/token" || f.claims["scope"] != googleTokenScope {
+		t.Errorf("JWT claims = %v", f.claims)
+	}
+	if want := "/v3/projects/moto-project/locations/global:translateText"; f.paths[len(f.paths)-1] != want {
+		t.Errorf("path = %s, want %s", f.paths[len(f.paths)-1], want)
+	}
+}
+
+func TestGoogleV2APIKey(t *testing.T) {
+	f := newFakeGoogle(t)
+	// an API key wins over a service account
+	tr, err := NewGoogleTranslator("key&1", f.credentialsFile(t), "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.host = f.URL
+
+	got, err := tr.Translate(context.Background(), "Hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "RU Hello" {
+		t.Errorf("Translate = %q, want RU Hello", got)
+	}
+	if !slices.Equal(f.paths, []string{"/language/translate/v2"}) {
+		t.Errorf("requests = %v, want only the v2 endpoint", f.paths)
+	}
+	if f.apiKey != "key&1" || f.auth != "" {
+		t.Errorf("key = %q, Authorization = %q; want the key parameter and no token", f.apiKey, f.auth)
+	}
+}
+
+func TestGoogleCountMismatch(t *testing.T) {
+	f := newFakeGoogle(t)
+	f.dropResult = true
+	tr, err := NewGoogleTranslator("key", "", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.host = f.URL
+
+	_, err = tr.TranslateBatch(context.Background(), []string{"one", "two"})
+	if err == nil || !strings.Contains(err.Error(), "returned 1 translations for 2 texts") {
+		t.Errorf("err = %v, want a count mismatch", err)
+	}
+}
+
+func TestGoogleRedactsAPIKey(t *testing.T) {
+	f := newFakeGoogle(t)
+	f.Close() // connection refused: the url.Error carries the request URL
+	tr, err := NewGoogleTranslator("secret/key", "", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.host = f.URL
+
+	_, err = tr.Translate(context.Background(), "Hello")
+	if err == nil {
+		t.Fatal("request to a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), "secret") || !strings.Contains(err.Error(), "REDACTED") {
+		t.Errorf("err = %v, want the API key redacted", err)
+	}
+}
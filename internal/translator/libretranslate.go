@@ -12,26 +12,30 @@ import (
 )
 
 type LibreTranslateTranslator struct {
-	host   string
-	client *http.Client
+	host     string
+	client   *http.Client
+	glossary Glossary
 }
 
 type libreTranslateRequest struct {
-	Q      string `json:"q"`
-	Source string `json:"source"`
-	Target string `json:"target"`
-	Format string `json:"format"`
+	Q      interface{} `json:"q"` // string, or []string for a batch
+	Source string      `json:"source"`
+	Target string      `json:"target"`
+	Format string      `json:"format"`
 }
 
+// libreTranslateResponse.TranslatedText mirrors the request: a string for a
+// single q, an array for a batch
 type libreTranslateResponse struct {
-	TranslatedText string `json:"translatedText"`
+	TranslatedText json.RawMessage `json:"translatedText"`
 }
 
-func NewLibreTranslateTranslator(host string) *LibreTranslateTranslator {
+func NewLibreTranslateTranslator(host string, transport http.RoundTripper) *LibreTranslateTranslator {
 	return &LibreTranslateTranslator{
 		host: strings.TrimSuffix(host, "/"),
 		client: &http.Client{
-			Timeout: 2 * time.Minute,
+			Transport: transport,
+			Timeout:   2 * time.Minute,
 		},
 	}
 }
@@ -40,6 +44,12 @@ func (t *LibreTranslateTranslator) Name() string {
 	return "LibreTranslate"
 }
 
+// SetGlossary makes glossary terms survive translation: they are swapped for
+// placeholders before the request and replaced with the target terms after
+func (t *LibreTranslateTranslator) SetGlossary(g Glossary) {
+	t.glossary = g
+}
+
 func (t *LibreTranslateTranslator) Translate(ctx context.Context, text string) (string, error) {
 	return t.translate(ctx, text)
 }
@@ -49,8 +59,50 @@ func (t *LibreTranslateTranslator) TranslateTitle(ctx context.Context, title str
 }
 
 func (t *LibreTranslateTranslator) translate(ctx context.Context, text string) (string, error) {
+	protected, terms := t.glossary.Protect(text)
+	results, err := t.post(ctx, protected)
+	if err != nil {
+		return "", err
+	}
+	if len(results) != 1 {
+		return "", fmt.Errorf("libretranslate returned %d translations for 1 text", len(results))
+	}
+
+	translated := strings.TrimSpace(t.glossary.Apply(t.glossary.Restore(results[0], terms)))
+	if translated == "" && strings.TrimSpace(text) != "" {
+		return "", fmt.Errorf("libretranslate returned empty translation for non-empty input")
+	}
+	return translated, nil
+}
+
+// TranslateBatch translates several texts in one request (q as an array)
+func (t *LibreTranslateTranslator) TranslateBatch(ctx context.Context, texts []string) ([]string, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	protected := make([]string, len(texts))
+	terms := make([][]string, len(texts))
+	for i, text := range texts {
+		protected[i], terms[i] = t.glossary.Protect(text)
+	}
+	results, err := t.post(ctx, protected)
+	if err != nil {
+		return nil, err
+	}
+	// Older servers answer a single string when the batch has one element
+	if len(results) != len(texts) {
+		return nil, fmt.Errorf("libretranslate returned %d translations for %d texts", len(results), len(texts))
+	}
+	for i := range results {
+		results[i] = strings.TrimSpace(t.glossary.Apply(t.glossary.Restore(results[i], terms[i])))
+	}
+	return results, nil
+}
+
+// post sends q (a string or []string) and returns the translations in order
+func (t *LibreTranslateTranslator) post(ctx context.Context, q interface{}) ([]string, error) {
 	reqBody := libreTranslateRequest{
-		Q:      text,
+		Q:      q,
 		Source: "en",
 		Target: "ru",
 		Format: "text",
@@ -58,36 +110,40 @@ func (t *LibreTranslateTranslator) translate(ctx context.Context, text string) (
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", t.host+"/translate", bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := t.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("libretranslate returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("libretranslate returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	var result libreTranslateResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	translated := strings.TrimSpace(result.TranslatedText)
-	if translated == "" && strings.TrimSpace(text) != "" {
-		return "", fmt.Errorf("libretranslate returned empty translation for non-empty input")
+	var single string
+	if err := json.Unmarshal(result.TranslatedText, &single); err == nil {
+		return []string{single}, nil
 	}
-	return translated, nil
+	var many []string
+	if err := json.Unmarshal(result.TranslatedText, &many); err != nil {
+		return nil, fmt.Errorf("failed to decode translatedText: %w", err)
+	}
+	return many, nil
 }
 
 // CheckConnection verifies LibreTranslate is running
@@ -0,0 +1,92 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// newLibreTranslateServer answers /translate with reply(q), q being the
+// decoded request field (a string or a list)
+func newLibreTranslateServer(t *testing.T, reply func(q any) any) *LibreTranslateTranslator {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Q any `json:"q"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"translatedText": reply(req.Q)})
+	}))
+	t.Cleanup(srv.Close)
+	return NewLibreTranslateTranslator(srv.URL, nil)
+}
+
+// prefixAll answers each text of q prefixed with "RU ", in the shape of q
+func prefixAll(q any) any {
+	switch q := q.(type) {
+	case string:
+		return "RU " + q
+	case []any:
+		out := make([]string, len(q))
+		for i, text := range q {
+			out[i] = "RU " + text.(string)
+		}
+		return out
+	}
+	return nil
+}
+
+func TestLibreTranslateBatch(t *testing.T) {
+	var requests []any
+	tr := newLibreTranslateServer(t, func(q any) any {
+		requests = append(requests, q)
+		return prefixAll(q)
+	})
+
+	got, err := tr.TranslateBatch(context.Background(), []string{"first", "second", "third"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"RU first", "RU second", "RU third"}; !slices.Equal(got, want) {
+		t.Errorf("batch = %q, want %q", got, want)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("%d requests for one batch, want 1", len(requests))
+	}
+	if _, ok := requests[0].([]any); !ok {
+		t.Errorf("batch q = %#v, want a list", requests[0])
+	}
+
+	title, err := tr.TranslateTitle(context.Background(), "single")
+	if err != nil || title != "RU single" {
+		t.Errorf("title = %q (%v), want %q", title, err, "RU single")
+	}
+	if _, ok := requests[1].(string); !ok {
+		t.Errorf("single q = %#v, want a string", requests[1])
+	}
+}
+
+func TestLibreTranslateBatchResponseShapes(t *testing.T) {
+	// older servers answer a one-element batch with a plain string
+	tr := newLibreTranslateServer(t, func(q any) any { return "RU only" })
+	if got, err := tr.TranslateBatch(context.Background(), []string{"only"}); err != nil || !slices.Equal(got, []string{"RU only"}) {
+		t.Errorf("one-element batch = %q (%v), want [RU only]", got, err)
+	}
+
+	// a short answer cannot be matched to the texts
+	tr = newLibreTranslateServer(t, func(q any) any { return []string{"RU first"} })
+	if got, err := tr.TranslateBatch(context.Background(), []string{"first", "second"}); err == nil {
+		t.Errorf("batch = %q, want an error for 1 translation of 2 texts", got)
+	}
+
+	tr = newLibreTranslateServer(t, func(q any) any { return map[string]string{"text": "?"} })
+	if _, err := tr.TranslateBatch(context.Background(), []string{"first", "second"}); err == nil {
+		t.Error("expected an error for an object translatedText")
+	}
+}
@@ -20,8 +20,16 @@ type OllamaTranslator struct {
 	topP        float64
 	numCtx      int
 	client      *http.Client
+	glossary    string // glossary instructions appended to both system prompts
+	endpoint    string // OllamaEndpointChat or OllamaEndpointGenerate
 }
 
+// Ollama APIs a translation can be requested from (ollama.endpoint)
+const (
+	OllamaEndpointChat     = "chat"     // /api/chat with system and user messages
+	OllamaEndpointGenerate = "generate" // /api/generate with one prompt, for models that ignore chat roles
+)
+
 // --- Chat API types ---
 
 type chatMessage struct {
@@ -47,7 +55,21 @@ type ollamaChatResponse struct {
 	Done    bool        `json:"done"`
 }
 
-func NewOllamaTranslator(host, model, prompt, titlePrompt string, temperature, topP float64, numCtx int) *OllamaTranslator {
+// --- Generate API types ---
+
+type ollamaGenerateRequest struct {
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	Stream  bool           `json:"stream"`
+	Options *ollamaOptions `json:"options,omitempty"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+}
+
+func NewOllamaTranslator(host, model, prompt, titlePrompt string, temperature, topP float64, numCtx int, transport http.RoundTripper) *OllamaTranslator {
 	return &OllamaTranslator{
 		host:        strings.TrimSuffix(host, "/"),
 		model:       model,
@@ -57,18 +79,34 @@ func NewOllamaTranslator(host, model, prompt, titlePrompt string, temperature, t
 		topP:        topP,
 		numCtx:      numCtx,
 		client: &http.Client{
-			Timeout: 30 * time.Minute, // Long timeout for large models on CPU
+			Transport: transport,
+			Timeout:   30 * time.Minute, // Long timeout for large models on CPU
 		},
+		endpoint: OllamaEndpointChat,
 	}
 }
 
+// SetEndpoint selects the Ollama API used for translations: "chat" (the
+// default) or "generate"
+func (t *OllamaTranslator) SetEndpoint(endpoint string) {
+	if endpoint == "" {
+		endpoint = OllamaEndpointChat
+	}
+	t.endpoint = endpoint
+}
+
+// SetGlossary adds the glossary terms to the system prompts
+func (t *OllamaTranslator) SetGlossary(g Glossary) {
+	t.glossary = g.PromptSection()
+}
+
 func (t *OllamaTranslator) Name() string {
 	return fmt.Sprintf("Ollama (%s)", t.model)
 }
 
 // Translate translates article content using the main system prompt
 func (t *OllamaTranslator) Translate(ctx context.Context, text string) (string, error) {
-	return t.chat(ctx, t.prompt, text)
+	return t.complete(ctx, t.prompt, text)
 }
 
 // TranslateTitle translates an article title using a dedicated title prompt
@@ -77,59 +115,83 @@ func (t *OllamaTranslator) TranslateTitle(ctx context.Context, title string) (st
 	if systemPrompt == "" {
 		systemPrompt = t.prompt
 	}
-	return t.chat(ctx, systemPrompt, title)
+	return t.complete(ctx, systemPrompt, title)
 }
 
-// chat sends a request to Ollama /api/chat with system + user messages
-func (t *OllamaTranslator) chat(ctx context.Context, systemPrompt, userContent string) (string, error) {
-	messages := []chatMessage{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: userContent},
+// complete sends the system prompt and the text to the configured endpoint
+func (t *OllamaTranslator) complete(ctx context.Context, systemPrompt, userContent string) (string, error) {
+	systemPrompt = appendPromptSection(systemPrompt, t.glossary)
+	options := &ollamaOptions{
+		Temperature: t.temperature,
+		TopP:        t.topP,
+		NumCtx:      t.numCtx,
 	}
 
-	reqBody := ollamaChatRequest{
-		Model:    t.model,
-		Messages: messages,
-		Stream:   false,
-		Options: &ollamaOptions{
-			Temperature: t.temperature,
-			TopP:        t.topP,
-			NumCtx:      t.numCtx,
-		},
+	var content string
+	var err error
+	if t.endpoint == OllamaEndpointGenerate {
+		// One prompt: the instructions, then the text to translate
+		var result ollamaGenerateResponse
+		err = t.post(ctx, "/api/generate", ollamaGenerateRequest{
+			Model:   t.model,
+			Prompt:  systemPrompt + "\n\n" + userContent,
+			Stream:  false,
+			Options: options,
+		}, &result)
+		content = result.Response
+	} else {
+		var result ollamaChatResponse
+		err = t.post(ctx, "/api/chat", ollamaChatRequest{
+			Model: t.model,
+			Messages: []chatMessage{
+				{Role: "system", Content: systemPrompt},
+				{Role: "user", Content: userContent},
+			},
+			Stream:  false,
+			Options: options,
+		}, &result)
+		content = result.Message.Content
+	}
+	if err != nil {
+		return "", err
 	}
 
+	content = strings.TrimSpace(content)
+	if content == "" && strings.TrimSpace(userContent) != "" {
+		return "", fmt.Errorf("ollama returned empty translation for non-empty input")
+	}
+	return content, nil
+}
+
+// post sends reqBody as JSON to the Ollama API path and decodes the reply
+// into result
+func (t *OllamaTranslator) post(ctx context.Context, path string, reqBody, result interface{}) error {
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", t.host+"/api/chat", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", t.host+path, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := t.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result ollamaChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	content := strings.TrimSpace(result.Message.Content)
-	if content == "" && strings.TrimSpace(userContent) != "" {
-		return "", fmt.Errorf("ollama returned empty translation for non-empty input")
-	}
-	return content, nil
+	return nil
 }
 
 // CheckConnection verifies Ollama is running and the model is available
@@ -0,0 +1,88 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newFakeOllama serves /api/chat and /api/generate, echoing the user text
+// (or the whole prompt) back with a "RU:" prefix, and records the path and
+// body of every request
+func newFakeOllama(t *testing.T) (*httptest.Server, *[]string, *[]map[string]any) {
+	var paths []string
+	var bodies []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		paths = append(paths, r.URL.Path)
+		bodies = append(bodies, body)
+		switch r.URL.Path {
+		case "/api/chat":
+			messages := body["messages"].([]any)
+			user := messages[len(messages)-1].(map[string]any)["content"].(string)
+			json.NewEncoder(w).Encode(ollamaChatResponse{Message: chatMessage{Role: "assistant", Content: " RU:" + user + "\n"}, Done: true})
+		case "/api/generate":
+			json.NewEncoder(w).Encode(ollamaGenerateResponse{Response: "RU:" + body["prompt"].(string), Done: true})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &paths, &bodies
+}
+
+func TestOllamaEndpoints(t *testing.T) {
+	srv, paths, bodies := newFakeOllama(t)
+	tr := NewOllamaTranslator(srv.URL+"/", "llama3", "Translate to Russian.", "Translate the title.", 0.2, 0, 4096, nil)
+
+	// chat (the default): system and user messages, content trimmed
+	got, err := tr.Translate(context.Background(), "Hello")
+	if err != nil || got != "RU:Hello" {
+		t.Errorf("chat Translate = %q (%v), want %q", got, err, "RU:Hello")
+	}
+	if len(*paths) != 1 || (*paths)[0] != "/api/chat" {
+		t.Fatalf("chat requests = %v, want /api/chat", *paths)
+	}
+	body := (*bodies)[0]
+	messages := body["messages"].([]any)
+	if len(messages) != 2 || messages[0].(map[string]any)["role"] != "system" || messages[0].(map[string]any)["content"] != "Translate to Russian." {
+		t.Errorf("chat messages = %v, want the system prompt then the text", messages)
+	}
+	if body["model"] != "llama3" || body["stream"] != false || body["options"].(map[string]any)["num_ctx"] != float64(4096) {
+		t.Errorf("chat body = %v, want model, no streaming and the options", body)
+	}
+
+	// generate: one prompt, the instructions before the text
+	tr.SetEndpoint(OllamaEndpointGenerate)
+	got, err = tr.TranslateTitle(context.Background(), "Big news")
+	if want := "RU:Translate the title.\n\nBig news"; err != nil || got != want {
+		t.Errorf("generate TranslateTitle = %q (%v), want %q", got, err, want)
+	}
+	if len(*paths) != 2 || (*paths)[1] != "/api/generate" {
+		t.Fatalf("requests = %v, want /api/generate second", *paths)
+	}
+	if body := (*bodies)[1]; body["messages"] != nil || body["model"] != "llama3" || body["stream"] != false {
+		t.Errorf("generate body = %v, want a prompt without messages", body)
+	}
+
+	// "" goes back to chat
+	tr.SetEndpoint("")
+	if _, err := tr.Translate(context.Background(), "Hi"); err != nil || (*paths)[2] != "/api/chat" {
+		t.Errorf("empty endpoint: requests = %v (%v), want /api/chat", *paths, err)
+	}
+}
+
+func TestOllamaEmptyReply(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ollamaGenerateResponse{Response: "  ", Done: true})
+	}))
+	defer srv.Close()
+	tr := NewOllamaTranslator(srv.URL, "llama3", "Translate.", "", 0, 0, 0, nil)
+	tr.SetEndpoint(OllamaEndpointGenerate)
+	if got, err := tr.Translate(context.Background(), "Hello"); err == nil {
+		t.Errorf("Translate = %q, want an error for an empty reply", got)
+	}
+}
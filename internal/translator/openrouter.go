@@ -22,6 +22,7 @@ type OpenRouterTranslator struct {
 	titlePrompt string
 	temperature float64
 	client      *http.Client
+	glossary    string // glossary instructions appended to both system prompts
 }
 
 // OpenRouter request/response (OpenAI-compatible chat completions)
@@ -42,7 +43,7 @@ type openRouterResponse struct {
 	} `json:"choices"`
 }
 
-func NewOpenRouterTranslator(baseURL, model, apiKey, prompt, titlePrompt string, temperature float64) *OpenRouterTranslator {
+func NewOpenRouterTranslator(baseURL, model, apiKey, prompt, titlePrompt string, temperature float64, transport http.RoundTripper) *OpenRouterTranslator {
 	if apiKey == "" {
 		apiKey = os.Getenv("OPENROUTER_API_KEY")
 	}
@@ -58,11 +59,17 @@ func NewOpenRouterTranslator(baseURL, model, apiKey, prompt, titlePrompt string,
 		titlePrompt: titlePrompt,
 		temperature: temperature,
 		client: &http.Client{
-			Timeout: 3 * time.Minute,
+			Transport: transport,
+			Timeout:   3 * time.Minute,
 		},
 	}
 }
 
+// SetGlossary adds the glossary terms to the system prompts
+func (t *OpenRouterTranslator) SetGlossary(g Glossary) {
+	t.glossary = g.PromptSection()
+}
+
 func (t *OpenRouterTranslator) Name() string {
 	return fmt.Sprintf("OpenRouter (%s)", t.model)
 }
@@ -89,7 +96,7 @@ func (t *OpenRouterTranslator) chat(ctx context.Context, systemPrompt, userConte
 	}
 
 	messages := []openRouterMessage{
-		{Role: "system", Content: systemPrompt},
+		{Role: "system", Content: appendPromptSection(systemPrompt, t.glossary)},
 		{Role: "user", Content: userContent},
 	}
 
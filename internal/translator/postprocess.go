@@ -0,0 +1,165 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PostRule is a regexp replacement run over translator output
+// (translator.postprocess.rules). Replace may refer to groups as $1 or ${name}.
+type PostRule struct {
+	Pattern string
+	Replace string
+}
+
+type compiledRule struct {
+	re      *regexp.Regexp
+	replace string
+}
+
+// PostProcessor fixes common machine translation artifacts in Russian
+// output: the built-in fixes first, then the configured rules in order.
+// A nil *PostProcessor leaves text unchanged.
+type PostProcessor struct {
+	builtin bool
+	rules   []compiledRule
+}
+
+// NewPostProcessor compiles the rules; builtin enables the built-in fixes
+// (guillemets, spacing, a dangling "Читать далее")
+func NewPostProcessor(builtin bool, rules []PostRule) (*PostProcessor, error) {
+	p := &PostProcessor{builtin: builtin}
+	for i, r := range rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("postprocess rule %d: %w", i, err)
+		}
+		p.rules = append(p.rules, compiledRule{re: re, replace: r.Replace})
+	}
+	return p, nil
+}
+
+// Apply returns text with all fixes applied
+func (p *PostProcessor) Apply(text string) string {
+	if p == nil || text == "" {
+		return text
+	}
+	if p.builtin {
+		text = fixQuotes(text)
+		text = fixSpacing(text)
+		text = readMoreTail.ReplaceAllString(text, "")
+		text = strings.TrimSpace(extraBlankLines.ReplaceAllString(text, "\n\n"))
+	}
+	for _, r := range p.rules {
+		text = r.re.ReplaceAllString(text, r.replace)
+	}
+	return text
+}
+
+var (
+	// readMoreTail is a last paragraph that only says "read more", often
+	// with the link it had on the source page
+	readMoreTail = regexp.MustCompile(`(?i)(^|\n)\s*\[?(читать далее|читать полностью|читать статью|подробнее|read more|continue reading)\]?(\([^)\n]*\))?[\s.:…→»>]*$`)
+	// extraBlankLines are runs of more than one blank line
+	extraBlankLines = regexp.MustCompile(`\n[ \t]*\n([ \t]*\n)+`)
+	// repeatedSpaces are runs of spaces and tabs after a non-space
+	repeatedSpaces = regexp.MustCompile(`(\S)[ \t]{2,}`)
+	// spaceBeforePunct is whitespace before closing punctuation
+	spaceBeforePunct = regexp.MustCompile(`(\S)[ \t]+([,.;:!?»)])`)
+	// spaceAfterOpening is whitespace after an opening guillemet or parenthesis
+	spaceAfterOpening = regexp.MustCompile(`([«(])[ \t]+`)
+)
+
+// fixSpacing collapses repeated spaces, removes spaces before closing and
+// after opening punctuation and trailing spaces; indentation is kept.
+// Lines inside fenced code blocks and with inline code are left alone.
+func fixSpacing(text string) string {
+	lines := strings.Split(text, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || strings.Contains(line, "`") {
+			continue
+		}
+		line = repeatedSpaces.ReplaceAllString(line, "$1 ")
+		line = spaceBeforePunct.ReplaceAllString(line, "$1$2")
+		line = spaceAfterOpening.ReplaceAllString(line, "$1")
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fixQuotes turns "straight" and “curly” double quotes into «guillemets»,
+// line by line. A quote opens after whitespace or an opening bracket and
+// closes before whitespace or punctuation, so inch marks (17" wheels) stay.
+// Lines with unbalanced quotes, „German-style“ nested quotes, code, HTML
+// or link targets keep their quotes.
+func fixQuotes(text string) string {
+	lines := strings.Split(text, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || strings.ContainsAny(line, "`<„") || strings.Contains(line, "](") {
+			continue
+		}
+		lines[i] = quoteLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func quoteLine(line string) string {
+	if !strings.ContainsAny(line, "\"“”") {
+		return line
+	}
+	var b strings.Builder
+	open := false
+	var prev rune = ' '
+	for i, r := range line {
+		if r != '"' && r != '“' && r != '”' {
+			b.WriteRune(r)
+			prev = r
+			continue
+		}
+		next, _ := utf8.DecodeRuneInString(line[i+utf8.RuneLen(r):])
+		switch {
+		case !open && r != '”' && opensQuote(prev, next):
+			b.WriteRune('«')
+			open = true
+		case open && r != '“' && closesQuote(prev, next):
+			b.WriteRune('»')
+			open = false
+		default:
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	if open {
+		return line
+	}
+	return b.String()
+}
+
+// opensQuote reports whether a quote between prev and next starts a quotation
+func opensQuote(prev, next rune) bool {
+	if next == utf8.RuneError || unicode.IsSpace(next) {
+		return false
+	}
+	return unicode.IsSpace(prev) || strings.ContainsRune("([{—–-/«", prev)
+}
+
+// closesQuote reports whether a quote between prev and next ends a quotation
+func closesQuote(prev, next rune) bool {
+	if unicode.IsSpace(prev) {
+		return false
+	}
+	return next == utf8.RuneError || unicode.IsSpace(next) || strings.ContainsRune(".,;:!?)]}…—–-»", next)
+}
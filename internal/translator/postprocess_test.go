@@ -0,0 +1,63 @@
+package translator
+
+import "testing"
+
+func TestPostProcessorBuiltin(t *testing.T) {
+	p, err := NewPostProcessor(true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, in, want string
+	}{
+		{"straight quotes", `Модель "Africa Twin" обновили.`, `Модель «Africa Twin» обновили.`},
+		{"curly quotes", `Он назвал её “лучшей в классе”.`, `Он назвал её «лучшей в классе».`},
+		{"inch marks kept", `Колёса 17" и 19" спереди.`, `Колёса 17" и 19" спереди.`},
+		{"unbalanced quotes kept", `Слово "без пары.`, `Слово "без пары.`},
+		{"link targets kept", `См. [обзор](https://example.com/"a") "тут".`, `См. [обзор](https://example.com/"a") "тут".`},
+		{"spacing", "Мотоцикл  стал   легче , а мощность ( 95 л.с. ) выросла .  ", "Мотоцикл стал легче, а мощность (95 л.с.) выросла."},
+		{"indentation kept", "Список:\n  - пункт  один", "Список:\n  - пункт один"},
+		{"code kept", "```\nx  =  \"a\"\n```\nКод `a  b` тут.", "```\nx  =  \"a\"\n```\nКод `a  b` тут."},
+		{"read more tail", "Текст статьи.\n\n[Читать далее](https://example.com/story) →", "Текст статьи."},
+		{"read more mid-text kept", "Подробнее о модели рассказали в Милане.", "Подробнее о модели рассказали в Милане."},
+		{"blank lines", "Первый абзац.\n\n\n\nВторой абзац.", "Первый абзац.\n\nВторой абзац."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Apply(tt.in); got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostProcessorRules(t *testing.T) {
+	p, err := NewPostProcessor(true, []PostRule{
+		{Pattern: `(?i)\bmotorcycle\b`, Replace: "мотоцикл"},
+		// runs after the built-in fixes: sees the guillemets
+		{Pattern: `«(\w+)»`, Replace: "«$1™»"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.Apply(`Новый Motorcycle  "Tenere".`), "Новый мотоцикл «Tenere™»."; got != want {
+		t.Errorf("Apply = %q, want %q", got, want)
+	}
+
+	// rules alone, without the built-in fixes
+	p, err = NewPostProcessor(false, []PostRule{{Pattern: `Читать далее$`, Replace: ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.Apply(`Текст  "как есть". Читать далее`), `Текст  "как есть". `; got != want {
+		t.Errorf("without builtin: Apply = %q, want %q", got, want)
+	}
+
+	if _, err := NewPostProcessor(true, []PostRule{{Pattern: "("}}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+	var none *PostProcessor
+	if got := none.Apply(`"как есть"`); got != `"как есть"` {
+		t.Errorf("nil processor changed the text: %q", got)
+	}
+}
@@ -13,3 +13,27 @@ type Translator interface {
 	// Name returns the translator name
 	Name() string
 }
+
+// ConnectionChecker is implemented by translators that can cheaply verify
+// the provider is reachable and the credentials or model are valid
+type ConnectionChecker interface {
+	CheckConnection(ctx context.Context) error
+}
+
+// UsageReporter is implemented by translators whose provider reports the
+// characters used in the current billing period
+type UsageReporter interface {
+	Usage(ctx context.Context) (*Usage, error)
+}
+
+// Usage is the provider's character count for the current billing period
+type Usage struct {
+	Characters int64 `json:"characters"`
+	Limit      int64 `json:"limit"` // 0 = the provider reported no limit
+}
+
+// BatchTranslator is implemented by translators that can translate several
+// texts in one request. Results are in input order.
+type BatchTranslator interface {
+	TranslateBatch(ctx context.Context, texts []string) ([]string, error)
+}